@@ -20,6 +20,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	goruntime "runtime"
 	"strings"
@@ -39,7 +40,9 @@ import (
 	"github.com/planetscale/operator-sdk-libs/pkg/leader"
 
 	"planetscale.dev/vitess-operator/pkg/operator/controllermanager"
+	"planetscale.dev/vitess-operator/pkg/operator/etcdsnapshot"
 	"planetscale.dev/vitess-operator/pkg/operator/fork"
+	"planetscale.dev/vitess-operator/pkg/operator/toposerver"
 	"planetscale.dev/vitess-operator/version"
 )
 
@@ -69,6 +72,15 @@ func main() {
 
 	printVersion()
 
+	// Some forks run a single task to completion rather than a manager.
+	if forkPath == etcdsnapshot.ForkPath {
+		if err := etcdsnapshot.Run(signals.SetupSignalHandler()); err != nil {
+			log.Error(err, "Failed to transfer etcd snapshot")
+			os.Exit(1)
+		}
+		return
+	}
+
 	namespace, err := k8sutil.GetWatchNamespace()
 	if err != nil {
 		log.Error(err, "Failed to get watch namespace")
@@ -100,6 +112,9 @@ func main() {
 		},
 		Metrics: server.Options{
 			BindAddress: fmt.Sprintf("%s:%d", metricsHost, metricsPort),
+			ExtraHandlers: map[string]http.Handler{
+				toposerver.DebugPath: toposerver.DebugHandler(),
+			},
 		},
 	}
 
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+func DefaultConsulLockserver(ls *ConsulLockserver) {
+	DefaultConsulLockserverTemplate(&ls.Spec.ConsulLockserverTemplate)
+}
+
+func DefaultConsulLockserverTemplate(ls *ConsulLockserverTemplate) {
+	if ls.Image == "" {
+		ls.Image = DefaultConsulImage
+	}
+	if ls.Replicas == nil {
+		ls.Replicas = ptr.To(int32(defaultConsulReplicas))
+	}
+	defaultManagedLockserverStorage(&ls.DataVolumeClaimTemplate)
+	defaultManagedLockserverResources(&ls.Resources)
+	if ls.CreatePDB == nil {
+		ls.CreatePDB = ptr.To(defaultConsulCreatePDB)
+	}
+	DefaultServiceOverrides(&ls.ClientService)
+}
+
+// defaultManagedLockserverStorage fills in the data volume defaults that
+// Consul and ZooKeeper share with etcd.
+func defaultManagedLockserverStorage(pvc *corev1.PersistentVolumeClaimSpec) {
+	if len(pvc.AccessModes) == 0 {
+		pvc.AccessModes = []corev1.PersistentVolumeAccessMode{
+			corev1.ReadWriteOnce,
+		}
+	}
+	if _, isSet := pvc.Resources.Requests[corev1.ResourceStorage]; !isSet {
+		if pvc.Resources.Requests == nil {
+			pvc.Resources.Requests = make(corev1.ResourceList)
+		}
+		pvc.Resources.Requests[corev1.ResourceStorage] = *resource.NewQuantity(defaultEtcdStorageRequestBytes, resource.BinarySI)
+	}
+}
+
+// defaultManagedLockserverResources fills in the compute resource defaults
+// that Consul and ZooKeeper share with etcd.
+func defaultManagedLockserverResources(resources *corev1.ResourceRequirements) {
+	if len(resources.Requests) == 0 {
+		resources.Requests = corev1.ResourceList{
+			corev1.ResourceCPU:    *resource.NewMilliQuantity(defaultEtcdCPUMillis, resource.DecimalSI),
+			corev1.ResourceMemory: *resource.NewQuantity(defaultEtcdMemoryBytes, resource.BinarySI),
+		}
+	}
+	if len(resources.Limits) == 0 {
+		resources.Limits = corev1.ResourceList{
+			corev1.ResourceMemory: *resource.NewQuantity(defaultEtcdMemoryBytes, resource.BinarySI),
+		}
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient
+
+// ConsulLockserver runs a cluster of Consul servers for use as a Vitess
+// lockserver. Like EtcdLockserver, each server has its own PVC, so servers
+// come back with their data after being rescheduled.
+// +kubebuilder:resource:path=consullockservers,shortName=consulls
+// +kubebuilder:subresource:status
+type ConsulLockserver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ConsulLockserverSpec   `json:"spec,omitempty"`
+	Status ConsulLockserverStatus `json:"status,omitempty"`
+}
+
+// ConsulLockserverSpec defines the desired state of a ConsulLockserver.
+type ConsulLockserverSpec struct {
+	// ConsulLockserverTemplate contains the user-specified parts of ConsulLockserverSpec.
+	// These are the parts that are configurable inside VitessCluster.
+	// The rest of the fields below are filled in by the parent controller.
+	ConsulLockserverTemplate `json:",inline"`
+
+	// Zone is the name of the Availability Zone that this lockserver should run in.
+	// This value should match the value of the "failure-domain.beta.kubernetes.io/zone"
+	// label on the Kubernetes Nodes in that AZ.
+	// If the Kubernetes Nodes don't have such a label, leave this empty.
+	Zone string `json:"zone,omitempty"`
+}
+
+// ConsulLockserverTemplate defines the user-configurable settings for a
+// Consul cluster that we deploy (not external), to serve as either a local
+// or global lockserver.
+type ConsulLockserverTemplate struct {
+	// Image is the Consul server image (including version tag) to deploy.
+	// Default: Let the operator choose.
+	Image string `json:"image,omitempty"`
+
+	// ImagePullPolicy specifies if/when to pull a container image.
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets specifies the container image pull secrets to add to all
+	// Consul Pods.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Replicas is the number of Consul servers. The cluster stays available
+	// as long as a majority of servers are available.
+	//
+	// Changing this adds or removes servers, and restarts the others as part
+	// of a rolling update so they expect the new number of servers. Consul's
+	// autopilot cleans up servers that were removed.
+	//
+	// Default: 3
+	// +kubebuilder:validation:Enum=1;3;5
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources specify the compute resources to allocate for each Consul server.
+	// Default: Let the operator choose.
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// DataVolumeClaimTemplate configures the PersistentVolumeClaims that will be created
+	// for each Consul server to store its data files.
+	// Default: Let the operator choose.
+	DataVolumeClaimTemplate corev1.PersistentVolumeClaimSpec `json:"dataVolumeClaimTemplate,omitempty"`
+
+	// ExtraFlags can optionally be used to override default flags set by the
+	// operator, or pass additional flags to "consul agent". All entries must
+	// be key-value string pairs of the form "flag": "value". The flag name
+	// should not have any prefix (just "flag", not "-flag").
+	ExtraFlags map[string]string `json:"extraFlags,omitempty"`
+
+	// ExtraEnv can optionally be used to override default environment variables
+	// set by the operator, or pass additional environment variables.
+	ExtraEnv []corev1.EnvVar `json:"extraEnv,omitempty"`
+
+	// Affinity allows you to set rules that constrain the scheduling of
+	// your Consul pods. WARNING: These affinity rules will override all default
+	// affinities that we set.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Annotations can optionally be used to attach custom annotations to Pods
+	// created for this component.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// ExtraLabels can optionally be used to attach custom labels to Pods
+	// created for this component.
+	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
+
+	// Tolerations allow you to schedule pods onto nodes with matching taints.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// CreatePDB sets whether to create a PodDisruptionBudget (PDB) for Consul
+	// server Pods.
+	//
+	// Note: Disabling this will NOT delete a PDB that was previously created.
+	//
+	// Default: true
+	CreatePDB *bool `json:"createPDB,omitempty"`
+
+	// ClientService can optionally be used to customize the Consul client Service.
+	ClientService *ServiceOverrides `json:"clientService,omitempty"`
+}
+
+// ConsulLockserverStatus defines the observed state of a ConsulLockserver.
+type ConsulLockserverStatus struct {
+	// The generation observed by the controller.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Available is a condition that indicates whether the cluster is able to serve queries.
+	Available corev1.ConditionStatus `json:"available,omitempty"`
+	// ClientServiceName is the name of the Service for Consul client connections.
+	ClientServiceName string `json:"clientServiceName,omitempty"`
+}
+
+// NewConsulLockserverStatus returns a new status with default values.
+func NewConsulLockserverStatus() *ConsulLockserverStatus {
+	return &ConsulLockserverStatus{
+		Available: corev1.ConditionUnknown,
+	}
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ConsulLockserverList contains a list of ConsulLockserver
+type ConsulLockserverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ConsulLockserver `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ConsulLockserver{}, &ConsulLockserverList{})
+}
@@ -16,6 +16,10 @@ limitations under the License.
 
 package v2
 
+import (
+	"time"
+)
+
 /*
 All hard-coded default values for configurable aspects of objects in our API should go here.
 However, hard-coded values that are not (yet) configurable in the API can live with the code.
@@ -56,6 +60,7 @@ const (
 	// Gi is the scale factor for Gibi (2**30)
 	Gi = 1 << 30
 
+	defaultEtcdReplicas            = 3
 	defaultEtcdStorageRequestBytes = 1 * Gi
 	defaultEtcdCPUMillis           = 100
 	defaultEtcdMemoryBytes         = 256 * Mi
@@ -63,6 +68,20 @@ const (
 	defaultEtcdCreateClientService = true
 	defaultEtcdCreatePeerService   = true
 
+	defaultEtcdMemberUnhealthyTimeout = 10 * time.Minute
+
+	defaultConsulReplicas  = 3
+	defaultConsulCreatePDB = true
+	defaultZkReplicas      = 3
+	defaultZkCreatePDB     = true
+
+	defaultEtcdAutoCompactionMode      = "revision"
+	defaultEtcdAutoCompactionRetention = "1000"
+	defaultEtcdDefragThresholdPercent  = 50
+
+	defaultCreatePDB         = true
+	defaultPDBMaxUnavailable = 1
+
 	defaultVtctldReplicas    = 1
 	defaultVtctldCPUMillis   = 100
 	defaultVtctldMemoryBytes = 128 * Mi
@@ -76,15 +95,29 @@ const (
 	defaultVtorcCPUMillis   = 100
 	defaultVtorcMemoryBytes = 128 * Mi
 
-	defaultVtgateReplicas    = 2
-	defaultVtgateCPUMillis   = 500
-	defaultVtgateMemoryBytes = 1 * Gi
+	defaultVtgateReplicas     = 2
+	defaultVtgatePoolReplicas = 1
+	defaultVtgateCPUMillis    = 500
+	defaultVtgateMemoryBytes  = 1 * Gi
+
+	defaultStaticAuthReloadInterval = 30 * time.Second
+
+	defaultVtgateDrainPreStopDelay = 5 * time.Second
+	defaultVtgateDrainTimeout      = 30 * time.Second
+
+	defaultManagedTLSDuration    = 90 * 24 * time.Hour
+	defaultManagedTLSRenewBefore = 30 * 24 * time.Hour
+
+	defaultInternalTLSServerName = "vitess-internal"
 
 	defaultBackupIntervalHours     = 24
 	defaultBackupMinRetentionHours = 72
 	defaultBackupMinRetentionCount = 1
 	defaultBackupEngine            = VitessBackupEngineBuiltIn
 
+	defaultDataVolumeAutoscalerUsageThresholdPercent = 80
+	defaultDataVolumeAutoscalerCooldownSeconds       = 3600
+
 	// DefaultWebPort is the port for debug status pages and dashboard UIs.
 	DefaultWebPort = 15000
 	// DefaultAPIPort is the port for API endpoint.
@@ -140,6 +173,12 @@ var DefaultImages = &VitessImages{
 	MysqldExporter: "prom/mysqld-exporter:v0.18.0",
 }
 
+// defaultOperatorPodLabels select the operator's own Pods, as deployed by
+// the manifests in deploy/.
+var defaultOperatorPodLabels = map[string]string{
+	"app": "vitess-operator",
+}
+
 var (
 	// DefaultVitessPriorityClass is the name of the PriorityClass to use by
 	// default for Pods that run Vitess components. This value can be configured
@@ -184,4 +223,14 @@ var (
 	// This value can be configured at operator startup time with the
 	// --default_etcd_image flag.
 	DefaultEtcdImage = "quay.io/coreos/etcd:v3.5.17"
+
+	// DefaultConsulImage is the image to use for Consul when the CRD doesn't
+	// specify. This value can be configured at operator startup time with the
+	// --default_consul_image flag.
+	DefaultConsulImage = "hashicorp/consul:1.20"
+
+	// DefaultZkImage is the image to use for ZooKeeper when the CRD doesn't
+	// specify. This value can be configured at operator startup time with the
+	// --default_zk_image flag.
+	DefaultZkImage = "zookeeper:3.9"
 )
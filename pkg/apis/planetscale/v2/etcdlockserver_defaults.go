@@ -19,6 +19,7 @@ package v2
 import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 )
 
@@ -34,6 +35,9 @@ func DefaultEtcdLockserverTemplate(ls *EtcdLockserverTemplate) {
 	if ls.Image == "" {
 		ls.Image = DefaultEtcdImage
 	}
+	if ls.Replicas == nil {
+		ls.Replicas = ptr.To(int32(defaultEtcdReplicas))
+	}
 	if len(ls.DataVolumeClaimTemplate.AccessModes) == 0 {
 		ls.DataVolumeClaimTemplate.AccessModes = []corev1.PersistentVolumeAccessMode{
 			corev1.ReadWriteOnce,
@@ -67,4 +71,25 @@ func DefaultEtcdLockserverTemplate(ls *EtcdLockserverTemplate) {
 	}
 	DefaultServiceOverrides(&ls.ClientService)
 	DefaultServiceOverrides(&ls.PeerService)
+	if ls.TLS != nil {
+		DefaultOperatorManagedTLS(ls.TLS.OperatorManaged)
+	}
+	if ls.MemberReplacement == nil {
+		ls.MemberReplacement = &EtcdMemberReplacement{}
+	}
+	if ls.MemberReplacement.UnhealthyTimeout == nil {
+		ls.MemberReplacement.UnhealthyTimeout = &metav1.Duration{Duration: defaultEtcdMemberUnhealthyTimeout}
+	}
+	if ls.Maintenance == nil {
+		ls.Maintenance = &EtcdMaintenance{}
+	}
+	if ls.Maintenance.AutoCompactionMode == "" {
+		ls.Maintenance.AutoCompactionMode = defaultEtcdAutoCompactionMode
+	}
+	if ls.Maintenance.AutoCompactionRetention == "" {
+		ls.Maintenance.AutoCompactionRetention = defaultEtcdAutoCompactionRetention
+	}
+	if ls.Maintenance.DefragThresholdPercent == nil {
+		ls.Maintenance.DefragThresholdPercent = ptr.To(int32(defaultEtcdDefragThresholdPercent))
+	}
 }
@@ -0,0 +1,29 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+// BackupLocation looks up a backup location in the list by name.
+// It returns nil if no location by that name exists.
+func (s *EtcdLockserverSpec) BackupLocation(name string) *VitessBackupLocation {
+	// Note that "" is a valid name, so we always check even if 'name' is empty.
+	for i := range s.BackupLocations {
+		if s.BackupLocations[i].Name == name {
+			return &s.BackupLocations[i]
+		}
+	}
+	return nil
+}
@@ -57,6 +57,10 @@ type EtcdLockserverSpec struct {
 	// label on the Kubernetes Nodes in that AZ.
 	// If the Kubernetes Nodes don't have such a label, leave this empty.
 	Zone string `json:"zone,omitempty"`
+
+	// BackupLocations are the backup locations defined in the VitessCluster.
+	// Snapshots are saved to and restored from locations in this list.
+	BackupLocations []VitessBackupLocation `json:"backupLocations,omitempty"`
 }
 
 // EtcdLockserverTemplate defines the user-configurable settings for an etcd
@@ -74,6 +78,23 @@ type EtcdLockserverTemplate struct {
 	// etcd Pods.
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
+	// Replicas is the number of etcd members. An etcd cluster stays available
+	// as long as a majority of its members are available, so a single member
+	// can't tolerate any failures, three members can tolerate one failure,
+	// five can tolerate two, and seven can tolerate three.
+	//
+	// Changing this on an existing lockserver scales the etcd cluster online,
+	// by adding or removing one member at a time and waiting for all members
+	// to be healthy in between. Members are added in order of increasing
+	// index, and removed in order of decreasing index. New members join as
+	// non-voting learners and are promoted once they've caught up with the
+	// leader, so scaling up doesn't cost the cluster its quorum, even from a
+	// single member.
+	//
+	// Default: 3
+	// +kubebuilder:validation:Enum=1;3;5;7
+	Replicas *int32 `json:"replicas,omitempty"`
+
 	// Resources specify the compute resources to allocate for each etcd member.
 	// Default: Let the operator choose.
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
@@ -168,23 +189,27 @@ type EtcdLockserverTemplate struct {
 	// AdvertisePeerURLs can optionally be used to override the URLs that etcd
 	// members use to find each other for peer-to-peer connections.
 	//
-	// If specified, the list must contain exactly 3 entries, one for each etcd
-	// member index (1,2,3) respectively.
+	// If specified, the list must contain exactly one entry for each etcd
+	// member index (1,2,3,...) respectively, so its length must match Replicas.
 	//
 	// Default: Build peer URLs automatically based on Kubernetes built-in DNS.
-	// +kubebuilder:validation:MinItems=3
-	// +kubebuilder:validation:MaxItems=3
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=7
 	AdvertisePeerURLs []string `json:"advertisePeerURLs,omitempty"`
 
 	// LocalMemberIndex can optionally be used to specify that only one etcd
 	// member should actually be deployed. This can be used to spread members
 	// across multiple Kubernetes clusters by configuring the EtcdLockserver CRD
 	// in each cluster to deploy a different member index. If specified, the
-	// index must be 1, 2, or 3.
+	// index must be between 1 and Replicas.
+	//
+	// Online scaling is not supported when this is set, since the operator
+	// can't see the other members. Changing Replicas only changes the initial
+	// cluster that a new member is bootstrapped with.
 	//
 	// Default: Deploy all etcd members locally.
 	// +kubebuilder:validation:Minimum=1
-	// +kubebuilder:validation:Maximum=3
+	// +kubebuilder:validation:Maximum=7
 	LocalMemberIndex *int32 `json:"localMemberIndex,omitempty"`
 
 	// ClientService can optionally be used to customize the etcd client Service.
@@ -197,6 +222,181 @@ type EtcdLockserverTemplate struct {
 	// +kubebuilder:validation:Schemaless
 	// +kubebuilder:pruning:PreserveUnknownFields
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// TLS can optionally be used to encrypt etcd client and peer traffic.
+	//
+	// WARNING: etcd members remember the peer URLs they were bootstrapped
+	// with, so TLS can only be enabled or disabled when the lockserver is
+	// first created.
+	TLS *EtcdLockserverTLS `json:"tls,omitempty"`
+
+	// Backup can optionally be used to periodically save snapshots of the
+	// etcd keyspace to a backup location.
+	Backup *EtcdLockserverBackup `json:"backup,omitempty"`
+
+	// Restore can optionally be used to bootstrap a new lockserver from a
+	// previously saved snapshot, instead of starting with an empty keyspace.
+	//
+	// The snapshot is only restored into members that have never been
+	// started. Setting this on an existing lockserver has no effect.
+	//
+	// WARNING: Restoring a lockserver resets it to a point in the past.
+	// Only restore into a Vitess cluster whose tablets are not already
+	// serving from a newer topology.
+	Restore *EtcdLockserverRestore `json:"restore,omitempty"`
+
+	// MemberReplacement configures how the operator replaces members that
+	// stay unhealthy, for example because their data volume was lost or their
+	// data is corrupt. A member is only replaced while all other members are
+	// healthy, so the cluster never loses quorum in the process.
+	//
+	// Members are not replaced if LocalMemberIndex is set, since the operator
+	// can't see the other members, or if there's only one member.
+	MemberReplacement *EtcdMemberReplacement `json:"memberReplacement,omitempty"`
+
+	// Maintenance configures compaction and defragmentation, which keep the
+	// etcd backend database from growing toward its quota.
+	Maintenance *EtcdMaintenance `json:"maintenance,omitempty"`
+}
+
+// EtcdMaintenance configures compaction and defragmentation of etcd.
+//
+// Compaction discards old revisions of keys, which leaves free space inside
+// the backend database file. Defragmentation rewrites the file to give that
+// space back, but blocks the member while it runs, so the operator only
+// defragments one member at a time, leaves the leader for last, and waits
+// for all members to be healthy in between.
+type EtcdMaintenance struct {
+	// AutoCompactionMode is the etcd "auto-compaction-mode".
+	// With "revision", AutoCompactionRetention is a number of revisions to
+	// keep. With "periodic", it's a duration of history to keep, like "1h".
+	//
+	// Default: revision
+	// +kubebuilder:validation:Enum=revision;periodic
+	AutoCompactionMode string `json:"autoCompactionMode,omitempty"`
+
+	// AutoCompactionRetention is the etcd "auto-compaction-retention".
+	// Default: 1000
+	AutoCompactionRetention string `json:"autoCompactionRetention,omitempty"`
+
+	// DefragSchedule is a cron schedule (in the standard 5-field format) on
+	// which to check whether members need to be defragmented. For example,
+	// "0 3 * * *" checks every day at 3:00 UTC.
+	//
+	// Once a check is due, members are defragmented one at a time until none
+	// is above DefragThresholdPercent, and then the next check is scheduled.
+	//
+	// Defragmentation is not supported when LocalMemberIndex is set, since
+	// the operator can't see the other members.
+	//
+	// Default: Never defragment.
+	DefragSchedule string `json:"defragSchedule,omitempty"`
+
+	// DefragThresholdPercent is how much of a member's backend database file
+	// must be free space, as a percentage of its total size, for the member
+	// to be defragmented.
+	//
+	// Default: 50
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	DefragThresholdPercent *int32 `json:"defragThresholdPercent,omitempty"`
+}
+
+// EtcdMemberReplacement configures automatic replacement of unhealthy etcd
+// members.
+//
+// To replace a member, the operator removes it from the etcd cluster, deletes
+// its Pod and data volume, and adds it back so it can rejoin with fresh data
+// copied from the other members.
+type EtcdMemberReplacement struct {
+	// Disabled can be set to true to turn off automatic member replacement.
+	// Default: false
+	Disabled bool `json:"disabled,omitempty"`
+
+	// UnhealthyTimeout is how long a member must be continuously unhealthy
+	// before it's replaced.
+	// Default: 10m
+	UnhealthyTimeout *metav1.Duration `json:"unhealthyTimeout,omitempty"`
+}
+
+// EtcdLockserverBackup configures scheduled etcd snapshots.
+type EtcdLockserverBackup struct {
+	// Schedule is the snapshot schedule in Cron format.
+	// For example, "0 */6 * * *" saves a snapshot every six hours.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// LocationName is the name of the backup location to save snapshots to.
+	// Default: Use the default (unnamed) backup location.
+	LocationName string `json:"locationName,omitempty"`
+
+	// Suspend can be set to true to temporarily stop taking snapshots.
+	// Default: false
+	Suspend bool `json:"suspend,omitempty"`
+
+	// ServiceAccountName can optionally be used to run the snapshot Pods
+	// with a service account that has access to the backup location.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Annotations can optionally be used to attach custom annotations to the
+	// snapshot Pods.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// EtcdLockserverRestore configures bootstrapping a lockserver from a snapshot.
+type EtcdLockserverRestore struct {
+	// SnapshotName is the name of the snapshot to restore. Snapshots are
+	// named after the UTC time at which they were taken, in the format
+	// "2006-01-02.150405".
+	// +kubebuilder:validation:MinLength=1
+	SnapshotName string `json:"snapshotName"`
+
+	// LocationName is the name of the backup location to restore from.
+	// Default: Use the default (unnamed) backup location.
+	LocationName string `json:"locationName,omitempty"`
+
+	// SourceLockserver is the name of the EtcdLockserver that saved the
+	// snapshot.
+	// Default: The name of this EtcdLockserver.
+	SourceLockserver string `json:"sourceLockserver,omitempty"`
+
+	// SourceCluster is the name of the VitessCluster that the source
+	// lockserver belonged to, which determines where in the backup location
+	// the snapshot is stored.
+	// Default: The VitessCluster that this EtcdLockserver belongs to.
+	SourceCluster string `json:"sourceCluster,omitempty"`
+
+	// ServiceAccountName can optionally be used to run the restore Pods
+	// with a service account that has access to the backup location.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// EtcdLockserverTLS configures TLS for etcd client and peer traffic.
+//
+// etcd members require clients and peers alike to present a certificate
+// signed by the CA they trust.
+type EtcdLockserverTLS struct {
+	// OperatorManaged configures the operator to generate a CA and issue and
+	// renew the server and client certificates itself. When this is set,
+	// ServerSecret and ClientSecret are ignored.
+	//
+	// This is only supported for lockservers deployed as part of a
+	// VitessCluster. All operator-managed lockservers in a VitessCluster
+	// share one CA and one client certificate.
+	OperatorManaged *OperatorManagedTLS `json:"operatorManaged,omitempty"`
+
+	// ServerSecret is the name of a Secret with the keys "tls.crt", "tls.key"
+	// and "ca.crt", which etcd members use both to serve clients and to
+	// connect to each other. The certificate must be valid for both server
+	// and client authentication, and for the DNS names of each member
+	// ("<lockserver>-<index>.<lockserver>-peer.<namespace>.svc"), the client
+	// Service, and "localhost".
+	ServerSecret string `json:"serverSecret,omitempty"`
+
+	// ClientSecret is the name of a Secret with the keys "tls.crt", "tls.key"
+	// and "ca.crt", which Vitess components and the operator use to connect
+	// to the lockserver.
+	ClientSecret string `json:"clientSecret,omitempty"`
 }
 
 // EtcdLockserverStatus defines the observed state of an EtcdLockserver.
@@ -207,6 +407,41 @@ type EtcdLockserverStatus struct {
 	Available corev1.ConditionStatus `json:"available,omitempty"`
 	// ClientServiceName is the name of the Service for etcd client connections.
 	ClientServiceName string `json:"clientServiceName,omitempty"`
+	// LastSnapshotTime is the time at which the most recent scheduled
+	// snapshot was successfully saved.
+	LastSnapshotTime *metav1.Time `json:"lastSnapshotTime,omitempty"`
+	// RestoredSnapshot is the name of the snapshot that this lockserver was
+	// bootstrapped from, if any. It's set once all members have been restored.
+	RestoredSnapshot string `json:"restoredSnapshot,omitempty"`
+	// LastDefragTime is when the last scheduled defragmentation check finished.
+	LastDefragTime *metav1.Time `json:"lastDefragTime,omitempty"`
+	// Replicas is the number of members in the etcd cluster, as last reported
+	// by etcd. It differs from the desired number while scaling is in progress.
+	Replicas int32 `json:"replicas,omitempty"`
+	// Members reports the health of each etcd member, as seen by the operator.
+	Members []EtcdMemberStatus `json:"members,omitempty"`
+}
+
+// EtcdMemberStatus is the observed state of one etcd member.
+type EtcdMemberStatus struct {
+	// Index is the member index.
+	Index int32 `json:"index"`
+	// ID is the hexadecimal member ID assigned by etcd.
+	ID string `json:"id,omitempty"`
+	// Healthy indicates whether the member is serving as part of the cluster.
+	Healthy corev1.ConditionStatus `json:"healthy,omitempty"`
+	// UnhealthySince is the time at which the member was first seen to be
+	// unhealthy, if it's currently unhealthy.
+	UnhealthySince *metav1.Time `json:"unhealthySince,omitempty"`
+	// Message explains why the member is unhealthy, if it is.
+	Message string `json:"message,omitempty"`
+	// Leader indicates whether the member is the current leader.
+	Leader bool `json:"leader,omitempty"`
+	// DBSizeBytes is the size of the member's backend database file.
+	DBSizeBytes int64 `json:"dbSizeBytes,omitempty"`
+	// DBSizeInUseBytes is the part of the backend database file that's in
+	// use. The rest can be reclaimed by defragmentation.
+	DBSizeInUseBytes int64 `json:"dbSizeInUseBytes,omitempty"`
 }
 
 // NewEtcdLockserverStatus returns a new status with default values.
@@ -0,0 +1,158 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// VitessGatewayExternalSpec configures how vtgates are reached from outside
+// the Kubernetes cluster.
+//
+// The operator keeps the regular ClusterIP vtgate Service as-is for clients
+// inside the Kubernetes cluster, and creates the objects configured here
+// alongside it.
+type VitessGatewayExternalSpec struct {
+	// Service can optionally be used to create a separate LoadBalancer or
+	// NodePort Service that exposes the vtgate MySQL and gRPC ports.
+	Service *VitessGatewayExternalService `json:"service,omitempty"`
+
+	// TCPRoute can optionally be used to create a Gateway API TCPRoute that
+	// attaches the vtgate MySQL port to an existing Gateway.
+	// This requires the Gateway API TCPRoute CRD to be installed.
+	TCPRoute *VitessGatewayTCPRoute `json:"tcpRoute,omitempty"`
+}
+
+// VitessGatewayExternalService configures an externally reachable vtgate
+// Service.
+type VitessGatewayExternalService struct {
+	// Type is the Service type.
+	// Default: LoadBalancer
+	// +kubebuilder:validation:Enum=LoadBalancer;NodePort
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Annotations specifies extra annotations to add to the Service object,
+	// such as settings for your cloud provider's load balancer.
+	// Annotations added in this way will NOT be automatically removed from the
+	// Service object if they are removed here.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Hostname can optionally be used to request a DNS record for the
+	// Service. It's set as the "external-dns.alpha.kubernetes.io/hostname"
+	// annotation, which is honored by external-dns if it runs in your
+	// Kubernetes cluster.
+	Hostname string `json:"hostname,omitempty"`
+
+	// LoadBalancerSourceRanges restricts which client IP ranges (in CIDR
+	// notation) may connect through the load balancer, if the cloud provider
+	// supports it. Only used when Type is LoadBalancer.
+	// Default: Allow all client IPs.
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// ExternalTrafficPolicy controls whether traffic may be forwarded to
+	// vtgates on other nodes ("Cluster"), or only to vtgates on the node
+	// that received it ("Local"), which preserves the client's source IP.
+	// Default: Cluster
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+}
+
+// VitessGatewayTCPRoute configures a Gateway API TCPRoute for vtgate.
+type VitessGatewayTCPRoute struct {
+	// ParentRefs lists the Gateways, and optionally the listeners within
+	// them, that the route attaches to. Each listener must use the TCP
+	// protocol.
+	// +kubebuilder:validation:MinItems=1
+	ParentRefs []VitessGatewayParentRef `json:"parentRefs"`
+}
+
+// VitessGatewayParentRef identifies a Gateway listener.
+type VitessGatewayParentRef struct {
+	// Name is the name of the Gateway.
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Gateway.
+	// Default: The namespace of the VitessCluster.
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName is the name of a listener within the Gateway.
+	// Default: Attach to all listeners that allow it.
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// VitessGatewayExternalStatus describes how clients outside the Kubernetes
+// cluster can reach vtgate.
+type VitessGatewayExternalStatus struct {
+	// ServiceName is the name of the external vtgate Service, if any.
+	ServiceName string `json:"serviceName,omitempty"`
+
+	// Addresses lists the IPs and hostnames assigned to the external Service
+	// by the load balancer. This is empty until the load balancer is ready,
+	// and always empty for NodePort Services.
+	Addresses []string `json:"addresses,omitempty"`
+
+	// Hostname is the DNS name requested for the external Service, if any.
+	Hostname string `json:"hostname,omitempty"`
+
+	// MysqlPort is the port on which clients connect to the MySQL protocol.
+	// For NodePort Services, this is the port allocated on each node.
+	MysqlPort int32 `json:"mysqlPort,omitempty"`
+
+	// TCPRouteName is the name of the Gateway API TCPRoute, if any.
+	TCPRouteName string `json:"tcpRouteName,omitempty"`
+}
+
+// VitessGatewayTopologySpec configures how clients inside the Kubernetes
+// cluster are routed to vtgates in their own zone.
+//
+// The zone of a vtgate is the Zone of the cell it belongs to.
+type VitessGatewayTopologySpec struct {
+	// TrafficDistribution is set on the cluster-wide vtgate Service.
+	// With "PreferSameZone", clients are sent to vtgates in their own zone,
+	// and only fall back to other zones if there are no ready vtgates there.
+	// "PreferClose" is the older name for the same behavior.
+	// This requires Kubernetes 1.31 or later (1.34 for "PreferSameZone").
+	// Default: Route to vtgates in any zone.
+	// +kubebuilder:validation:Enum=PreferClose;PreferSameZone
+	TrafficDistribution *string `json:"trafficDistribution,omitempty"`
+
+	// TopologyAwareHints enables topology-aware hints on the cluster-wide
+	// vtgate Service, for Kubernetes versions that don't support
+	// TrafficDistribution. Kubernetes only uses the hints while vtgates are
+	// spread evenly enough across zones relative to node capacity.
+	// Default: false
+	TopologyAwareHints bool `json:"topologyAwareHints,omitempty"`
+
+	// InternalTrafficPolicy is set on the cluster-wide vtgate Service.
+	// With "Local", clients only reach vtgates on their own node, and
+	// connections fail if there are none, so this is only useful if vtgates
+	// run on every node that runs clients (e.g. as sidecars or with
+	// matching affinity rules).
+	// Default: Cluster
+	// +kubebuilder:validation:Enum=Cluster;Local
+	InternalTrafficPolicy *corev1.ServiceInternalTrafficPolicy `json:"internalTrafficPolicy,omitempty"`
+
+	// ZoneServices can be set to create a headless Service for each zone
+	// that selects only the vtgates in that zone. Clients can resolve the
+	// Service for their own zone first, and fall back to the cluster-wide
+	// Service if it has no ready endpoints.
+	//
+	// Enabling or disabling this adds or removes a zone label on vtgate Pods,
+	// which triggers a rolling update of the vtgates.
+	// Default: false
+	ZoneServices bool `json:"zoneServices,omitempty"`
+}
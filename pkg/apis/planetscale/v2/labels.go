@@ -27,6 +27,8 @@ const (
 	ClusterLabel = LabelPrefix + "/" + "cluster"
 	// CellLabel is the key for identifying the Vitess cell to which an object belongs.
 	CellLabel = LabelPrefix + "/" + "cell"
+	// ZoneLabel is the key for identifying the zone of the cell to which a vtgate Pod belongs.
+	ZoneLabel = LabelPrefix + "/" + "zone"
 	// KeyspaceLabel is the key for identifying the Vitess keyspace to which an object belongs.
 	KeyspaceLabel = LabelPrefix + "/" + "keyspace"
 	// ShardLabel is the key for identifying the Vitess shard to which an object belongs.
@@ -40,6 +42,8 @@ const (
 	TabletPoolNameLabel = LabelPrefix + "/" + "pool-name"
 	// TabletIndexLabel is the key for identifying the index of a Vitess tablet within its pool.
 	TabletIndexLabel = LabelPrefix + "/" + "tablet-index"
+	// GatewayPoolLabel is the key for identifying the vtgate pool to which a Pod belongs.
+	GatewayPoolLabel = LabelPrefix + "/" + "gateway-pool"
 	// BackupScheduleLabel is the key for identifying to which VitessBackupSchedule a Job belongs to.
 	BackupScheduleLabel = LabelPrefix + "/" + "backup-schedule"
 	// BackupMethodLabel is the key for identifying the backup method used by a VitessBackupSchedule job.
@@ -53,12 +57,28 @@ const (
 	VtorcComponentName = "vtorc"
 	// VtgateComponentName is the ComponentLabel value for vtgate.
 	VtgateComponentName = "vtgate"
+	// VtgatePoolComponentName is the ComponentLabel value for vtgates in a
+	// named pool. It differs from VtgateComponentName so that pool vtgates
+	// aren't selected by the cell-wide and cluster-wide vtgate Services.
+	VtgatePoolComponentName = "vtgate-pool"
+	// VtgateZoneComponentName is the ComponentLabel value for the per-zone
+	// vtgate Services. No Pods carry this value; it only keeps those Services
+	// apart from the other vtgate Services.
+	VtgateZoneComponentName = "vtgate-zone"
 	// VttabletComponentName is the ComponentLabel value for vttablet.
 	VttabletComponentName = "vttablet"
 	// VtbackupComponentName is the ComponentLabel value for vtbackup.
 	VtbackupComponentName = "vtbackup"
 	// EtcdComponentName is the ComponentLabel value for etcd.
 	EtcdComponentName = "etcd"
+	// EtcdSnapshotComponentName is the ComponentLabel value for etcd snapshot Pods.
+	EtcdSnapshotComponentName = "etcd-snapshot"
+	// EtcdRestoreComponentName is the ComponentLabel value for etcd restore Pods.
+	EtcdRestoreComponentName = "etcd-restore"
+	// ConsulComponentName is the ComponentLabel value for Consul.
+	ConsulComponentName = "consul"
+	// ZkComponentName is the ComponentLabel value for ZooKeeper.
+	ZkComponentName = "zk"
 	// VBSSubcontrollerComponentName is the ComponentLabel value for the vitessbackupstorage subcontroller.
 	VBSSubcontrollerComponentName = "vbs-subcontroller"
 
@@ -80,6 +100,7 @@ var (
 	VitessComponentNames = []string{
 		VtctldComponentName,
 		VtgateComponentName,
+		VtgatePoolComponentName,
 		VttabletComponentName,
 	}
 )
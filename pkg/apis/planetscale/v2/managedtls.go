@@ -0,0 +1,50 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OperatorManagedTLS configures the operator to issue and renew TLS
+// certificates itself, signed by a CA that it generates and keeps in a Secret.
+type OperatorManagedTLS struct {
+	// Duration is how long each issued certificate is valid.
+	// Default: 2160h (90 days)
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// RenewBefore is how long before expiry a certificate is reissued.
+	// It must be less than Duration. If it isn't, a third of Duration is
+	// used instead.
+	// Default: 720h (30 days)
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+
+	// ExtraDNSNames are additional DNS names to include in issued
+	// certificates, such as the hostname of an external load balancer.
+	// +listType=set
+	ExtraDNSNames []string `json:"extraDNSNames,omitempty"`
+}
+
+// CertificateStatus describes a certificate managed by the operator.
+type CertificateStatus struct {
+	// SecretName is the name of the Secret in which the certificate is stored.
+	SecretName string `json:"secretName,omitempty"`
+	// NotAfter is when the certificate expires.
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+	// RenewalTime is when the operator will reissue the certificate.
+	RenewalTime *metav1.Time `json:"renewalTime,omitempty"`
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// VitessNetworkPolicySpec configures NetworkPolicies that restrict ingress
+// traffic to the Pods of a VitessCluster.
+//
+// Once a Pod is selected by any NetworkPolicy, it only accepts the traffic
+// that some policy allows. The operator creates one policy per component:
+//
+//   - vtgate: MySQL and gRPC from clientIngress; gRPC and web from other
+//     Vitess components and the operator.
+//   - vtadmin: web and API from clientIngress.
+//   - vtctld: web and gRPC from other Vitess components and the operator.
+//   - vtorc: web from other Vitess components and the operator.
+//   - vttablet: gRPC and web from vtgate, vtctld, vtorc, the operator, and
+//     other tablets (for VReplication); MySQL only from the tablets and
+//     vtbackup Pods of the same shard.
+//   - etcd: the client port from Vitess components and the operator; the peer
+//     port only from other etcd members.
+//   - consul: the HTTP API from Vitess components and the operator; the
+//     server RPC and gossip ports only from other Consul servers.
+//   - zk: the client port from Vitess components and the operator; the peer
+//     and leader election ports only from other ZooKeeper servers.
+//
+// Sources in metricsIngress may also reach the web port, which serves
+// metrics, of every Vitess component. Sources in extraIngress may reach every
+// component on every port.
+type VitessNetworkPolicySpec struct {
+	// ClientIngress lists the sources, such as application Pods, that may
+	// connect to the vtgate MySQL and gRPC ports, and to vtadmin.
+	// Default: Allow all sources.
+	ClientIngress []networkingv1.NetworkPolicyPeer `json:"clientIngress,omitempty"`
+
+	// OperatorIngress lists the sources that match the operator's own Pods.
+	// The operator needs to reach vttablet, vtgate and etcd.
+	// Default: Pods labeled "app: vitess-operator" in any namespace.
+	OperatorIngress []networkingv1.NetworkPolicyPeer `json:"operatorIngress,omitempty"`
+
+	// MetricsIngress lists the sources, such as Prometheus, that may scrape
+	// metrics from the web port of vtgate, vttablet, vtctld, vtorc and
+	// vtadmin. etcd serves its metrics on the client port, so add a scraper
+	// to extraIngress instead if it also needs to reach etcd.
+	MetricsIngress []networkingv1.NetworkPolicyPeer `json:"metricsIngress,omitempty"`
+
+	// ExtraIngress lists additional sources that may reach every component
+	// on every port.
+	ExtraIngress []networkingv1.NetworkPolicyPeer `json:"extraIngress,omitempty"`
+}
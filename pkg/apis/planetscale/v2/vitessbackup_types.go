@@ -32,6 +32,8 @@ import (
 // These objects are created automatically by the VitessBackupStorage controller
 // to provide access to backup metadata from Kubernetes. Each backup found in
 // the storage location will be represented by its own VitessBackup object.
+// VitessBackupSchedules that use the "snapshot" backup method also create
+// VitessBackup objects, one for each VolumeSnapshot they take.
 // +kubebuilder:resource:path=vitessbackups,shortName=vtb
 type VitessBackup struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -67,6 +69,10 @@ type VitessBackupStatus struct {
 	// the name of the VitessBackup object created to represent metadata about
 	// the actual backup in storage.
 	StorageName string `json:"storageName,omitempty"`
+	// VolumeSnapshotName is the name of the VolumeSnapshot that holds this
+	// backup, if it was taken with the "snapshot" backup method. Such backups
+	// are not in backup storage, so StorageDirectory and StorageName are empty.
+	VolumeSnapshotName string `json:"volumeSnapshotName,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
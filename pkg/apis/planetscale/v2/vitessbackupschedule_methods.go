@@ -129,6 +129,19 @@ func (vbsc *VitessBackupSchedule) GetSuccessfulJobsLimit() int32 {
 	return *vbsc.Spec.SuccessfulJobsHistoryLimit
 }
 
+// DefaultSnapshotRetentionCount is the number of complete snapshots to keep
+// for each shard when using the "snapshot" backup method.
+const DefaultSnapshotRetentionCount = 3
+
+// GetSnapshotRetentionCount returns the number of complete snapshots to keep for each shard.
+// Returns DefaultSnapshotRetentionCount if the value was not specified by the user.
+func (vbsc *VitessBackupSchedule) GetSnapshotRetentionCount() int32 {
+	if vbsc.Spec.Snapshot == nil || vbsc.Spec.Snapshot.RetentionCount == nil {
+		return DefaultSnapshotRetentionCount
+	}
+	return *vbsc.Spec.Snapshot.RetentionCount
+}
+
 // DefaultAllowedMissedRuns is the default that will be used in case of bug in the operator,
 // which could be caused by the apiserver's clock for instance. In the event of such bug,
 // the VitessBackupSchedule will try catching up the missed scheduled runs one by one
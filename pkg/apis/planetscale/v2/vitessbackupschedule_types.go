@@ -37,7 +37,7 @@ const (
 )
 
 // BackupMethod defines the method used to take scheduled backups.
-// +kubebuilder:validation:Enum=vtbackup;vtctldclient
+// +kubebuilder:validation:Enum=vtbackup;vtctldclient;snapshot
 type BackupMethod string
 
 const (
@@ -50,6 +50,14 @@ const (
 	// Kubernetes Job sends a BackupShard command to vtctld, which tells a running
 	// serving replica to take the backup. No PVC is needed.
 	BackupMethodVtctldclient BackupMethod = "vtctldclient"
+
+	// BackupMethodSnapshot takes a CSI VolumeSnapshot of a replica's data volume.
+	// The controller drains the replica and stops replication, takes the snapshot,
+	// and then puts the replica back into service. MySQL keeps running during the
+	// snapshot, so it's crash-consistent and a restore goes through InnoDB crash
+	// recovery. The snapshot is registered as a VitessBackup, but it lives in the
+	// cluster rather than in backup storage.
+	BackupMethodSnapshot BackupMethod = "snapshot"
 )
 
 // ConcurrencyPolicy describes how the concurrency of new jobs created by VitessBackupSchedule
@@ -135,10 +143,17 @@ type VitessBackupScheduleTemplate struct {
 	// restores from the latest backup, catches up on replication, and takes a new backup.
 	// "vtctldclient" sends a BackupShard command to vtctld, which tells a running serving
 	// replica to take the backup directly. No PVC is needed for vtctldclient.
+	// "snapshot" takes a crash-consistent CSI VolumeSnapshot of the data volume of a
+	// replica that is drained and has replication stopped.
 	// +optional
 	// +kubebuilder:default="vtbackup"
 	BackupMethod BackupMethod `json:"backupMethod,omitempty"`
 
+	// Snapshot configures backups taken with the "snapshot" backup method.
+	// This field is ignored for other backup methods.
+	// +optional
+	Snapshot *VitessBackupScheduleSnapshotSpec `json:"snapshot,omitempty"`
+
 	// Strategy defines how we are going to take a backup.
 	// If you want to take several backups within the same schedule you can add more items
 	// to the Strategy list. Each VitessBackupScheduleStrategy will be executed within different
@@ -227,6 +242,22 @@ type VitessBackupScheduleTemplate struct {
 	Tolerations *[]corev1.Toleration `json:"tolerations,omitempty"`
 }
 
+// VitessBackupScheduleSnapshotSpec configures VolumeSnapshot-based backups.
+type VitessBackupScheduleSnapshotSpec struct {
+	// VolumeSnapshotClassName is the VolumeSnapshotClass to use when taking
+	// snapshots. It must use the same CSI driver as the tablets' data volumes.
+	// Default: Use the cluster's default VolumeSnapshotClass.
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+
+	// RetentionCount is how many complete snapshots to keep for each shard.
+	// Older snapshots are deleted once a newer one is complete.
+	// Default: 3
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	RetentionCount *int32 `json:"retentionCount,omitempty"`
+}
+
 // VitessBackupScheduleStrategy defines how we are going to take a backup.
 // The VitessBackupSchedule controller uses this data to build either a vtbackup
 // pod or a vtctldclient command, depending on the configured BackupMethod.
@@ -19,6 +19,7 @@ package v2
 import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 )
 
@@ -35,10 +36,8 @@ func DefaultLocalLockserver(ls *LockserverSpec) {
 		// We'll just pass those params to Vitess.
 		return
 	}
-	if ls.Etcd != nil {
-		// If etcd was specified, fill in defaults.
-		DefaultEtcdLockserverTemplate(ls.Etcd)
-	}
+	// If we were asked to deploy a lockserver, fill in defaults.
+	defaultManagedLockserver(ls)
 }
 
 func DefaultVitessGateway(gtway *VitessCellGatewaySpec) {
@@ -56,7 +55,32 @@ func DefaultVitessGateway(gtway *VitessCellGatewaySpec) {
 			corev1.ResourceMemory: *resource.NewQuantity(defaultVtgateMemoryBytes, resource.BinarySI),
 		}
 	}
+	if gtway.Authentication.Static != nil && gtway.Authentication.Static.ReloadInterval == nil {
+		gtway.Authentication.Static.ReloadInterval = &metav1.Duration{Duration: defaultStaticAuthReloadInterval}
+	}
+	if gtway.SecureTransport != nil && gtway.SecureTransport.TLS != nil {
+		DefaultOperatorManagedTLS(gtway.SecureTransport.TLS.OperatorManaged)
+	}
 	DefaultServiceOverrides(&gtway.Service)
+	DefaultVitessGatewayExternal(gtway.External)
+	DefaultVitessGatewayDrain(gtway.Drain)
+	DefaultPodDisruptionBudget(&gtway.PodDisruptionBudget)
+	for i := range gtway.Pools {
+		DefaultVitessGatewayPool(&gtway.Pools[i], gtway)
+	}
+}
+
+// DefaultVitessGatewayPool fills in defaults for a vtgate pool, inheriting
+// from the cell's main gateway spec, which should already be defaulted.
+func DefaultVitessGatewayPool(pool *VitessGatewayPoolSpec, gtway *VitessCellGatewaySpec) {
+	if pool.Replicas == nil {
+		pool.Replicas = ptr.To(int32(defaultVtgatePoolReplicas))
+	}
+	if len(pool.Resources.Requests) == 0 && len(pool.Resources.Limits) == 0 {
+		gtway.Resources.DeepCopyInto(&pool.Resources)
+	}
+	DefaultServiceOverrides(&pool.Service)
+	DefaultPodDisruptionBudget(&pool.PodDisruptionBudget)
 }
 
 // DefaultVitessCellImages fills in unspecified keyspace-level images from cluster-level defaults.
@@ -66,3 +90,30 @@ func DefaultVitessCellImages(dst *VitessCellImages, clusterDefaults *VitessImage
 		dst.Vtgate = clusterDefaults.Vtgate
 	}
 }
+
+// DefaultVitessGatewayExternal fills in defaults for an external vtgate
+// endpoint, if one is configured.
+func DefaultVitessGatewayExternal(ext *VitessGatewayExternalSpec) {
+	if ext == nil || ext.Service == nil {
+		return
+	}
+	if ext.Service.Type == "" {
+		ext.Service.Type = corev1.ServiceTypeLoadBalancer
+	}
+	if ext.Service.ExternalTrafficPolicy == "" {
+		ext.Service.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyCluster
+	}
+}
+
+// DefaultVitessGatewayDrain fills in defaults for vtgate draining, if enabled.
+func DefaultVitessGatewayDrain(drain *VitessGatewayDrainSpec) {
+	if drain == nil {
+		return
+	}
+	if drain.PreStopDelay == nil {
+		drain.PreStopDelay = &metav1.Duration{Duration: defaultVtgateDrainPreStopDelay}
+	}
+	if drain.Timeout == nil {
+		drain.Timeout = &metav1.Duration{Duration: defaultVtgateDrainTimeout}
+	}
+}
@@ -38,6 +38,13 @@ func (s *VitessCellGatewaySpec) ReloadSecretNames() sets.String {
 		}
 	}
 
+	// vtgate doesn't reload the LDAP config, so it must be restarted.
+	if s.Authentication.LDAP != nil &&
+		s.Authentication.LDAP.ConfigSecret != nil &&
+		s.Authentication.LDAP.ConfigSecret.Name != "" {
+		secretNames.Insert(s.Authentication.LDAP.ConfigSecret.Name)
+	}
+
 	for i := range s.ExtraVolumes {
 		vol := &s.ExtraVolumes[i]
 		if vol.Secret != nil {
@@ -47,3 +54,27 @@ func (s *VitessCellGatewaySpec) ReloadSecretNames() sets.String {
 
 	return secretNames
 }
+
+// StaticAuthSecretName returns the name of the Secret containing the static
+// auth file, or "" if static auth isn't loaded from a Secret. vtgate reloads
+// this file on its own, so changes to it don't require restarting vtgates.
+func (s *VitessCellGatewaySpec) StaticAuthSecretName() string {
+	if s.Authentication.Static == nil || s.Authentication.Static.Secret == nil {
+		return ""
+	}
+	if s.Authentication.Static.Secret.VolumeName != "" {
+		// The file comes from a Volume, which takes precedence over the Secret.
+		return ""
+	}
+	return s.Authentication.Static.Secret.Name
+}
+
+// TotalDrainingReplicas returns the number of draining vtgates in the cell,
+// including those in pools.
+func (s *VitessCellGatewayStatus) TotalDrainingReplicas() int32 {
+	total := s.DrainingReplicas
+	for _, pool := range s.Pools {
+		total += pool.DrainingReplicas
+	}
+	return total
+}
@@ -78,8 +78,20 @@ type VitessCellSpec struct {
 	// ExtraVitessFlags is inherited from the parent's VitessClusterSpec.
 	ExtraVitessFlags map[string]string `json:"extraVitessFlags,omitempty"`
 
+	// InternalTLS is inherited from the parent's VitessClusterSpec.
+	// If the certificate is managed by the operator, the Secret fields point
+	// to the operator-managed Secret.
+	InternalTLS *VitessInternalTLSSpec `json:"internalTLS,omitempty"`
+
 	// TopologyReconciliation is inherited from the parent's VitessClusterSpec.
 	TopologyReconciliation *TopoReconcileConfig `json:"topologyReconciliation,omitempty"`
+
+	// GatewayTopology is inherited from the parent's VitessClusterSpec.
+	GatewayTopology *VitessGatewayTopologySpec `json:"gatewayTopology,omitempty"`
+
+	// BackupLocations are the backup locations defined in the VitessCluster.
+	// They're used for snapshots of the cell-local lockserver, if enabled.
+	BackupLocations []VitessBackupLocation `json:"backupLocations,omitempty"`
 }
 
 // VitessCellTemplate contains only the user-specified parts of a VitessCell object.
@@ -133,6 +145,24 @@ type AutoscalerSpec struct {
 	// +kubebuilder:validation:Minimum=0
 	MaxReplicas int32 `json:"maxReplicas,omitempty"`
 
+	// TargetQPSPerPod scales vtgate to keep the average number of queries per
+	// second served by each vtgate replica at or below this value.
+	//
+	// This metric is served by the operator's external metrics API, which must
+	// be enabled and registered with the API server for the HPA to use it.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetQPSPerPod *int32 `json:"targetQPSPerPod,omitempty"`
+
+	// TargetConnectionsPerPod scales vtgate to keep the average number of open
+	// MySQL client connections to each vtgate replica at or below this value.
+	//
+	// This metric is served by the operator's external metrics API, which must
+	// be enabled and registered with the API server for the HPA to use it.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetConnectionsPerPod *int32 `json:"targetConnectionsPerPod,omitempty"`
+
 	// Metrics is meant to provide a customizable way to configure HPA metrics.
 	// currently the only supported custom metrics is type=Pod.
 	// Use TargetCPUUtilization or TargetMemoryUtilization instead if scaling on these common resource metrics.
@@ -223,6 +253,16 @@ type VitessCellGatewaySpec struct {
 	// Service can optionally be used to customize the per-cell vtgate Service.
 	Service *ServiceOverrides `json:"service,omitempty"`
 
+	// External can optionally be used to expose this cell's vtgates to
+	// clients outside the Kubernetes cluster. To expose the vtgates of all
+	// cells behind a single endpoint instead, use GatewayExternal in the
+	// VitessCluster spec.
+	External *VitessGatewayExternalSpec `json:"external,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget (PDB) that covers
+	// the vtgate Pods in this cell.
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
 	// Tolerations allow you to schedule pods onto nodes with matching taints.
 	// +kubebuilder:validation:Schemaless
 	// +kubebuilder:pruning:PreserveUnknownFields
@@ -242,21 +282,189 @@ type VitessCellGatewaySpec struct {
 	// terminationGracePeriodSeconds of the vtgate pod.
 	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
 
+	// Drain can optionally be used to let vtgates finish serving connected
+	// clients before they shut down, for example during rollouts and
+	// scale-down. This also applies to the vtgates of any pools in this cell.
+	Drain *VitessGatewayDrainSpec `json:"drain,omitempty"`
+
 	// Strategy can optionally be used to define the deployment strategy
 	// of the vtgate deployment.
 	Strategy appsv1.DeploymentStrategy `json:"strategy,omitempty"`
+
+	// Pools can optionally be used to deploy additional, named pools of
+	// vtgates in this cell, each with its own Deployment and Service.
+	// For example, a pool can be dedicated to an analytics keyspace so its
+	// traffic doesn't compete with OLTP traffic on the main vtgates.
+	//
+	// Pool vtgates are not part of the cell's main vtgate Service or the
+	// cluster-wide vtgate Service; clients must connect to the pool's own
+	// Service. Settings not available on the pool (e.g. authentication,
+	// secure transport, volumes and scheduling) are inherited from this
+	// gateway spec.
+	// +patchMergeKey=name
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=name
+	Pools []VitessGatewayPoolSpec `json:"pools,omitempty"`
+}
+
+// VitessGatewayPoolSpec configures a named pool of vtgates within a cell.
+type VitessGatewayPoolSpec struct {
+	// Name is the pool name, which must be unique within the cell.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=25
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([a-z0-9-]*[a-z0-9])?$
+	Name string `json:"name"`
+
+	// Replicas is the number of vtgate instances to deploy in this pool.
+	// Default: 1
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Autoscaler specifies the pod autoscaling configuration to use
+	// for this pool's vtgate Deployment.
+	// +optional
+	Autoscaler *AutoscalerSpec `json:"autoscaler,omitempty"`
+
+	// Resources determines the compute resources reserved for each vtgate
+	// replica in this pool.
+	// Default: the resources of the cell's main vtgates.
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Keyspaces optionally limits this pool to serving only the listed
+	// keyspaces. If empty, the pool serves all keyspaces.
+	// +listType=set
+	Keyspaces []string `json:"keyspaces,omitempty"`
+
+	// TabletTypes optionally limits the tablet types to which this pool is
+	// allowed to route queries (e.g. "replica" and "rdonly" for a read-only
+	// pool). If empty, the pool may use all tablet types.
+	// +kubebuilder:validation:items:Enum=primary;replica;rdonly
+	// +listType=set
+	TabletTypes []string `json:"tabletTypes,omitempty"`
+
+	// ExtraFlags can optionally be used to pass additional flags to the
+	// vtgates in this pool. These are applied on top of the cell gateway's
+	// ExtraFlags.
+	ExtraFlags map[string]string `json:"extraFlags,omitempty"`
+
+	// Annotations can optionally be used to attach custom annotations to Pods
+	// in this pool, in addition to the cell gateway's Annotations.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// ExtraLabels can optionally be used to attach custom labels to Pods in
+	// this pool, in addition to the cell gateway's ExtraLabels.
+	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
+
+	// Service can optionally be used to customize this pool's vtgate Service.
+	Service *ServiceOverrides `json:"service,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget (PDB) that covers
+	// the vtgate Pods in this pool.
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+}
+
+// VitessGatewayDrainSpec configures how vtgates drain client connections
+// before they shut down.
+//
+// When a vtgate Pod is deleted, Kubernetes removes it from the Service
+// endpoints, but it takes a moment for load balancers and kube-proxy to stop
+// sending new connections to it. A preStop hook first marks vtgate as
+// draining, which fails its readiness probe, and then waits for
+// PreStopDelay before vtgate is told to shut down. vtgate then stops
+// accepting new connections and waits up to Timeout for connected clients
+// to disconnect before it exits.
+//
+// Unless a Strategy is set, the vtgate Deployment also switches to a
+// rolling update that brings up new vtgates before any old ones start to
+// drain, so serving capacity never drops during a rollout.
+//
+// Drain progress is reported as DrainingReplicas in the gateway status, and
+// each vtgate exports its remaining client connections as the
+// MysqlServerConnCount metric.
+type VitessGatewayDrainSpec struct {
+	// PreStopDelay is how long a vtgate keeps serving normally after its Pod
+	// has been deleted, so new connections stop arriving before it drains.
+	// This is ignored if Lifecycle sets its own preStop hook.
+	// Default: 5s
+	PreStopDelay *metav1.Duration `json:"preStopDelay,omitempty"`
+
+	// Timeout is how long vtgate waits for connected clients to disconnect
+	// after it stops accepting new connections. Clients still connected
+	// after this are cut off.
+	// Unless TerminationGracePeriodSeconds is set, the grace period is
+	// extended to cover PreStopDelay plus Timeout.
+	// Default: 30s
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
 // VitessGatewayAuthentication configures authentication for vtgate in this cell.
+// At most one authentication method may be specified.
+// +kubebuilder:validation:MaxProperties=1
 type VitessGatewayAuthentication struct {
 	// Static configures vtgate to use a static file containing usernames and passwords.
 	Static *VitessGatewayStaticAuthentication `json:"static,omitempty"`
+
+	// LDAP configures vtgate to authenticate users against an LDAP server.
+	LDAP *VitessGatewayLDAPAuthentication `json:"ldap,omitempty"`
+
+	// ClientCert configures vtgate to authenticate users by their TLS client
+	// certificate. The Common Name (CN) of the certificate is used as the
+	// username, which can then be referenced in table ACLs.
+	//
+	// This requires secureTransport.tls to be configured with a
+	// clientCACertSecret, which is used to verify client certificates.
+	// Until it is, the operator doesn't update vtgate, so vtgate keeps its
+	// current authentication.
+	ClientCert *VitessGatewayClientCertAuthentication `json:"clientCert,omitempty"`
 }
 
 // VitessGatewayStaticAuthentication configures static file authentication for vtgate.
+//
+// vtgate periodically reloads the static auth file, so rotating credentials
+// doesn't require restarting vtgate. The file can come from a Secret that you
+// update, or from a Volume kept up to date by another agent (for example, a
+// credentials file rendered by a Vault agent sidecar into a shared volume).
+// To use a Volume, set secret.volumeName to a Volume added to the vtgate
+// Pods with extraVolumes, and secret.key to the name of the file in it.
+// The operator can't see into such a Volume, so it doesn't report
+// staticAuthContentHash for it.
 type VitessGatewayStaticAuthentication struct {
 	// Secret configures vtgate to load the static auth file from a given key in a given Secret.
 	Secret *SecretSource `json:"secret,omitempty"`
+
+	// ReloadInterval is how often vtgate checks the static auth file for
+	// changes.
+	// Default: 30s
+	ReloadInterval *metav1.Duration `json:"reloadInterval,omitempty"`
+}
+
+// VitessGatewayLDAPAuthentication configures LDAP authentication for vtgate.
+type VitessGatewayLDAPAuthentication struct {
+	// ConfigSecret configures vtgate to load its LDAP auth config file from a
+	// given key in a given Secret. The file is a JSON object with fields such
+	// as LdapServer, LdapCA, User, Password, UserDnPattern, GroupQuery and
+	// RefreshSeconds. See the Vitess documentation for details.
+	//
+	// vtgate only reads this file at startup, so changing the Secret triggers
+	// a rolling restart of vtgate.
+	ConfigSecret *SecretSource `json:"configSecret"`
+
+	// Method is the MySQL authentication method that clients use to send
+	// their LDAP password to vtgate.
+	// Default: mysql_clear_password
+	// +kubebuilder:validation:Enum=mysql_clear_password;dialog
+	Method string `json:"method,omitempty"`
+}
+
+// VitessGatewayClientCertAuthentication configures TLS client certificate
+// authentication for vtgate.
+type VitessGatewayClientCertAuthentication struct {
+	// Method is the MySQL authentication method that vtgate advertises to
+	// clients that present a certificate.
+	// Default: mysql_clear_password
+	// +kubebuilder:validation:Enum=mysql_clear_password;dialog
+	Method string `json:"method,omitempty"`
 }
 
 // VitessGatewaySecureTransport configures secure transport connections for vtgate.
@@ -281,6 +489,18 @@ type VitessGatewayTLSSecureTransport struct {
 	CertSecret *SecretSource `json:"certSecret,omitempty"`
 	// KeySecret configures vtgate to load the TLS key PEM file from a given key in a given Secret.
 	KeySecret *SecretSource `json:"keySecret,omitempty"`
+
+	// OperatorManaged configures the operator to issue and renew vtgate's
+	// serving certificate itself, instead of loading it from CertSecret and
+	// KeySecret, which are ignored when this is set.
+	//
+	// The certificate covers the names of this cell's vtgate Services and the
+	// cluster-wide vtgate Service, and is signed by a CA that the operator
+	// generates for the VitessCluster. Clients can get the CA certificate
+	// from the "ca.crt" key of the Secret named in the VitessCluster status
+	// (gatewayCA.secretName). Renewing the certificate triggers a rolling
+	// restart of vtgate.
+	OperatorManaged *OperatorManagedTLS `json:"operatorManaged,omitempty"`
 }
 
 // VitessCellGatewayStatus is a summary of the status of vtgate in this cell.
@@ -296,6 +516,42 @@ type VitessCellGatewayStatus struct {
 	// HorizontalPodAutoscaler to determine the current number of replicas.
 	// +kubebuilder:validation:Minimum=0
 	Replicas int32 `json:"replicas,omitempty"`
+	// TLS describes the serving certificate issued by the operator, if
+	// operator-managed TLS is enabled.
+	TLS *CertificateStatus `json:"tls,omitempty"`
+	// StaticAuthContentHash is a hash of the contents of the static auth
+	// Secret, if one is configured. When this changes, vtgates pick up the new
+	// credentials on their next reload without being restarted.
+	StaticAuthContentHash string `json:"staticAuthContentHash,omitempty"`
+	// DrainingReplicas is the number of vtgates that are shutting down and
+	// may still be serving connected clients.
+	DrainingReplicas int32 `json:"drainingReplicas,omitempty"`
+	// Pools is a summary of the status of each vtgate pool in this cell,
+	// keyed by pool name.
+	Pools map[string]VitessCellGatewayPoolStatus `json:"pools,omitempty"`
+	// External describes how clients outside the Kubernetes cluster can
+	// reach this cell's vtgates, if External is set in the gateway spec.
+	External *VitessGatewayExternalStatus `json:"external,omitempty"`
+}
+
+// VitessCellGatewayPoolStatus is a summary of the status of a vtgate pool.
+type VitessCellGatewayPoolStatus struct {
+	// Available indicates whether the pool's vtgate service is fully available.
+	Available corev1.ConditionStatus `json:"available,omitempty"`
+	// ServiceName is the name of the Service for this pool's vtgates.
+	ServiceName string `json:"serviceName,omitempty"`
+	// Replicas is the desired number of vtgates in this pool.
+	Replicas int32 `json:"replicas,omitempty"`
+	// DrainingReplicas is the number of vtgates in this pool that are
+	// shutting down and may still be serving connected clients.
+	DrainingReplicas int32 `json:"drainingReplicas,omitempty"`
+}
+
+// NewVitessCellGatewayPoolStatus creates a new status object with default values.
+func NewVitessCellGatewayPoolStatus() VitessCellGatewayPoolStatus {
+	return VitessCellGatewayPoolStatus{
+		Available: corev1.ConditionUnknown,
+	}
 }
 
 // VitessCellStatus defines the observed state of VitessCell
@@ -316,6 +572,16 @@ type VitessCellStatus struct {
 	// If Idle is True, there are no keyspaces deployed in the cell, so it
 	// should be safe to turn down the cell.
 	Idle corev1.ConditionStatus `json:"idle,omitempty"`
+	// PendingPruneSrvKeyspaces is a list of unwanted keyspaces whose serving
+	// records in this cell were not pruned because PruneSrvKeyspacesDryRun is
+	// enabled. Each one is pruned once it's approved with the
+	// "topo.planetscale.com/approve-prune-srv-keyspaces" annotation on this
+	// VitessCell.
+	PendingPruneSrvKeyspaces []string `json:"pendingPruneSrvKeyspaces,omitempty"`
+	// UsedPruneApprovals lists, for each approval annotation on this
+	// VitessCell, the approved names whose records have already been
+	// pruned. They're ignored until they're removed from the annotation.
+	UsedPruneApprovals map[string][]string `json:"usedPruneApprovals,omitempty"`
 }
 
 // NewVitessCellStatus creates a new status object with default values.
@@ -323,6 +589,7 @@ func NewVitessCellStatus() VitessCellStatus {
 	return VitessCellStatus{
 		Gateway: VitessCellGatewayStatus{
 			Available: corev1.ConditionUnknown,
+			Pools:     make(map[string]VitessCellGatewayPoolStatus),
 		},
 		Keyspaces: make(map[string]VitessCellKeyspaceStatus),
 		Idle:      corev1.ConditionUnknown,
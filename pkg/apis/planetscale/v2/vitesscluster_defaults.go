@@ -18,7 +18,10 @@ package v2
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 )
 
@@ -34,6 +37,9 @@ func DefaultVitessCluster(vt *VitessCluster) {
 	DefaultUpdateStrategy(&vt.Spec.UpdateStrategy)
 	DefaultServiceOverrides(&vt.Spec.GatewayService)
 	DefaultServiceOverrides(&vt.Spec.TabletService)
+	DefaultVitessGatewayExternal(vt.Spec.GatewayExternal)
+	DefaultVitessInternalTLS(vt.Spec.InternalTLS)
+	DefaultVitessNetworkPolicies(vt.Spec.NetworkPolicies)
 }
 
 func defaultGlobalLockserver(vt *VitessCluster) {
@@ -43,11 +49,25 @@ func defaultGlobalLockserver(vt *VitessCluster) {
 		// We'll just pass those params to Vitess.
 		return
 	}
-	if gls.Etcd == nil {
+	if gls.Etcd == nil && gls.Consul == nil && gls.Zk == nil {
 		// By default, deploy our own etcd cluster with default settings.
 		gls.Etcd = &EtcdLockserverTemplate{}
 	}
-	DefaultEtcdLockserverTemplate(gls.Etcd)
+	defaultManagedLockserver(gls)
+}
+
+// defaultManagedLockserver fills in defaults for whichever lockservers we
+// were asked to deploy.
+func defaultManagedLockserver(ls *LockserverSpec) {
+	if ls.Etcd != nil {
+		DefaultEtcdLockserverTemplate(ls.Etcd)
+	}
+	if ls.Consul != nil {
+		DefaultConsulLockserverTemplate(ls.Consul)
+	}
+	if ls.Zk != nil {
+		DefaultZkLockserverTemplate(ls.Zk)
+	}
 }
 
 // DefaultVitessImages copies images from src to dst to fill any
@@ -98,6 +118,7 @@ func DefaultVitessDashboard(dashboard **VitessDashboardSpec) {
 		}
 	}
 	DefaultServiceOverrides(&(*dashboard).Service)
+	DefaultPodDisruptionBudget(&(*dashboard).PodDisruptionBudget)
 }
 
 func DefaultVtAdmin(dashboard **VtAdminSpec) {
@@ -214,6 +235,26 @@ func DefaultTopoReconcileConfig(confPtr **TopoReconcileConfig) {
 	if conf.PruneSrvKeyspaces == nil {
 		conf.PruneSrvKeyspaces = ptr.To(true)
 	}
+
+	// Defaulting pruning dry-run code.
+	if conf.PruneCellsDryRun == nil {
+		conf.PruneCellsDryRun = ptr.To(false)
+	}
+	if conf.PruneKeyspacesDryRun == nil {
+		conf.PruneKeyspacesDryRun = ptr.To(false)
+	}
+	if conf.PruneShardsDryRun == nil {
+		conf.PruneShardsDryRun = ptr.To(false)
+	}
+	if conf.PruneShardCellsDryRun == nil {
+		conf.PruneShardCellsDryRun = ptr.To(false)
+	}
+	if conf.PruneTabletsDryRun == nil {
+		conf.PruneTabletsDryRun = ptr.To(false)
+	}
+	if conf.PruneSrvKeyspacesDryRun == nil {
+		conf.PruneSrvKeyspacesDryRun = ptr.To(false)
+	}
 }
 
 func DefaultUpdateStrategy(updateStratPtr **VitessClusterUpdateStrategy) {
@@ -242,3 +283,61 @@ func DefaultServiceOverrides(so **ServiceOverrides) {
 		*so = &ServiceOverrides{}
 	}
 }
+
+// DefaultPodDisruptionBudget applies defaults to a PodDisruptionBudgetSpec field.
+func DefaultPodDisruptionBudget(pdb **PodDisruptionBudgetSpec) {
+	if *pdb == nil {
+		*pdb = &PodDisruptionBudgetSpec{}
+	}
+	if (*pdb).Create == nil {
+		(*pdb).Create = ptr.To(defaultCreatePDB)
+	}
+	if (*pdb).MaxUnavailable == nil {
+		(*pdb).MaxUnavailable = ptr.To(intstr.FromInt32(defaultPDBMaxUnavailable))
+	}
+}
+
+// DefaultOperatorManagedTLS fills in defaults for operator-managed TLS, if enabled.
+func DefaultOperatorManagedTLS(tls *OperatorManagedTLS) {
+	if tls == nil {
+		return
+	}
+	if tls.Duration == nil || tls.Duration.Duration <= 0 {
+		tls.Duration = &metav1.Duration{Duration: defaultManagedTLSDuration}
+	}
+	if tls.RenewBefore == nil {
+		tls.RenewBefore = &metav1.Duration{Duration: defaultManagedTLSRenewBefore}
+	}
+	// A certificate that's due for renewal as soon as it's issued would be
+	// reissued on every reconcile. Fall back to a third of the duration,
+	// which is the same ratio as the defaults.
+	if tls.RenewBefore.Duration < 0 || tls.RenewBefore.Duration >= tls.Duration.Duration {
+		tls.RenewBefore = &metav1.Duration{Duration: tls.Duration.Duration / 3}
+	}
+}
+
+// DefaultVitessInternalTLS fills in defaults for internal gRPC TLS, if enabled.
+func DefaultVitessInternalTLS(tls *VitessInternalTLSSpec) {
+	if tls == nil {
+		return
+	}
+	if tls.ServerName == "" {
+		tls.ServerName = defaultInternalTLSServerName
+	}
+	DefaultOperatorManagedTLS(tls.OperatorManaged)
+}
+
+// DefaultVitessNetworkPolicies fills in defaults for NetworkPolicies, if enabled.
+func DefaultVitessNetworkPolicies(np *VitessNetworkPolicySpec) {
+	if np == nil {
+		return
+	}
+	if len(np.OperatorIngress) == 0 {
+		np.OperatorIngress = []networkingv1.NetworkPolicyPeer{
+			{
+				PodSelector:       &metav1.LabelSelector{MatchLabels: defaultOperatorPodLabels},
+				NamespaceSelector: &metav1.LabelSelector{},
+			},
+		}
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDefaultOperatorManagedTLS(t *testing.T) {
+	tests := []struct {
+		name                      string
+		duration, renewBefore     *metav1.Duration
+		wantDuration, wantRenewal time.Duration
+	}{
+		{name: "defaults", wantDuration: defaultManagedTLSDuration, wantRenewal: defaultManagedTLSRenewBefore},
+		{name: "valid", duration: &metav1.Duration{Duration: 24 * time.Hour}, renewBefore: &metav1.Duration{Duration: time.Hour}, wantDuration: 24 * time.Hour, wantRenewal: time.Hour},
+		{name: "renew before longer than duration", duration: &metav1.Duration{Duration: 24 * time.Hour}, wantDuration: 24 * time.Hour, wantRenewal: 8 * time.Hour},
+		{name: "renew before equal to duration", duration: &metav1.Duration{Duration: 24 * time.Hour}, renewBefore: &metav1.Duration{Duration: 24 * time.Hour}, wantDuration: 24 * time.Hour, wantRenewal: 8 * time.Hour},
+		{name: "negative renew before", renewBefore: &metav1.Duration{Duration: -time.Hour}, wantDuration: defaultManagedTLSDuration, wantRenewal: defaultManagedTLSDuration / 3},
+		{name: "zero duration", duration: &metav1.Duration{}, wantDuration: defaultManagedTLSDuration, wantRenewal: defaultManagedTLSRenewBefore},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tls := &OperatorManagedTLS{Duration: test.duration, RenewBefore: test.renewBefore}
+			DefaultOperatorManagedTLS(tls)
+			assert.Equal(t, test.wantDuration, tls.Duration.Duration)
+			assert.Equal(t, test.wantRenewal, tls.RenewBefore.Duration)
+		})
+	}
+}
@@ -17,7 +17,12 @@ limitations under the License.
 package v2
 
 import (
+	"fmt"
+	"maps"
+	"slices"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 // Cell looks up an item in the Cells list by name.
@@ -41,6 +46,32 @@ func (s *VitessClusterSpec) ZoneMap() map[string]string {
 	return zones
 }
 
+// ValidateCellsAliases checks that every cell listed in CellsAliases is
+// defined in Cells, that no cell is in more than one alias, and that no
+// alias has the same name as a cell.
+func (s *VitessClusterSpec) ValidateCellsAliases() error {
+	aliasOf := make(map[string]string)
+	for _, alias := range slices.Sorted(maps.Keys(s.CellsAliases)) {
+		cells := s.CellsAliases[alias]
+		if s.Cell(alias) != nil {
+			return fmt.Errorf("cells alias %q has the same name as a cell", alias)
+		}
+		if len(cells) == 0 {
+			return fmt.Errorf("cells alias %q has no cells", alias)
+		}
+		for _, cellName := range cells {
+			if s.Cell(cellName) == nil {
+				return fmt.Errorf("cells alias %q refers to non-existent cell %q", alias, cellName)
+			}
+			if other, ok := aliasOf[cellName]; ok && other != alias {
+				return fmt.Errorf("cell %q is in both cells alias %q and %q", cellName, other, alias)
+			}
+			aliasOf[cellName] = alias
+		}
+	}
+	return nil
+}
+
 // Image returns the first mysqld flavor image that's set.
 func (image *MysqldImage) Image() string {
 	switch {
@@ -83,3 +114,63 @@ func (externalOptions *ExternalVitessClusterUpdateStrategyOptions) ResourceChang
 
 	return false
 }
+
+// SecretNames returns the names of the Secrets that hold the internal TLS
+// certificate, key and CA. Components must be restarted when these change.
+func (t *VitessInternalTLSSpec) SecretNames() sets.String {
+	secretNames := sets.NewString()
+	if t == nil {
+		return secretNames
+	}
+	for _, secret := range []*SecretSource{t.CertSecret, t.KeySecret, t.CACertSecret} {
+		if secret != nil && secret.Name != "" {
+			secretNames.Insert(secret.Name)
+		}
+	}
+	return secretNames
+}
+
+// ManagedConsul returns the template for a Consul cluster that we should
+// deploy as this lockserver, or nil if we shouldn't deploy one.
+func (s *LockserverSpec) ManagedConsul() *ConsulLockserverTemplate {
+	if s.External != nil || s.Etcd != nil {
+		return nil
+	}
+	return s.Consul
+}
+
+// ManagedZk returns the template for a ZooKeeper ensemble that we should
+// deploy as this lockserver, or nil if we shouldn't deploy one.
+func (s *LockserverSpec) ManagedZk() *ZkLockserverTemplate {
+	if s.External != nil || s.Etcd != nil || s.Consul != nil {
+		return nil
+	}
+	return s.Zk
+}
+
+// lockserverMigrationPhases lists the phases of a lockserver migration in order.
+var lockserverMigrationPhases = []LockserverMigrationPhase{
+	LockserverMigrationCopying,
+	LockserverMigrationVerifying,
+	LockserverMigrationSwitchingWriters,
+	LockserverMigrationSwitchingCells,
+	LockserverMigrationComplete,
+}
+
+// Reached returns whether the migration has progressed at least as far as the
+// given phase.
+func (s *LockserverMigrationStatus) Reached(phase LockserverMigrationPhase) bool {
+	if s == nil {
+		return false
+	}
+	return slices.Index(lockserverMigrationPhases, s.Phase) >= slices.Index(lockserverMigrationPhases, phase)
+}
+
+// NextPhase returns the phase that follows the current one.
+func (s *LockserverMigrationStatus) NextPhase() LockserverMigrationPhase {
+	i := slices.Index(lockserverMigrationPhases, s.Phase)
+	if i < 0 {
+		return LockserverMigrationCopying
+	}
+	return lockserverMigrationPhases[min(i+1, len(lockserverMigrationPhases)-1)]
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCellsAliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		aliases map[string][]string
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "by region", aliases: map[string][]string{"useast1": {"zone1", "zone2"}, "uswest2": {"zone3"}}},
+		{name: "non-existent cell rejected", aliases: map[string][]string{"useast1": {"zone1", "zone4"}}, wantErr: true},
+		{name: "cell in two aliases rejected", aliases: map[string][]string{"useast1": {"zone1", "zone2"}, "all": {"zone2", "zone3"}}, wantErr: true},
+		{name: "same name as cell rejected", aliases: map[string][]string{"zone1": {"zone1"}}, wantErr: true},
+		{name: "empty alias rejected", aliases: map[string][]string{"useast1": {}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &VitessClusterSpec{
+				Cells:        []VitessCellTemplate{{Name: "zone1"}, {Name: "zone2"}, {Name: "zone3"}},
+				CellsAliases: tt.aliases,
+			}
+			err := spec.ValidateCellsAliases()
+			if tt.wantErr {
+				require.Error(t, err, "ValidateCellsAliases() error = nil, want error")
+			} else {
+				require.NoError(t, err, "ValidateCellsAliases() error = %v, want nil", err)
+			}
+		})
+	}
+}
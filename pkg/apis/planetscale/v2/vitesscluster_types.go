@@ -19,6 +19,7 @@ package v2
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
@@ -92,6 +93,23 @@ type VitessClusterSpec struct {
 	// +patchStrategy=merge
 	Cells []VitessCellTemplate `json:"cells" patchStrategy:"merge" patchMergeKey:"name"`
 
+	// CellsAliases defines named groups of cells, such as all the cells in
+	// a region. vtgate uses cells aliases to decide which replica tablets in
+	// other cells it may route queries to. Each key is an alias name and
+	// each value is the list of cells in that alias.
+	//
+	// Every cell listed must be defined in Cells, a cell may belong to at
+	// most one alias, and an alias can't have the same name as a cell.
+	//
+	// If this is set, it replaces the default alias that the operator
+	// registers with all cells in it. Aliases that the operator registered
+	// are removed from topology when they're removed from this map.
+	// This has no effect unless TopologyReconciliation.RegisterCellsAliases
+	// is enabled.
+	//
+	// Default: A single alias that contains all cells.
+	CellsAliases map[string][]string `json:"cellsAliases,omitempty"`
+
 	// Keyspaces defines the logical databases to deploy.
 	//
 	// A VitessKeyspace can deploy to multiple VitessCells.
@@ -125,8 +143,64 @@ type VitessClusterSpec struct {
 	// definition.
 	GatewayService *ServiceOverrides `json:"gatewayService,omitempty"`
 
+	// GatewayExternal can optionally be used to expose the vtgates of all
+	// cells to clients outside the Kubernetes cluster through a single
+	// endpoint. Per-cell endpoints can be configured within each cell
+	// definition.
+	GatewayExternal *VitessGatewayExternalSpec `json:"gatewayExternal,omitempty"`
+
+	// GatewayTopology can optionally be used to route clients to vtgates in
+	// their own zone.
+	GatewayTopology *VitessGatewayTopologySpec `json:"gatewayTopology,omitempty"`
+
 	// TabletService can optionally be used to customize the global, headless vttablet Service.
 	TabletService *ServiceOverrides `json:"tabletService,omitempty"`
+
+	// InternalTLS can optionally be used to encrypt gRPC traffic between
+	// vtgate, vttablet, vtctld and vtorc with mutual TLS.
+	InternalTLS *VitessInternalTLSSpec `json:"internalTLS,omitempty"`
+
+	// NetworkPolicies can optionally be used to create NetworkPolicies that
+	// only allow the traffic each component needs.
+	// Default: Don't create any NetworkPolicies.
+	NetworkPolicies *VitessNetworkPolicySpec `json:"networkPolicies,omitempty"`
+}
+
+// VitessInternalTLSSpec configures mutual TLS for gRPC traffic between
+// Vitess components.
+//
+// Every component serves gRPC with the same certificate, and requires clients
+// to present a certificate signed by the same CA. Clients verify that the
+// server certificate is valid for ServerName, rather than for the address
+// they dialed, so the certificate doesn't need to cover Pod IPs.
+//
+// Changing the contents of the certificate Secrets triggers a rolling restart
+// of all components.
+//
+// The operator uses the same certificate for its own connections to vttablet.
+// It reads the certificate from the Secrets, so this only works if they are
+// given by name rather than by volumeName.
+type VitessInternalTLSSpec struct {
+	// OperatorManaged configures the operator to generate a CA and issue and
+	// renew the certificate itself. When this is set, CertSecret, KeySecret
+	// and CACertSecret are ignored. The certificate and CA are stored in the
+	// Secret named in the VitessCluster status (internalTLS.secretName).
+	OperatorManaged *OperatorManagedTLS `json:"operatorManaged,omitempty"`
+
+	// CertSecret is the Secret key containing the PEM-encoded certificate
+	// used by all components, as both a server and a client certificate.
+	CertSecret *SecretSource `json:"certSecret,omitempty"`
+	// KeySecret is the Secret key containing the PEM-encoded private key
+	// for CertSecret.
+	KeySecret *SecretSource `json:"keySecret,omitempty"`
+	// CACertSecret is the Secret key containing the PEM-encoded CA
+	// certificate used to verify both servers and clients.
+	CACertSecret *SecretSource `json:"caCertSecret,omitempty"`
+
+	// ServerName is the name that clients expect to find in the server
+	// certificate. It must be one of the DNS names in the certificate.
+	// Default: vitess-internal
+	ServerName string `json:"serverName,omitempty"`
 }
 
 // VitessClusterUpdateStrategy indicates the strategy that the operator
@@ -178,6 +252,11 @@ type ExternalVitessClusterUpdateStrategyOptions struct {
 
 // TopoReconcileConfig can be used to turn on or off registration or pruning of specific vitess components from topo records.
 // This should only be necessary if you need to override defaults, and shouldn't be required for the vast majority of use cases.
+//
+// Each approval annotation used by the dry-run options only counts once. After the approved record is pruned,
+// its name is listed under usedPruneApprovals in the status of the annotated object and ignored from then on,
+// so the approval doesn't carry over to a new record that later gets the same name. To approve the same name
+// again, remove it from the annotation and add it back.
 type TopoReconcileConfig struct {
 	// RegisterCellsAliases can be used to enable or disable registering cells aliases into topo records.
 	// Default: true
@@ -191,25 +270,76 @@ type TopoReconcileConfig struct {
 	// Default: true
 	PruneCells *bool `json:"pruneCells,omitempty"`
 
+	// PruneCellsDryRun can be used to report extraneous cells instead of pruning them right away.
+	// Cells that would be pruned are listed in the VitessCluster status under pendingPruneCells,
+	// and each one is only pruned once its name is added to the comma-separated list in the
+	// "topo.planetscale.com/approve-prune-cells" annotation on the VitessCluster.
+	// This has no effect unless PruneCells is enabled.
+	// Default: false
+	PruneCellsDryRun *bool `json:"pruneCellsDryRun,omitempty"`
+
 	// PruneKeyspaces can be used to enable or disable pruning of extraneous keyspaces from topo records.
 	// Default: true
 	PruneKeyspaces *bool `json:"pruneKeyspaces,omitempty"`
 
+	// PruneKeyspacesDryRun can be used to report extraneous keyspaces instead of pruning them right away.
+	// Keyspaces that would be pruned are listed in the VitessCluster status under pendingPruneKeyspaces,
+	// and each one is only pruned once its name is added to the comma-separated list in the
+	// "topo.planetscale.com/approve-prune-keyspaces" annotation on the VitessCluster.
+	// This has no effect unless PruneKeyspaces is enabled.
+	// Default: false
+	PruneKeyspacesDryRun *bool `json:"pruneKeyspacesDryRun,omitempty"`
+
 	// PruneSrvKeyspaces can be used to enable or disable pruning of extraneous serving keyspaces from topo records.
 	// Default: true
 	PruneSrvKeyspaces *bool `json:"pruneSrvKeyspaces,omitempty"`
 
+	// PruneSrvKeyspacesDryRun can be used to report extraneous serving keyspaces instead of pruning them right away.
+	// Keyspaces whose serving records would be pruned are listed in the VitessCell status under
+	// pendingPruneSrvKeyspaces, and each one is only pruned once its name is added to the comma-separated list in
+	// the "topo.planetscale.com/approve-prune-srv-keyspaces" annotation on the VitessCell.
+	// This has no effect unless PruneSrvKeyspaces is enabled.
+	// Default: false
+	PruneSrvKeyspacesDryRun *bool `json:"pruneSrvKeyspacesDryRun,omitempty"`
+
 	// PruneShards can be used to enable or disable pruning of extraneous shards from topo records.
 	// Default: true
 	PruneShards *bool `json:"pruneShards,omitempty"`
 
+	// PruneShardsDryRun can be used to report extraneous shards instead of pruning them right away.
+	// Shards that would be pruned are listed in the VitessKeyspace status under pendingPruneShards,
+	// and each one is only pruned once its name (e.g. "-80") is added to the comma-separated list in the
+	// "topo.planetscale.com/approve-prune-shards" annotation on the VitessKeyspace.
+	// The annotation can be set through the annotations field of the keyspace template.
+	// This has no effect unless PruneShards is enabled.
+	// Default: false
+	PruneShardsDryRun *bool `json:"pruneShardsDryRun,omitempty"`
+
 	// PruneShardCells can be used to enable or disable pruning of extraneous shard cells from topo records.
 	// Default: true
 	PruneShardCells *bool `json:"pruneShardCells,omitempty"`
 
+	// PruneShardCellsDryRun can be used to report extraneous shard cells instead of pruning them right away.
+	// Cells that would be removed from a shard record are listed in the VitessShard status under
+	// pendingPruneShardCells, and each one is only removed once its name is added to the comma-separated list in
+	// the "topo.planetscale.com/approve-prune-shard-cells" annotation on the VitessShard.
+	// The annotation can be set through the annotations field of the shard template.
+	// This has no effect unless PruneShardCells is enabled.
+	// Default: false
+	PruneShardCellsDryRun *bool `json:"pruneShardCellsDryRun,omitempty"`
+
 	// PruneTablets can be used to enable or disable pruning of extraneous tablets from topo records.
 	// Default: true
 	PruneTablets *bool `json:"pruneTablets,omitempty"`
+
+	// PruneTabletsDryRun can be used to report extraneous tablets instead of pruning them right away.
+	// Tablets that would be pruned are listed in the VitessShard status under pendingPruneTablets,
+	// and each one is only pruned once its alias (e.g. "zone1-0000000101") is added to the comma-separated list
+	// in the "topo.planetscale.com/approve-prune-tablets" annotation on the VitessShard.
+	// The annotation can be set through the annotations field of the shard template.
+	// This has no effect unless PruneTablets is enabled.
+	// Default: false
+	PruneTabletsDryRun *bool `json:"pruneTabletsDryRun,omitempty"`
 }
 
 // VitessImages specifies container images to use for Vitess components.
@@ -352,15 +482,127 @@ type LockserverSpec struct {
 	// Etcd deploys our own etcd cluster as a lockserver.
 	Etcd *EtcdLockserverTemplate `json:"etcd,omitempty"`
 
+	// Consul deploys our own Consul cluster as a lockserver.
+	// Only one of Etcd, Consul and Zk should be set. If more than one is set,
+	// Etcd takes precedence over Consul, and Consul over Zk.
+	Consul *ConsulLockserverTemplate `json:"consul,omitempty"`
+
+	// Zk deploys our own ZooKeeper ensemble as a lockserver.
+	// Only one of Etcd, Consul and Zk should be set.
+	Zk *ZkLockserverTemplate `json:"zk,omitempty"`
+
 	// CellInfoAddress is the host:port of topology service which will be saved to cell info.
-	// Default: etcd client service.
+	// Default: the client Service of the lockserver we deploy.
 	CellInfoAddress string `json:"cellInfoAddress,omitempty"`
+
+	// MigrateFrom tells the operator to move the global lockserver from the
+	// one described here, which Vitess components use today, to the one
+	// described by the rest of this LockserverSpec.
+	//
+	// The operator first stops vtorc in every keyspace, so nothing reparents
+	// on its own, and then copies topology data from the old lockserver to the
+	// new one and verifies the copy. It then moves everything that writes to
+	// topology (vtctld, vttablet, vtorc and vtbackup) to the new lockserver
+	// together, and after that the cells (vtgate), waiting for each group to
+	// be fully rolled out before the next. vtorc comes back once it has moved.
+	// The operator never writes to the old lockserver and never deletes it.
+	//
+	// After each group has moved, the operator checks that nothing but tablet
+	// records (which tablets write again when they move) has changed on the
+	// old lockserver since the copy was verified. If something has, those
+	// changes only exist on the old lockserver, so the migration stops and
+	// says so in status instead of moving on. Avoid changing the topology
+	// through the old vtctld while a migration is in progress.
+	//
+	// Remove this field once the migration is Complete and you've verified the
+	// cluster on the new lockserver. Removing it earlier moves all components
+	// to the new lockserver at once.
+	//
+	// This is only supported for the global lockserver, and is ignored for cells.
+	MigrateFrom *VitessLockserverParams `json:"migrateFrom,omitempty"`
 }
 
 // LockserverStatus is the lockserver component of status.
 type LockserverStatus struct {
 	// Etcd is the status of the EtcdCluster, if we were asked to deploy one.
 	Etcd *EtcdLockserverStatus `json:"etcd,omitempty"`
+	// Consul is the status of the ConsulLockserver, if we were asked to deploy one.
+	Consul *ConsulLockserverStatus `json:"consul,omitempty"`
+	// Zk is the status of the ZkLockserver, if we were asked to deploy one.
+	Zk *ZkLockserverStatus `json:"zk,omitempty"`
+}
+
+// LockserverMigrationPhase is a step in moving the global lockserver.
+// +kubebuilder:validation:Enum=Copying;Verifying;SwitchingWriters;SwitchingCells;Complete
+type LockserverMigrationPhase string
+
+const (
+	// LockserverMigrationCopying means vtorc is being stopped and topology
+	// data is being copied to the new lockserver.
+	LockserverMigrationCopying LockserverMigrationPhase = "Copying"
+	// LockserverMigrationVerifying means the copy is being compared against
+	// the old lockserver.
+	LockserverMigrationVerifying LockserverMigrationPhase = "Verifying"
+	// LockserverMigrationSwitchingWriters means vtctld, vttablet and vtbackup
+	// are being moved to the new lockserver together, while vtorc is stopped.
+	LockserverMigrationSwitchingWriters LockserverMigrationPhase = "SwitchingWriters"
+	// LockserverMigrationSwitchingCells means vtgate is being moved to the new
+	// lockserver.
+	LockserverMigrationSwitchingCells LockserverMigrationPhase = "SwitchingCells"
+	// LockserverMigrationComplete means all components use the new lockserver.
+	LockserverMigrationComplete LockserverMigrationPhase = "Complete"
+)
+
+// LockserverMigrationStatus is the progress of moving the global lockserver.
+type LockserverMigrationStatus struct {
+	// From is the lockserver that is being migrated away from.
+	From VitessLockserverParams `json:"from"`
+	// Phase is the current step of the migration.
+	Phase LockserverMigrationPhase `json:"phase,omitempty"`
+	// CopyTime is when topology data was last copied to the new lockserver.
+	CopyTime *metav1.Time `json:"copyTime,omitempty"`
+	// VerifiedChecksum is a checksum of the topology data in the old
+	// lockserver, except tablet records, taken when the copy was verified.
+	VerifiedChecksum string `json:"verifiedChecksum,omitempty"`
+	// Message explains what the migration is waiting for, if anything.
+	Message string `json:"message,omitempty"`
+}
+
+// TopologyDriftStatus is the result of comparing the Vitess topology with a
+// VitessCluster and the objects it manages.
+type TopologyDriftStatus struct {
+	// AuditTime is when the topology was last compared.
+	AuditTime *metav1.Time `json:"auditTime,omitempty"`
+	// Error is set if part of the topology couldn't be read during the last
+	// audit. The lists only cover the parts that could be read.
+	Error string `json:"error,omitempty"`
+	// Unknown lists records that exist in topology but are not wanted by
+	// the VitessCluster. Records that are being kept around by a blocked
+	// turn-down (orphans) are not included, since pruning skips them too.
+	// Tablets and SrvKeyspaces are only listed for cells in this cluster.
+	Unknown TopologyDriftRecords `json:"unknown,omitempty"`
+	// Missing lists records that are wanted by the VitessCluster but don't
+	// exist in topology yet.
+	Missing TopologyDriftRecords `json:"missing,omitempty"`
+}
+
+// TopologyDriftRecords lists topology records by type.
+// Each list is sorted and holds at most 50 entries.
+// The full counts are exported as metrics.
+type TopologyDriftRecords struct {
+	// Cells is a list of cell names.
+	Cells []string `json:"cells,omitempty"`
+	// Keyspaces is a list of keyspace names.
+	Keyspaces []string `json:"keyspaces,omitempty"`
+	// Shards is a list of shards in the form "keyspace/shard".
+	Shards []string `json:"shards,omitempty"`
+	// Tablets is a list of tablet aliases in the form "cell-uid".
+	Tablets []string `json:"tablets,omitempty"`
+	// SrvKeyspaces is a list of cell-local keyspace records in the form
+	// "cell/keyspace".
+	SrvKeyspaces []string `json:"srvKeyspaces,omitempty"`
+	// Truncated is true if any of the lists had more than 50 entries.
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 // VitessLockserverParams contains only the values that Vitess needs
@@ -373,6 +615,16 @@ type VitessLockserverParams struct {
 	// RootPath is a path prefix for all lockserver data belonging to a given Vitess cluster.
 	// Multiple Vitess clusters can share a lockserver as long as they have unique root paths.
 	RootPath string `json:"rootPath"`
+	// ClientTLSSecret can optionally be used to connect to the lockserver
+	// with TLS. It names a Secret in the same namespace with the keys
+	// "tls.crt" and "tls.key" for the client certificate and private key,
+	// and "ca.crt" for the CA certificate that signed the server certificate.
+	//
+	// Vitess components and the operator itself use this certificate for
+	// every lockserver they connect to, including cell-local ones, so all
+	// lockservers in a VitessCluster must trust it.
+	// Only the "etcd2" implementation supports this.
+	ClientTLSSecret string `json:"clientTLSSecret,omitempty"`
 }
 
 // VitessDashboardSpec specifies deployment parameters for vtctld.
@@ -446,6 +698,10 @@ type VitessDashboardSpec struct {
 	// Service can optionally be used to customize the vtctld Service.
 	Service *ServiceOverrides `json:"service,omitempty"`
 
+	// PodDisruptionBudget configures the PodDisruptionBudget (PDB) that covers
+	// vtctld Pods in all cells.
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
 	// Tolerations allow you to schedule pods onto nodes with matching taints.
 	// +kubebuilder:validation:Schemaless
 	// +kubebuilder:pruning:PreserveUnknownFields
@@ -571,6 +827,23 @@ type ServiceOverrides struct {
 	ClusterIP string `json:"clusterIP,omitempty"`
 }
 
+// PodDisruptionBudgetSpec configures a PodDisruptionBudget (PDB) that the
+// operator maintains for a component.
+type PodDisruptionBudgetSpec struct {
+	// Create sets whether to create the PDB.
+	//
+	// Note: Disabling this will delete a PDB that was previously created.
+	//
+	// Default: true
+	Create *bool `json:"create,omitempty"`
+
+	// MaxUnavailable is the number of Pods covered by the PDB that may be
+	// evicted at the same time. It can be an absolute number or a percentage.
+	//
+	// Default: 1
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
 // VitessDashboardStatus is a summary of the status of the vtctld deployment.
 type VitessDashboardStatus struct {
 	// Available indicates whether the vtctld service has available endpoints.
@@ -595,9 +868,33 @@ type VitessClusterStatus struct {
 	// GlobalLockserver is the status of the global lockserver.
 	GlobalLockserver LockserverStatus `json:"globalLockserver,omitempty"`
 
+	// GlobalLockserverMigration is the progress of moving the global
+	// lockserver, if GlobalLockserver.MigrateFrom is set.
+	GlobalLockserverMigration *LockserverMigrationStatus `json:"globalLockserverMigration,omitempty"`
+
 	// GatewayServiceName is the name of the cluster-wide vtgate Service.
 	GatewayServiceName string `json:"gatewayServiceName,omitempty"`
 
+	// GatewayExternal describes how clients outside the Kubernetes cluster
+	// can reach the cluster-wide vtgate endpoint, if GatewayExternal is set.
+	GatewayExternal *VitessGatewayExternalStatus `json:"gatewayExternal,omitempty"`
+
+	// GatewayZoneServiceNames maps each zone to the name of the headless
+	// vtgate Service for that zone, if ZoneServices is enabled.
+	GatewayZoneServiceNames map[string]string `json:"gatewayZoneServiceNames,omitempty"`
+
+	// GatewayCA describes the CA that signs operator-managed vtgate
+	// certificates, if any cell uses operator-managed TLS.
+	GatewayCA *CertificateStatus `json:"gatewayCA,omitempty"`
+
+	// InternalTLS describes the certificate used for gRPC between Vitess
+	// components, if it's managed by the operator.
+	InternalTLS *CertificateStatus `json:"internalTLS,omitempty"`
+
+	// LockserverTLS describes the client certificate used to connect to etcd
+	// lockservers, if it's managed by the operator.
+	LockserverTLS *CertificateStatus `json:"lockserverTLS,omitempty"`
+
 	// VitessDashboard is a summary of the status of the vtctld deployment.
 	VitessDashboard VitessDashboardStatus `json:"vitessDashboard,omitempty"`
 
@@ -613,6 +910,32 @@ type VitessClusterStatus struct {
 	OrphanedCells map[string]OrphanStatus `json:"orphanedCells,omitempty"`
 	// OrphanedKeyspaces is a list of unwanted keyspaces that could not be turned down.
 	OrphanedKeyspaces map[string]OrphanStatus `json:"orphanedKeyspaces,omitempty"`
+
+	// CellsAliases is a list of the cells aliases that the operator has
+	// registered in topology, so it knows which ones to remove if they're
+	// taken out of the spec.
+	CellsAliases []string `json:"cellsAliases,omitempty"`
+
+	// PendingPruneCells is a list of unwanted cells that were not pruned
+	// from topology because PruneCellsDryRun is enabled. Each one is pruned
+	// once it's approved with the "topo.planetscale.com/approve-prune-cells"
+	// annotation on this VitessCluster.
+	PendingPruneCells []string `json:"pendingPruneCells,omitempty"`
+	// PendingPruneKeyspaces is a list of unwanted keyspaces that were not
+	// pruned from topology because PruneKeyspacesDryRun is enabled. Each one
+	// is pruned once it's approved with the
+	// "topo.planetscale.com/approve-prune-keyspaces" annotation on this
+	// VitessCluster.
+	PendingPruneKeyspaces []string `json:"pendingPruneKeyspaces,omitempty"`
+	// UsedPruneApprovals lists, for each approval annotation on this
+	// VitessCluster, the approved names whose records have already been
+	// pruned. They're ignored until they're removed from the annotation.
+	UsedPruneApprovals map[string][]string `json:"usedPruneApprovals,omitempty"`
+
+	// TopologyDrift is the result of the last periodic comparison between
+	// the Vitess topology and this VitessCluster. It shows what the prune
+	// options in TopologyReconciliation would remove if they were enabled.
+	TopologyDrift *TopologyDriftStatus `json:"topologyDrift,omitempty"`
 }
 
 // NewVitessClusterStatus creates a new status object with default values.
@@ -682,6 +1005,10 @@ type VitessClusterKeyspaceStatus struct {
 	// Cells is a list of cells in which any observed tablets for this keyspace
 	// are deployed.
 	Cells []string `json:"cells,omitempty"`
+	// PendingPruneShards is a list of unwanted shards that are waiting for
+	// approval to be pruned from topology. See the VitessKeyspace status for
+	// details.
+	PendingPruneShards []string `json:"pendingPruneShards,omitempty"`
 }
 
 // NewVitessClusterKeyspaceStatus creates a new status object with default values.
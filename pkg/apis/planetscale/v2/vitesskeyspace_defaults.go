@@ -48,6 +48,7 @@ func DefaultVitessOrchestrator(vtorc **VitessOrchestratorSpec) {
 		}
 	}
 	DefaultServiceOverrides(&(*vtorc).Service)
+	DefaultPodDisruptionBudget(&(*vtorc).PodDisruptionBudget)
 }
 
 // DefaultVitessKeyspaceImages fills in unspecified keyspace-level images from cluster-level defaults.
@@ -78,6 +78,19 @@ type VitessKeyspaceSpec struct {
 	// ExtraVitessFlags is inherited from the parent's VitessClusterSpec.
 	ExtraVitessFlags map[string]string `json:"extraVitessFlags,omitempty"`
 
+	// InternalTLS is inherited from the parent's VitessClusterSpec.
+	// If the certificate is managed by the operator, the Secret fields point
+	// to the operator-managed Secret.
+	InternalTLS *VitessInternalTLSSpec `json:"internalTLS,omitempty"`
+
+	// NetworkPolicies is inherited from the parent's VitessClusterSpec.
+	NetworkPolicies *VitessNetworkPolicySpec `json:"networkPolicies,omitempty"`
+
+	// PauseVitessOrchestrator is set by the VitessCluster while a global
+	// lockserver migration can't let vtorc change the topology. No vtorc is
+	// deployed while it's set.
+	PauseVitessOrchestrator bool `json:"pauseVitessOrchestrator,omitempty"`
+
 	// TopologyReconciliation is inherited from the parent's VitessClusterSpec.
 	TopologyReconciliation *TopoReconcileConfig `json:"topologyReconciliation,omitempty"`
 
@@ -282,6 +295,10 @@ type VitessOrchestratorSpec struct {
 	// Service can optionally be used to customize the vtorc Service.
 	Service *ServiceOverrides `json:"service,omitempty"`
 
+	// PodDisruptionBudget configures the PodDisruptionBudget (PDB) that covers
+	// the vtorc Pods of each shard, across all cells.
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
 	// Tolerations allow you to schedule pods onto nodes with matching taints.
 	// +kubebuilder:validation:Schemaless
 	// +kubebuilder:pruning:PreserveUnknownFields
@@ -426,6 +443,15 @@ type VitessKeyspaceStatus struct {
 	Partitionings []VitessKeyspacePartitioningStatus `json:"partitionings,omitempty"`
 	// OrphanedShards is a list of unwanted shards that could not be turned down.
 	OrphanedShards map[string]OrphanStatus `json:"orphanedShards,omitempty"`
+	// PendingPruneShards is a list of unwanted shards that were not pruned
+	// from topology because PruneShardsDryRun is enabled. Each one is pruned
+	// once it's approved with the "topo.planetscale.com/approve-prune-shards"
+	// annotation on this VitessKeyspace.
+	PendingPruneShards []string `json:"pendingPruneShards,omitempty"`
+	// UsedPruneApprovals lists, for each approval annotation on this
+	// VitessKeyspace, the approved names whose records have already been
+	// pruned. They're ignored until they're removed from the annotation.
+	UsedPruneApprovals map[string][]string `json:"usedPruneApprovals,omitempty"`
 	// Idle is a condition indicating whether the keyspace can be turned down.
 	// If Idle is True, the keyspace is not deployed in any cells, so it should
 	// be safe to turn down the keyspace.
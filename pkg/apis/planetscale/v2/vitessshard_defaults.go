@@ -28,6 +28,9 @@ func DefaultVitessShard(dst *VitessShard) {
 	DefaultUpdateStrategy(&dst.Spec.UpdateStrategy)
 	DefaultTopoReconcileConfig(&dst.Spec.TopologyReconciliation)
 	DefaultVitessShardTemplate(&dst.Spec.VitessShardTemplate)
+	if dst.Spec.VitessOrchestrator != nil {
+		DefaultPodDisruptionBudget(&dst.Spec.VitessOrchestrator.PodDisruptionBudget)
+	}
 }
 
 func DefaultVitessShardTemplate(shardTemplate *VitessShardTemplate) {
@@ -36,6 +39,23 @@ func DefaultVitessShardTemplate(shardTemplate *VitessShardTemplate) {
 	}
 
 	DefaultVitessReplicationSpec(&shardTemplate.Replication)
+	DefaultPodDisruptionBudget(&shardTemplate.TabletPodDisruptionBudget)
+
+	for i := range shardTemplate.TabletPools {
+		DefaultDataVolumeAutoscalerSpec(shardTemplate.TabletPools[i].DataVolumeAutoscaler)
+	}
+}
+
+func DefaultDataVolumeAutoscalerSpec(autoscaler *DataVolumeAutoscalerSpec) {
+	if autoscaler == nil {
+		return
+	}
+	if autoscaler.UsageThresholdPercent == nil {
+		autoscaler.UsageThresholdPercent = ptr.To(int32(defaultDataVolumeAutoscalerUsageThresholdPercent))
+	}
+	if autoscaler.CooldownSeconds == nil {
+		autoscaler.CooldownSeconds = ptr.To(int64(defaultDataVolumeAutoscalerCooldownSeconds))
+	}
 }
 
 func DefaultVitessReplicationSpec(replicationSpec *VitessReplicationSpec) {
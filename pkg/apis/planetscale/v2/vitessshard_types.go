@@ -18,6 +18,7 @@ package v2
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -84,6 +85,9 @@ type VitessShardSpec struct {
 	// VitessOrchestrator is inherited from the parent's VitessKeyspace.
 	VitessOrchestrator *VitessOrchestratorSpec `json:"vitessOrchestrator,omitempty"`
 
+	// PauseVitessOrchestrator is inherited from the parent's VitessKeyspace.
+	PauseVitessOrchestrator bool `json:"pauseVitessOrchestrator,omitempty"`
+
 	// BackupLocations are the backup locations defined in the VitessCluster.
 	BackupLocations []VitessBackupLocation `json:"backupLocations,omitempty"`
 
@@ -93,6 +97,14 @@ type VitessShardSpec struct {
 	// ExtraVitessFlags is inherited from the parent's VitessClusterSpec.
 	ExtraVitessFlags map[string]string `json:"extraVitessFlags,omitempty"`
 
+	// InternalTLS is inherited from the parent's VitessClusterSpec.
+	// If the certificate is managed by the operator, the Secret fields point
+	// to the operator-managed Secret.
+	InternalTLS *VitessInternalTLSSpec `json:"internalTLS,omitempty"`
+
+	// NetworkPolicies is inherited from the parent's VitessClusterSpec.
+	NetworkPolicies *VitessNetworkPolicySpec `json:"networkPolicies,omitempty"`
+
 	// TopologyReconciliation is inherited from the parent's VitessClusterSpec.
 	TopologyReconciliation *TopoReconcileConfig `json:"topologyReconciliation,omitempty"`
 
@@ -126,6 +138,14 @@ type VitessShardTemplate struct {
 
 	// Annotations can optionally be used to attach custom annotations to the VitessShard object.
 	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// TabletPodDisruptionBudget configures the PodDisruptionBudget (PDB) that
+	// covers all tablet Pods in the shard, across all pools and cells.
+	//
+	// While any tablet in the shard is being drained, the PDB doesn't allow
+	// evicting any other tablet. Once a tablet's drain is finished, that
+	// tablet is no longer covered by the PDB so it can be evicted.
+	TabletPodDisruptionBudget *PodDisruptionBudgetSpec `json:"tabletPodDisruptionBudget,omitempty"`
 }
 
 // VitessReplicationSpec specifies how Vitess will set up MySQL replication.
@@ -193,8 +213,40 @@ type VitessShardTabletPool struct {
 	// IMPORTANT: For a tablet pool in a Kubernetes cluster that spans multiple
 	// zones, you should ensure that `volumeBindingMode: WaitForFirstConsumer`
 	// is set on the StorageClass specified in the storageClassName field here.
+	//
+	// Changing storageClassName triggers an online migration: tablets are
+	// drained and replaced one at a time (primary last) with new PVCs of the
+	// new class, and each replacement restores its data from the latest
+	// backup. This requires a backupLocationName for the pool and at least one
+	// complete backup for the shard. Without them the migration doesn't start,
+	// and the StorageMigration condition in the shard status says why.
+	// Replacements are never cloned from another replica instead, since
+	// volume clones and snapshots can't change StorageClass.
 	DataVolumeClaimTemplate *corev1.PersistentVolumeClaimSpec `json:"dataVolumeClaimTemplate,omitempty"`
 
+	// DataVolumeAutoscaler optionally grows each tablet's data volume when
+	// its filesystem usage crosses a threshold. Volumes are only ever grown,
+	// never shrunk, and the StorageClass must allow volume expansion.
+	// Usage is read from the kubelet of the node running each tablet Pod.
+	// If a custom metrics adapter serves the kubelet_volume_stats_used_bytes and
+	// kubelet_volume_stats_capacity_bytes metrics for PersistentVolumeClaims,
+	// those are used instead.
+	// Like other disk changes, volumes are only grown when the shard's
+	// update strategy is Immediate, or External with storage changes allowed.
+	// Default: Don't autoscale data volumes.
+	// +optional
+	DataVolumeAutoscaler *DataVolumeAutoscalerSpec `json:"dataVolumeAutoscaler,omitempty"`
+
+	// DataVolumeFromSnapshot provisions the data volume of each new tablet in
+	// this pool from the newest complete VolumeSnapshot backup of the shard,
+	// if there is one, instead of restoring from backup storage. Snapshots are
+	// taken by a VitessBackupSchedule with the "snapshot" backup method.
+	// New volumes are grown to the snapshot's restore size if that's larger
+	// than the size requested in DataVolumeClaimTemplate.
+	// Default: false
+	// +optional
+	DataVolumeFromSnapshot bool `json:"dataVolumeFromSnapshot,omitempty"`
+
 	// BackupLocationName is the name of the backup location to use for this
 	// tablet pool. It must match the name of one of the backup locations
 	// defined in the VitessCluster.
@@ -277,6 +329,31 @@ type VitessShardTabletPool struct {
 	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
 }
 
+// DataVolumeAutoscalerSpec configures automatic growth of tablet data volumes.
+type DataVolumeAutoscalerSpec struct {
+	// UsageThresholdPercent is the filesystem usage, as a percentage of the
+	// volume's capacity, at or above which the volume will be grown.
+	// Default: 80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	// +optional
+	UsageThresholdPercent *int32 `json:"usageThresholdPercent,omitempty"`
+
+	// Increment is how much storage to add each time the volume is grown.
+	Increment resource.Quantity `json:"increment"`
+
+	// MaxSize is the largest size to which the volume will be grown.
+	MaxSize resource.Quantity `json:"maxSize"`
+
+	// CooldownSeconds is the minimum time to wait after growing a volume
+	// before it may be grown again. This gives the new capacity time to
+	// become visible in the filesystem usage we observe.
+	// Default: 3600
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CooldownSeconds *int64 `json:"cooldownSeconds,omitempty"`
+}
+
 // VttabletSpec configures the vttablet server within a tablet.
 type VttabletSpec struct {
 	// Resources specify the compute resources to allocate for just the vttablet
@@ -407,6 +484,21 @@ type VitessShardStatus struct {
 	Tablets map[string]VitessTabletStatus `json:"tablets,omitempty"`
 	// OrphanedTablets is a list of unwanted tablets that could not be turned down.
 	OrphanedTablets map[string]OrphanStatus `json:"orphanedTablets,omitempty"`
+	// PendingPruneTablets is a list of unwanted tablets that were not pruned
+	// from topology because PruneTabletsDryRun is enabled. Each one is pruned
+	// once it's approved with the "topo.planetscale.com/approve-prune-tablets"
+	// annotation on this VitessShard.
+	PendingPruneTablets []string `json:"pendingPruneTablets,omitempty"`
+	// PendingPruneShardCells is a list of unwanted cells that were not removed
+	// from the shard record because PruneShardCellsDryRun is enabled. Each one
+	// is removed once it's approved with the
+	// "topo.planetscale.com/approve-prune-shard-cells" annotation on this
+	// VitessShard.
+	PendingPruneShardCells []string `json:"pendingPruneShardCells,omitempty"`
+	// UsedPruneApprovals lists, for each approval annotation on this
+	// VitessShard, the approved names whose records have already been
+	// pruned. They're ignored until they're removed from the annotation.
+	UsedPruneApprovals map[string][]string `json:"usedPruneApprovals,omitempty"`
 
 	// Cells is a list of cells in which any tablets for this shard are deployed.
 	Cells []string `json:"cells,omitempty"`
@@ -467,6 +559,14 @@ type VitessOrchestratorStatus struct {
 // VitessShardConditionType and the value is a VitessShardCondition.
 type VitessShardConditionType string
 
+// These are valid conditions of VitessShard.
+const (
+	// VitessShardStorageMigration indicates whether a data volume StorageClass
+	// migration is in progress. It's False with a Reason of BackupRequired if
+	// a migration is needed but can't start.
+	VitessShardStorageMigration VitessShardConditionType = "StorageMigration"
+)
+
 // VitessShardCondition contains details for the current condition of this VitessShard.
 type VitessShardCondition struct {
 	// Status is the status of the condition.
@@ -522,8 +622,31 @@ type VitessTabletStatus struct {
 	// PendingChanges describes changes to the tablet Pod that will be applied
 	// the next time a rolling update allows.
 	PendingChanges string `json:"pendingChanges,omitempty"`
+	// DataVolumeMigration reports the progress of replacing the tablet's data
+	// volume after a change to the storageClassName in the pool's
+	// dataVolumeClaimTemplate. It's empty if no migration is needed.
+	DataVolumeMigration VitessTabletDataVolumeMigrationPhase `json:"dataVolumeMigration,omitempty"`
 }
 
+// VitessTabletDataVolumeMigrationPhase is the progress of a tablet through a
+// data volume StorageClass migration.
+type VitessTabletDataVolumeMigrationPhase string
+
+const (
+	// DataVolumeMigrationPending means the tablet's data volume needs to be
+	// replaced, but the migration hasn't reached this tablet yet.
+	DataVolumeMigrationPending VitessTabletDataVolumeMigrationPhase = "Pending"
+	// DataVolumeMigrationDraining means the tablet is being drained so it can
+	// be safely replaced.
+	DataVolumeMigrationDraining VitessTabletDataVolumeMigrationPhase = "Draining"
+	// DataVolumeMigrationReplacing means the tablet Pod and its old data volume
+	// are being deleted so they can be recreated with the new StorageClass.
+	DataVolumeMigrationReplacing VitessTabletDataVolumeMigrationPhase = "Replacing"
+	// DataVolumeMigrationBlocked means the tablet is next in line, but can't
+	// be replaced because there's no complete backup to restore it from.
+	DataVolumeMigrationBlocked VitessTabletDataVolumeMigrationPhase = "Blocked"
+)
+
 // NewVitessTabletStatus creates a new status object with default values.
 func NewVitessTabletStatus(poolType VitessTabletPoolType, index int32) VitessTabletStatus {
 	return VitessTabletStatus{
@@ -0,0 +1,40 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"k8s.io/utils/ptr"
+)
+
+func DefaultZkLockserver(ls *ZkLockserver) {
+	DefaultZkLockserverTemplate(&ls.Spec.ZkLockserverTemplate)
+}
+
+func DefaultZkLockserverTemplate(ls *ZkLockserverTemplate) {
+	if ls.Image == "" {
+		ls.Image = DefaultZkImage
+	}
+	if ls.Replicas == nil {
+		ls.Replicas = ptr.To(int32(defaultZkReplicas))
+	}
+	defaultManagedLockserverStorage(&ls.DataVolumeClaimTemplate)
+	defaultManagedLockserverResources(&ls.Resources)
+	if ls.CreatePDB == nil {
+		ls.CreatePDB = ptr.To(defaultZkCreatePDB)
+	}
+	DefaultServiceOverrides(&ls.ClientService)
+}
@@ -7,8 +7,10 @@ package v2
 import (
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -19,6 +21,16 @@ func (in *AutoscalerSpec) DeepCopyInto(out *AutoscalerSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.TargetQPSPerPod != nil {
+		in, out := &in.TargetQPSPerPod, &out.TargetQPSPerPod
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetConnectionsPerPod != nil {
+		in, out := &in.TargetConnectionsPerPod, &out.TargetConnectionsPerPod
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Metrics != nil {
 		in, out := &in.Metrics, &out.Metrics
 		*out = make([]autoscalingv2.MetricSpec, len(*in))
@@ -75,6 +87,29 @@ func (in *CephBackupLocation) DeepCopy() *CephBackupLocation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateStatus) DeepCopyInto(out *CertificateStatus) {
+	*out = *in
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.RenewalTime != nil {
+		in, out := &in.RenewalTime, &out.RenewalTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateStatus.
+func (in *CertificateStatus) DeepCopy() *CertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterBackupSpec) DeepCopyInto(out *ClusterBackupSpec) {
 	*out = *in
@@ -110,7 +145,7 @@ func (in *ClusterBackupSpec) DeepCopy() *ClusterBackupSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EtcdLockserver) DeepCopyInto(out *EtcdLockserver) {
+func (in *ConsulLockserver) DeepCopyInto(out *ConsulLockserver) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -118,6 +153,200 @@ func (in *EtcdLockserver) DeepCopyInto(out *EtcdLockserver) {
 	out.Status = in.Status
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsulLockserver.
+func (in *ConsulLockserver) DeepCopy() *ConsulLockserver {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsulLockserver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ConsulLockserver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsulLockserverList) DeepCopyInto(out *ConsulLockserverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ConsulLockserver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsulLockserverList.
+func (in *ConsulLockserverList) DeepCopy() *ConsulLockserverList {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsulLockserverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ConsulLockserverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsulLockserverSpec) DeepCopyInto(out *ConsulLockserverSpec) {
+	*out = *in
+	in.ConsulLockserverTemplate.DeepCopyInto(&out.ConsulLockserverTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsulLockserverSpec.
+func (in *ConsulLockserverSpec) DeepCopy() *ConsulLockserverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsulLockserverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsulLockserverStatus) DeepCopyInto(out *ConsulLockserverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsulLockserverStatus.
+func (in *ConsulLockserverStatus) DeepCopy() *ConsulLockserverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsulLockserverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsulLockserverTemplate) DeepCopyInto(out *ConsulLockserverTemplate) {
+	*out = *in
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	in.DataVolumeClaimTemplate.DeepCopyInto(&out.DataVolumeClaimTemplate)
+	if in.ExtraFlags != nil {
+		in, out := &in.ExtraFlags, &out.ExtraFlags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraLabels != nil {
+		in, out := &in.ExtraLabels, &out.ExtraLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CreatePDB != nil {
+		in, out := &in.CreatePDB, &out.CreatePDB
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ClientService != nil {
+		in, out := &in.ClientService, &out.ClientService
+		*out = new(ServiceOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsulLockserverTemplate.
+func (in *ConsulLockserverTemplate) DeepCopy() *ConsulLockserverTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsulLockserverTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeAutoscalerSpec) DeepCopyInto(out *DataVolumeAutoscalerSpec) {
+	*out = *in
+	if in.UsageThresholdPercent != nil {
+		in, out := &in.UsageThresholdPercent, &out.UsageThresholdPercent
+		*out = new(int32)
+		**out = **in
+	}
+	out.Increment = in.Increment.DeepCopy()
+	out.MaxSize = in.MaxSize.DeepCopy()
+	if in.CooldownSeconds != nil {
+		in, out := &in.CooldownSeconds, &out.CooldownSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeAutoscalerSpec.
+func (in *DataVolumeAutoscalerSpec) DeepCopy() *DataVolumeAutoscalerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeAutoscalerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdLockserver) DeepCopyInto(out *EtcdLockserver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdLockserver.
 func (in *EtcdLockserver) DeepCopy() *EtcdLockserver {
 	if in == nil {
@@ -136,6 +365,28 @@ func (in *EtcdLockserver) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdLockserverBackup) DeepCopyInto(out *EtcdLockserverBackup) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdLockserverBackup.
+func (in *EtcdLockserverBackup) DeepCopy() *EtcdLockserverBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdLockserverBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdLockserverList) DeepCopyInto(out *EtcdLockserverList) {
 	*out = *in
@@ -168,10 +419,32 @@ func (in *EtcdLockserverList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdLockserverRestore) DeepCopyInto(out *EtcdLockserverRestore) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdLockserverRestore.
+func (in *EtcdLockserverRestore) DeepCopy() *EtcdLockserverRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdLockserverRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdLockserverSpec) DeepCopyInto(out *EtcdLockserverSpec) {
 	*out = *in
 	in.EtcdLockserverTemplate.DeepCopyInto(&out.EtcdLockserverTemplate)
+	if in.BackupLocations != nil {
+		in, out := &in.BackupLocations, &out.BackupLocations
+		*out = make([]VitessBackupLocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdLockserverSpec.
@@ -187,6 +460,21 @@ func (in *EtcdLockserverSpec) DeepCopy() *EtcdLockserverSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdLockserverStatus) DeepCopyInto(out *EtcdLockserverStatus) {
 	*out = *in
+	if in.LastSnapshotTime != nil {
+		in, out := &in.LastSnapshotTime, &out.LastSnapshotTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastDefragTime != nil {
+		in, out := &in.LastDefragTime, &out.LastDefragTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]EtcdMemberStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdLockserverStatus.
@@ -199,6 +487,26 @@ func (in *EtcdLockserverStatus) DeepCopy() *EtcdLockserverStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdLockserverTLS) DeepCopyInto(out *EtcdLockserverTLS) {
+	*out = *in
+	if in.OperatorManaged != nil {
+		in, out := &in.OperatorManaged, &out.OperatorManaged
+		*out = new(OperatorManagedTLS)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdLockserverTLS.
+func (in *EtcdLockserverTLS) DeepCopy() *EtcdLockserverTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdLockserverTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdLockserverTemplate) DeepCopyInto(out *EtcdLockserverTemplate) {
 	*out = *in
@@ -207,6 +515,11 @@ func (in *EtcdLockserverTemplate) DeepCopyInto(out *EtcdLockserverTemplate) {
 		*out = make([]v1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
 	in.Resources.DeepCopyInto(&out.Resources)
 	in.DataVolumeClaimTemplate.DeepCopyInto(&out.DataVolumeClaimTemplate)
 	if in.ExtraFlags != nil {
@@ -312,6 +625,31 @@ func (in *EtcdLockserverTemplate) DeepCopyInto(out *EtcdLockserverTemplate) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(EtcdLockserverTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(EtcdLockserverBackup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(EtcdLockserverRestore)
+		**out = **in
+	}
+	if in.MemberReplacement != nil {
+		in, out := &in.MemberReplacement, &out.MemberReplacement
+		*out = new(EtcdMemberReplacement)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(EtcdMaintenance)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdLockserverTemplate.
@@ -324,6 +662,65 @@ func (in *EtcdLockserverTemplate) DeepCopy() *EtcdLockserverTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdMaintenance) DeepCopyInto(out *EtcdMaintenance) {
+	*out = *in
+	if in.DefragThresholdPercent != nil {
+		in, out := &in.DefragThresholdPercent, &out.DefragThresholdPercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdMaintenance.
+func (in *EtcdMaintenance) DeepCopy() *EtcdMaintenance {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdMaintenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdMemberReplacement) DeepCopyInto(out *EtcdMemberReplacement) {
+	*out = *in
+	if in.UnhealthyTimeout != nil {
+		in, out := &in.UnhealthyTimeout, &out.UnhealthyTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdMemberReplacement.
+func (in *EtcdMemberReplacement) DeepCopy() *EtcdMemberReplacement {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdMemberReplacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdMemberStatus) DeepCopyInto(out *EtcdMemberStatus) {
+	*out = *in
+	if in.UnhealthySince != nil {
+		in, out := &in.UnhealthySince, &out.UnhealthySince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdMemberStatus.
+func (in *EtcdMemberStatus) DeepCopy() *EtcdMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalDatastore) DeepCopyInto(out *ExternalDatastore) {
 	*out = *in
@@ -385,6 +782,26 @@ func (in *GCSBackupLocation) DeepCopy() *GCSBackupLocation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LockserverMigrationStatus) DeepCopyInto(out *LockserverMigrationStatus) {
+	*out = *in
+	out.From = in.From
+	if in.CopyTime != nil {
+		in, out := &in.CopyTime, &out.CopyTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LockserverMigrationStatus.
+func (in *LockserverMigrationStatus) DeepCopy() *LockserverMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LockserverMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LockserverSpec) DeepCopyInto(out *LockserverSpec) {
 	*out = *in
@@ -398,6 +815,21 @@ func (in *LockserverSpec) DeepCopyInto(out *LockserverSpec) {
 		*out = new(EtcdLockserverTemplate)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Consul != nil {
+		in, out := &in.Consul, &out.Consul
+		*out = new(ConsulLockserverTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Zk != nil {
+		in, out := &in.Zk, &out.Zk
+		*out = new(ZkLockserverTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MigrateFrom != nil {
+		in, out := &in.MigrateFrom, &out.MigrateFrom
+		*out = new(VitessLockserverParams)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LockserverSpec.
@@ -416,6 +848,16 @@ func (in *LockserverStatus) DeepCopyInto(out *LockserverStatus) {
 	if in.Etcd != nil {
 		in, out := &in.Etcd, &out.Etcd
 		*out = new(EtcdLockserverStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Consul != nil {
+		in, out := &in.Consul, &out.Consul
+		*out = new(ConsulLockserverStatus)
+		**out = **in
+	}
+	if in.Zk != nil {
+		in, out := &in.Zk, &out.Zk
+		*out = new(ZkLockserverStatus)
 		**out = **in
 	}
 }
@@ -522,6 +964,36 @@ func (in *MysqldSpec) DeepCopy() *MysqldSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorManagedTLS) DeepCopyInto(out *OperatorManagedTLS) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ExtraDNSNames != nil {
+		in, out := &in.ExtraDNSNames, &out.ExtraDNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorManagedTLS.
+func (in *OperatorManagedTLS) DeepCopy() *OperatorManagedTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorManagedTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OrphanStatus) DeepCopyInto(out *OrphanStatus) {
 	*out = *in
@@ -537,6 +1009,31 @@ func (in *OrphanStatus) DeepCopy() *OrphanStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetSpec) DeepCopyInto(out *PodDisruptionBudgetSpec) {
+	*out = *in
+	if in.Create != nil {
+		in, out := &in.Create, &out.Create
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetSpec.
+func (in *PodDisruptionBudgetSpec) DeepCopy() *PodDisruptionBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReshardingStatus) DeepCopyInto(out *ReshardingStatus) {
 	*out = *in
@@ -656,31 +1153,61 @@ func (in *TopoReconcileConfig) DeepCopyInto(out *TopoReconcileConfig) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PruneCellsDryRun != nil {
+		in, out := &in.PruneCellsDryRun, &out.PruneCellsDryRun
+		*out = new(bool)
+		**out = **in
+	}
 	if in.PruneKeyspaces != nil {
 		in, out := &in.PruneKeyspaces, &out.PruneKeyspaces
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PruneKeyspacesDryRun != nil {
+		in, out := &in.PruneKeyspacesDryRun, &out.PruneKeyspacesDryRun
+		*out = new(bool)
+		**out = **in
+	}
 	if in.PruneSrvKeyspaces != nil {
 		in, out := &in.PruneSrvKeyspaces, &out.PruneSrvKeyspaces
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PruneSrvKeyspacesDryRun != nil {
+		in, out := &in.PruneSrvKeyspacesDryRun, &out.PruneSrvKeyspacesDryRun
+		*out = new(bool)
+		**out = **in
+	}
 	if in.PruneShards != nil {
 		in, out := &in.PruneShards, &out.PruneShards
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PruneShardsDryRun != nil {
+		in, out := &in.PruneShardsDryRun, &out.PruneShardsDryRun
+		*out = new(bool)
+		**out = **in
+	}
 	if in.PruneShardCells != nil {
 		in, out := &in.PruneShardCells, &out.PruneShardCells
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PruneShardCellsDryRun != nil {
+		in, out := &in.PruneShardCellsDryRun, &out.PruneShardCellsDryRun
+		*out = new(bool)
+		**out = **in
+	}
 	if in.PruneTablets != nil {
 		in, out := &in.PruneTablets, &out.PruneTablets
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PruneTabletsDryRun != nil {
+		in, out := &in.PruneTabletsDryRun, &out.PruneTabletsDryRun
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopoReconcileConfig.
@@ -693,6 +1220,67 @@ func (in *TopoReconcileConfig) DeepCopy() *TopoReconcileConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyDriftRecords) DeepCopyInto(out *TopologyDriftRecords) {
+	*out = *in
+	if in.Cells != nil {
+		in, out := &in.Cells, &out.Cells
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Keyspaces != nil {
+		in, out := &in.Keyspaces, &out.Keyspaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tablets != nil {
+		in, out := &in.Tablets, &out.Tablets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SrvKeyspaces != nil {
+		in, out := &in.SrvKeyspaces, &out.SrvKeyspaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyDriftRecords.
+func (in *TopologyDriftRecords) DeepCopy() *TopologyDriftRecords {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyDriftRecords)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyDriftStatus) DeepCopyInto(out *TopologyDriftStatus) {
+	*out = *in
+	if in.AuditTime != nil {
+		in, out := &in.AuditTime, &out.AuditTime
+		*out = (*in).DeepCopy()
+	}
+	in.Unknown.DeepCopyInto(&out.Unknown)
+	in.Missing.DeepCopyInto(&out.Missing)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyDriftStatus.
+func (in *TopologyDriftStatus) DeepCopy() *TopologyDriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyDriftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessBackup) DeepCopyInto(out *VitessBackup) {
 	*out = *in
@@ -858,6 +1446,31 @@ func (in *VitessBackupScheduleList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessBackupScheduleSnapshotSpec) DeepCopyInto(out *VitessBackupScheduleSnapshotSpec) {
+	*out = *in
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.RetentionCount != nil {
+		in, out := &in.RetentionCount, &out.RetentionCount
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessBackupScheduleSnapshotSpec.
+func (in *VitessBackupScheduleSnapshotSpec) DeepCopy() *VitessBackupScheduleSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessBackupScheduleSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessBackupScheduleSpec) DeepCopyInto(out *VitessBackupScheduleSpec) {
 	*out = *in
@@ -944,6 +1557,11 @@ func (in *VitessBackupScheduleStrategy) DeepCopy() *VitessBackupScheduleStrategy
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessBackupScheduleTemplate) DeepCopyInto(out *VitessBackupScheduleTemplate) {
 	*out = *in
+	if in.Snapshot != nil {
+		in, out := &in.Snapshot, &out.Snapshot
+		*out = new(VitessBackupScheduleSnapshotSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Strategy != nil {
 		in, out := &in.Strategy, &out.Strategy
 		*out = make([]VitessBackupScheduleStrategy, len(*in))
@@ -1184,6 +1802,21 @@ func (in *VitessCell) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessCellGatewayPoolStatus) DeepCopyInto(out *VitessCellGatewayPoolStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessCellGatewayPoolStatus.
+func (in *VitessCellGatewayPoolStatus) DeepCopy() *VitessCellGatewayPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessCellGatewayPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessCellGatewaySpec) DeepCopyInto(out *VitessCellGatewaySpec) {
 	*out = *in
@@ -1270,6 +1903,16 @@ func (in *VitessCellGatewaySpec) DeepCopyInto(out *VitessCellGatewaySpec) {
 		*out = new(ServiceOverrides)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(VitessGatewayExternalSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]v1.Toleration, len(*in))
@@ -1290,7 +1933,19 @@ func (in *VitessCellGatewaySpec) DeepCopyInto(out *VitessCellGatewaySpec) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(VitessGatewayDrainSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Strategy.DeepCopyInto(&out.Strategy)
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]VitessGatewayPoolSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessCellGatewaySpec.
@@ -1306,6 +1961,23 @@ func (in *VitessCellGatewaySpec) DeepCopy() *VitessCellGatewaySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessCellGatewayStatus) DeepCopyInto(out *VitessCellGatewayStatus) {
 	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(CertificateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make(map[string]VitessCellGatewayPoolStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(VitessGatewayExternalStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessCellGatewayStatus.
@@ -1404,11 +2076,28 @@ func (in *VitessCellSpec) DeepCopyInto(out *VitessCellSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.InternalTLS != nil {
+		in, out := &in.InternalTLS, &out.InternalTLS
+		*out = new(VitessInternalTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TopologyReconciliation != nil {
 		in, out := &in.TopologyReconciliation, &out.TopologyReconciliation
 		*out = new(TopoReconcileConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GatewayTopology != nil {
+		in, out := &in.GatewayTopology, &out.GatewayTopology
+		*out = new(VitessGatewayTopologySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BackupLocations != nil {
+		in, out := &in.BackupLocations, &out.BackupLocations
+		*out = make([]VitessBackupLocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessCellSpec.
@@ -1425,7 +2114,7 @@ func (in *VitessCellSpec) DeepCopy() *VitessCellSpec {
 func (in *VitessCellStatus) DeepCopyInto(out *VitessCellStatus) {
 	*out = *in
 	in.Lockserver.DeepCopyInto(&out.Lockserver)
-	out.Gateway = in.Gateway
+	in.Gateway.DeepCopyInto(&out.Gateway)
 	if in.Keyspaces != nil {
 		in, out := &in.Keyspaces, &out.Keyspaces
 		*out = make(map[string]VitessCellKeyspaceStatus, len(*in))
@@ -1433,6 +2122,27 @@ func (in *VitessCellStatus) DeepCopyInto(out *VitessCellStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.PendingPruneSrvKeyspaces != nil {
+		in, out := &in.PendingPruneSrvKeyspaces, &out.PendingPruneSrvKeyspaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UsedPruneApprovals != nil {
+		in, out := &in.UsedPruneApprovals, &out.UsedPruneApprovals
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessCellStatus.
@@ -1512,6 +2222,11 @@ func (in *VitessClusterKeyspaceStatus) DeepCopyInto(out *VitessClusterKeyspaceSt
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.PendingPruneShards != nil {
+		in, out := &in.PendingPruneShards, &out.PendingPruneShards
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessClusterKeyspaceStatus.
@@ -1589,6 +2304,22 @@ func (in *VitessClusterSpec) DeepCopyInto(out *VitessClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CellsAliases != nil {
+		in, out := &in.CellsAliases, &out.CellsAliases
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	if in.Keyspaces != nil {
 		in, out := &in.Keyspaces, &out.Keyspaces
 		*out = make([]VitessKeyspaceTemplate, len(*in))
@@ -1618,11 +2349,31 @@ func (in *VitessClusterSpec) DeepCopyInto(out *VitessClusterSpec) {
 		*out = new(ServiceOverrides)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GatewayExternal != nil {
+		in, out := &in.GatewayExternal, &out.GatewayExternal
+		*out = new(VitessGatewayExternalSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GatewayTopology != nil {
+		in, out := &in.GatewayTopology, &out.GatewayTopology
+		*out = new(VitessGatewayTopologySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TabletService != nil {
 		in, out := &in.TabletService, &out.TabletService
 		*out = new(ServiceOverrides)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.InternalTLS != nil {
+		in, out := &in.InternalTLS, &out.InternalTLS
+		*out = new(VitessInternalTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicies != nil {
+		in, out := &in.NetworkPolicies, &out.NetworkPolicies
+		*out = new(VitessNetworkPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessClusterSpec.
@@ -1639,6 +2390,38 @@ func (in *VitessClusterSpec) DeepCopy() *VitessClusterSpec {
 func (in *VitessClusterStatus) DeepCopyInto(out *VitessClusterStatus) {
 	*out = *in
 	in.GlobalLockserver.DeepCopyInto(&out.GlobalLockserver)
+	if in.GlobalLockserverMigration != nil {
+		in, out := &in.GlobalLockserverMigration, &out.GlobalLockserverMigration
+		*out = new(LockserverMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GatewayExternal != nil {
+		in, out := &in.GatewayExternal, &out.GatewayExternal
+		*out = new(VitessGatewayExternalStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GatewayZoneServiceNames != nil {
+		in, out := &in.GatewayZoneServiceNames, &out.GatewayZoneServiceNames
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.GatewayCA != nil {
+		in, out := &in.GatewayCA, &out.GatewayCA
+		*out = new(CertificateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InternalTLS != nil {
+		in, out := &in.InternalTLS, &out.InternalTLS
+		*out = new(CertificateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LockserverTLS != nil {
+		in, out := &in.LockserverTLS, &out.LockserverTLS
+		*out = new(CertificateStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	out.VitessDashboard = in.VitessDashboard
 	out.Vtadmin = in.Vtadmin
 	if in.Cells != nil {
@@ -1669,6 +2452,42 @@ func (in *VitessClusterStatus) DeepCopyInto(out *VitessClusterStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.CellsAliases != nil {
+		in, out := &in.CellsAliases, &out.CellsAliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingPruneCells != nil {
+		in, out := &in.PendingPruneCells, &out.PendingPruneCells
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingPruneKeyspaces != nil {
+		in, out := &in.PendingPruneKeyspaces, &out.PendingPruneKeyspaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UsedPruneApprovals != nil {
+		in, out := &in.UsedPruneApprovals, &out.UsedPruneApprovals
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.TopologyDrift != nil {
+		in, out := &in.TopologyDrift, &out.TopologyDrift
+		*out = new(TopologyDriftStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessClusterStatus.
@@ -1786,6 +2605,11 @@ func (in *VitessDashboardSpec) DeepCopyInto(out *VitessDashboardSpec) {
 		*out = new(ServiceOverrides)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]v1.Toleration, len(*in))
@@ -1828,6 +2652,16 @@ func (in *VitessGatewayAuthentication) DeepCopyInto(out *VitessGatewayAuthentica
 		*out = new(VitessGatewayStaticAuthentication)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.LDAP != nil {
+		in, out := &in.LDAP, &out.LDAP
+		*out = new(VitessGatewayLDAPAuthentication)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCert != nil {
+		in, out := &in.ClientCert, &out.ClientCert
+		*out = new(VitessGatewayClientCertAuthentication)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayAuthentication.
@@ -1840,6 +2674,220 @@ func (in *VitessGatewayAuthentication) DeepCopy() *VitessGatewayAuthentication {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessGatewayClientCertAuthentication) DeepCopyInto(out *VitessGatewayClientCertAuthentication) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayClientCertAuthentication.
+func (in *VitessGatewayClientCertAuthentication) DeepCopy() *VitessGatewayClientCertAuthentication {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessGatewayClientCertAuthentication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessGatewayDrainSpec) DeepCopyInto(out *VitessGatewayDrainSpec) {
+	*out = *in
+	if in.PreStopDelay != nil {
+		in, out := &in.PreStopDelay, &out.PreStopDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayDrainSpec.
+func (in *VitessGatewayDrainSpec) DeepCopy() *VitessGatewayDrainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessGatewayDrainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessGatewayExternalService) DeepCopyInto(out *VitessGatewayExternalService) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayExternalService.
+func (in *VitessGatewayExternalService) DeepCopy() *VitessGatewayExternalService {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessGatewayExternalService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessGatewayExternalSpec) DeepCopyInto(out *VitessGatewayExternalSpec) {
+	*out = *in
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(VitessGatewayExternalService)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TCPRoute != nil {
+		in, out := &in.TCPRoute, &out.TCPRoute
+		*out = new(VitessGatewayTCPRoute)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayExternalSpec.
+func (in *VitessGatewayExternalSpec) DeepCopy() *VitessGatewayExternalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessGatewayExternalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessGatewayExternalStatus) DeepCopyInto(out *VitessGatewayExternalStatus) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayExternalStatus.
+func (in *VitessGatewayExternalStatus) DeepCopy() *VitessGatewayExternalStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessGatewayExternalStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessGatewayLDAPAuthentication) DeepCopyInto(out *VitessGatewayLDAPAuthentication) {
+	*out = *in
+	if in.ConfigSecret != nil {
+		in, out := &in.ConfigSecret, &out.ConfigSecret
+		*out = new(SecretSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayLDAPAuthentication.
+func (in *VitessGatewayLDAPAuthentication) DeepCopy() *VitessGatewayLDAPAuthentication {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessGatewayLDAPAuthentication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessGatewayParentRef) DeepCopyInto(out *VitessGatewayParentRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayParentRef.
+func (in *VitessGatewayParentRef) DeepCopy() *VitessGatewayParentRef {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessGatewayParentRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessGatewayPoolSpec) DeepCopyInto(out *VitessGatewayPoolSpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Autoscaler != nil {
+		in, out := &in.Autoscaler, &out.Autoscaler
+		*out = new(AutoscalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Keyspaces != nil {
+		in, out := &in.Keyspaces, &out.Keyspaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TabletTypes != nil {
+		in, out := &in.TabletTypes, &out.TabletTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExtraFlags != nil {
+		in, out := &in.ExtraFlags, &out.ExtraFlags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraLabels != nil {
+		in, out := &in.ExtraLabels, &out.ExtraLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ServiceOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayPoolSpec.
+func (in *VitessGatewayPoolSpec) DeepCopy() *VitessGatewayPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessGatewayPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessGatewaySecureTransport) DeepCopyInto(out *VitessGatewaySecureTransport) {
 	*out = *in
@@ -1855,27 +2903,52 @@ func (in *VitessGatewaySecureTransport) DeepCopy() *VitessGatewaySecureTransport
 	if in == nil {
 		return nil
 	}
-	out := new(VitessGatewaySecureTransport)
+	out := new(VitessGatewaySecureTransport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessGatewayStaticAuthentication) DeepCopyInto(out *VitessGatewayStaticAuthentication) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(SecretSource)
+		**out = **in
+	}
+	if in.ReloadInterval != nil {
+		in, out := &in.ReloadInterval, &out.ReloadInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayStaticAuthentication.
+func (in *VitessGatewayStaticAuthentication) DeepCopy() *VitessGatewayStaticAuthentication {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessGatewayStaticAuthentication)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VitessGatewayStaticAuthentication) DeepCopyInto(out *VitessGatewayStaticAuthentication) {
+func (in *VitessGatewayTCPRoute) DeepCopyInto(out *VitessGatewayTCPRoute) {
 	*out = *in
-	if in.Secret != nil {
-		in, out := &in.Secret, &out.Secret
-		*out = new(SecretSource)
-		**out = **in
+	if in.ParentRefs != nil {
+		in, out := &in.ParentRefs, &out.ParentRefs
+		*out = make([]VitessGatewayParentRef, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayStaticAuthentication.
-func (in *VitessGatewayStaticAuthentication) DeepCopy() *VitessGatewayStaticAuthentication {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayTCPRoute.
+func (in *VitessGatewayTCPRoute) DeepCopy() *VitessGatewayTCPRoute {
 	if in == nil {
 		return nil
 	}
-	out := new(VitessGatewayStaticAuthentication)
+	out := new(VitessGatewayTCPRoute)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1898,6 +2971,11 @@ func (in *VitessGatewayTLSSecureTransport) DeepCopyInto(out *VitessGatewayTLSSec
 		*out = new(SecretSource)
 		**out = **in
 	}
+	if in.OperatorManaged != nil {
+		in, out := &in.OperatorManaged, &out.OperatorManaged
+		*out = new(OperatorManagedTLS)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayTLSSecureTransport.
@@ -1910,6 +2988,31 @@ func (in *VitessGatewayTLSSecureTransport) DeepCopy() *VitessGatewayTLSSecureTra
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessGatewayTopologySpec) DeepCopyInto(out *VitessGatewayTopologySpec) {
+	*out = *in
+	if in.TrafficDistribution != nil {
+		in, out := &in.TrafficDistribution, &out.TrafficDistribution
+		*out = new(string)
+		**out = **in
+	}
+	if in.InternalTrafficPolicy != nil {
+		in, out := &in.InternalTrafficPolicy, &out.InternalTrafficPolicy
+		*out = new(v1.ServiceInternalTrafficPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessGatewayTopologySpec.
+func (in *VitessGatewayTopologySpec) DeepCopy() *VitessGatewayTopologySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessGatewayTopologySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessImagePullPolicies) DeepCopyInto(out *VitessImagePullPolicies) {
 	*out = *in
@@ -1945,6 +3048,41 @@ func (in *VitessImages) DeepCopy() *VitessImages {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessInternalTLSSpec) DeepCopyInto(out *VitessInternalTLSSpec) {
+	*out = *in
+	if in.OperatorManaged != nil {
+		in, out := &in.OperatorManaged, &out.OperatorManaged
+		*out = new(OperatorManagedTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertSecret != nil {
+		in, out := &in.CertSecret, &out.CertSecret
+		*out = new(SecretSource)
+		**out = **in
+	}
+	if in.KeySecret != nil {
+		in, out := &in.KeySecret, &out.KeySecret
+		*out = new(SecretSource)
+		**out = **in
+	}
+	if in.CACertSecret != nil {
+		in, out := &in.CACertSecret, &out.CACertSecret
+		*out = new(SecretSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessInternalTLSSpec.
+func (in *VitessInternalTLSSpec) DeepCopy() *VitessInternalTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessInternalTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessKeyRange) DeepCopyInto(out *VitessKeyRange) {
 	*out = *in
@@ -2211,6 +3349,16 @@ func (in *VitessKeyspaceSpec) DeepCopyInto(out *VitessKeyspaceSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.InternalTLS != nil {
+		in, out := &in.InternalTLS, &out.InternalTLS
+		*out = new(VitessInternalTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicies != nil {
+		in, out := &in.NetworkPolicies, &out.NetworkPolicies
+		*out = new(VitessNetworkPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TopologyReconciliation != nil {
 		in, out := &in.TopologyReconciliation, &out.TopologyReconciliation
 		*out = new(TopoReconcileConfig)
@@ -2257,6 +3405,27 @@ func (in *VitessKeyspaceStatus) DeepCopyInto(out *VitessKeyspaceStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.PendingPruneShards != nil {
+		in, out := &in.PendingPruneShards, &out.PendingPruneShards
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UsedPruneApprovals != nil {
+		in, out := &in.UsedPruneApprovals, &out.UsedPruneApprovals
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	if in.Resharding != nil {
 		in, out := &in.Resharding, &out.Resharding
 		*out = new(ReshardingStatus)
@@ -2351,6 +3520,49 @@ func (in *VitessLockserverParams) DeepCopy() *VitessLockserverParams {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VitessNetworkPolicySpec) DeepCopyInto(out *VitessNetworkPolicySpec) {
+	*out = *in
+	if in.ClientIngress != nil {
+		in, out := &in.ClientIngress, &out.ClientIngress
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OperatorIngress != nil {
+		in, out := &in.OperatorIngress, &out.OperatorIngress
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MetricsIngress != nil {
+		in, out := &in.MetricsIngress, &out.MetricsIngress
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraIngress != nil {
+		in, out := &in.ExtraIngress, &out.ExtraIngress
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessNetworkPolicySpec.
+func (in *VitessNetworkPolicySpec) DeepCopy() *VitessNetworkPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VitessNetworkPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VitessOrchestratorSpec) DeepCopyInto(out *VitessOrchestratorSpec) {
 	*out = *in
@@ -2421,6 +3633,11 @@ func (in *VitessOrchestratorSpec) DeepCopyInto(out *VitessOrchestratorSpec) {
 		*out = new(ServiceOverrides)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]v1.Toleration, len(*in))
@@ -2602,6 +3819,16 @@ func (in *VitessShardSpec) DeepCopyInto(out *VitessShardSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.InternalTLS != nil {
+		in, out := &in.InternalTLS, &out.InternalTLS
+		*out = new(VitessInternalTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicies != nil {
+		in, out := &in.NetworkPolicies, &out.NetworkPolicies
+		*out = new(VitessNetworkPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TopologyReconciliation != nil {
 		in, out := &in.TopologyReconciliation, &out.TopologyReconciliation
 		*out = new(TopoReconcileConfig)
@@ -2641,6 +3868,32 @@ func (in *VitessShardStatus) DeepCopyInto(out *VitessShardStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.PendingPruneTablets != nil {
+		in, out := &in.PendingPruneTablets, &out.PendingPruneTablets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingPruneShardCells != nil {
+		in, out := &in.PendingPruneShardCells, &out.PendingPruneShardCells
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UsedPruneApprovals != nil {
+		in, out := &in.UsedPruneApprovals, &out.UsedPruneApprovals
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	if in.Cells != nil {
 		in, out := &in.Cells, &out.Cells
 		*out = make([]string, len(*in))
@@ -2685,6 +3938,11 @@ func (in *VitessShardTabletPool) DeepCopyInto(out *VitessShardTabletPool) {
 		*out = new(v1.PersistentVolumeClaimSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DataVolumeAutoscaler != nil {
+		in, out := &in.DataVolumeAutoscaler, &out.DataVolumeAutoscaler
+		*out = new(DataVolumeAutoscalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Vttablet.DeepCopyInto(&out.Vttablet)
 	if in.Mysqld != nil {
 		in, out := &in.Mysqld, &out.Mysqld
@@ -2800,6 +4058,11 @@ func (in *VitessShardTemplate) DeepCopyInto(out *VitessShardTemplate) {
 			(*out)[key] = val
 		}
 	}
+	if in.TabletPodDisruptionBudget != nil {
+		in, out := &in.TabletPodDisruptionBudget, &out.TabletPodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VitessShardTemplate.
@@ -2992,3 +4255,170 @@ func (in *VttabletSpec) DeepCopy() *VttabletSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZkLockserver) DeepCopyInto(out *ZkLockserver) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZkLockserver.
+func (in *ZkLockserver) DeepCopy() *ZkLockserver {
+	if in == nil {
+		return nil
+	}
+	out := new(ZkLockserver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ZkLockserver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZkLockserverList) DeepCopyInto(out *ZkLockserverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ZkLockserver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZkLockserverList.
+func (in *ZkLockserverList) DeepCopy() *ZkLockserverList {
+	if in == nil {
+		return nil
+	}
+	out := new(ZkLockserverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ZkLockserverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZkLockserverSpec) DeepCopyInto(out *ZkLockserverSpec) {
+	*out = *in
+	in.ZkLockserverTemplate.DeepCopyInto(&out.ZkLockserverTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZkLockserverSpec.
+func (in *ZkLockserverSpec) DeepCopy() *ZkLockserverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ZkLockserverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZkLockserverStatus) DeepCopyInto(out *ZkLockserverStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZkLockserverStatus.
+func (in *ZkLockserverStatus) DeepCopy() *ZkLockserverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ZkLockserverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZkLockserverTemplate) DeepCopyInto(out *ZkLockserverTemplate) {
+	*out = *in
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	in.DataVolumeClaimTemplate.DeepCopyInto(&out.DataVolumeClaimTemplate)
+	if in.ExtraConfig != nil {
+		in, out := &in.ExtraConfig, &out.ExtraConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraLabels != nil {
+		in, out := &in.ExtraLabels, &out.ExtraLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CreatePDB != nil {
+		in, out := &in.CreatePDB, &out.CreatePDB
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ClientService != nil {
+		in, out := &in.ClientService, &out.ClientService
+		*out = new(ServiceOverrides)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZkLockserverTemplate.
+func (in *ZkLockserverTemplate) DeepCopy() *ZkLockserverTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ZkLockserverTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
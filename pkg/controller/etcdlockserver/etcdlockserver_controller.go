@@ -19,9 +19,11 @@ package etcdlockserver
 import (
 	"context"
 	"flag"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
@@ -41,6 +43,7 @@ import (
 	"planetscale.dev/vitess-operator/pkg/operator/metrics"
 	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
 	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/resync"
 )
 
 const (
@@ -49,6 +52,7 @@ const (
 
 var (
 	maxConcurrentReconciles = flag.Int("etcdlockserver_concurrent_reconciles", 10, "the maximum number of different etcdlockservers to reconcile concurrently")
+	resyncPeriod            = flag.Duration("etcdlockserver_resync_period", 1*time.Minute, "reconcile etcdlockservers with this period even if no Kubernetes events occur, to recheck member health")
 )
 
 var log = logrus.WithField("controller", "EtcdLockserver")
@@ -59,6 +63,8 @@ var watchResources = []client.Object{
 	&corev1.Service{},
 	&corev1.PersistentVolumeClaim{},
 	&policyv1.PodDisruptionBudget{},
+	&batchv1.CronJob{},
+	&batchv1.Job{},
 }
 
 // Add creates a new EtcdLockserver Controller and adds it to the Manager. The Manager will set fields on the Controller
@@ -68,7 +74,7 @@ func Add(mgr manager.Manager) error {
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager) *ReconcileEtcdLockserver {
 	c := mgr.GetClient()
 	scheme := mgr.GetScheme()
 	recorder := mgr.GetEventRecorderFor(controllerName)
@@ -78,11 +84,12 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 		scheme:     scheme,
 		recorder:   recorder,
 		reconciler: reconciler.New(c, scheme, recorder),
+		resync:     resync.NewPeriodic(controllerName, *resyncPeriod),
 	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r *ReconcileEtcdLockserver) error {
 	// Create a new controller
 	c, err := controller.New(controllerName, mgr, controller.Options{
 		Reconciler:              r,
@@ -111,6 +118,11 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		}
 	}
 
+	// Periodically resync even when no Kubernetes events have come in.
+	if err := c.Watch(r.resync.WatchSource()); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -124,6 +136,7 @@ type ReconcileEtcdLockserver struct {
 	scheme     *runtime.Scheme
 	recorder   record.EventRecorder
 	reconciler *reconciler.Reconciler
+	resync     *resync.Periodic
 }
 
 // Reconcile reads that state of the cluster for a EtcdLockserver object and makes changes based on the state read
@@ -150,6 +163,7 @@ func (r *ReconcileEtcdLockserver) Reconcile(cctx context.Context, request reconc
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			forgetDefrag(request.NamespacedName)
 			return resultBuilder.Result()
 		}
 		// Error reading the object - requeue the request.
@@ -162,15 +176,25 @@ func (r *ReconcileEtcdLockserver) Reconcile(cctx context.Context, request reconc
 	// Reset status, since that's all out of date info that we will recompute now.
 	oldStatus := ls.Status
 	ls.Status = *planetscalev2.NewEtcdLockserverStatus()
+	// Remember that we already restored a snapshot, so we never do it twice.
+	ls.Status.RestoredSnapshot = oldStatus.RestoredSnapshot
+	// Keep the last known cluster size until etcd reports it again.
+	ls.Status.Replicas = oldStatus.Replicas
+	// Remember when we last finished defragmenting, to know when it's due again.
+	ls.Status.LastDefragTime = oldStatus.LastDefragTime
 
 	// Create/update Services.
 	svcResult, err := r.reconcileServices(ctx, ls)
 	resultBuilder.Merge(svcResult, err)
 
 	// Create/update desired etcd members.
-	memberResult, err := r.reconcileMembers(ctx, ls)
+	memberResult, err := r.reconcileMembers(ctx, ls, oldStatus.Members)
 	resultBuilder.Merge(memberResult, err)
 
+	// Create/update the snapshot schedule.
+	snapshotResult, err := r.reconcileSnapshots(ctx, ls)
+	resultBuilder.Merge(snapshotResult, err)
+
 	// Create/update PDB.
 	pdbResult, err := r.reconcilePodDisruptionBudget(ctx, ls)
 	resultBuilder.Merge(pdbResult, err)
@@ -186,12 +210,17 @@ func (r *ReconcileEtcdLockserver) Reconcile(cctx context.Context, request reconc
 		}
 	}
 
+	// Request a periodic resync so we can recheck member health even if no
+	// Kubernetes events have occurred.
+	r.resync.Enqueue(request.NamespacedName)
+
 	// Update metrics.
 	available := float64(0)
 	if ls.Status.Available == corev1.ConditionTrue {
 		available = 1
 	}
 	clusterAvailable.WithLabelValues(ls.Name).Set(available)
+	updateMemberMetrics(ls)
 
 	result, err := resultBuilder.Result()
 	reconcileCount.WithLabelValues(ls.Name, metrics.Result(err)).Inc()
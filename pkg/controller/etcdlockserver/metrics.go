@@ -17,8 +17,12 @@ limitations under the License.
 package etcdlockserver
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
 
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/etcd"
 	"planetscale.dev/vitess-operator/pkg/operator/metrics"
 )
 
@@ -26,6 +30,7 @@ const (
 	metricsSubsystemName = "etcd_lockserver"
 
 	etcdClusterMetricsLabel = "etcd_cluster"
+	etcdMemberMetricsLabel  = "member"
 )
 
 var (
@@ -42,11 +47,50 @@ var (
 		Name:      "cluster_available",
 		Help:      "Whether an EtcdLockserver cluster is Available",
 	}, []string{etcdClusterMetricsLabel})
+
+	memberDBSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metricsSubsystemName,
+		Name:      "member_db_size_bytes",
+		Help:      "Size of the backend database file of an etcd member",
+	}, []string{etcdClusterMetricsLabel, etcdMemberMetricsLabel})
+
+	memberDBSizeInUse = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metricsSubsystemName,
+		Name:      "member_db_size_in_use_bytes",
+		Help:      "Part of the backend database file of an etcd member that's in use",
+	}, []string{etcdClusterMetricsLabel, etcdMemberMetricsLabel})
 )
 
 func init() {
 	metrics.Registry.MustRegister(
 		reconcileCount,
 		clusterAvailable,
+		memberDBSize,
+		memberDBSizeInUse,
 	)
 }
+
+// updateMemberMetrics exports the DB sizes that members last reported, and
+// drops metrics for members that are gone or couldn't be checked.
+func updateMemberMetrics(ls *planetscalev2.EtcdLockserver) {
+	reported := make(map[int32]*planetscalev2.EtcdMemberStatus, len(ls.Status.Members))
+	for i := range ls.Status.Members {
+		member := &ls.Status.Members[i]
+		if member.DBSizeBytes > 0 {
+			reported[member.Index] = member
+		}
+	}
+	for index := int32(1); index <= etcd.MaxReplicas; index++ {
+		memberLabel := strconv.Itoa(int(index))
+		member, ok := reported[index]
+		if !ok {
+			memberDBSize.DeleteLabelValues(ls.Name, memberLabel)
+			memberDBSizeInUse.DeleteLabelValues(ls.Name, memberLabel)
+			continue
+		}
+		memberDBSize.WithLabelValues(ls.Name, memberLabel).Set(float64(member.DBSizeBytes))
+		memberDBSizeInUse.WithLabelValues(ls.Name, memberLabel).Set(float64(member.DBSizeInUseBytes))
+	}
+}
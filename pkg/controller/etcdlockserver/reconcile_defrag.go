@@ -0,0 +1,183 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdlockserver
+
+import (
+	"context"
+	"crypto/tls"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+const (
+	// defragTimeout bounds how long we wait for one member to be defragmented.
+	defragTimeout = 5 * time.Minute
+	// defragPollInterval is how soon we check again on a running defrag.
+	defragPollInterval = 10 * time.Second
+)
+
+// defragRun is a defrag of one member that runs in the background, so it
+// doesn't hold up the reconcile loop.
+type defragRun struct {
+	index       int
+	freePercent int
+	done        chan struct{}
+	// err is only valid once done is closed.
+	err error
+}
+
+// defragRuns maps each lockserver to its running or finished defrag, if any.
+var defragRuns sync.Map
+
+// defragInProgress returns whether a defrag of one of the lockserver's
+// members is still running.
+func defragInProgress(ls *planetscalev2.EtcdLockserver) bool {
+	value, ok := defragRuns.Load(types.NamespacedName{Namespace: ls.Namespace, Name: ls.Name})
+	if !ok {
+		return false
+	}
+	select {
+	case <-value.(*defragRun).done:
+		return false
+	default:
+		return true
+	}
+}
+
+// forgetDefrag drops any record of a defrag for a lockserver that was deleted.
+func forgetDefrag(key types.NamespacedName) {
+	defragRuns.Delete(key)
+}
+
+// reconcileDefrag defragments at most one member if a scheduled check is due.
+// The check is finished, and the next one scheduled, once no member is above
+// the fragmentation threshold.
+//
+// The defrag itself runs in the background, since it can take minutes on a
+// large database. It returns whether a defrag is running or just finished,
+// in which case the caller should requeue to check on it.
+func (r *ReconcileEtcdLockserver) reconcileDefrag(ls *planetscalev2.EtcdLockserver, inCluster []*observedMember, tlsConfig *tls.Config, now time.Time) (bool, error) {
+	key := types.NamespacedName{Namespace: ls.Namespace, Name: ls.Name}
+	if value, ok := defragRuns.Load(key); ok {
+		run := value.(*defragRun)
+		select {
+		case <-run.done:
+		default:
+			return true, nil
+		}
+		defragRuns.Delete(key)
+		if run.err != nil {
+			r.recorder.Eventf(ls, corev1.EventTypeWarning, "DefragFailed", "failed to defragment etcd member %v: %v", run.index, run.err)
+			return true, run.err
+		}
+		r.recorder.Eventf(ls, corev1.EventTypeNormal, "Defragmented", "defragmented etcd member %v, which was %v%% free space", run.index, run.freePercent)
+		// Check the next member once we've seen the new sizes.
+		return true, nil
+	}
+
+	maintenance := ls.Spec.Maintenance
+	if maintenance == nil || maintenance.DefragSchedule == "" || maintenance.DefragThresholdPercent == nil {
+		return false, nil
+	}
+	sched, err := cron.ParseStandard(maintenance.DefragSchedule)
+	if err != nil {
+		r.recorder.Eventf(ls, corev1.EventTypeWarning, "DefragScheduleInvalid", "invalid etcd defragSchedule %q: %v", maintenance.DefragSchedule, err)
+		return false, nil
+	}
+
+	last := ls.CreationTimestamp.Time
+	if ls.Status.LastDefragTime != nil {
+		last = ls.Status.LastDefragTime.Time
+	}
+	if now.Before(sched.Next(last.UTC())) {
+		return false, nil
+	}
+
+	// Wait until every member is healthy, so the cluster can spare one.
+	if countUnhealthyMembers(inCluster) > 0 {
+		return false, nil
+	}
+
+	om := chooseDefrag(inCluster, int(*maintenance.DefragThresholdPercent))
+	if om == nil {
+		// Every member is below the threshold, so this check is done.
+		finished := metav1.NewTime(now)
+		ls.Status.LastDefragTime = &finished
+		return false, nil
+	}
+
+	run := &defragRun{
+		index:       om.spec.Index,
+		freePercent: fragmentationPercent(&om.status),
+		done:        make(chan struct{}),
+	}
+	defragRuns.Store(key, run)
+	go run.defragment(om.spec.ClientURL(ls.Namespace), tlsConfig)
+	r.recorder.Eventf(ls, corev1.EventTypeNormal, "DefragStarted", "defragmenting etcd member %v, which has %v%% free space", run.index, run.freePercent)
+	return true, nil
+}
+
+// defragment connects to a single member and defragments it. It doesn't
+// use the reconcile context, since it outlives the reconcile pass.
+func (run *defragRun) defragment(endpoint string, tlsConfig *tls.Config) {
+	defer close(run.done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defragTimeout)
+	defer cancel()
+	admin, err := openMemberAdmin(ctx, []string{endpoint}, tlsConfig)
+	if err != nil {
+		run.err = err
+		return
+	}
+	defer admin.Close()
+	run.err = admin.Defragment(ctx, endpoint)
+}
+
+// chooseDefrag returns the member that should be defragmented next, if any.
+// The leader is only chosen once no other member is above the threshold,
+// since defragmenting it makes the cluster hold an election if it takes
+// too long.
+func chooseDefrag(inCluster []*observedMember, thresholdPercent int) *observedMember {
+	var leader *observedMember
+	for _, om := range inCluster {
+		if fragmentationPercent(&om.status) <= thresholdPercent {
+			continue
+		}
+		if om.status.Leader {
+			leader = om
+			continue
+		}
+		return om
+	}
+	return leader
+}
+
+// fragmentationPercent returns how much of a member's backend database file
+// is free space, as a percentage of its total size.
+func fragmentationPercent(status *planetscalev2.EtcdMemberStatus) int {
+	if status.DBSizeBytes <= 0 || status.DBSizeInUseBytes >= status.DBSizeBytes {
+		return 0
+	}
+	return int((status.DBSizeBytes - status.DBSizeInUseBytes) * 100 / status.DBSizeBytes)
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdlockserver
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/etcd"
+	"planetscale.dev/vitess-operator/pkg/operator/etcdclient"
+)
+
+func TestChooseDefrag(t *testing.T) {
+	member := func(index int, freePercent int64, leader bool) *observedMember {
+		return &observedMember{
+			spec: &etcd.Spec{Index: index},
+			status: planetscalev2.EtcdMemberStatus{
+				Leader:           leader,
+				DBSizeBytes:      1000,
+				DBSizeInUseBytes: 1000 - freePercent*10,
+			},
+		}
+	}
+
+	table := []struct {
+		name      string
+		inCluster []*observedMember
+		wantIndex int
+	}{
+		{
+			name:      "all below threshold",
+			inCluster: []*observedMember{member(1, 10, true), member(2, 50, false), member(3, 0, false)},
+		},
+		{
+			name:      "follower above threshold",
+			inCluster: []*observedMember{member(1, 80, true), member(2, 20, false), member(3, 60, false)},
+			wantIndex: 3,
+		},
+		{
+			name:      "leader last",
+			inCluster: []*observedMember{member(1, 10, false), member(2, 80, true), member(3, 20, false)},
+			wantIndex: 2,
+		},
+		{
+			name:      "unknown size",
+			inCluster: []*observedMember{{spec: &etcd.Spec{Index: 1}}},
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			got := chooseDefrag(test.inCluster, 50)
+			if test.wantIndex == 0 {
+				assert.Nil(t, got)
+				return
+			}
+			if assert.NotNil(t, got) {
+				assert.Equal(t, test.wantIndex, got.spec.Index)
+			}
+		})
+	}
+}
+
+func TestReconcileDefragInBackground(t *testing.T) {
+	admin := &fakeMemberAdmin{defragRelease: make(chan struct{})}
+	oldOpen := openMemberAdmin
+	openMemberAdmin = func(ctx context.Context, endpoints []string, tlsConfig *tls.Config) (memberAdmin, error) {
+		return admin, nil
+	}
+	t.Cleanup(func() { openMemberAdmin = oldOpen })
+
+	ls := &planetscalev2.EtcdLockserver{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "ns",
+			Name:              "ls",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-48 * time.Hour)),
+		},
+		Spec: planetscalev2.EtcdLockserverSpec{
+			EtcdLockserverTemplate: planetscalev2.EtcdLockserverTemplate{
+				Maintenance: &planetscalev2.EtcdMaintenance{
+					DefragSchedule:         "0 0 * * *",
+					DefragThresholdPercent: ptr.To(int32(50)),
+				},
+			},
+		},
+	}
+	t.Cleanup(func() { forgetDefrag(types.NamespacedName{Namespace: "ns", Name: "ls"}) })
+	inCluster := []*observedMember{{
+		spec:   &etcd.Spec{LockserverName: "ls", Index: 1},
+		member: &etcdclient.Member{ID: 1, Name: "member"},
+		status: planetscalev2.EtcdMemberStatus{Healthy: corev1.ConditionTrue, DBSizeBytes: 1000, DBSizeInUseBytes: 200},
+	}}
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileEtcdLockserver{recorder: recorder}
+
+	// The first pass starts the defrag and returns without waiting for it.
+	requeue, err := r.reconcileDefrag(ls, inCluster, nil, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, requeue)
+	assert.True(t, defragInProgress(ls))
+	assert.Contains(t, <-recorder.Events, "DefragStarted")
+
+	// While it's running, nothing else is started.
+	requeue, err = r.reconcileDefrag(ls, inCluster, nil, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, requeue)
+	assert.Empty(t, recorder.Events)
+
+	close(admin.defragRelease)
+	assert.Eventually(t, func() bool { return !defragInProgress(ls) }, 5*time.Second, 10*time.Millisecond)
+
+	// The next pass reports the outcome.
+	requeue, err = r.reconcileDefrag(ls, inCluster, nil, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, requeue)
+	assert.Contains(t, <-recorder.Events, "Defragmented")
+	assert.Nil(t, ls.Status.LastDefragTime, "the check isn't finished until every member is below the threshold")
+}
@@ -0,0 +1,534 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdlockserver
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/etcd"
+	"planetscale.dev/vitess-operator/pkg/operator/etcdclient"
+)
+
+// memberHealthTimeout bounds the time spent talking to etcd in one pass.
+const memberHealthTimeout = 10 * time.Second
+
+// memberAdmin is the subset of etcdclient.Client used to check and replace members.
+type memberAdmin interface {
+	Members(ctx context.Context) ([]etcdclient.Member, uint64, error)
+	Status(ctx context.Context, endpoint string) (*etcdclient.Status, error)
+	Defragment(ctx context.Context, endpoint string) error
+	RemoveMember(ctx context.Context, id uint64) error
+	AddLearner(ctx context.Context, peerURL string) (uint64, error)
+	PromoteMember(ctx context.Context, id uint64) error
+	Close() error
+}
+
+// openMemberAdmin connects to an etcd cluster. It's a variable so tests can
+// replace it.
+var openMemberAdmin = func(ctx context.Context, endpoints []string, tlsConfig *tls.Config) (memberAdmin, error) {
+	c, err := etcdclient.New(ctx, endpoints, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// memberHealth is what the rest of the reconcile pass needs to know about
+// the outcome of checking member health and changing membership.
+type memberHealth struct {
+	// known is whether the cluster's member list could be read. If not,
+	// active and clusterSize are not valid.
+	known bool
+	// active is the set of member indexes in the cluster's member list.
+	active map[int]bool
+	// clusterSize is the highest member index in the cluster's member list.
+	clusterSize int
+	// joining is the set of member indexes that are in the cluster's member
+	// list but haven't started yet.
+	joining map[int]bool
+	// removed is the set of member indexes whose Pod and PVC were deleted
+	// in this pass, either to be recreated with fresh data or to scale down.
+	removed map[int]bool
+	// defragging is whether a member is being defragmented in the background.
+	defragging bool
+}
+
+// observedMember is a desired member together with what etcd says about it.
+type observedMember struct {
+	spec   *etcd.Spec
+	member *etcdclient.Member
+	status planetscalev2.EtcdMemberStatus
+	// promotable is whether the member is a learner that passes every
+	// health check, so it's only waiting to be promoted.
+	promotable bool
+}
+
+// reconcileMemberHealth checks the health of each member through the etcd
+// client API and records it in the lockserver status.
+//
+// It then makes at most one membership change, and only if the change can't
+// cost the cluster its quorum. In order of priority, it promotes a learner
+// that has caught up, replaces a member that has stayed unhealthy for too
+// long, removes a member to scale down, or adds a member to scale up.
+//
+// New members are always added as learners, which don't vote until they're
+// promoted. That way, adding a member whose Pod doesn't exist yet can't make
+// the cluster lose quorum, even when scaling up from a single member.
+func (r *ReconcileEtcdLockserver) reconcileMemberHealth(ctx context.Context, ls *planetscalev2.EtcdLockserver, members []*etcd.Spec, oldMembers []planetscalev2.EtcdMemberStatus) (*memberHealth, error) {
+	health := &memberHealth{
+		active:  map[int]bool{},
+		joining: map[int]bool{},
+		removed: map[int]bool{},
+	}
+	replicas := int(*ls.Spec.Replicas)
+
+	// If we're only deploying one member locally, we can't see the rest of
+	// the cluster, and it's not safe to change membership on our own.
+	if ls.Spec.LocalMemberIndex != nil {
+		ls.Status.Members = nil
+		return health, nil
+	}
+
+	// Only report on desired members until we know who's in the cluster.
+	desired := members[:min(replicas, len(members))]
+
+	tlsConfig, err := r.memberClientTLSConfig(ctx, ls)
+	if err != nil {
+		setMemberStatusUnknown(ls, desired, err)
+		return health, err
+	}
+
+	// Connect through any member that might exist.
+	numEndpoints := max(replicas, int(ls.Status.Replicas))
+	endpoints := make([]string, 0, numEndpoints)
+	for _, member := range members {
+		if member.Index <= numEndpoints {
+			endpoints = append(endpoints, member.ClientURL(ls.Namespace))
+		}
+	}
+	admin, err := openMemberAdmin(ctx, endpoints, tlsConfig)
+	if err != nil {
+		// The cluster may not be up yet. Don't take any action.
+		setMemberStatusUnknown(ls, desired, err)
+		return health, nil
+	}
+	defer admin.Close()
+
+	// Bound the time spent checking health and changing membership.
+	checkCtx, cancel := context.WithTimeout(ctx, memberHealthTimeout)
+	defer cancel()
+
+	clusterMembers, clusterID, err := admin.Members(checkCtx)
+	if err != nil {
+		// Without the member list, we can't tell whether the cluster has
+		// quorum, so don't take any action.
+		setMemberStatusUnknown(ls, desired, err)
+		return health, nil
+	}
+
+	observed := observeMembers(checkCtx, admin, ls.Namespace, members, clusterMembers, clusterID, oldMembers, time.Now())
+	inCluster := make([]*observedMember, 0, len(observed))
+	for _, om := range observed {
+		if om.member == nil {
+			continue
+		}
+		inCluster = append(inCluster, om)
+		health.active[om.spec.Index] = true
+		if !om.member.Started() {
+			health.joining[om.spec.Index] = true
+		}
+	}
+	health.known = true
+
+	if canChangeMembership(ls) {
+		var changed bool
+		// Leave membership alone while a member is being defragmented,
+		// since it may not respond to health checks until it's done.
+		if !defragInProgress(ls) {
+			changed, err = r.changeMembership(checkCtx, ls, admin, health, observed, inCluster)
+		}
+		// Only do maintenance while membership is stable.
+		if !changed && err == nil {
+			health.defragging, err = r.reconcileDefrag(ls, inCluster, tlsConfig, time.Now())
+		}
+	}
+
+	// Report on members that are either desired or still in the cluster.
+	ls.Status.Members = make([]planetscalev2.EtcdMemberStatus, 0, len(observed))
+	for _, om := range observed {
+		if om.spec.Index <= replicas || health.active[om.spec.Index] {
+			ls.Status.Members = append(ls.Status.Members, om.status)
+		}
+	}
+	for index := range health.active {
+		health.clusterSize = max(health.clusterSize, index)
+	}
+	ls.Status.Replicas = int32(len(health.active))
+
+	return health, err
+}
+
+// changeMembership makes at most one change to the membership of the cluster,
+// and updates health and the observed statuses to match. It returns whether
+// it attempted a change.
+func (r *ReconcileEtcdLockserver) changeMembership(ctx context.Context, ls *planetscalev2.EtcdLockserver, admin memberAdmin, health *memberHealth, observed, inCluster []*observedMember) (bool, error) {
+	replicas := int(*ls.Spec.Replicas)
+	now := time.Now()
+
+	if om := choosePromotion(inCluster); om != nil {
+		if err := admin.PromoteMember(ctx, om.member.ID); err != nil {
+			if etcdclient.IsLearnerNotReady(err) {
+				// It's still catching up. Check again next time.
+				om.status.Message = "waiting for learner to catch up with the leader"
+				return true, nil
+			}
+			r.recorder.Eventf(ls, corev1.EventTypeWarning, "MemberPromoteFailed", "failed to promote etcd member %v: %v", om.spec.Index, err)
+			return true, err
+		}
+		r.recorder.Eventf(ls, corev1.EventTypeNormal, "MemberPromoted", "promoted etcd member %v to a voting member", om.spec.Index)
+		om.status.Healthy = corev1.ConditionTrue
+		om.status.UnhealthySince = nil
+		om.status.Message = ""
+		return true, nil
+	}
+
+	if canReplaceMembers(ls) {
+		if om := chooseReplacement(inCluster, ls.Spec.MemberReplacement.UnhealthyTimeout.Duration, now); om != nil {
+			if err := r.replaceMember(ctx, ls, admin, om); err != nil {
+				r.recorder.Eventf(ls, corev1.EventTypeWarning, "MemberReplaceFailed", "failed to replace unhealthy etcd member %v: %v", om.spec.Index, err)
+				return true, err
+			}
+			r.recorder.Eventf(ls, corev1.EventTypeNormal, "MemberReplaced", "replaced unhealthy etcd member %v: %v", om.spec.Index, om.status.Message)
+			health.removed[om.spec.Index] = true
+			health.joining[om.spec.Index] = true
+			// Give the new member time to catch up before judging it.
+			markJoining(om, now, "replacing member with fresh data")
+			return true, nil
+		}
+	}
+
+	if om := chooseScaleDown(inCluster, replicas); om != nil {
+		if err := r.removeMember(ctx, ls, admin, om); err != nil {
+			r.recorder.Eventf(ls, corev1.EventTypeWarning, "ScaleDownFailed", "failed to remove etcd member %v: %v", om.spec.Index, err)
+			return true, err
+		}
+		r.recorder.Eventf(ls, corev1.EventTypeNormal, "MemberRemoved", "removed etcd member %v to scale down to %v members", om.spec.Index, replicas)
+		health.removed[om.spec.Index] = true
+		delete(health.active, om.spec.Index)
+		delete(health.joining, om.spec.Index)
+		return true, nil
+	}
+
+	if om := chooseScaleUp(observed, inCluster, replicas); om != nil {
+		if _, err := admin.AddLearner(ctx, om.spec.PeerURL()); err != nil {
+			r.recorder.Eventf(ls, corev1.EventTypeWarning, "ScaleUpFailed", "failed to add etcd member %v: %v", om.spec.Index, err)
+			return true, err
+		}
+		r.recorder.Eventf(ls, corev1.EventTypeNormal, "MemberAdded", "added etcd member %v to scale up to %v members", om.spec.Index, replicas)
+		health.active[om.spec.Index] = true
+		health.joining[om.spec.Index] = true
+		markJoining(om, now, "waiting to join the cluster")
+		return true, nil
+	}
+	return false, nil
+}
+
+// markJoining resets the status of a member that was just added to the cluster.
+func markJoining(om *observedMember, now time.Time, message string) {
+	since := metav1.NewTime(now)
+	om.status = planetscalev2.EtcdMemberStatus{
+		Index:          om.status.Index,
+		Healthy:        corev1.ConditionFalse,
+		UnhealthySince: &since,
+		Message:        message,
+	}
+}
+
+// canChangeMembership returns whether the operator may change the membership
+// of the cluster at all.
+func canChangeMembership(ls *planetscalev2.EtcdLockserver) bool {
+	// Don't interfere with members that are still being restored from a snapshot.
+	return ls.Spec.Restore == nil || ls.Status.RestoredSnapshot != ""
+}
+
+// canReplaceMembers returns whether automatic member replacement is enabled.
+func canReplaceMembers(ls *planetscalev2.EtcdLockserver) bool {
+	return ls.Spec.MemberReplacement != nil && !ls.Spec.MemberReplacement.Disabled && ls.Spec.MemberReplacement.UnhealthyTimeout != nil
+}
+
+// memberClientTLSConfig returns the TLS config the operator uses to connect
+// to the lockserver, or nil if it doesn't use TLS.
+func (r *ReconcileEtcdLockserver) memberClientTLSConfig(ctx context.Context, ls *planetscalev2.EtcdLockserver) (*tls.Config, error) {
+	if ls.Spec.TLS == nil || ls.Spec.TLS.ServerSecret == "" {
+		return nil, nil
+	}
+	// Prefer the client certificate, but the server certificate is also
+	// valid for client authentication.
+	secretName := ls.Spec.TLS.ClientSecret
+	if secretName == "" {
+		secretName = ls.Spec.TLS.ServerSecret
+	}
+	secret := &corev1.Secret{}
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: ls.Namespace, Name: secretName}, secret); err != nil {
+		return nil, fmt.Errorf("can't get etcd client TLS Secret %v: %v", secretName, err)
+	}
+	return etcdclient.TLSConfig(secret)
+}
+
+// observeMembers matches desired members with cluster members and asks each
+// one for its status.
+func observeMembers(ctx context.Context, admin memberAdmin, namespace string, members []*etcd.Spec, clusterMembers []etcdclient.Member, clusterID uint64, oldMembers []planetscalev2.EtcdMemberStatus, now time.Time) []*observedMember {
+	oldStatus := make(map[int32]*planetscalev2.EtcdMemberStatus, len(oldMembers))
+	for i := range oldMembers {
+		oldStatus[oldMembers[i].Index] = &oldMembers[i]
+	}
+
+	observed := make([]*observedMember, 0, len(members))
+	for _, spec := range members {
+		om := &observedMember{
+			spec:   spec,
+			member: findClusterMember(spec, clusterMembers),
+			status: planetscalev2.EtcdMemberStatus{
+				Index: int32(spec.Index),
+			},
+		}
+		observed = append(observed, om)
+
+		if om.member != nil {
+			om.status.ID = strconv.FormatUint(om.member.ID, 16)
+		}
+		om.status.Healthy, om.status.Message = checkMember(ctx, admin, namespace, om, clusterID)
+
+		if om.status.Healthy == corev1.ConditionTrue {
+			continue
+		}
+		// Remember when the member first became unhealthy.
+		if old := oldStatus[om.status.Index]; old != nil && old.UnhealthySince != nil {
+			om.status.UnhealthySince = old.UnhealthySince
+		} else {
+			since := metav1.NewTime(now)
+			om.status.UnhealthySince = &since
+		}
+	}
+	return observed
+}
+
+// checkMember determines whether a member is healthy, and if not, why not.
+// It also fills in the leader and DB size fields of the member's status.
+func checkMember(ctx context.Context, admin memberAdmin, namespace string, om *observedMember, clusterID uint64) (corev1.ConditionStatus, string) {
+	if om.member == nil {
+		return corev1.ConditionFalse, "not a member of the cluster"
+	}
+	if !om.member.Started() {
+		return corev1.ConditionFalse, "waiting to join the cluster"
+	}
+	status, err := admin.Status(ctx, om.spec.ClientURL(namespace))
+	if err != nil {
+		return corev1.ConditionFalse, fmt.Sprintf("can't get member status: %v", err)
+	}
+	om.status.Leader = status.IsLeader()
+	om.status.DBSizeBytes = status.DBSize
+	om.status.DBSizeInUseBytes = status.DBSizeInUse
+
+	if status.ClusterID != clusterID {
+		return corev1.ConditionFalse, fmt.Sprintf("member belongs to cluster %x instead of %x", status.ClusterID, clusterID)
+	}
+	if status.MemberID != om.member.ID {
+		return corev1.ConditionFalse, fmt.Sprintf("member reports ID %x instead of %x", status.MemberID, om.member.ID)
+	}
+	if len(status.Errors) > 0 {
+		return corev1.ConditionFalse, strings.Join(status.Errors, "; ")
+	}
+	if om.member.IsLearner {
+		// It doesn't vote yet, so it can't count as healthy.
+		om.promotable = true
+		return corev1.ConditionFalse, "waiting to be promoted from learner"
+	}
+	return corev1.ConditionTrue, ""
+}
+
+// findClusterMember returns the cluster member that corresponds to the
+// desired member, or nil if there is none.
+func findClusterMember(spec *etcd.Spec, clusterMembers []etcdclient.Member) *etcdclient.Member {
+	name := etcd.PodName(spec.LockserverName, spec.Index)
+	peerURL := spec.PeerURL()
+	for i := range clusterMembers {
+		m := &clusterMembers[i]
+		if m.Name == name {
+			return m
+		}
+		// Members that haven't started yet have no name.
+		for _, u := range m.PeerURLs {
+			if u == peerURL {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+// choosePromotion returns the learner that should be promoted to a voting
+// member, if any.
+func choosePromotion(inCluster []*observedMember) *observedMember {
+	for _, om := range inCluster {
+		if om.promotable {
+			return om
+		}
+	}
+	return nil
+}
+
+// chooseReplacement returns the member of the cluster that should be
+// replaced, if any.
+//
+// A member is replaced if it has been unhealthy for longer than the timeout.
+// To avoid losing quorum, nothing is replaced unless all the other members
+// are healthy. Members that are missing from the cluster entirely are added
+// back by chooseScaleUp instead.
+func chooseReplacement(inCluster []*observedMember, timeout time.Duration, now time.Time) *observedMember {
+	// A single member can't be replaced without losing all data.
+	if len(inCluster) < 2 {
+		return nil
+	}
+	candidate := onlyUnhealthyMember(inCluster)
+	if candidate == nil {
+		return nil
+	}
+	if candidate.status.UnhealthySince != nil && now.Sub(candidate.status.UnhealthySince.Time) >= timeout {
+		return candidate
+	}
+	return nil
+}
+
+// chooseScaleDown returns the member of the cluster that should be removed
+// to scale down, if any. Members are removed in order of decreasing index,
+// once all the other members are healthy.
+func chooseScaleDown(inCluster []*observedMember, replicas int) *observedMember {
+	var candidate *observedMember
+	for _, om := range inCluster {
+		if om.spec.Index > replicas && (candidate == nil || om.spec.Index > candidate.spec.Index) {
+			candidate = om
+		}
+	}
+	if candidate == nil {
+		return nil
+	}
+	// It's fine if the member we remove is itself unhealthy.
+	for _, om := range inCluster {
+		if om != candidate && om.status.Healthy != corev1.ConditionTrue {
+			return nil
+		}
+	}
+	return candidate
+}
+
+// chooseScaleUp returns the member that should be added to the cluster to
+// scale up, if any. Members are added in order of increasing index, once all
+// existing members are healthy.
+func chooseScaleUp(observed, inCluster []*observedMember, replicas int) *observedMember {
+	if countUnhealthyMembers(inCluster) > 0 {
+		return nil
+	}
+	for _, om := range observed {
+		if om.member == nil && om.spec.Index <= replicas {
+			return om
+		}
+	}
+	return nil
+}
+
+// onlyUnhealthyMember returns the unhealthy member if exactly one member is
+// unhealthy, or nil otherwise.
+func onlyUnhealthyMember(members []*observedMember) *observedMember {
+	var unhealthy *observedMember
+	for _, om := range members {
+		if om.status.Healthy == corev1.ConditionTrue {
+			continue
+		}
+		if unhealthy != nil {
+			return nil
+		}
+		unhealthy = om
+	}
+	return unhealthy
+}
+
+// countUnhealthyMembers returns how many members are not healthy.
+func countUnhealthyMembers(members []*observedMember) int {
+	count := 0
+	for _, om := range members {
+		if om.status.Healthy != corev1.ConditionTrue {
+			count++
+		}
+	}
+	return count
+}
+
+// replaceMember removes a member from the cluster, deletes its Pod and data
+// volume, and adds it back as a learner so it will rejoin with fresh data.
+func (r *ReconcileEtcdLockserver) replaceMember(ctx context.Context, ls *planetscalev2.EtcdLockserver, admin memberAdmin, om *observedMember) error {
+	if err := r.removeMember(ctx, ls, admin, om); err != nil {
+		return err
+	}
+	if _, err := admin.AddLearner(ctx, om.spec.PeerURL()); err != nil {
+		return fmt.Errorf("can't add member back: %v", err)
+	}
+	return nil
+}
+
+// removeMember removes a member from the cluster and deletes its Pod and
+// data volume.
+func (r *ReconcileEtcdLockserver) removeMember(ctx context.Context, ls *planetscalev2.EtcdLockserver, admin memberAdmin, om *observedMember) error {
+	if err := admin.RemoveMember(ctx, om.member.ID); err != nil {
+		return fmt.Errorf("can't remove member %x: %v", om.member.ID, err)
+	}
+
+	// The Pod and the PVC have the same name.
+	key := client.ObjectKey{Namespace: ls.Namespace, Name: etcd.PodName(ls.Name, om.spec.Index)}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	if err := r.client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("can't delete Pod %v: %v", key.Name, err)
+	}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	if err := r.client.Delete(ctx, pvc); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("can't delete PVC %v: %v", key.Name, err)
+	}
+	return nil
+}
+
+// setMemberStatusUnknown records that member health couldn't be determined.
+func setMemberStatusUnknown(ls *planetscalev2.EtcdLockserver, members []*etcd.Spec, err error) {
+	ls.Status.Members = make([]planetscalev2.EtcdMemberStatus, 0, len(members))
+	for _, member := range members {
+		ls.Status.Members = append(ls.Status.Members, planetscalev2.EtcdMemberStatus{
+			Index:   int32(member.Index),
+			Healthy: corev1.ConditionUnknown,
+			Message: fmt.Sprintf("can't check member health: %v", err),
+		})
+	}
+}
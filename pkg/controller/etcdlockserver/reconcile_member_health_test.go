@@ -0,0 +1,335 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdlockserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/etcd"
+	"planetscale.dev/vitess-operator/pkg/operator/etcdclient"
+)
+
+// fakeMemberAdmin answers status requests from a fixed map.
+type fakeMemberAdmin struct {
+	statuses map[string]*etcdclient.Status
+
+	promoteErr error
+	promoted   []uint64
+	learners   []string
+
+	// defragRelease, if set, blocks Defragment until it's closed.
+	defragRelease chan struct{}
+}
+
+func (f *fakeMemberAdmin) Members(ctx context.Context) ([]etcdclient.Member, uint64, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func (f *fakeMemberAdmin) Status(ctx context.Context, endpoint string) (*etcdclient.Status, error) {
+	if status, ok := f.statuses[endpoint]; ok {
+		return status, nil
+	}
+	return nil, errors.New("connection refused")
+}
+
+func (f *fakeMemberAdmin) Defragment(ctx context.Context, endpoint string) error {
+	if f.defragRelease != nil {
+		<-f.defragRelease
+	}
+	return nil
+}
+
+func (f *fakeMemberAdmin) RemoveMember(ctx context.Context, id uint64) error { return nil }
+
+func (f *fakeMemberAdmin) AddLearner(ctx context.Context, peerURL string) (uint64, error) {
+	f.learners = append(f.learners, peerURL)
+	return 0, nil
+}
+
+func (f *fakeMemberAdmin) PromoteMember(ctx context.Context, id uint64) error {
+	if f.promoteErr != nil {
+		return f.promoteErr
+	}
+	f.promoted = append(f.promoted, id)
+	return nil
+}
+
+func (f *fakeMemberAdmin) Close() error { return nil }
+
+func TestObserveMembers(t *testing.T) {
+	const clusterID = 0xc1
+	now := time.Now()
+	earlier := metav1.NewTime(now.Add(-time.Hour))
+
+	specs := make([]*etcd.Spec, 0, 4)
+	for i := 1; i <= 4; i++ {
+		specs = append(specs, &etcd.Spec{LockserverName: "ls", Index: i, Replicas: 4, InitialClusterSize: 3})
+	}
+	clusterMembers := []etcdclient.Member{
+		{ID: 0x1, Name: etcd.PodName("ls", 1), PeerURLs: []string{specs[0].PeerURL()}},
+		// Member 2 was added but hasn't started, so it has no name yet.
+		{ID: 0x2, PeerURLs: []string{specs[1].PeerURL()}},
+		{ID: 0x3, Name: etcd.PodName("ls", 3), PeerURLs: []string{specs[2].PeerURL()}},
+		{ID: 0x4, Name: etcd.PodName("ls", 4), PeerURLs: []string{specs[3].PeerURL()}, IsLearner: true},
+	}
+	admin := &fakeMemberAdmin{statuses: map[string]*etcdclient.Status{
+		specs[0].ClientURL("ns"): {ClusterID: clusterID, MemberID: 0x1, Leader: 0x1, DBSize: 200, DBSizeInUse: 100},
+		specs[2].ClientURL("ns"): {ClusterID: clusterID, MemberID: 0x3, Leader: 0x1, Errors: []string{"CORRUPT"}},
+		specs[3].ClientURL("ns"): {ClusterID: clusterID, MemberID: 0x4, Leader: 0x1},
+	}}
+	oldMembers := []planetscalev2.EtcdMemberStatus{
+		{Index: 3, Healthy: corev1.ConditionFalse, UnhealthySince: &earlier},
+	}
+
+	observed := observeMembers(context.Background(), admin, "ns", specs, clusterMembers, clusterID, oldMembers, now)
+
+	assert.Equal(t, planetscalev2.EtcdMemberStatus{
+		Index:            1,
+		ID:               "1",
+		Healthy:          corev1.ConditionTrue,
+		Leader:           true,
+		DBSizeBytes:      200,
+		DBSizeInUseBytes: 100,
+	}, observed[0].status)
+
+	assert.Equal(t, corev1.ConditionFalse, observed[1].status.Healthy)
+	assert.Equal(t, "waiting to join the cluster", observed[1].status.Message)
+	assert.Equal(t, now.Unix(), observed[1].status.UnhealthySince.Unix())
+
+	assert.Equal(t, corev1.ConditionFalse, observed[2].status.Healthy)
+	assert.Equal(t, "CORRUPT", observed[2].status.Message)
+	assert.Equal(t, &earlier, observed[2].status.UnhealthySince, "UnhealthySince should be carried over")
+	assert.False(t, observed[2].promotable)
+
+	assert.Equal(t, corev1.ConditionFalse, observed[3].status.Healthy, "a learner doesn't vote, so it isn't healthy yet")
+	assert.True(t, observed[3].promotable)
+}
+
+func TestChangeMembershipLearners(t *testing.T) {
+	member := func(index int, learner, healthy bool) *observedMember {
+		om := &observedMember{
+			spec:       &etcd.Spec{LockserverName: "ls", Index: index},
+			member:     &etcdclient.Member{ID: uint64(index), Name: "member", IsLearner: learner},
+			status:     planetscalev2.EtcdMemberStatus{Index: int32(index), Healthy: corev1.ConditionFalse},
+			promotable: learner && healthy,
+		}
+		if healthy && !learner {
+			om.status.Healthy = corev1.ConditionTrue
+		}
+		return om
+	}
+	ls := &planetscalev2.EtcdLockserver{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ls"},
+		Spec: planetscalev2.EtcdLockserverSpec{
+			EtcdLockserverTemplate: planetscalev2.EtcdLockserverTemplate{Replicas: ptr.To(int32(3))},
+		},
+	}
+	r := &ReconcileEtcdLockserver{recorder: record.NewFakeRecorder(10)}
+	newHealth := func() *memberHealth {
+		return &memberHealth{active: map[int]bool{}, joining: map[int]bool{}, removed: map[int]bool{}}
+	}
+
+	t.Run("scale up from one member adds a learner", func(t *testing.T) {
+		admin := &fakeMemberAdmin{}
+		inCluster := []*observedMember{member(1, false, true)}
+		missing := &observedMember{spec: &etcd.Spec{LockserverName: "ls", Index: 2}}
+		observed := append([]*observedMember{missing}, inCluster...)
+
+		changed, err := r.changeMembership(t.Context(), ls, admin, newHealth(), observed, inCluster)
+		assert.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, []string{missing.spec.PeerURL()}, admin.learners)
+	})
+
+	t.Run("promote caught up learner", func(t *testing.T) {
+		admin := &fakeMemberAdmin{}
+		learner := member(2, true, true)
+		inCluster := []*observedMember{member(1, false, true), learner}
+
+		changed, err := r.changeMembership(t.Context(), ls, admin, newHealth(), inCluster, inCluster)
+		assert.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, []uint64{2}, admin.promoted)
+		assert.Equal(t, corev1.ConditionTrue, learner.status.Healthy)
+		assert.Empty(t, admin.learners, "nothing else should change in the same pass")
+	})
+
+	t.Run("learner still catching up", func(t *testing.T) {
+		admin := &fakeMemberAdmin{promoteErr: rpctypes.ErrLearnerNotReady}
+		learner := member(2, true, true)
+		inCluster := []*observedMember{member(1, false, true), learner}
+
+		changed, err := r.changeMembership(t.Context(), ls, admin, newHealth(), inCluster, inCluster)
+		assert.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, corev1.ConditionFalse, learner.status.Healthy)
+		assert.Empty(t, admin.learners, "don't add another member until the learner is promoted")
+	})
+}
+
+func TestChooseReplacement(t *testing.T) {
+	const timeout = 10 * time.Minute
+	now := time.Now()
+
+	healthy := func(index int) *observedMember {
+		return &observedMember{
+			spec:   &etcd.Spec{Index: index},
+			member: &etcdclient.Member{ID: uint64(index), Name: "member"},
+			status: planetscalev2.EtcdMemberStatus{Healthy: corev1.ConditionTrue},
+		}
+	}
+	unhealthyFor := func(index int, d time.Duration) *observedMember {
+		since := metav1.NewTime(now.Add(-d))
+		om := healthy(index)
+		om.status = planetscalev2.EtcdMemberStatus{Healthy: corev1.ConditionFalse, UnhealthySince: &since}
+		return om
+	}
+	table := []struct {
+		name      string
+		observed  []*observedMember
+		wantIndex int
+	}{
+		{
+			name:     "all healthy",
+			observed: []*observedMember{healthy(1), healthy(2), healthy(3)},
+		},
+		{
+			name:     "unhealthy within timeout",
+			observed: []*observedMember{healthy(1), unhealthyFor(2, time.Minute), healthy(3)},
+		},
+		{
+			name:      "unhealthy past timeout",
+			observed:  []*observedMember{healthy(1), unhealthyFor(2, time.Hour), healthy(3)},
+			wantIndex: 2,
+		},
+		{
+			name:     "only member",
+			observed: []*observedMember{unhealthyFor(1, time.Hour)},
+		},
+		{
+			name:     "would lose quorum",
+			observed: []*observedMember{unhealthyFor(1, time.Hour), unhealthyFor(2, time.Hour), healthy(3)},
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			got := chooseReplacement(test.observed, timeout, now)
+			if test.wantIndex == 0 {
+				assert.Nil(t, got)
+				return
+			}
+			if assert.NotNil(t, got) {
+				assert.Equal(t, test.wantIndex, got.spec.Index)
+			}
+		})
+	}
+}
+
+func TestChooseScaling(t *testing.T) {
+	member := func(index int, inCluster, healthy bool) *observedMember {
+		om := &observedMember{
+			spec:   &etcd.Spec{Index: index},
+			status: planetscalev2.EtcdMemberStatus{Healthy: corev1.ConditionFalse},
+		}
+		if inCluster {
+			om.member = &etcdclient.Member{ID: uint64(index), Name: "member"}
+		}
+		if healthy {
+			om.status.Healthy = corev1.ConditionTrue
+		}
+		return om
+	}
+
+	table := []struct {
+		name          string
+		observed      []*observedMember
+		replicas      int
+		wantScaleDown int
+		wantScaleUp   int
+	}{
+		{
+			name:     "steady",
+			observed: []*observedMember{member(1, true, true), member(2, true, true), member(3, true, true), member(4, false, false)},
+			replicas: 3,
+		},
+		{
+			name:        "scale up adds lowest missing index",
+			observed:    []*observedMember{member(1, true, true), member(2, true, true), member(3, true, true), member(4, false, false), member(5, false, false)},
+			replicas:    5,
+			wantScaleUp: 4,
+		},
+		{
+			name:     "scale up waits for joining member",
+			observed: []*observedMember{member(1, true, true), member(2, true, true), member(3, true, true), member(4, true, false), member(5, false, false)},
+			replicas: 5,
+		},
+		{
+			name:          "scale down removes highest index",
+			observed:      []*observedMember{member(1, true, true), member(2, true, true), member(3, true, true), member(4, true, true), member(5, true, true)},
+			replicas:      3,
+			wantScaleDown: 5,
+		},
+		{
+			name:          "scale down removes unhealthy member",
+			observed:      []*observedMember{member(1, true, true), member(2, true, true), member(3, true, true), member(4, true, true), member(5, true, false)},
+			replicas:      3,
+			wantScaleDown: 5,
+		},
+		{
+			name:     "scale down waits for other members",
+			observed: []*observedMember{member(1, true, false), member(2, true, true), member(3, true, true), member(4, true, true), member(5, true, true)},
+			replicas: 3,
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			var inCluster []*observedMember
+			for _, om := range test.observed {
+				if om.member != nil {
+					inCluster = append(inCluster, om)
+				}
+			}
+
+			gotDown := chooseScaleDown(inCluster, test.replicas)
+			if test.wantScaleDown == 0 {
+				assert.Nil(t, gotDown)
+			} else if assert.NotNil(t, gotDown) {
+				assert.Equal(t, test.wantScaleDown, gotDown.spec.Index)
+			}
+
+			gotUp := chooseScaleUp(test.observed, inCluster, test.replicas)
+			if test.wantScaleUp == 0 {
+				assert.Nil(t, gotUp)
+			} else if assert.NotNil(t, gotUp) {
+				assert.Equal(t, test.wantScaleUp, gotUp.spec.Index)
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/kubectl/pkg/util/podutils"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,7 +34,7 @@ import (
 	"planetscale.dev/vitess-operator/pkg/operator/results"
 )
 
-func (r *ReconcileEtcdLockserver) reconcileMembers(ctx context.Context, ls *planetscalev2.EtcdLockserver) (reconcile.Result, error) {
+func (r *ReconcileEtcdLockserver) reconcileMembers(ctx context.Context, ls *planetscalev2.EtcdLockserver, oldMembers []planetscalev2.EtcdMemberStatus) (reconcile.Result, error) {
 	resultBuilder := &results.Builder{}
 	lockserverName := ls.Name
 
@@ -41,25 +42,99 @@ func (r *ReconcileEtcdLockserver) reconcileMembers(ctx context.Context, ls *plan
 		etcd.LockserverLabel: lockserverName,
 	}
 
-	// Generate spec for each desired etcd member.
+	// Generate spec for each possible etcd member. Which of them we deploy
+	// depends on the current membership of the cluster.
 	members := memberSpecs(ls, labels)
 
-	// Generate keys (object names) for all desired members.
+	// Check member health, and change membership if necessary.
+	health, err := r.reconcileMemberHealth(ctx, ls, members, oldMembers)
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+	if health.defragging {
+		// Check on the defrag sooner than the periodic resync would.
+		resultBuilder.RequeueAfter(defragPollInterval)
+	}
+
+	// Look up existing data volumes. They tell us how each member was
+	// bootstrapped, and which members exist if we can't ask etcd.
+	// We use the same name for the Pod and the data volume PVC.
+	pvcs := make(map[int]*corev1.PersistentVolumeClaim, len(members))
+	for _, member := range members {
+		key := client.ObjectKey{Namespace: ls.Namespace, Name: etcd.PodName(lockserverName, member.Index)}
+		pvc := &corev1.PersistentVolumeClaim{}
+		switch err := r.client.Get(ctx, key, pvc); {
+		case err == nil:
+			pvcs[member.Index] = pvc
+		case apierrors.IsNotFound(err):
+		default:
+			return resultBuilder.Error(err)
+		}
+	}
+	replicas := int(*ls.Spec.Replicas)
+
+	// Generate keys (object names) for all members we should deploy.
 	// Keep a map back from generated names to the tablet specs.
 	keys := make([]client.ObjectKey, 0, len(members))
 	memberMap := make(map[client.ObjectKey]*etcd.Spec, len(members))
+	terminatingPVCs := make(map[client.ObjectKey]bool, len(members))
 	for _, member := range members {
-		// We use the same name for the Pod and the data volume PVC.
+		pvc := pvcs[member.Index]
+
+		switch {
+		case health.removed[member.Index]:
+			// Skip members that were just deleted to be replaced or removed.
+			// Replaced members will be recreated on the next pass.
+			continue
+		case ls.Spec.LocalMemberIndex != nil:
+			// We can't see the other members, so just deploy ours.
+		case health.known:
+			// Deploy exactly the members that are in the cluster.
+			if !health.active[member.Index] {
+				continue
+			}
+		case len(pvcs) == 0:
+			// The cluster hasn't been bootstrapped yet.
+			if member.Index > replicas {
+				continue
+			}
+		default:
+			// We can't ask etcd, so keep whatever members already exist.
+			if pvc == nil {
+				continue
+			}
+		}
+
 		podName := etcd.PodName(lockserverName, member.Index)
 		member.DataVolumePVCName = podName
 
 		key := client.ObjectKey{Namespace: ls.Namespace, Name: podName}
 		keys = append(keys, key)
 		memberMap[key] = member
+
+		// A member's bootstrap flags must match the cluster it joined, for as
+		// long as its data volume exists. A member that was added to the
+		// existing cluster must join it rather than bootstrap a new one.
+		// Members that are part of the initial cluster also look like they
+		// haven't started yet, so we only decide this when creating a fresh
+		// data volume, and then remember it on the PVC.
+		if pvc != nil {
+			member.JoinExisting = pvc.Annotations[etcd.JoinExistingAnnotation] == "true"
+			member.InitialClusterSize = etcd.DefaultReplicas
+			if size, err := strconv.Atoi(pvc.Annotations[etcd.InitialClusterSizeAnnotation]); err == nil && size > 0 {
+				member.InitialClusterSize = size
+			}
+			terminatingPVCs[key] = pvc.DeletionTimestamp != nil
+		} else if health.joining[member.Index] {
+			member.JoinExisting = true
+			member.InitialClusterSize = health.clusterSize
+		} else {
+			member.InitialClusterSize = replicas
+		}
 	}
 
 	// Reconcile member PVCs. Note that we use the same keys as the corresponding Pods.
-	err := r.reconciler.ReconcileObjectSet(ctx, ls, keys, labels, reconciler.Strategy{
+	err = r.reconciler.ReconcileObjectSet(ctx, ls, keys, labels, reconciler.Strategy{
 		Kind: &corev1.PersistentVolumeClaim{},
 
 		New: func(key client.ObjectKey) runtime.Object {
@@ -74,9 +149,24 @@ func (r *ReconcileEtcdLockserver) reconcileMembers(ctx context.Context, ls *plan
 		resultBuilder.Error(err)
 	}
 
+	// Hold back members that are waiting for a snapshot to be restored.
+	restoredKeys, err := r.reconcileRestore(ctx, ls, keys, memberMap)
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	// Hold back members whose old data volume is still being deleted,
+	// since a Pod can't use a PVC that's terminating.
+	podKeys := make([]client.ObjectKey, 0, len(restoredKeys))
+	for _, key := range restoredKeys {
+		if !terminatingPVCs[key] {
+			podKeys = append(podKeys, key)
+		}
+	}
+
 	// Reconcile member Pods.
 	numPodsReady := 0
-	err = r.reconciler.ReconcileObjectSet(ctx, ls, keys, labels, reconciler.Strategy{
+	err = r.reconciler.ReconcileObjectSet(ctx, ls, podKeys, labels, reconciler.Strategy{
 		Kind: &corev1.Pod{},
 
 		New: func(key client.ObjectKey) runtime.Object {
@@ -106,7 +196,7 @@ func (r *ReconcileEtcdLockserver) reconcileMembers(ctx context.Context, ls *plan
 		// We're deploying all members locally, so we can see all of them.
 		// We should be available for queries if the number of Ready replicas is
 		// enough to reach quorum.
-		ls.Status.Available = k8s.ConditionStatus(numPodsReady >= etcd.QuorumSize)
+		ls.Status.Available = k8s.ConditionStatus(numPodsReady >= etcd.QuorumSize(replicas))
 	} else {
 		// We're only deploying one member locally, so all we can report is
 		// whether our one Pod is ready.
@@ -116,10 +206,12 @@ func (r *ReconcileEtcdLockserver) reconcileMembers(ctx context.Context, ls *plan
 	return resultBuilder.Result()
 }
 
-// memberSpecs creates a list of etcd.Specs for desired members.
+// memberSpecs creates a list of etcd.Specs for all possible members, in order
+// of index. Members beyond the desired number are included because they may
+// still be in the cluster while it's scaled down.
 func memberSpecs(ls *planetscalev2.EtcdLockserver, parentLabels map[string]string) []*etcd.Spec {
-	members := make([]*etcd.Spec, 0, etcd.NumReplicas)
-	for i := 1; i <= etcd.NumReplicas; i++ {
+	members := make([]*etcd.Spec, 0, etcd.MaxReplicas)
+	for i := 1; i <= etcd.MaxReplicas; i++ {
 		// If we're only deploying one member locally, skip the others.
 		if ls.Spec.LocalMemberIndex != nil && int32(i) != *ls.Spec.LocalMemberIndex {
 			continue
@@ -162,6 +254,11 @@ func memberSpecs(ls *planetscalev2.EtcdLockserver, parentLabels map[string]strin
 			Annotations:       ls.Spec.Annotations,
 			AdvertisePeerURLs: ls.Spec.AdvertisePeerURLs,
 			Tolerations:       ls.Spec.Tolerations,
+			TLS:               ls.Spec.TLS,
+			Replicas:          int(*ls.Spec.Replicas),
+
+			AutoCompactionMode:      ls.Spec.Maintenance.AutoCompactionMode,
+			AutoCompactionRetention: ls.Spec.Maintenance.AutoCompactionRetention,
 		})
 	}
 	return members
@@ -49,15 +49,17 @@ func (r *ReconcileEtcdLockserver) reconcilePodDisruptionBudget(ctx context.Conte
 		Namespace: ls.Namespace,
 		Name:      etcd.PDBName(lockserverName),
 	}
+	// Use the desired number of members, since that's what scaling converges to.
+	replicas := int(*ls.Spec.Replicas)
 	err := r.reconciler.ReconcileObject(ctx, ls, key, labels, true, reconciler.Strategy{
 		Kind: &policyv1.PodDisruptionBudget{},
 
 		New: func(key client.ObjectKey) runtime.Object {
-			return etcd.NewPDB(key, labels)
+			return etcd.NewPDB(key, labels, replicas)
 		},
 		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
 			curObj := obj.(*policyv1.PodDisruptionBudget)
-			etcd.UpdatePDBInPlace(curObj, labels)
+			etcd.UpdatePDBInPlace(curObj, labels, replicas)
 		},
 	})
 	if err != nil {
@@ -0,0 +1,247 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdlockserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/etcd"
+	"planetscale.dev/vitess-operator/pkg/operator/etcdsnapshot"
+	"planetscale.dev/vitess-operator/pkg/operator/fork"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+)
+
+// operatorContainerNameSubstring needs to be contained within the name of
+// the main container in deploy/operator.yaml.
+const operatorContainerNameSubstring = "-operator"
+
+func (r *ReconcileEtcdLockserver) reconcileSnapshots(ctx context.Context, ls *planetscalev2.EtcdLockserver) (reconcile.Result, error) {
+	resultBuilder := &results.Builder{}
+
+	key := client.ObjectKey{
+		Namespace: ls.Namespace,
+		Name:      etcd.SnapshotCronJobName(ls.Name),
+	}
+	labels := snapshotLabels(ls, planetscalev2.EtcdSnapshotComponentName)
+	backup := ls.Spec.Backup
+	enabled := backup != nil
+
+	var spec *etcd.SnapshotSpec
+	if enabled {
+		var err error
+		spec, err = r.newSnapshotSpec(ctx, ls, labels, backup.LocationName, ls.Labels[planetscalev2.ClusterLabel], ls.Name, backup.ServiceAccountName)
+		if err != nil {
+			r.recorder.Eventf(ls, corev1.EventTypeWarning, "SnapshotConfigInvalid", "can't schedule etcd snapshots: %v", err)
+			return resultBuilder.Error(err)
+		}
+		spec.Annotations = backup.Annotations
+	}
+
+	err := r.reconciler.ReconcileObject(ctx, ls, key, labels, enabled, reconciler.Strategy{
+		Kind: &batchv1.CronJob{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return etcd.NewSnapshotCronJob(key, spec, backup)
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			newObj := obj.(*batchv1.CronJob)
+			etcd.UpdateSnapshotCronJob(newObj, spec, backup)
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			curObj := obj.(*batchv1.CronJob)
+			ls.Status.LastSnapshotTime = curObj.Status.LastSuccessfulTime
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	return resultBuilder.Result()
+}
+
+// reconcileRestore creates Jobs to restore a snapshot into the data volumes of
+// members that have never been started, if a restore was requested. It returns
+// the keys of member Pods that are ready to be reconciled.
+func (r *ReconcileEtcdLockserver) reconcileRestore(ctx context.Context, ls *planetscalev2.EtcdLockserver, keys []client.ObjectKey, memberMap map[client.ObjectKey]*etcd.Spec) ([]client.ObjectKey, error) {
+	labels := snapshotLabels(ls, planetscalev2.EtcdRestoreComponentName)
+	restore := ls.Spec.Restore
+
+	if restore == nil || ls.Status.RestoredSnapshot != "" {
+		// Nothing to restore, so clean up any Jobs left from a past restore.
+		err := r.reconciler.ReconcileObjectSet(ctx, ls, nil, labels, reconciler.Strategy{
+			Kind: &batchv1.Job{},
+		})
+		return keys, err
+	}
+
+	// Find out which members have already been started, and which ones
+	// have a restore in progress.
+	var started, pending []client.ObjectKey
+	restoreStarted := false
+	for _, key := range keys {
+		if err := r.client.Get(ctx, key, &corev1.Pod{}); err == nil {
+			started = append(started, key)
+			continue
+		} else if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		pending = append(pending, key)
+
+		jobKey := client.ObjectKey{Namespace: ls.Namespace, Name: etcd.RestoreJobName(ls.Name, memberMap[key].Index)}
+		if err := r.client.Get(ctx, jobKey, &batchv1.Job{}); err == nil {
+			restoreStarted = true
+		} else if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	if len(pending) == 0 || (len(started) > 0 && !restoreStarted) {
+		// Members have already been bootstrapped without a restore, so it's
+		// too late to restore now. Any members that haven't started yet will
+		// join the existing cluster.
+		return keys, nil
+	}
+
+	sourceLockserver := restore.SourceLockserver
+	if sourceLockserver == "" {
+		sourceLockserver = ls.Name
+	}
+	sourceCluster := restore.SourceCluster
+	if sourceCluster == "" {
+		sourceCluster = ls.Labels[planetscalev2.ClusterLabel]
+	}
+	spec, err := r.newSnapshotSpec(ctx, ls, labels, restore.LocationName, sourceCluster, sourceLockserver, restore.ServiceAccountName)
+	if err != nil {
+		r.recorder.Eventf(ls, corev1.EventTypeWarning, "RestoreConfigInvalid", "can't restore etcd snapshot: %v", err)
+		return started, err
+	}
+
+	jobKeys := make([]client.ObjectKey, 0, len(pending))
+	jobMap := make(map[client.ObjectKey]*etcd.Spec, len(pending))
+	for _, key := range pending {
+		member := memberMap[key]
+		jobKey := client.ObjectKey{Namespace: ls.Namespace, Name: etcd.RestoreJobName(ls.Name, member.Index)}
+		jobKeys = append(jobKeys, jobKey)
+		jobMap[jobKey] = member
+	}
+
+	// Members may start once the snapshot has been restored into their volumes.
+	ready := started
+	err = r.reconciler.ReconcileObjectSet(ctx, ls, jobKeys, labels, reconciler.Strategy{
+		Kind: &batchv1.Job{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return etcd.NewRestoreJob(key, jobMap[key], spec, restore.SnapshotName)
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			curObj := obj.(*batchv1.Job)
+			member := jobMap[key]
+			switch {
+			case jobConditionTrue(curObj, batchv1.JobComplete):
+				ready = append(ready, client.ObjectKey{Namespace: ls.Namespace, Name: etcd.PodName(ls.Name, member.Index)})
+			case jobConditionTrue(curObj, batchv1.JobFailed):
+				r.recorder.Eventf(ls, corev1.EventTypeWarning, "RestoreFailed", "failed to restore etcd snapshot %v into member %v; see Job %v", restore.SnapshotName, member.Index, key.Name)
+			}
+		},
+	})
+	if err != nil {
+		return ready, err
+	}
+
+	if len(ready) == len(keys) {
+		ls.Status.RestoredSnapshot = restore.SnapshotName
+		r.recorder.Eventf(ls, corev1.EventTypeNormal, "Restored", "restored etcd snapshot %v", restore.SnapshotName)
+	}
+	return ready, nil
+}
+
+// newSnapshotSpec returns the parameters for transferring snapshots of a given
+// lockserver to or from a backup location.
+func (r *ReconcileEtcdLockserver) newSnapshotSpec(ctx context.Context, ls *planetscalev2.EtcdLockserver, labels map[string]string, locationName, clusterName, lockserverName, serviceAccountName string) (*etcd.SnapshotSpec, error) {
+	location := ls.Spec.BackupLocation(locationName)
+	if location == nil {
+		return nil, fmt.Errorf("backup location %q not found", locationName)
+	}
+
+	// The snapshot file is transferred by a fork of the operator, so it can
+	// use the same backup storage implementations as Vitess.
+	podSpec, err := fork.NewPodSpec(ctx, r.client, etcdsnapshot.ForkPath)
+	if err != nil {
+		return nil, err
+	}
+	var transfer *corev1.Container
+	for i := range podSpec.Containers {
+		if strings.Contains(podSpec.Containers[i].Name, operatorContainerNameSubstring) {
+			transfer = &podSpec.Containers[i]
+			break
+		}
+	}
+	if transfer == nil {
+		return nil, fmt.Errorf("can't find operator container (name containing %q) in my own Pod", operatorContainerNameSubstring)
+	}
+
+	imagePullSecrets := append([]corev1.LocalObjectReference{}, ls.Spec.ImagePullSecrets...)
+	imagePullSecrets = append(imagePullSecrets, podSpec.ImagePullSecrets...)
+
+	return &etcd.SnapshotSpec{
+		LockserverName:     ls.Name,
+		Image:              ls.Spec.Image,
+		ImagePullPolicy:    ls.Spec.ImagePullPolicy,
+		ImagePullSecrets:   imagePullSecrets,
+		Labels:             labels,
+		TLS:                ls.Spec.TLS,
+		ServiceAccountName: serviceAccountName,
+		Location:           location,
+		ClusterName:        clusterName,
+		Dir:                etcdsnapshot.Dir(lockserverName),
+		Transfer:           transfer,
+	}, nil
+}
+
+// snapshotLabels returns the labels for snapshot or restore objects of a
+// lockserver.
+func snapshotLabels(ls *planetscalev2.EtcdLockserver, component string) map[string]string {
+	labels := map[string]string{
+		etcd.BackupLockserverLabel:   ls.Name,
+		planetscalev2.ComponentLabel: component,
+	}
+	// Only add the cluster label if the EtcdLockserver has it. This also lets
+	// snapshot Pods through the lockserver's NetworkPolicy, if any.
+	if clusterName, hasClusterLabel := ls.Labels[planetscalev2.ClusterLabel]; hasClusterLabel {
+		labels[planetscalev2.ClusterLabel] = clusterName
+	}
+	return labels
+}
+
+func jobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == conditionType && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,556 @@
+/*
+Copyright 2024 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitessbackupschedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil/policy"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	// register grpc tabletmanager client
+	_ "vitess.io/vitess/go/vt/vttablet/grpctmclient"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/names"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/toposerver"
+	"planetscale.dev/vitess-operator/pkg/operator/vitessbackup"
+	"planetscale.dev/vitess-operator/pkg/operator/vttablet"
+)
+
+const (
+	// snapshotTabletAnnotation records the alias of the tablet that a snapshot
+	// backup is taken from.
+	snapshotTabletAnnotation = "planetscale.com/snapshot-tablet"
+	// snapshotTabletTypeAnnotation records the tablet type to restore once the
+	// snapshot has been taken.
+	snapshotTabletTypeAnnotation = "planetscale.com/snapshot-tablet-type"
+	// snapshotQuiescedAnnotation is set while the tablet may be out of service
+	// for the snapshot. We set it before touching the tablet so we never forget
+	// to put the tablet back, even if we fail halfway through.
+	snapshotQuiescedAnnotation = "planetscale.com/snapshot-quiesced"
+
+	// snapshotRequeueDelay is how often to check on snapshots in progress.
+	snapshotRequeueDelay = 10 * time.Second
+)
+
+var errNoSnapshotTablet = errors.New("no tablet available to take a snapshot from")
+
+/*
+reconcileSnapshotStrategy takes scheduled VolumeSnapshot backups for one shard.
+
+Each backup is tracked by a VitessBackup object, which goes through these steps
+across several reconciles:
+
+ 1. Pick a healthy replica or rdonly tablet with a data volume.
+ 2. Take it out of service and stop replication while holding the shard
+    lock, so nothing writes to its data volume, and record its replication
+    position.
+ 3. Create a VolumeSnapshot of the tablet's data volume.
+ 4. Once the snapshot has been cut, put the tablet back into service.
+ 5. Once the snapshot is ready to use, mark the backup complete.
+
+If a backup doesn't complete within the schedule's jobTimeoutMinutes, we put
+the tablet back into service and delete the backup, which also deletes the
+VolumeSnapshot.
+*/
+func (r *ReconcileVitessBackupsSchedule) reconcileSnapshotStrategy(
+	ctx context.Context,
+	strategy planetscalev2.VitessBackupScheduleStrategy,
+	vbsc planetscalev2.VitessBackupSchedule,
+	vkr planetscalev2.VitessKeyRange,
+) (reconcile.Result, error) {
+	resultBuilder := &results.Builder{}
+
+	snapshots, err := vitessbackup.GetSnapshots(ctx, vbsc.Namespace, vbsc.Spec.Cluster, strategy.Keyspace, vkr.SafeName(),
+		func(ctx context.Context, allBackupsList *planetscalev2.VitessBackupList, listOpts *client.ListOptions) error {
+			return r.client.List(ctx, allBackupsList, listOpts)
+		},
+	)
+	if err != nil {
+		return resultBuilder.Error(err)
+	}
+
+	var inProgress, complete []*planetscalev2.VitessBackup
+	var mostRecentTime *time.Time
+	for i := range snapshots {
+		backup := &snapshots[i]
+		if backup.Labels[planetscalev2.BackupScheduleLabel] != vbsc.Name {
+			continue
+		}
+		if backup.Status.Complete {
+			complete = append(complete, backup)
+		} else {
+			inProgress = append(inProgress, backup)
+		}
+
+		scheduledTime, err := time.Parse(time.RFC3339, backup.Annotations[scheduledTimeAnnotation])
+		if err != nil {
+			log.WithError(err).Errorf("unable to parse schedule time for existing snapshot, found: %s", backup.Annotations[scheduledTimeAnnotation])
+			continue
+		}
+		if mostRecentTime == nil || mostRecentTime.Before(scheduledTime) {
+			mostRecentTime = &scheduledTime
+		}
+	}
+
+	if len(inProgress) > 0 {
+		if err := r.advanceSnapshots(ctx, vbsc, strategy, inProgress); err != nil {
+			_, _ = resultBuilder.Error(err)
+		}
+		_, _ = resultBuilder.RequeueAfter(snapshotRequeueDelay)
+	}
+
+	r.cleanupSnapshotsWithLimit(ctx, complete, vbsc.GetSnapshotRetentionCount())
+
+	effectiveSchedule, err := getEffectiveSchedule(vbsc, strategy)
+	if err != nil {
+		return resultBuilder.Error(reconcile.TerminalError(err))
+	}
+
+	missedRun, nextRun, err := getNextSchedule(effectiveSchedule, vbsc, time.Now(), mostRecentTime)
+	if err != nil {
+		log.Error(err, "unable to figure out VitessBackupSchedule schedule")
+		return resultBuilder.Error(reconcile.TerminalError(err))
+	}
+	vbsc.Status.NextScheduledTimes[strategy.Name] = &metav1.Time{Time: nextRun}
+	if missedRun.IsZero() {
+		return resultBuilder.Result()
+	}
+
+	requeueAfter := nextRun.Sub(time.Now())
+	_, _ = resultBuilder.RequeueAfter(requeueAfter)
+
+	if vbsc.Spec.StartingDeadlineSeconds != nil && missedRun.Add(time.Duration(*vbsc.Spec.StartingDeadlineSeconds)*time.Second).Before(time.Now()) {
+		log.Infof("missed starting deadline for latest run; skipping; next run is scheduled for: %s", nextRun.Format(time.RFC3339))
+		return resultBuilder.Result()
+	}
+
+	// Regardless of the concurrency policy, we never take more than one
+	// tablet per shard out of service for snapshots at the same time.
+	if len(inProgress) > 0 {
+		log.Infof("snapshot already in progress for shard %s/%s: skipping", strategy.Keyspace, strategy.Shard)
+		return resultBuilder.Result()
+	}
+
+	backup, err := r.newSnapshotBackup(ctx, vbsc, strategy, missedRun, vkr)
+	if err != nil {
+		if errors.Is(err, errNoSnapshotTablet) {
+			log.WithError(err).Infof("skipping scheduled snapshot of shard %s/%s", strategy.Keyspace, strategy.Shard)
+			r.recorder.Eventf(&vbsc, corev1.EventTypeWarning, "SnapshotSkipped", "Skipped scheduled snapshot of shard %s/%s: %v", strategy.Keyspace, strategy.Shard, err)
+			return resultBuilder.Result()
+		}
+		return resultBuilder.Error(err)
+	}
+	if err := r.client.Create(ctx, backup); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return resultBuilder.Result()
+		}
+		return resultBuilder.Error(err)
+	}
+	log.Infof("started snapshot %s of tablet %s, next snapshot scheduled in %s", backup.Name, backup.Annotations[snapshotTabletAnnotation], requeueAfter.String())
+	vbsc.Status.LastScheduledTimes[strategy.Name] = &metav1.Time{Time: missedRun}
+
+	// Start taking the snapshot right away.
+	_, _ = resultBuilder.RequeueAfter(time.Second)
+	return resultBuilder.Result()
+}
+
+// newSnapshotBackup returns a new VitessBackup to track a snapshot of a
+// tablet chosen from the given shard.
+func (r *ReconcileVitessBackupsSchedule) newSnapshotBackup(
+	ctx context.Context,
+	vbsc planetscalev2.VitessBackupSchedule,
+	strategy planetscalev2.VitessBackupScheduleStrategy,
+	scheduledTime time.Time,
+	vkr planetscalev2.VitessKeyRange,
+) (*planetscalev2.VitessBackup, error) {
+	vts, err := r.getShardFromKeyspace(ctx, vbsc.Namespace, vbsc.Spec.Cluster, strategy.Keyspace, strategy.Shard)
+	if err != nil {
+		return nil, err
+	}
+	if vts.Status.HasMaster != corev1.ConditionTrue {
+		return nil, fmt.Errorf("%w: shard has no primary", errNoSnapshotTablet)
+	}
+	alias, tabletType, ok := pickSnapshotTablet(vts.Status.Tablets)
+	if !ok {
+		return nil, errNoSnapshotTablet
+	}
+
+	labels := map[string]string{}
+	maps.Copy(labels, vbsc.Labels)
+	maps.Copy(labels, map[string]string{
+		planetscalev2.BackupScheduleLabel: vbsc.Name,
+		planetscalev2.ClusterLabel:        vbsc.Spec.Cluster,
+		planetscalev2.KeyspaceLabel:       strategy.Keyspace,
+		planetscalev2.ShardLabel:          vkr.SafeName(),
+		planetscalev2.BackupMethodLabel:   string(planetscalev2.BackupMethodSnapshot),
+	})
+	annotations := map[string]string{}
+	maps.Copy(annotations, vbsc.Spec.Annotations)
+	maps.Copy(annotations, map[string]string{
+		scheduledTimeAnnotation:      scheduledTime.Format(time.RFC3339),
+		snapshotTabletAnnotation:     alias,
+		snapshotTabletTypeAnnotation: tabletType,
+	})
+
+	return &planetscalev2.VitessBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   vbsc.Namespace,
+			Name:        names.JoinWithConstraints(names.DefaultConstraints, vbsc.Name, strategy.Keyspace, vkr.SafeName(), strconv.Itoa(int(scheduledTime.Unix()))),
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Status: planetscalev2.VitessBackupStatus{
+			StartTime: metav1.Now(),
+			Engine:    vitessbackup.SnapshotEngine,
+		},
+	}, nil
+}
+
+// pickSnapshotTablet chooses the tablet to take a snapshot from. We prefer
+// rdonly tablets, and only take a replica if there's another Ready replica
+// that can keep serving and acknowledging writes while it's out.
+func pickSnapshotTablet(tablets map[string]planetscalev2.VitessTabletStatus) (alias, tabletType string, ok bool) {
+	readyReplicas := 0
+	for _, tablet := range tablets {
+		if tablet.Type == "replica" && tablet.Ready == corev1.ConditionTrue {
+			readyReplicas++
+		}
+	}
+
+	var candidates []string
+	for tabletAlias, tablet := range tablets {
+		if tablet.Ready != corev1.ConditionTrue || tablet.DataVolumeBound != corev1.ConditionTrue {
+			continue
+		}
+		switch tablet.Type {
+		case "rdonly":
+		case "replica":
+			if readyReplicas < 2 {
+				continue
+			}
+		default:
+			continue
+		}
+		candidates = append(candidates, tabletAlias)
+	}
+	if len(candidates) == 0 {
+		return "", "", false
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := tablets[candidates[i]], tablets[candidates[j]]
+		if a.Type != b.Type {
+			return a.Type == "rdonly"
+		}
+		return candidates[i] < candidates[j]
+	})
+	return candidates[0], tablets[candidates[0]].Type, true
+}
+
+// advanceSnapshots moves each of the given snapshots in progress along to
+// their next step.
+func (r *ReconcileVitessBackupsSchedule) advanceSnapshots(
+	ctx context.Context,
+	vbsc planetscalev2.VitessBackupSchedule,
+	strategy planetscalev2.VitessBackupScheduleStrategy,
+	backups []*planetscalev2.VitessBackup,
+) error {
+	vts, err := r.getShardFromKeyspace(ctx, vbsc.Namespace, vbsc.Spec.Cluster, strategy.Keyspace, strategy.Shard)
+	if err != nil {
+		return err
+	}
+	ts, err := toposerver.Open(ctx, vts.Namespace, vts.Spec.GlobalLockserver)
+	if err != nil {
+		return fmt.Errorf("failed to connect to global lockserver: %v", err)
+	}
+	defer ts.Close()
+
+	// TabletManagerClient lets us talk directly to the management API of vttablets.
+	tmc := tmclient.NewTabletManagerClient()
+	defer tmc.Close()
+
+	s := &snapshotter{
+		r:        r,
+		vbsc:     &vbsc,
+		ts:       ts,
+		tmc:      tmc,
+		keyspace: strategy.Keyspace,
+		shard:    strategy.Shard,
+	}
+
+	var errs []error
+	for _, backup := range backups {
+		if err := s.advance(ctx, backup); err != nil {
+			r.recorder.Eventf(&vbsc, corev1.EventTypeWarning, "SnapshotFailed", "Snapshot %s: %v", backup.Name, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// snapshotter takes snapshot backups of tablets in one shard.
+type snapshotter struct {
+	r        *ReconcileVitessBackupsSchedule
+	vbsc     *planetscalev2.VitessBackupSchedule
+	ts       *toposerver.Conn
+	tmc      tmclient.TabletManagerClient
+	keyspace string
+	shard    string
+}
+
+func (s *snapshotter) advance(ctx context.Context, backup *planetscalev2.VitessBackup) error {
+	quiesced := backup.Annotations[snapshotQuiescedAnnotation] != ""
+
+	if time.Since(backup.Status.StartTime.Time) > time.Duration(s.vbsc.Spec.JobTimeoutMinutes)*time.Minute {
+		if quiesced {
+			if err := s.resumeTablet(ctx, backup); err != nil {
+				return err
+			}
+		}
+		s.r.recorder.Eventf(s.vbsc, corev1.EventTypeWarning, "SnapshotTimedOut", "Snapshot %s did not complete within %v minutes", backup.Name, s.vbsc.Spec.JobTimeoutMinutes)
+		// The VolumeSnapshot is owned by the VitessBackup, so it goes too.
+		return client.IgnoreNotFound(s.r.client.Delete(ctx, backup))
+	}
+
+	switch {
+	case backup.Status.VolumeSnapshotName == "":
+		return s.takeSnapshot(ctx, backup)
+	case quiesced:
+		volumeSnapshot, err := s.getVolumeSnapshot(ctx, backup)
+		if err != nil {
+			return err
+		}
+		// The snapshot has been cut once it has a creation time. From then
+		// on, the tablet's data volume can change without affecting it.
+		if _, found, _ := unstructured.NestedString(volumeSnapshot.Object, "status", "creationTime"); !found {
+			return nil
+		}
+		if err := s.resumeTablet(ctx, backup); err != nil {
+			return err
+		}
+		delete(backup.Annotations, snapshotQuiescedAnnotation)
+		return s.r.client.Update(ctx, backup)
+	default:
+		volumeSnapshot, err := s.getVolumeSnapshot(ctx, backup)
+		if err != nil {
+			return err
+		}
+		if message, found, _ := unstructured.NestedString(volumeSnapshot.Object, "status", "error", "message"); found {
+			return fmt.Errorf("VolumeSnapshot %s: %s", volumeSnapshot.GetName(), message)
+		}
+		if ready, _, _ := unstructured.NestedBool(volumeSnapshot.Object, "status", "readyToUse"); !ready {
+			return nil
+		}
+		backup.Status.Complete = true
+		backup.Status.FinishedTime = &metav1.Time{Time: time.Now()}
+		if err := s.r.client.Update(ctx, backup); err != nil {
+			return err
+		}
+		s.r.recorder.Eventf(s.vbsc, corev1.EventTypeNormal, "SnapshotComplete", "Snapshot %s of tablet %s is ready to use", backup.Name, backup.Annotations[snapshotTabletAnnotation])
+		return nil
+	}
+}
+
+// takeSnapshot stops the tablet and creates the VolumeSnapshot of its data volume.
+func (s *snapshotter) takeSnapshot(ctx context.Context, backup *planetscalev2.VitessBackup) error {
+	// Persist our intent to stop the tablet before doing it.
+	if backup.Annotations[snapshotQuiescedAnnotation] == "" {
+		backup.Annotations[snapshotQuiescedAnnotation] = "true"
+		if err := s.r.client.Update(ctx, backup); err != nil {
+			return err
+		}
+	}
+
+	tablet, err := s.getTablet(ctx, backup)
+	if err != nil {
+		return err
+	}
+	position, err := s.quiesceTablet(ctx, tablet)
+	if err != nil {
+		return err
+	}
+
+	volumeSnapshot := &unstructured.Unstructured{}
+	volumeSnapshot.SetGroupVersionKind(vitessbackup.VolumeSnapshotGVK)
+	volumeSnapshot.SetNamespace(backup.Namespace)
+	volumeSnapshot.SetName(backup.Name)
+	volumeSnapshot.SetLabels(backup.Labels)
+	pvcName := vttablet.PodName(s.vbsc.Spec.Cluster, *tablet.Alias)
+	if err := unstructured.SetNestedField(volumeSnapshot.Object, pvcName, "spec", "source", "persistentVolumeClaimName"); err != nil {
+		return err
+	}
+	if s.vbsc.Spec.Snapshot != nil && s.vbsc.Spec.Snapshot.VolumeSnapshotClassName != nil {
+		if err := unstructured.SetNestedField(volumeSnapshot.Object, *s.vbsc.Spec.Snapshot.VolumeSnapshotClassName, "spec", "volumeSnapshotClassName"); err != nil {
+			return err
+		}
+	}
+	if err := ctrl.SetControllerReference(backup, volumeSnapshot, s.r.scheme); err != nil {
+		return err
+	}
+	if err := s.r.client.Create(ctx, volumeSnapshot); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create VolumeSnapshot of PVC %v: %v", pvcName, err)
+	}
+
+	backup.Status.Position = position
+	backup.Status.VolumeSnapshotName = volumeSnapshot.GetName()
+	return s.r.client.Update(ctx, backup)
+}
+
+/*
+quiesceTablet takes the tablet out of service and stops replication, and
+returns the replication position its data on disk is at.
+
+We hold the shard lock while we do it, so we don't race with a reparent or
+anything else that changes the tablet's type or replication. With nothing
+writing to it, the data volume doesn't change while the snapshot is cut.
+MySQL keeps running, though, so the snapshot is crash-consistent: a tablet
+restored from it goes through InnoDB crash recovery when mysqld starts.
+*/
+func (s *snapshotter) quiesceTablet(ctx context.Context, tablet *topodatapb.Tablet) (position string, err error) {
+	ctx, unlock, err := s.ts.LockShard(ctx, s.keyspace, s.shard, "snapshotBackup")
+	if err != nil {
+		return "", fmt.Errorf("failed to lock shard %v/%v: %v", s.keyspace, s.shard, err)
+	}
+	defer unlock(&err)
+
+	// These are no-ops if we already did them in a previous attempt.
+	if err := s.tmc.ChangeType(ctx, tablet, topodatapb.TabletType_DRAINED, false); err != nil {
+		return "", fmt.Errorf("failed to take tablet %v out of service: %v", topoproto.TabletAliasString(tablet.Alias), err)
+	}
+	if err := s.tmc.StopReplication(ctx, tablet); err != nil {
+		return "", fmt.Errorf("failed to stop replication on tablet %v: %v", topoproto.TabletAliasString(tablet.Alias), err)
+	}
+	status, err := s.tmc.ReplicationStatus(ctx, tablet)
+	if err != nil {
+		return "", fmt.Errorf("failed to get replication position of tablet %v: %v", topoproto.TabletAliasString(tablet.Alias), err)
+	}
+	return status.Position, nil
+}
+
+// resumeTablet restarts replication on the tablet and puts it back into
+// service, holding the shard lock like quiesceTablet does.
+func (s *snapshotter) resumeTablet(ctx context.Context, backup *planetscalev2.VitessBackup) (err error) {
+	tablet, err := s.getTablet(ctx, backup)
+	if err != nil {
+		return err
+	}
+	tabletType, err := topoproto.ParseTabletType(backup.Annotations[snapshotTabletTypeAnnotation])
+	if err != nil {
+		return err
+	}
+
+	ctx, unlock, err := s.ts.LockShard(ctx, s.keyspace, s.shard, "snapshotBackup")
+	if err != nil {
+		return fmt.Errorf("failed to lock shard %v/%v: %v", s.keyspace, s.shard, err)
+	}
+	defer unlock(&err)
+
+	semiSync, err := s.isReplicaSemiSync(ctx, tablet)
+	if err != nil {
+		return err
+	}
+	if err := s.tmc.StartReplication(ctx, tablet, semiSync); err != nil {
+		return fmt.Errorf("failed to start replication on tablet %v: %v", topoproto.TabletAliasString(tablet.Alias), err)
+	}
+	if err := s.tmc.ChangeType(ctx, tablet, tabletType, semiSync); err != nil {
+		return fmt.Errorf("failed to put tablet %v back into service: %v", topoproto.TabletAliasString(tablet.Alias), err)
+	}
+	return nil
+}
+
+// isReplicaSemiSync returns whether the tablet should acknowledge writes
+// with semi-sync replication, according to the keyspace durability policy.
+func (s *snapshotter) isReplicaSemiSync(ctx context.Context, tablet *topodatapb.Tablet) (bool, error) {
+	keyspaceDurability, err := s.ts.GetKeyspaceDurability(ctx, s.keyspace)
+	if err != nil {
+		return false, err
+	}
+	durability, err := policy.GetDurabilityPolicy(keyspaceDurability)
+	if err != nil {
+		return false, err
+	}
+	shard, err := s.ts.GetShard(ctx, s.keyspace, s.shard)
+	if err != nil {
+		return false, err
+	}
+	if topoproto.TabletAliasIsZero(shard.PrimaryAlias) {
+		return false, nil
+	}
+	primary, err := s.ts.GetTablet(ctx, shard.PrimaryAlias)
+	if err != nil {
+		return false, err
+	}
+	return policy.IsReplicaSemiSync(durability, primary.Tablet, tablet), nil
+}
+
+func (s *snapshotter) getTablet(ctx context.Context, backup *planetscalev2.VitessBackup) (*topodatapb.Tablet, error) {
+	alias, err := topoproto.ParseTabletAlias(backup.Annotations[snapshotTabletAnnotation])
+	if err != nil {
+		return nil, err
+	}
+	tablet, err := s.ts.GetTablet(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+	return tablet.Tablet, nil
+}
+
+func (s *snapshotter) getVolumeSnapshot(ctx context.Context, backup *planetscalev2.VitessBackup) (*unstructured.Unstructured, error) {
+	volumeSnapshot := &unstructured.Unstructured{}
+	volumeSnapshot.SetGroupVersionKind(vitessbackup.VolumeSnapshotGVK)
+	key := client.ObjectKey{Namespace: backup.Namespace, Name: backup.Status.VolumeSnapshotName}
+	if err := s.r.client.Get(ctx, key, volumeSnapshot); err != nil {
+		return nil, err
+	}
+	return volumeSnapshot, nil
+}
+
+// cleanupSnapshotsWithLimit deletes the oldest complete snapshots, keeping at most "limit".
+func (r *ReconcileVitessBackupsSchedule) cleanupSnapshotsWithLimit(ctx context.Context, backups []*planetscalev2.VitessBackup, limit int32) {
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].Status.StartTime.Before(&backups[j].Status.StartTime)
+	})
+
+	for i, backup := range backups {
+		if int32(i) >= int32(len(backups))-limit {
+			break
+		}
+		if err := r.client.Delete(ctx, backup); client.IgnoreNotFound(err) != nil {
+			log.WithError(err).Errorf("unable to delete old snapshot %s", backup.Name)
+		} else {
+			log.Infof("deleted old snapshot %s", backup.Name)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitessbackupschedule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+func TestPickSnapshotTablet(t *testing.T) {
+	tablet := func(tabletType string, ready corev1.ConditionStatus) planetscalev2.VitessTabletStatus {
+		return planetscalev2.VitessTabletStatus{Type: tabletType, Ready: ready, DataVolumeBound: corev1.ConditionTrue}
+	}
+
+	tests := []struct {
+		name      string
+		tablets   map[string]planetscalev2.VitessTabletStatus
+		wantAlias string
+		wantOK    bool
+	}{
+		{
+			name: "prefers rdonly",
+			tablets: map[string]planetscalev2.VitessTabletStatus{
+				"zone1-0000000001": tablet("primary", corev1.ConditionTrue),
+				"zone1-0000000002": tablet("replica", corev1.ConditionTrue),
+				"zone1-0000000003": tablet("replica", corev1.ConditionTrue),
+				"zone1-0000000004": tablet("rdonly", corev1.ConditionTrue),
+			},
+			wantAlias: "zone1-0000000004",
+			wantOK:    true,
+		},
+		{
+			name: "lowest replica alias",
+			tablets: map[string]planetscalev2.VitessTabletStatus{
+				"zone1-0000000001": tablet("primary", corev1.ConditionTrue),
+				"zone1-0000000003": tablet("replica", corev1.ConditionTrue),
+				"zone1-0000000002": tablet("replica", corev1.ConditionTrue),
+			},
+			wantAlias: "zone1-0000000002",
+			wantOK:    true,
+		},
+		{
+			name: "keeps the only ready replica serving",
+			tablets: map[string]planetscalev2.VitessTabletStatus{
+				"zone1-0000000001": tablet("primary", corev1.ConditionTrue),
+				"zone1-0000000002": tablet("replica", corev1.ConditionTrue),
+				"zone1-0000000003": tablet("replica", corev1.ConditionFalse),
+			},
+			wantOK: false,
+		},
+		{
+			name: "needs a data volume",
+			tablets: map[string]planetscalev2.VitessTabletStatus{
+				"zone1-0000000001": tablet("primary", corev1.ConditionTrue),
+				"zone1-0000000002": {Type: "rdonly", Ready: corev1.ConditionTrue},
+			},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alias, _, ok := pickSnapshotTablet(tt.tablets)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantAlias, alias)
+		})
+	}
+}
@@ -309,6 +309,11 @@ func (r *ReconcileVitessBackupsSchedule) reconcileStrategy(
 		Start: start,
 		End:   end,
 	}
+	// Snapshot backups are taken by the controller itself rather than by Jobs.
+	if vbsc.Spec.BackupMethod == planetscalev2.BackupMethodSnapshot {
+		return r.reconcileSnapshotStrategy(ctx, strategy, vbsc, vkr)
+	}
+
 	jobs, mostRecentTime, err := r.getJobsList(ctx, req, vbsc, strategy.Keyspace, vkr.SafeName())
 	if err != nil {
 		// We had an error reading the jobs, we can requeue.
@@ -326,21 +331,9 @@ func (r *ReconcileVitessBackupsSchedule) reconcileStrategy(
 		return resultBuilder.Error(err)
 	}
 
-	// Determine the effective cron schedule string.
-	effectiveSchedule := vbsc.Spec.Schedule
-	if vbsc.Spec.Frequency != "" {
-		freq, err := time.ParseDuration(vbsc.Spec.Frequency)
-		if err != nil {
-			return resultBuilder.Error(reconcile.TerminalError(fmt.Errorf("invalid frequency %q: %v", vbsc.Spec.Frequency, err)))
-		}
-		generated, err := generateCronFromFrequency(freq, vbsc.Spec.Cluster, strategy.Keyspace, strategy.Shard, strategy.Name)
-		if err != nil {
-			return resultBuilder.Error(reconcile.TerminalError(err))
-		}
-		effectiveSchedule = generated
-		vbsc.Status.GeneratedSchedules[strategy.Name] = generated
-	} else {
-		delete(vbsc.Status.GeneratedSchedules, strategy.Name)
+	effectiveSchedule, err := getEffectiveSchedule(vbsc, strategy)
+	if err != nil {
+		return resultBuilder.Error(reconcile.TerminalError(err))
 	}
 
 	missedRun, nextRun, err := getNextSchedule(effectiveSchedule, vbsc, time.Now(), mostRecentTime)
@@ -408,6 +401,25 @@ func (r *ReconcileVitessBackupsSchedule) reconcileStrategy(
 	return resultBuilder.Result()
 }
 
+// getEffectiveSchedule returns the cron schedule string for a strategy, which
+// is generated from the frequency if one is set.
+func getEffectiveSchedule(vbsc planetscalev2.VitessBackupSchedule, strategy planetscalev2.VitessBackupScheduleStrategy) (string, error) {
+	if vbsc.Spec.Frequency == "" {
+		delete(vbsc.Status.GeneratedSchedules, strategy.Name)
+		return vbsc.Spec.Schedule, nil
+	}
+	freq, err := time.ParseDuration(vbsc.Spec.Frequency)
+	if err != nil {
+		return "", fmt.Errorf("invalid frequency %q: %v", vbsc.Spec.Frequency, err)
+	}
+	generated, err := generateCronFromFrequency(freq, vbsc.Spec.Cluster, strategy.Keyspace, strategy.Shard, strategy.Name)
+	if err != nil {
+		return "", err
+	}
+	vbsc.Status.GeneratedSchedules[strategy.Name] = generated
+	return generated, nil
+}
+
 func getNextSchedule(cronSchedule string, vbsc planetscalev2.VitessBackupSchedule, now time.Time, mostRecentTime *time.Time) (time.Time, time.Time, error) {
 	sched, err := cron.ParseStandard(cronSchedule)
 	if err != nil {
@@ -32,10 +32,18 @@ var (
 		Name:      "reconcile_count",
 		Help:      "Reconciliation attempts for a VitessCell",
 	}, []string{metrics.ClusterLabel, metrics.CellLabel, metrics.ResultLabel})
+
+	drainingVtgates = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metricsSubsystemName,
+		Name:      "draining_vtgates",
+		Help:      "Number of vtgates in a VitessCell that are shutting down and may still be draining client connections",
+	}, []string{metrics.ClusterLabel, metrics.CellLabel})
 )
 
 func init() {
 	metrics.Registry.MustRegister(
 		reconcileCount,
+		drainingVtgates,
 	)
 }
@@ -52,11 +52,11 @@ func (r *ReconcileVitessCell) reconcileLocalEtcd(ctx context.Context, vtc *plane
 		Kind: &planetscalev2.EtcdLockserver{},
 
 		New: func(key client.ObjectKey) runtime.Object {
-			return lockserver.NewEtcdLockserver(key, vtc.Spec.Lockserver.Etcd, labels, vtc.Spec.Zone)
+			return lockserver.NewEtcdLockserver(key, vtc.Spec.Lockserver.Etcd, labels, vtc.Spec.Zone, vtc.Spec.BackupLocations)
 		},
 		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
 			newObj := obj.(*planetscalev2.EtcdLockserver)
-			lockserver.UpdateEtcdLockserver(newObj, vtc.Spec.Lockserver.Etcd, labels, vtc.Spec.Zone)
+			lockserver.UpdateEtcdLockserver(newObj, vtc.Spec.Lockserver.Etcd, labels, vtc.Spec.Zone, vtc.Spec.BackupLocations)
 		},
 		Status: func(key client.ObjectKey, obj runtime.Object) {
 			curObj := obj.(*planetscalev2.EtcdLockserver)
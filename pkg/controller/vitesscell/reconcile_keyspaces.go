@@ -94,12 +94,12 @@ func (r *ReconcileVitessCell) reconcileKeyspaces(ctx context.Context, vtc *plane
 		vtc.Status.Idle = corev1.ConditionFalse
 	}
 
-	if vtc.Status.Lockserver.Etcd != nil {
-		// We know something about local etcd status.
+	if kind, available := localLockserverAvailable(&vtc.Status.Lockserver); kind != "" {
+		// We know something about local lockserver status.
 		// We can use that to avoid trying to connect when we know it won't work.
-		if vtc.Status.Lockserver.Etcd.Available != corev1.ConditionTrue {
-			r.recorder.Event(vtc, corev1.EventTypeNormal, "TopoWaiting", "waiting for local etcd to become Available")
-			// Return success. We don't need to requeue because we'll get queued any time the EtcdCluster status changes.
+		if available != corev1.ConditionTrue {
+			r.recorder.Eventf(vtc, corev1.EventTypeNormal, "TopoWaiting", "waiting for local %s to become Available", kind)
+			// Return success. We don't need to requeue because we'll get queued any time the lockserver status changes.
 			return resultBuilder.Result()
 		}
 	}
@@ -107,7 +107,7 @@ func (r *ReconcileVitessCell) reconcileKeyspaces(ctx context.Context, vtc *plane
 	// We actually know the address of the local lockserver already,
 	// but for now we'll follow the same rule as all Vitess components,
 	// which is to use the global lockserver to find the local ones.
-	ts, err := toposerver.Open(ctx, vtc.Spec.GlobalLockserver)
+	ts, err := toposerver.Open(ctx, vtc.Namespace, vtc.Spec.GlobalLockserver)
 	if err != nil {
 		r.recorder.Eventf(vtc, corev1.EventTypeWarning, "TopoConnectFailed", "failed to connect to global lockserver: %v", err)
 		return resultBuilder.RequeueAfter(topoRequeueDelay)
@@ -136,3 +136,18 @@ func (r *ReconcileVitessCell) reconcileKeyspaces(ctx context.Context, vtc *plane
 
 	return resultBuilder.Result()
 }
+
+// localLockserverAvailable returns the kind and availability of the
+// cell-local lockserver we deployed, or an empty kind if we didn't deploy one.
+func localLockserverAvailable(status *planetscalev2.LockserverStatus) (string, corev1.ConditionStatus) {
+	switch {
+	case status.Etcd != nil:
+		return "etcd", status.Etcd.Available
+	case status.Consul != nil:
+		return "Consul", status.Consul.Available
+	case status.Zk != nil:
+		return "ZooKeeper", status.Zk.Available
+	default:
+		return "", corev1.ConditionUnknown
+	}
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscell
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
+)
+
+func (r *ReconcileVitessCell) reconcileLocalServerSet(ctx context.Context, vtc *planetscalev2.VitessCell, ls *lockserver.ServerSet) error {
+	clusterName := vtc.Labels[planetscalev2.ClusterLabel]
+
+	key := client.ObjectKey{
+		Namespace: vtc.Namespace,
+		Name:      ls.LocalName(clusterName, vtc.Spec.Name),
+	}
+	labels := map[string]string{
+		planetscalev2.ClusterLabel:   clusterName,
+		planetscalev2.CellLabel:      vtc.Spec.Name,
+		planetscalev2.ComponentLabel: ls.ComponentName,
+	}
+
+	return r.reconciler.ReconcileObject(ctx, vtc, key, labels, ls.Enabled, ls.Strategy(labels, vtc.Spec.Zone, &vtc.Status.Lockserver))
+}
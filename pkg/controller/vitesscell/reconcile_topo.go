@@ -26,6 +26,7 @@ import (
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
 	"planetscale.dev/vitess-operator/pkg/operator/results"
 	"planetscale.dev/vitess-operator/pkg/operator/toposerver"
+	"planetscale.dev/vitess-operator/pkg/operator/vitesstopo"
 )
 
 const (
@@ -40,15 +41,20 @@ const (
 func (r *ReconcileVitessCell) reconcileTopology(ctx context.Context, vtc *planetscalev2.VitessCell, ts *toposerver.Conn, keyspaces []*planetscalev2.VitessKeyspace) (reconcile.Result, error) {
 	resultBuilder := &results.Builder{}
 
+	// The status still lists what was pending last time, so we only
+	// announce newly pending keyspaces. Clear it in case we don't prune now.
+	previouslyPending := vtc.Status.PendingPruneSrvKeyspaces
+	vtc.Status.PendingPruneSrvKeyspaces = nil
+
 	if *vtc.Spec.TopologyReconciliation.PruneSrvKeyspaces {
-		result, err := r.pruneSrvKeyspaces(ctx, vtc, keyspaces, ts)
+		result, err := r.pruneSrvKeyspaces(ctx, vtc, keyspaces, ts, previouslyPending)
 		resultBuilder.Merge(result, err)
 	}
 
 	return resultBuilder.Result()
 }
 
-func (r *ReconcileVitessCell) pruneSrvKeyspaces(ctx context.Context, vtc *planetscalev2.VitessCell, keyspaces []*planetscalev2.VitessKeyspace, ts *toposerver.Conn) (reconcile.Result, error) {
+func (r *ReconcileVitessCell) pruneSrvKeyspaces(ctx context.Context, vtc *planetscalev2.VitessCell, keyspaces []*planetscalev2.VitessKeyspace, ts *toposerver.Conn, previouslyPending []string) (reconcile.Result, error) {
 	resultBuilder := &results.Builder{}
 
 	// Don't hold our slot in the reconcile work queue for too long.
@@ -59,6 +65,7 @@ func (r *ReconcileVitessCell) pruneSrvKeyspaces(ctx context.Context, vtc *planet
 	srvKeyspaceNames, err := ts.GetSrvKeyspaceNames(ctx, vtc.Spec.Name)
 	if err != nil {
 		r.recorder.Eventf(vtc, corev1.EventTypeWarning, "TopoListFailed", "failed to list keyspaces in cell-local lockserver: %v", err)
+		vtc.Status.PendingPruneSrvKeyspaces = previouslyPending
 		return resultBuilder.RequeueAfter(topoRequeueDelay)
 	}
 
@@ -66,19 +73,31 @@ func (r *ReconcileVitessCell) pruneSrvKeyspaces(ctx context.Context, vtc *planet
 	for _, vtk := range keyspaces {
 		wanted[vtk.Spec.Name] = true
 	}
+	var candidates []string
 	for _, srvKeyspaceName := range srvKeyspaceNames {
-		if wanted[srvKeyspaceName] {
-			continue
+		if !wanted[srvKeyspaceName] {
+			candidates = append(candidates, srvKeyspaceName)
 		}
+	}
 
+	approval := vitesstopo.NewPruneApproval(vtc, vitesstopo.ApprovePruneSrvKeyspacesAnnotation,
+		*vtc.Spec.TopologyReconciliation.PruneSrvKeyspacesDryRun, vtc.Status.UsedPruneApprovals, previouslyPending)
+	release, pending := approval.Hold(r.recorder, vtc, "SrvKeyspace", candidates)
+
+	var pruned []string
+	for _, srvKeyspaceName := range release {
 		// It's not wanted. Try to delete it.
 		if err := ts.DeleteSrvKeyspace(ctx, vtc.Spec.Name, srvKeyspaceName); err != nil {
 			r.recorder.Eventf(vtc, corev1.EventTypeWarning, "TopoCleanupBlocked", "unable to remove keyspace %s from cell-local topology: %v", srvKeyspaceName, err)
 			resultBuilder.RequeueAfter(topoRequeueDelay)
 		} else {
 			r.recorder.Eventf(vtc, corev1.EventTypeNormal, "TopoCleanup", "removed unwanted keyspace %s from cell-local topology", srvKeyspaceName)
+			pruned = append(pruned, srvKeyspaceName)
 		}
 	}
 
+	vtc.Status.PendingPruneSrvKeyspaces = pending
+	vitesstopo.RecordUsedApprovals(&vtc.Status.UsedPruneApprovals, vitesstopo.ApprovePruneSrvKeyspacesAnnotation, approval.UsedAfter(pruned))
+
 	return resultBuilder.Result()
 }
@@ -23,13 +23,17 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
 	"planetscale.dev/vitess-operator/pkg/operator/conditions"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
+	"planetscale.dev/vitess-operator/pkg/operator/pdb"
 	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
 	"planetscale.dev/vitess-operator/pkg/operator/results"
 	"planetscale.dev/vitess-operator/pkg/operator/secrets"
@@ -61,7 +65,7 @@ func (m *secretCellsMapper) Map(ctx context.Context, secret *corev1.Secret) []re
 	var requests []reconcile.Request
 	for i := range cellList.Items {
 		cell := &cellList.Items[i]
-		if cell.Spec.Gateway.ReloadSecretNames().Has(secretName) {
+		if cell.Spec.Gateway.ReloadSecretNames().Has(secretName) || cell.Spec.Gateway.StaticAuthSecretName() == secretName || cell.Spec.InternalTLS.SecretNames().Has(secretName) || lockserver.ClientTLSSecretNames(&cell.Spec.GlobalLockserver).Has(secretName) {
 			requests = append(requests, reconcile.Request{
 				NamespacedName: apitypes.NamespacedName{
 					Namespace: cell.Namespace,
@@ -108,13 +112,29 @@ func (r *ReconcileVitessCell) reconcileVtgate(ctx context.Context, vtc *planetsc
 		resultBuilder.Error(err)
 	}
 
-	reloadSecretNames := vtc.Spec.Gateway.ReloadSecretNames()
+	// Restart vtgates when the internal or lockserver TLS certificates are
+	// rotated, too.
+	reloadSecretNames := vtc.Spec.Gateway.ReloadSecretNames().
+		Union(vtc.Spec.InternalTLS.SecretNames()).
+		Union(lockserver.ClientTLSSecretNames(&vtc.Spec.GlobalLockserver))
 	gatewaySecrets, err := secrets.GetByNames(ctx, r.client, vtc.Namespace, reloadSecretNames)
 	if err != nil {
 		// Record error and return, to avoid generating a Deployment based on incomplete information.
 		return resultBuilder.Error(err)
 	}
 
+	// Track the content of the static auth Secret in status only. vtgate
+	// reloads the file on its own, so it must not affect the Pod template.
+	if staticAuthSecretName := vtc.Spec.Gateway.StaticAuthSecretName(); staticAuthSecretName != "" {
+		staticAuthSecrets, err := secrets.GetByNames(ctx, r.client, vtc.Namespace, sets.NewString(staticAuthSecretName))
+		if err != nil {
+			// Record error but continue. The Pods will wait for the Secret to appear.
+			resultBuilder.Error(err)
+		} else {
+			vtc.Status.Gateway.StaticAuthContentHash = secrets.ContentHash(staticAuthSecrets...)
+		}
+	}
+
 	annotations := map[string]string{
 		"planetscale.com/secret-hash": secrets.ContentHash(gatewaySecrets...),
 	}
@@ -125,6 +145,15 @@ func (r *ReconcileVitessCell) reconcileVtgate(ctx context.Context, vtc *planetsc
 	update.StringMap(&extraFlags, vtc.Spec.ExtraVitessFlags)
 	update.StringMap(&extraFlags, vtc.Spec.Gateway.ExtraFlags)
 
+	// Label vtgate Pods with their zone so the per-zone Services can select
+	// them. This only goes on the Pods, since the Deployment selector is
+	// immutable.
+	extraLabels := vtc.Spec.Gateway.ExtraLabels
+	if vtgate.ZoneServicesEnabled(vtc.Spec.GatewayTopology) && vtc.Spec.Zone != "" {
+		extraLabels = map[string]string{planetscalev2.ZoneLabel: vtc.Spec.Zone}
+		update.Labels(&extraLabels, vtc.Spec.Gateway.ExtraLabels)
+	}
+
 	// Reconcile vtgate Deployment.
 	spec := &vtgate.Spec{
 		Cell:                          &vtc.Spec,
@@ -141,15 +170,25 @@ func (r *ReconcileVitessCell) reconcileVtgate(ctx context.Context, vtc *planetsc
 		InitContainers:                vtc.Spec.Gateway.InitContainers,
 		SidecarContainers:             vtc.Spec.Gateway.SidecarContainers,
 		Annotations:                   annotations,
-		ExtraLabels:                   vtc.Spec.Gateway.ExtraLabels,
+		ExtraLabels:                   extraLabels,
 		Tolerations:                   vtc.Spec.Gateway.Tolerations,
 		TopologySpreadConstraints:     vtc.Spec.Gateway.TopologySpreadConstraints,
 		Lifecycle:                     vtc.Spec.Gateway.Lifecycle,
 		TerminationGracePeriodSeconds: vtc.Spec.Gateway.TerminationGracePeriodSeconds,
 		Strategy:                      vtc.Spec.Gateway.Strategy,
+		Drain:                         vtc.Spec.Gateway.Drain,
 	}
 	key = client.ObjectKey{Namespace: vtc.Namespace, Name: vtgate.DeploymentName(clusterName, vtc.Spec.Name)}
 
+	// Don't roll out client certificate authentication without a CA. That
+	// would either leave vtgate unable to verify anyone, or silently switch it
+	// to a different authentication method. Keep the current Deployments, and
+	// with them the current authentication, until the spec is fixed.
+	keepAuth := vtgate.ClientCertAuthMissingCA(spec)
+	if keepAuth {
+		r.recorder.Event(vtc, corev1.EventTypeWarning, "InvalidAuthentication", "client certificate authentication requires secureTransport.tls.clientCACertSecret to be set; not updating vtgate until it is")
+	}
+
 	err = r.reconciler.ReconcileObject(ctx, vtc, key, labels, true, reconciler.Strategy{
 		Kind: &appsv1.Deployment{},
 
@@ -157,6 +196,9 @@ func (r *ReconcileVitessCell) reconcileVtgate(ctx context.Context, vtc *planetsc
 			return vtgate.NewDeployment(key, spec, mysqldImage)
 		},
 		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			if keepAuth {
+				return
+			}
 			newObj := obj.(*appsv1.Deployment)
 			vtgate.UpdateDeployment(newObj, spec, mysqldImage)
 		},
@@ -172,6 +214,7 @@ func (r *ReconcileVitessCell) reconcileVtgate(ctx context.Context, vtc *planetsc
 				labelSelectorExprs = append(labelSelectorExprs, key+"="+value)
 			}
 			status.LabelSelector = strings.Join(labelSelectorExprs, ",")
+			status.DrainingReplicas = vtgate.DrainingReplicas(curObj)
 			if available := conditions.Deployment(curObj.Status.Conditions, appsv1.DeploymentAvailable); available != nil {
 				status.Available = available.Status
 			}
@@ -192,6 +235,10 @@ func (r *ReconcileVitessCell) reconcileVtgate(ctx context.Context, vtc *planetsc
 			MaxReplicas: vtc.Spec.Gateway.Autoscaler.MaxReplicas,
 			Behavior:    vtc.Spec.Gateway.Autoscaler.Behavior,
 			Metrics:     vtc.Spec.Gateway.Autoscaler.Metrics,
+
+			TargetQPSPerPod:         vtc.Spec.Gateway.Autoscaler.TargetQPSPerPod,
+			TargetConnectionsPerPod: vtc.Spec.Gateway.Autoscaler.TargetConnectionsPerPod,
+			PodLabels:               labels,
 		}
 	}
 
@@ -211,5 +258,27 @@ func (r *ReconcileVitessCell) reconcileVtgate(ctx context.Context, vtc *planetsc
 		resultBuilder.Error(err)
 	}
 
+	// Reconcile vtgate PodDisruptionBudget.
+	// This keeps node drains from evicting too many vtgates in this cell at once.
+	key = client.ObjectKey{Namespace: vtc.Namespace, Name: vtgate.PDBName(clusterName, vtc.Spec.Name)}
+	wantPDB := pdb.Enabled(vtc.Spec.Gateway.PodDisruptionBudget)
+	err = r.reconciler.ReconcileObject(ctx, vtc, key, labels, wantPDB, reconciler.Strategy{
+		Kind: &policyv1.PodDisruptionBudget{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return pdb.NewPDB(key, pdb.NewSpec(labels, vtc.Spec.Gateway.PodDisruptionBudget))
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			newObj := obj.(*policyv1.PodDisruptionBudget)
+			pdb.UpdatePDB(newObj, pdb.NewSpec(labels, vtc.Spec.Gateway.PodDisruptionBudget))
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	// Reconcile any additional, named vtgate pools.
+	resultBuilder.Merge(r.reconcileVtgatePools(ctx, vtc, spec, mysqldImage))
+
 	return resultBuilder.Result()
 }
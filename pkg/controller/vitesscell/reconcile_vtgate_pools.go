@@ -0,0 +1,262 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscell
+
+import (
+	"context"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/conditions"
+	"planetscale.dev/vitess-operator/pkg/operator/pdb"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+	"planetscale.dev/vitess-operator/pkg/operator/vtgate"
+)
+
+// vtgatePool holds everything needed to reconcile the objects for one named
+// vtgate pool.
+type vtgatePool struct {
+	pool   *planetscalev2.VitessGatewayPoolSpec
+	labels map[string]string
+	spec   *vtgate.Spec
+}
+
+// reconcileVtgatePools reconciles the Deployment, Service, HorizontalPodAutoscaler
+// and PodDisruptionBudget of each named vtgate pool in the cell, and removes
+// those of pools that are no longer wanted.
+//
+// The base spec is the one used for the cell's main vtgates. Each pool starts
+// from a copy of it and applies its own overrides.
+func (r *ReconcileVitessCell) reconcileVtgatePools(ctx context.Context, vtc *planetscalev2.VitessCell, base *vtgate.Spec, mysqldImage string) (reconcile.Result, error) {
+	clusterName := vtc.Labels[planetscalev2.ClusterLabel]
+	labels := map[string]string{
+		planetscalev2.ClusterLabel:   clusterName,
+		planetscalev2.CellLabel:      vtc.Spec.Name,
+		planetscalev2.ComponentLabel: planetscalev2.VtgatePoolComponentName,
+	}
+
+	pools := vtc.Spec.Gateway.Pools
+	deploymentKeys := make([]client.ObjectKey, 0, len(pools))
+	serviceKeys := make([]client.ObjectKey, 0, len(pools))
+	var hpaKeys, pdbKeys []client.ObjectKey
+	// Deployments, HPAs and PDBs share the same name, so they share a map.
+	deploymentPools := make(map[client.ObjectKey]*vtgatePool, len(pools))
+	servicePools := make(map[client.ObjectKey]*vtgatePool, len(pools))
+
+	for i := range pools {
+		pool := &pools[i]
+		p := newVtgatePool(pool, labels, base)
+
+		key := client.ObjectKey{Namespace: vtc.Namespace, Name: vtgate.PoolDeploymentName(clusterName, vtc.Spec.Name, pool.Name)}
+		deploymentKeys = append(deploymentKeys, key)
+		deploymentPools[key] = p
+		if pool.Autoscaler != nil {
+			hpaKeys = append(hpaKeys, key)
+		}
+		if pdb.Enabled(pool.PodDisruptionBudget) {
+			pdbKeys = append(pdbKeys, key)
+		}
+
+		key = client.ObjectKey{Namespace: vtc.Namespace, Name: vtgate.PoolServiceName(clusterName, vtc.Spec.Name, pool.Name)}
+		serviceKeys = append(serviceKeys, key)
+		servicePools[key] = p
+
+		// Initialize a status entry for every desired pool, so it will be
+		// listed even if we end up not having anything to report about it.
+		vtc.Status.Gateway.Pools[pool.Name] = planetscalev2.NewVitessCellGatewayPoolStatus()
+	}
+
+	resultBuilder := results.Builder{}
+
+	// Reconcile pool Services.
+	err := r.reconciler.ReconcileObjectSet(ctx, vtc, serviceKeys, labels, reconciler.Strategy{
+		Kind: &corev1.Service{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			p := servicePools[key]
+			svc := vtgate.NewService(key, p.labels)
+			update.ServiceOverrides(svc, p.pool.Service)
+			return svc
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			p := servicePools[key]
+			svc := obj.(*corev1.Service)
+			vtgate.UpdateService(svc, p.labels)
+			update.InPlaceServiceOverrides(svc, p.pool.Service)
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			p := servicePools[key]
+			status := vtc.Status.Gateway.Pools[p.pool.Name]
+			status.ServiceName = key.Name
+			vtc.Status.Gateway.Pools[p.pool.Name] = status
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	// Reconcile pool Deployments.
+	err = r.reconciler.ReconcileObjectSet(ctx, vtc, deploymentKeys, labels, reconciler.Strategy{
+		Kind: &appsv1.Deployment{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return vtgate.NewDeployment(key, deploymentPools[key].spec, mysqldImage)
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			if vtgate.ClientCertAuthMissingCA(base) {
+				// Keep the current authentication. See reconcileVtgate.
+				return
+			}
+			p := deploymentPools[key]
+			newObj := obj.(*appsv1.Deployment)
+			spec := p.spec
+			if p.pool.Autoscaler != nil && newObj.Spec.Replicas != nil {
+				// The HPA owns the replica count of an autoscaled pool.
+				specCopy := *spec
+				specCopy.Replicas = *newObj.Spec.Replicas
+				spec = &specCopy
+			}
+			vtgate.UpdateDeployment(newObj, spec, mysqldImage)
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			p := deploymentPools[key]
+			curObj := obj.(*appsv1.Deployment)
+
+			status := vtc.Status.Gateway.Pools[p.pool.Name]
+			if replicas := curObj.Spec.Replicas; replicas != nil {
+				status.Replicas = *replicas
+			}
+			if available := conditions.Deployment(curObj.Status.Conditions, appsv1.DeploymentAvailable); available != nil {
+				status.Available = available.Status
+			}
+			status.DrainingReplicas = vtgate.DrainingReplicas(curObj)
+			vtc.Status.Gateway.Pools[p.pool.Name] = status
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	// Reconcile pool HorizontalPodAutoscalers, which scale the pool Deployments directly.
+	err = r.reconciler.ReconcileObjectSet(ctx, vtc, hpaKeys, labels, reconciler.Strategy{
+		Kind: &autoscalingv2.HorizontalPodAutoscaler{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return vtgate.NewHorizontalPodAutoscaler(key, deploymentPools[key].hpaSpec(key))
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			newObj := obj.(*autoscalingv2.HorizontalPodAutoscaler)
+			vtgate.UpdateHorizontalPodAutoscaler(newObj, deploymentPools[key].hpaSpec(key))
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	// Reconcile pool PodDisruptionBudgets.
+	err = r.reconciler.ReconcileObjectSet(ctx, vtc, pdbKeys, labels, reconciler.Strategy{
+		Kind: &policyv1.PodDisruptionBudget{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			p := deploymentPools[key]
+			return pdb.NewPDB(key, pdb.NewSpec(p.labels, p.pool.PodDisruptionBudget))
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			p := deploymentPools[key]
+			newObj := obj.(*policyv1.PodDisruptionBudget)
+			pdb.UpdatePDB(newObj, pdb.NewSpec(p.labels, p.pool.PodDisruptionBudget))
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	return resultBuilder.Result()
+}
+
+// newVtgatePool derives the vtgate spec for a named pool from the base spec
+// of the cell's main vtgates.
+func newVtgatePool(pool *planetscalev2.VitessGatewayPoolSpec, parentLabels map[string]string, base *vtgate.Spec) *vtgatePool {
+	labels := make(map[string]string, len(parentLabels)+1)
+	update.Labels(&labels, parentLabels)
+	labels[planetscalev2.GatewayPoolLabel] = pool.Name
+
+	extraFlags := make(map[string]string)
+	update.StringMap(&extraFlags, base.ExtraFlags)
+	if len(pool.Keyspaces) > 0 {
+		extraFlags["keyspaces_to_watch"] = strings.Join(pool.Keyspaces, ",")
+	}
+	if len(pool.TabletTypes) > 0 {
+		extraFlags["allowed_tablet_types"] = strings.Join(pool.TabletTypes, ",")
+	}
+	update.StringMap(&extraFlags, pool.ExtraFlags)
+
+	annotations := make(map[string]string)
+	update.Annotations(&annotations, base.Annotations)
+	update.Annotations(&annotations, pool.Annotations)
+
+	extraLabels := make(map[string]string)
+	update.Labels(&extraLabels, base.ExtraLabels)
+	update.Labels(&extraLabels, pool.ExtraLabels)
+
+	spec := *base
+	spec.Labels = labels
+	spec.Replicas = *pool.Replicas
+	spec.Resources = pool.Resources
+	spec.ExtraFlags = extraFlags
+	spec.Annotations = annotations
+	spec.ExtraLabels = extraLabels
+
+	return &vtgatePool{
+		pool:   pool,
+		labels: labels,
+		spec:   &spec,
+	}
+}
+
+// hpaSpec returns the HorizontalPodAutoscaler spec for an autoscaled pool,
+// whose Deployment has the given key.
+func (p *vtgatePool) hpaSpec(key client.ObjectKey) *vtgate.HpaSpec {
+	autoscaler := p.pool.Autoscaler
+	return &vtgate.HpaSpec{
+		Labels:      p.labels,
+		MinReplicas: autoscaler.MinReplicas,
+		MaxReplicas: autoscaler.MaxReplicas,
+		Behavior:    autoscaler.Behavior,
+		Metrics:     autoscaler.Metrics,
+
+		TargetQPSPerPod:         autoscaler.TargetQPSPerPod,
+		TargetConnectionsPerPod: autoscaler.TargetConnectionsPerPod,
+		PodLabels:               p.labels,
+
+		ScaleTargetRef: &autoscalingv2.CrossVersionObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       key.Name,
+		},
+	}
+}
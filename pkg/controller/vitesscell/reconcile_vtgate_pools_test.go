@@ -0,0 +1,223 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscell
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+	"planetscale.dev/vitess-operator/pkg/operator/vtgate"
+)
+
+func TestNewVtgatePool(t *testing.T) {
+	table := []struct {
+		name string
+		pool planetscalev2.VitessGatewayPoolSpec
+		want map[string]string
+	}{
+		{
+			name: "no overrides",
+			pool: planetscalev2.VitessGatewayPoolSpec{Name: "pool"},
+			want: map[string]string{"base_flag": "base"},
+		},
+		{
+			name: "keyspaces",
+			pool: planetscalev2.VitessGatewayPoolSpec{
+				Name:      "pool",
+				Keyspaces: []string{"commerce", "customer"},
+			},
+			want: map[string]string{
+				"base_flag":          "base",
+				"keyspaces_to_watch": "commerce,customer",
+			},
+		},
+		{
+			name: "tablet types",
+			pool: planetscalev2.VitessGatewayPoolSpec{
+				Name:        "pool",
+				TabletTypes: []string{"REPLICA", "RDONLY"},
+			},
+			want: map[string]string{
+				"base_flag":            "base",
+				"allowed_tablet_types": "REPLICA,RDONLY",
+			},
+		},
+		{
+			name: "extra flags win",
+			pool: planetscalev2.VitessGatewayPoolSpec{
+				Name:        "pool",
+				Keyspaces:   []string{"commerce"},
+				TabletTypes: []string{"RDONLY"},
+				ExtraFlags: map[string]string{
+					"base_flag":            "pool",
+					"allowed_tablet_types": "REPLICA",
+				},
+			},
+			want: map[string]string{
+				"base_flag":            "pool",
+				"keyspaces_to_watch":   "commerce",
+				"allowed_tablet_types": "REPLICA",
+			},
+		},
+	}
+
+	parentLabels := map[string]string{
+		planetscalev2.ComponentLabel: planetscalev2.VtgatePoolComponentName,
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			base := &vtgate.Spec{
+				Replicas:   2,
+				ExtraFlags: map[string]string{"base_flag": "base"},
+			}
+			test.pool.Replicas = ptr.To[int32](3)
+
+			p := newVtgatePool(&test.pool, parentLabels, base)
+
+			assert.Equal(t, test.want, p.spec.ExtraFlags)
+			assert.Equal(t, int32(3), p.spec.Replicas)
+			assert.Equal(t, "pool", p.labels[planetscalev2.GatewayPoolLabel])
+			assert.Equal(t, planetscalev2.VtgatePoolComponentName, p.labels[planetscalev2.ComponentLabel])
+
+			// The base spec is shared with the main vtgates, so it must not change.
+			assert.Equal(t, map[string]string{"base_flag": "base"}, base.ExtraFlags)
+			assert.Equal(t, int32(2), base.Replicas)
+			assert.Len(t, parentLabels, 1)
+		})
+	}
+}
+
+func TestVtgatePoolHpaSpec(t *testing.T) {
+	pool := &planetscalev2.VitessGatewayPoolSpec{
+		Name:     "analytics",
+		Replicas: ptr.To[int32](1),
+		Autoscaler: &planetscalev2.AutoscalerSpec{
+			MinReplicas:     ptr.To[int32](1),
+			MaxReplicas:     5,
+			TargetQPSPerPod: ptr.To[int32](100),
+		},
+	}
+	p := newVtgatePool(pool, map[string]string{planetscalev2.CellLabel: "zone1"}, &vtgate.Spec{})
+	key := client.ObjectKey{Namespace: "default", Name: vtgate.PoolDeploymentName("example", "zone1", "analytics")}
+
+	hpa := p.hpaSpec(key)
+
+	require.NotNil(t, hpa.ScaleTargetRef)
+	assert.Equal(t, "apps/v1", hpa.ScaleTargetRef.APIVersion)
+	assert.Equal(t, "Deployment", hpa.ScaleTargetRef.Kind)
+	assert.Equal(t, key.Name, hpa.ScaleTargetRef.Name)
+	assert.Equal(t, int32(5), hpa.MaxReplicas)
+	assert.Equal(t, ptr.To[int32](100), hpa.TargetQPSPerPod)
+	// Per-pod metrics must only select this pool's vtgates.
+	assert.Equal(t, "analytics", hpa.PodLabels[planetscalev2.GatewayPoolLabel])
+}
+
+func TestReconcileVtgatePoolsRemovesPool(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, planetscalev2.SchemeBuilder.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	require.NoError(t, autoscalingv2.AddToScheme(scheme))
+	require.NoError(t, policyv1.AddToScheme(scheme))
+
+	cluster := "example"
+	vtc := &planetscalev2.VitessCell{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "example-zone1",
+			UID:       "vtc-uid",
+			Labels: map[string]string{
+				planetscalev2.ClusterLabel: cluster,
+			},
+		},
+		Spec: planetscalev2.VitessCellSpec{
+			Name: "zone1",
+			Gateway: planetscalev2.VitessCellGatewaySpec{
+				Pools: []planetscalev2.VitessGatewayPoolSpec{
+					{Name: "analytics", Replicas: ptr.To[int32](1)},
+				},
+			},
+		},
+		Status: planetscalev2.NewVitessCellStatus(),
+	}
+
+	// The "reporting" pool was removed from the spec, but its objects remain.
+	oldLabels := map[string]string{
+		planetscalev2.ClusterLabel:     cluster,
+		planetscalev2.CellLabel:        "zone1",
+		planetscalev2.ComponentLabel:   planetscalev2.VtgatePoolComponentName,
+		planetscalev2.GatewayPoolLabel: "reporting",
+	}
+	oldService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: vtc.Namespace,
+			Name:      vtgate.PoolServiceName(cluster, "zone1", "reporting"),
+			Labels:    oldLabels,
+		},
+	}
+	oldDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: vtc.Namespace,
+			Name:      vtgate.PoolDeploymentName(cluster, "zone1", "reporting"),
+			Labels:    oldLabels,
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(oldService, oldDeployment).Build()
+	recorder := record.NewFakeRecorder(20)
+	r := &ReconcileVitessCell{
+		client:     c,
+		scheme:     scheme,
+		recorder:   recorder,
+		reconciler: reconciler.New(c, scheme, recorder),
+	}
+
+	base := &vtgate.Spec{
+		Cell:           &vtc.Spec,
+		Authentication: &vtc.Spec.Gateway.Authentication,
+	}
+	_, err := r.reconcileVtgatePools(t.Context(), vtc, base, "")
+	require.NoError(t, err)
+
+	// The removed pool's objects are gone.
+	err = c.Get(t.Context(), client.ObjectKeyFromObject(oldService), &corev1.Service{})
+	assert.True(t, apierrors.IsNotFound(err), "Service of removed pool still exists: %v", err)
+	err = c.Get(t.Context(), client.ObjectKeyFromObject(oldDeployment), &appsv1.Deployment{})
+	assert.True(t, apierrors.IsNotFound(err), "Deployment of removed pool still exists: %v", err)
+
+	// The remaining pool has its own Service, and it's the only pool in status.
+	svcKey := client.ObjectKey{Namespace: vtc.Namespace, Name: vtgate.PoolServiceName(cluster, "zone1", "analytics")}
+	require.NoError(t, c.Get(t.Context(), svcKey, &corev1.Service{}))
+	require.Len(t, vtc.Status.Gateway.Pools, 1)
+	assert.Equal(t, svcKey.Name, vtc.Status.Gateway.Pools["analytics"].ServiceName)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscell
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/certs"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+	"planetscale.dev/vitess-operator/pkg/operator/vtgate"
+)
+
+const (
+	// waitForCADelay is how long to wait before checking again for the
+	// cluster's vtgate CA, if the VitessCluster controller hasn't created it yet.
+	waitForCADelay = 10 * time.Second
+)
+
+// reconcileVtgateTLS issues and renews the vtgate serving certificate for the
+// cell, if operator-managed TLS is enabled.
+//
+// This must run before reconcileVtgate, because it fills in the cert and key
+// Secrets of the in-memory gateway spec so the vtgate Deployment mounts them.
+// Since those Secrets count towards the vtgate secret hash, renewing the
+// certificate triggers a rolling restart of vtgate.
+func (r *ReconcileVitessCell) reconcileVtgateTLS(ctx context.Context, vtc *planetscalev2.VitessCell) (reconcile.Result, error) {
+	resultBuilder := results.Builder{}
+	clusterName := vtc.Labels[planetscalev2.ClusterLabel]
+	key := client.ObjectKey{Namespace: vtc.Namespace, Name: vtgate.TLSSecretName(clusterName, vtc.Spec.Name)}
+	labels := map[string]string{
+		planetscalev2.ClusterLabel:   clusterName,
+		planetscalev2.CellLabel:      vtc.Spec.Name,
+		planetscalev2.ComponentLabel: planetscalev2.VtgateComponentName,
+	}
+
+	var managed *planetscalev2.OperatorManagedTLS
+	if secureTransport := vtc.Spec.Gateway.SecureTransport; secureTransport != nil && secureTransport.TLS != nil {
+		managed = secureTransport.TLS.OperatorManaged
+	}
+	if managed == nil {
+		// Clean up the certificate if operator-managed TLS was turned off.
+		err := r.reconciler.ReconcileObject(ctx, vtc, key, labels, false, reconciler.Strategy{
+			Kind: &corev1.Secret{},
+		})
+		return resultBuilder.Merge(reconcile.Result{}, err)
+	}
+
+	// Point vtgate at the operator-managed certificate.
+	tls := vtc.Spec.Gateway.SecureTransport.TLS
+	tls.CertSecret = &planetscalev2.SecretSource{Name: key.Name, Key: certs.CertKey}
+	tls.KeySecret = &planetscalev2.SecretSource{Name: key.Name, Key: certs.PrivateKeyKey}
+
+	caSecret := &corev1.Secret{}
+	caKey := client.ObjectKey{Namespace: vtc.Namespace, Name: vtgate.CASecretName(clusterName)}
+	if err := r.client.Get(ctx, caKey, caSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return resultBuilder.RequeueAfter(waitForCADelay)
+		}
+		return resultBuilder.Error(err)
+	}
+	ca := certs.KeyPairFromSecret(caSecret)
+	if ca == nil {
+		return resultBuilder.RequeueAfter(waitForCADelay)
+	}
+
+	dnsNames := vtgate.TLSDNSNames(vtc.Namespace, clusterName, &vtc.Spec, managed.ExtraDNSNames)
+	var issueErr error
+	ensureCert := func(secret *corev1.Secret) {
+		if !certs.NeedsRenewal(certs.KeyPairFromSecret(secret), ca.CertPEM, dnsNames, managed.RenewBefore.Duration, time.Now()) {
+			return
+		}
+		kp, err := certs.IssueCert(ca, key.Name, dnsNames, managed.Duration.Duration, time.Now())
+		if err != nil {
+			issueErr = err
+			return
+		}
+		secret.Data = kp.SecretData(ca.CertPEM)
+		r.recorder.Eventf(vtc, corev1.EventTypeNormal, "CertificateIssued", "issued vtgate serving certificate in Secret %v", key.Name)
+	}
+
+	err := r.reconciler.ReconcileObject(ctx, vtc, key, labels, true, reconciler.Strategy{
+		Kind: &corev1.Secret{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: key.Namespace,
+					Name:      key.Name,
+					Labels:    labels,
+				},
+				Type: corev1.SecretTypeTLS,
+			}
+			ensureCert(secret)
+			return secret
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			secret := obj.(*corev1.Secret)
+			update.Labels(&secret.Labels, labels)
+			ensureCert(secret)
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			secret := obj.(*corev1.Secret)
+			status := &planetscalev2.CertificateStatus{SecretName: secret.Name}
+			vtc.Status.Gateway.TLS = status
+
+			cert, err := certs.ParseCertificate(secret.Data[certs.CertKey])
+			if err != nil {
+				return
+			}
+			renewalTime := certs.RenewalTime(cert, managed.RenewBefore.Duration)
+			status.NotAfter = &metav1.Time{Time: cert.NotAfter}
+			status.RenewalTime = &metav1.Time{Time: renewalTime}
+			// Come back in time to renew the certificate.
+			resultBuilder.RequeueAfter(certs.RenewalDelay(cert, managed.RenewBefore.Duration, time.Now()))
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+	if issueErr != nil {
+		resultBuilder.Error(issueErr)
+	}
+
+	return resultBuilder.Result()
+}
@@ -19,6 +19,8 @@ package vitesscell
 import (
 	"context"
 	"flag"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -26,9 +28,11 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -40,6 +44,7 @@ import (
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
 	"planetscale.dev/vitess-operator/pkg/operator/environment"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
 	"planetscale.dev/vitess-operator/pkg/operator/metrics"
 	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
 	"planetscale.dev/vitess-operator/pkg/operator/results"
@@ -62,8 +67,12 @@ var watchResources = []client.Object{
 	&corev1.Service{},
 	&appsv1.Deployment{},
 	&autoscalingv2.HorizontalPodAutoscaler{},
+	&policyv1.PodDisruptionBudget{},
+	&corev1.Secret{},
 
 	&planetscalev2.EtcdLockserver{},
+	&planetscalev2.ConsulLockserver{},
+	&planetscalev2.ZkLockserver{},
 }
 
 // Add creates a new VitessCell Controller and adds it to the Manager. The Manager will set fields on the Controller
@@ -178,6 +187,7 @@ func (r *ReconcileVitessCell) Reconcile(cctx context.Context, request reconcile.
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			forgetDrainingVtgates(request.NamespacedName)
 			return resultBuilder.Result()
 		}
 		// Error reading the object - requeue the request.
@@ -187,6 +197,10 @@ func (r *ReconcileVitessCell) Reconcile(cctx context.Context, request reconcile.
 	// Reset status so it's all based on the latest observed state.
 	oldStatus := vtc.Status
 	vtc.Status = planetscalev2.NewVitessCellStatus()
+	// Topology reconciliation needs to know which prunes were already pending
+	// or approved. It updates these itself when it gets that far.
+	vtc.Status.PendingPruneSrvKeyspaces = oldStatus.PendingPruneSrvKeyspaces
+	vtc.Status.UsedPruneApprovals = oldStatus.UsedPruneApprovals
 
 	// Materialize all hard-coded default values into the object.
 	// TODO(enisoc): Use versioned defaults when operator-sdk supports mutating webhooks.
@@ -198,6 +212,15 @@ func (r *ReconcileVitessCell) Reconcile(cctx context.Context, request reconcile.
 		resultBuilder.Error(err)
 	}
 
+	// Create/update cell-local Consul or ZooKeeper, if requested.
+	// Each kind is reconciled even if it's not wanted, so we notice if
+	// the cell switched away from it.
+	for _, ls := range lockserver.ServerSets(&vtc.Spec.Lockserver) {
+		if err := r.reconcileLocalServerSet(ctx, vtc, ls); err != nil {
+			resultBuilder.Error(err)
+		}
+	}
+
 	// List all VitessShard in the same cluster, we do this to determine what is the image used for the mysqld container
 	// Note that this is cheap because it comes from the local cache.
 	labels := map[string]string{
@@ -218,9 +241,22 @@ func (r *ReconcileVitessCell) Reconcile(cctx context.Context, request reconcile.
 		mysqldImage = vts.Items[0].Spec.Images.Mysqld.Image()
 	}
 
+	// Issue/renew operator-managed vtgate certificates.
+	// This must come before reconcileVtgate, which mounts them.
+	tlsResult, err := r.reconcileVtgateTLS(ctx, vtc)
+	resultBuilder.Merge(tlsResult, err)
+
 	// Create/update vtgate deployments.
 	vtgateResult, err := r.reconcileVtgate(ctx, vtc, mysqldImage)
 	resultBuilder.Merge(vtgateResult, err)
+	updateDrainingVtgates(request.NamespacedName, vtc)
+	if oldHash, newHash := oldStatus.Gateway.StaticAuthContentHash, vtc.Status.Gateway.StaticAuthContentHash; oldHash != "" && newHash != "" && oldHash != newHash {
+		r.recorder.Eventf(vtc, corev1.EventTypeNormal, "StaticAuthRotated", "static auth Secret changed; vtgates will reload it within %v", vtc.Spec.Gateway.Authentication.Static.ReloadInterval.Duration)
+	}
+
+	// Create/update objects that expose vtgate outside the Kubernetes cluster.
+	vtgateExternalResult, err := r.reconcileVtgateExternal(ctx, vtc)
+	resultBuilder.Merge(vtgateExternalResult, err)
 
 	// Check which VitessKeyspaces are deployed to this cell.
 	keyspaceResult, err := r.reconcileKeyspaces(ctx, vtc)
@@ -245,3 +281,29 @@ func (r *ReconcileVitessCell) Reconcile(cctx context.Context, request reconcile.
 	reconcileCount.WithLabelValues(vtc.Labels[planetscalev2.ClusterLabel], vtc.Spec.Name, metrics.Result(err)).Inc()
 	return result, err
 }
+
+// drainingVtgatesLabels remembers the drainingVtgates label values exported
+// for each VitessCell, so they can be dropped once it's gone.
+var drainingVtgatesLabels sync.Map
+
+// updateDrainingVtgates exports the number of draining vtgates in a cell, if
+// draining is enabled, and drops the metric otherwise.
+func updateDrainingVtgates(key types.NamespacedName, vtc *planetscalev2.VitessCell) {
+	labels := []string{vtc.Labels[planetscalev2.ClusterLabel], vtc.Spec.Name}
+	if old, ok := drainingVtgatesLabels.Load(key); ok && !slices.Equal(old.([]string), labels) {
+		drainingVtgates.DeleteLabelValues(old.([]string)...)
+	}
+	if vtc.Spec.Gateway.Drain == nil {
+		forgetDrainingVtgates(key)
+		return
+	}
+	drainingVtgates.WithLabelValues(labels...).Set(float64(vtc.Status.Gateway.TotalDrainingReplicas()))
+	drainingVtgatesLabels.Store(key, labels)
+}
+
+// forgetDrainingVtgates drops the drainingVtgates metric of a VitessCell.
+func forgetDrainingVtgates(key types.NamespacedName) {
+	if old, ok := drainingVtgatesLabels.LoadAndDelete(key); ok {
+		drainingVtgates.DeleteLabelValues(old.([]string)...)
+	}
+}
@@ -17,14 +17,20 @@ limitations under the License.
 package vitesscluster
 
 import (
+	"slices"
+
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
+// defaultCellsAlias is the alias containing all cells that the operator
+// registers if the spec doesn't define any cells aliases.
+const defaultCellsAlias = "planetscale_operator_default"
+
 func buildCellsAliases(desiredCells map[string]*planetscalev2.LockserverSpec) map[string]*topodatapb.CellsAlias {
 	cellsAlias := make(map[string]*topodatapb.CellsAlias)
 	for name := range desiredCells {
-		alias := "planetscale_operator_default"
+		alias := defaultCellsAlias
 		if _, ok := cellsAlias[alias]; ok {
 			cellsAlias[alias].Cells = append(cellsAlias[alias].Cells, name)
 		} else {
@@ -38,3 +44,14 @@ func buildCellsAliases(desiredCells map[string]*planetscalev2.LockserverSpec) ma
 	}
 	return cellsAlias
 }
+
+// buildCustomCellsAliases returns the cells aliases defined in the spec.
+func buildCustomCellsAliases(aliases map[string][]string) map[string]*topodatapb.CellsAlias {
+	cellsAliases := make(map[string]*topodatapb.CellsAlias, len(aliases))
+	for alias, cells := range aliases {
+		cellsAliases[alias] = &topodatapb.CellsAlias{
+			Cells: slices.Clone(cells),
+		}
+	}
+	return cellsAliases
+}
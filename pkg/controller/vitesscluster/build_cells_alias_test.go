@@ -73,3 +73,20 @@ func TestBuildCellAliases(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildCustomCellsAliases(t *testing.T) {
+	spec := map[string][]string{
+		"useast1":    {"awsuseast1a", "awsuseast1b"},
+		"uscentral1": {"gcpuscentral1a"},
+	}
+
+	results := buildCustomCellsAliases(spec)
+	assert.Len(t, results, 2)
+	assert.Equal(t, []string{"awsuseast1a", "awsuseast1b"}, results["useast1"].Cells)
+	assert.Equal(t, []string{"gcpuscentral1a"}, results["uscentral1"].Cells)
+	assert.NotContains(t, results, defaultCellsAlias)
+
+	// Changing the result must not change the spec.
+	results["useast1"].Cells[0] = "changed"
+	assert.Equal(t, "awsuseast1a", spec["useast1"][0])
+}
@@ -24,6 +24,12 @@ import (
 
 const (
 	metricsSubsystemName = "cluster"
+
+	// recordTypeLabel is the type of topology record, such as "tablet".
+	recordTypeLabel = "record_type"
+	// driftLabel is "unknown" for records only in topology, or "missing"
+	// for records only in the spec.
+	driftLabel = "drift"
 )
 
 var (
@@ -33,10 +39,26 @@ var (
 		Name:      "reconcile_count",
 		Help:      "Reconciliation attempts for a VitessCluster",
 	}, []string{metrics.ClusterLabel, metrics.ResultLabel})
+
+	topologyDriftAuditCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metricsSubsystemName,
+		Name:      "topology_drift_audit_count",
+		Help:      "Comparisons of Vitess topology with a VitessCluster",
+	}, []string{metrics.ClusterLabel, metrics.ResultLabel})
+
+	topologyDriftRecords = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: metricsSubsystemName,
+		Name:      "topology_drift_records",
+		Help:      "Number of topology records that differ from a VitessCluster as of the last audit",
+	}, []string{metrics.ClusterLabel, recordTypeLabel, driftLabel})
 )
 
 func init() {
 	metrics.Registry.MustRegister(
 		reconcileCount,
+		topologyDriftAuditCount,
+		topologyDriftRecords,
 	)
 }
@@ -25,7 +25,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
-	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
 	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
 	"planetscale.dev/vitess-operator/pkg/operator/rollout"
 	"planetscale.dev/vitess-operator/pkg/operator/update"
@@ -126,6 +125,11 @@ func newVitessCell(key client.ObjectKey, vt *planetscalev2.VitessCluster, parent
 	}
 	labels[planetscalev2.CellLabel] = cell.Name
 
+	var backupLocations []planetscalev2.VitessBackupLocation
+	if vt.Spec.Backup != nil {
+		backupLocations = vt.Spec.Backup.Locations
+	}
+
 	return &planetscalev2.VitessCell{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: key.Namespace,
@@ -134,13 +138,16 @@ func newVitessCell(key client.ObjectKey, vt *planetscalev2.VitessCluster, parent
 		},
 		Spec: planetscalev2.VitessCellSpec{
 			VitessCellTemplate:     *template,
-			GlobalLockserver:       *lockserver.GlobalConnectionParams(&vt.Spec.GlobalLockserver, vt.Namespace, vt.Name),
+			GlobalLockserver:       *globalLockserverParams(vt, planetscalev2.LockserverMigrationSwitchingCells),
 			AllCells:               allCells,
 			Images:                 images,
 			ImagePullPolicies:      vt.Spec.ImagePullPolicies,
 			ImagePullSecrets:       vt.Spec.ImagePullSecrets,
 			ExtraVitessFlags:       vt.Spec.ExtraVitessFlags,
+			InternalTLS:            internalTLSSpec(vt),
 			TopologyReconciliation: vt.Spec.TopologyReconciliation,
+			GatewayTopology:        vt.Spec.GatewayTopology,
+			BackupLocations:        backupLocations,
 		},
 	}
 }
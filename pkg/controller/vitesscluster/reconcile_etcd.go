@@ -38,6 +38,11 @@ func (r *ReconcileVitessCluster) reconcileGlobalEtcd(ctx context.Context, vt *pl
 	}
 	enabled := vt.Spec.GlobalLockserver.Etcd != nil
 
+	var backupLocations []planetscalev2.VitessBackupLocation
+	if vt.Spec.Backup != nil {
+		backupLocations = vt.Spec.Backup.Locations
+	}
+
 	// Initialize status only if etcd is enabled.
 	if enabled {
 		vt.Status.GlobalLockserver.Etcd = planetscalev2.NewEtcdLockserverStatus()
@@ -47,11 +52,11 @@ func (r *ReconcileVitessCluster) reconcileGlobalEtcd(ctx context.Context, vt *pl
 		Kind: &planetscalev2.EtcdLockserver{},
 
 		New: func(key client.ObjectKey) runtime.Object {
-			return lockserver.NewEtcdLockserver(key, vt.Spec.GlobalLockserver.Etcd, labels, "")
+			return lockserver.NewEtcdLockserver(key, vt.Spec.GlobalLockserver.Etcd, labels, "", backupLocations)
 		},
 		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
 			newObj := obj.(*planetscalev2.EtcdLockserver)
-			lockserver.UpdateEtcdLockserver(newObj, vt.Spec.GlobalLockserver.Etcd, labels, "")
+			lockserver.UpdateEtcdLockserver(newObj, vt.Spec.GlobalLockserver.Etcd, labels, "", backupLocations)
 		},
 		Status: func(key client.ObjectKey, obj runtime.Object) {
 			curObj := obj.(*planetscalev2.EtcdLockserver)
@@ -0,0 +1,124 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscluster
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/certs"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+	"planetscale.dev/vitess-operator/pkg/operator/vtgate"
+)
+
+// reconcileGatewayCA maintains the CA that VitessCells use to sign
+// operator-managed vtgate serving certificates. The CA only exists while at
+// least one cell uses operator-managed TLS.
+func (r *ReconcileVitessCluster) reconcileGatewayCA(ctx context.Context, vt *planetscalev2.VitessCluster) (reconcile.Result, error) {
+	resultBuilder := results.Builder{}
+	key := client.ObjectKey{Namespace: vt.Namespace, Name: vtgate.CASecretName(vt.Name)}
+	labels := map[string]string{
+		planetscalev2.ClusterLabel:   vt.Name,
+		planetscalev2.ComponentLabel: planetscalev2.VtgateComponentName,
+	}
+
+	_, status, err := r.reconcileCA(ctx, vt, key, labels, gatewayManagedTLS(vt))
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+	vt.Status.GatewayCA = status
+
+	return resultBuilder.Result()
+}
+
+// reconcileCA maintains a Secret holding a CA generated by the operator.
+// It returns the CA key pair and its status, or nil if the CA doesn't exist.
+func (r *ReconcileVitessCluster) reconcileCA(ctx context.Context, vt *planetscalev2.VitessCluster, key client.ObjectKey, labels map[string]string, wanted bool) (*certs.KeyPair, *planetscalev2.CertificateStatus, error) {
+	var ca *certs.KeyPair
+	var status *planetscalev2.CertificateStatus
+
+	// Generating a CA can only fail if the system's source of randomness is
+	// broken, but if it does, we leave the Secret empty and try again later.
+	var caErr error
+	ensureCA := func(secret *corev1.Secret) {
+		if certs.KeyPairFromSecret(secret) != nil {
+			if _, err := certs.ParseCertificate(secret.Data[certs.CertKey]); err == nil {
+				return
+			}
+		}
+		newCA, err := certs.NewCA(key.Name, time.Now())
+		if err != nil {
+			caErr = err
+			return
+		}
+		secret.Data = newCA.SecretData(newCA.CertPEM)
+	}
+
+	err := r.reconciler.ReconcileObject(ctx, vt, key, labels, wanted, reconciler.Strategy{
+		Kind: &corev1.Secret{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: key.Namespace,
+					Name:      key.Name,
+					Labels:    labels,
+				},
+				Type: corev1.SecretTypeTLS,
+			}
+			ensureCA(secret)
+			return secret
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			secret := obj.(*corev1.Secret)
+			update.Labels(&secret.Labels, labels)
+			ensureCA(secret)
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			secret := obj.(*corev1.Secret)
+			status = &planetscalev2.CertificateStatus{SecretName: secret.Name}
+			if cert, err := certs.ParseCertificate(secret.Data[certs.CertKey]); err == nil {
+				ca = certs.KeyPairFromSecret(secret)
+				status.NotAfter = &metav1.Time{Time: cert.NotAfter}
+			}
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return ca, status, caErr
+}
+
+// gatewayManagedTLS returns whether any cell uses operator-managed vtgate TLS.
+func gatewayManagedTLS(vt *planetscalev2.VitessCluster) bool {
+	for i := range vt.Spec.Cells {
+		secureTransport := vt.Spec.Cells[i].Gateway.SecureTransport
+		if secureTransport != nil && secureTransport.TLS != nil && secureTransport.TLS.OperatorManaged != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscluster
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/certs"
+	"planetscale.dev/vitess-operator/pkg/operator/internaltls"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+)
+
+type secretClustersMapper struct {
+	client client.Client
+}
+
+// Map maps a Secret to a list of requests for VitessClusters
+// that use it for internal or lockserver TLS.
+func (m *secretClustersMapper) Map(ctx context.Context, secret *corev1.Secret) []reconcile.Request {
+	clusterList := &planetscalev2.VitessClusterList{}
+	opts := &client.ListOptions{
+		Namespace: secret.Namespace,
+	}
+	if err := m.client.List(ctx, clusterList, opts); err != nil {
+		log.WithError(err).Error("failed to list VitessClusters; unable to map Secrets to matching VitessClusters")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range clusterList.Items {
+		vt := &clusterList.Items[i]
+		if internalTLSSpec(vt).SecretNames().Has(secret.Name) || lockserver.ClientTLSSecretNames(lockserver.GlobalConnectionParams(&vt.Spec.GlobalLockserver, vt.Namespace, vt.Name)).Has(secret.Name) || lockserver.ClientTLSSecretNames(vt.Spec.GlobalLockserver.MigrateFrom).Has(secret.Name) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKey{
+					Namespace: vt.Namespace,
+					Name:      vt.Name,
+				},
+			})
+		}
+	}
+	return requests
+}
+
+// reconcileInternalTLS maintains the CA and certificate used for gRPC between
+// Vitess components, if they're managed by the operator.
+//
+// The certificate is renewed in place. Every component mounts it, and hashes
+// its contents into the Pod template, so renewal triggers a rolling restart.
+func (r *ReconcileVitessCluster) reconcileInternalTLS(ctx context.Context, vt *planetscalev2.VitessCluster) (reconcile.Result, error) {
+	resultBuilder := results.Builder{}
+	labels := map[string]string{
+		planetscalev2.ClusterLabel: vt.Name,
+	}
+
+	var managed *planetscalev2.OperatorManagedTLS
+	if vt.Spec.InternalTLS != nil {
+		managed = vt.Spec.InternalTLS.OperatorManaged
+	}
+
+	caKey := client.ObjectKey{Namespace: vt.Namespace, Name: internaltls.CASecretName(vt.Name)}
+	ca, _, err := r.reconcileCA(ctx, vt, caKey, labels, managed != nil)
+	if err != nil {
+		return resultBuilder.Error(err)
+	}
+
+	key := client.ObjectKey{Namespace: vt.Namespace, Name: internaltls.SecretName(vt.Name)}
+	if managed == nil {
+		// Clean up the certificate if operator-managed TLS was turned off.
+		err := r.reconciler.ReconcileObject(ctx, vt, key, labels, false, reconciler.Strategy{
+			Kind: &corev1.Secret{},
+		})
+		return resultBuilder.Merge(reconcile.Result{}, err)
+	}
+	if ca == nil {
+		// We'll try again once the CA is readable.
+		return resultBuilder.RequeueAfter(time.Minute)
+	}
+
+	serverName := vt.Spec.InternalTLS.ServerName
+	dnsNames := append([]string{serverName}, managed.ExtraDNSNames...)
+	var issueErr error
+	ensureCert := func(secret *corev1.Secret) {
+		if !certs.NeedsRenewal(certs.KeyPairFromSecret(secret), ca.CertPEM, dnsNames, managed.RenewBefore.Duration, time.Now()) {
+			return
+		}
+		kp, err := certs.IssueCert(ca, serverName, dnsNames, managed.Duration.Duration, time.Now())
+		if err != nil {
+			issueErr = err
+			return
+		}
+		secret.Data = kp.SecretData(ca.CertPEM)
+		r.recorder.Eventf(vt, corev1.EventTypeNormal, "CertificateIssued", "issued internal TLS certificate in Secret %v", key.Name)
+	}
+
+	err = r.reconciler.ReconcileObject(ctx, vt, key, labels, true, reconciler.Strategy{
+		Kind: &corev1.Secret{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: key.Namespace,
+					Name:      key.Name,
+					Labels:    labels,
+				},
+				Type: corev1.SecretTypeTLS,
+			}
+			ensureCert(secret)
+			return secret
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			secret := obj.(*corev1.Secret)
+			update.Labels(&secret.Labels, labels)
+			ensureCert(secret)
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			secret := obj.(*corev1.Secret)
+			status := &planetscalev2.CertificateStatus{SecretName: secret.Name}
+			vt.Status.InternalTLS = status
+
+			cert, err := certs.ParseCertificate(secret.Data[certs.CertKey])
+			if err != nil {
+				return
+			}
+			renewalTime := certs.RenewalTime(cert, managed.RenewBefore.Duration)
+			status.NotAfter = &metav1.Time{Time: cert.NotAfter}
+			status.RenewalTime = &metav1.Time{Time: renewalTime}
+			// Come back in time to renew the certificate.
+			resultBuilder.RequeueAfter(certs.RenewalDelay(cert, managed.RenewBefore.Duration, time.Now()))
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+	if issueErr != nil {
+		resultBuilder.Error(issueErr)
+	}
+
+	return resultBuilder.Result()
+}
+
+// internalTLSSpec returns the internal TLS settings to pass down to the
+// components of the cluster. If the certificate is managed by the operator,
+// the Secret fields are filled in to point to it.
+func internalTLSSpec(vt *planetscalev2.VitessCluster) *planetscalev2.VitessInternalTLSSpec {
+	if vt.Spec.InternalTLS == nil {
+		return nil
+	}
+	tls := vt.Spec.InternalTLS.DeepCopy()
+	if tls.OperatorManaged != nil {
+		secretName := internaltls.SecretName(vt.Name)
+		tls.CertSecret = &planetscalev2.SecretSource{Name: secretName, Key: certs.CertKey}
+		tls.KeySecret = &planetscalev2.SecretSource{Name: secretName, Key: certs.PrivateKeyKey}
+		tls.CACertSecret = &planetscalev2.SecretSource{Name: secretName, Key: certs.CACertKey}
+	}
+	return tls
+}
@@ -26,7 +26,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
-	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
 	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
 	"planetscale.dev/vitess-operator/pkg/operator/rollout"
 	"planetscale.dev/vitess-operator/pkg/operator/update"
@@ -109,6 +108,7 @@ func (r *ReconcileVitessCluster) reconcileKeyspaces(ctx context.Context, vt *pla
 				status.Cells = append(status.Cells, cell)
 			}
 			sort.Strings(status.Cells)
+			status.PendingPruneShards = curObj.Status.PendingPruneShards
 
 			vt.Status.Keyspaces[curObj.Spec.Name] = status
 		},
@@ -174,17 +174,20 @@ func newVitessKeyspace(key client.ObjectKey, vt *planetscalev2.VitessCluster, pa
 			Annotations: keyspace.Annotations,
 		},
 		Spec: planetscalev2.VitessKeyspaceSpec{
-			VitessKeyspaceTemplate: *template,
-			GlobalLockserver:       *lockserver.GlobalConnectionParams(&vt.Spec.GlobalLockserver, vt.Namespace, vt.Name),
-			Images:                 images,
-			ImagePullPolicies:      vt.Spec.ImagePullPolicies,
-			ImagePullSecrets:       vt.Spec.ImagePullSecrets,
-			ZoneMap:                vt.Spec.ZoneMap(),
-			BackupLocations:        backupLocations,
-			BackupEngine:           backupEngine,
-			ExtraVitessFlags:       vt.Spec.ExtraVitessFlags,
-			TopologyReconciliation: vt.Spec.TopologyReconciliation,
-			UpdateStrategy:         vt.Spec.UpdateStrategy,
+			VitessKeyspaceTemplate:  *template,
+			GlobalLockserver:        *globalLockserverParams(vt, planetscalev2.LockserverMigrationSwitchingWriters),
+			Images:                  images,
+			ImagePullPolicies:       vt.Spec.ImagePullPolicies,
+			ImagePullSecrets:        vt.Spec.ImagePullSecrets,
+			ZoneMap:                 vt.Spec.ZoneMap(),
+			BackupLocations:         backupLocations,
+			BackupEngine:            backupEngine,
+			ExtraVitessFlags:        vt.Spec.ExtraVitessFlags,
+			InternalTLS:             internalTLSSpec(vt),
+			NetworkPolicies:         vt.Spec.NetworkPolicies,
+			PauseVitessOrchestrator: vtorcPausedForMigration(vt),
+			TopologyReconciliation:  vt.Spec.TopologyReconciliation,
+			UpdateStrategy:          vt.Spec.UpdateStrategy,
 		},
 	}
 }
@@ -212,6 +215,13 @@ func updateVitessKeyspaceInPlace(key client.ObjectKey, vtk *planetscalev2.Vitess
 	// Switching update strategies should always take effect immediately.
 	vtk.Spec.UpdateStrategy = newKeyspace.Spec.UpdateStrategy
 
+	// NetworkPolicies don't touch any Pods, so they're always safe to update.
+	vtk.Spec.NetworkPolicies = newKeyspace.Spec.NetworkPolicies
+
+	// vtorc must stop as soon as a lockserver migration needs it to, and come
+	// back as soon as it's done.
+	vtk.Spec.PauseVitessOrchestrator = newKeyspace.Spec.PauseVitessOrchestrator
+
 	// Update disk size immediately if specified to.
 	if *vtk.Spec.UpdateStrategy.Type == planetscalev2.ExternalVitessClusterUpdateStrategyType {
 		if vtk.Spec.UpdateStrategy.External.ResourceChangesAllowed(corev1.ResourceStorage) {
@@ -0,0 +1,286 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscluster
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/toposerver"
+	"planetscale.dev/vitess-operator/pkg/operator/vitesstopo"
+)
+
+const (
+	// migrationCopyTimeout is how long we allow for copying or comparing
+	// topology data during a lockserver migration.
+	migrationCopyTimeout = 5 * time.Minute
+	// migrationPollInterval is how often to check whether components have
+	// moved to the new lockserver.
+	migrationPollInterval = 30 * time.Second
+)
+
+// openTopo connects to a lockserver. Tests replace it.
+var openTopo = toposerver.Open
+
+// migrationComponents lists the ComponentLabel values of the Pods that move
+// to the new global lockserver in each phase of a migration.
+var migrationComponents = map[planetscalev2.LockserverMigrationPhase][]string{
+	// Everything that writes to topology moves at once, so nothing keeps
+	// writing to the old lockserver after the others have left it.
+	planetscalev2.LockserverMigrationSwitchingWriters: {
+		planetscalev2.VtctldComponentName,
+		planetscalev2.VttabletComponentName,
+		planetscalev2.VtorcComponentName,
+		planetscalev2.VtbackupComponentName,
+	},
+	planetscalev2.LockserverMigrationSwitchingCells: {
+		planetscalev2.VtgateComponentName,
+		planetscalev2.VtgatePoolComponentName,
+		planetscalev2.VtgateZoneComponentName,
+	},
+}
+
+// globalLockserverParams returns the global lockserver params to give to
+// components that move to a new global lockserver in the given phase of a
+// migration.
+func globalLockserverParams(vt *planetscalev2.VitessCluster, switchPhase planetscalev2.LockserverMigrationPhase) *planetscalev2.VitessLockserverParams {
+	from := vt.Spec.GlobalLockserver.MigrateFrom
+	if from == nil || vt.Status.GlobalLockserverMigration.Reached(switchPhase) {
+		return lockserver.GlobalConnectionParams(&vt.Spec.GlobalLockserver, vt.Namespace, vt.Name)
+	}
+	return from
+}
+
+// lockserverMigrationInProgress returns whether some components might still
+// use the old global lockserver.
+func lockserverMigrationInProgress(vt *planetscalev2.VitessCluster) bool {
+	return vt.Spec.GlobalLockserver.MigrateFrom != nil && !vt.Status.GlobalLockserverMigration.Reached(planetscalev2.LockserverMigrationComplete)
+}
+
+// vtorcPausedForMigration returns whether vtorc must be stopped because a
+// global lockserver migration hasn't moved every topology writer yet.
+// vtorc reparents on its own, so a vtorc on either side of a half-done move
+// could make changes that the other side never sees.
+func vtorcPausedForMigration(vt *planetscalev2.VitessCluster) bool {
+	return lockserverMigrationInProgress(vt) && !vt.Status.GlobalLockserverMigration.Reached(planetscalev2.LockserverMigrationSwitchingCells)
+}
+
+func (r *ReconcileVitessCluster) reconcileGlobalLockserverMigration(ctx context.Context, vt *planetscalev2.VitessCluster) (reconcile.Result, error) {
+	resultBuilder := &results.Builder{}
+
+	from := vt.Spec.GlobalLockserver.MigrateFrom
+	if from == nil {
+		vt.Status.GlobalLockserverMigration = nil
+		return resultBuilder.Result()
+	}
+	to := lockserver.GlobalConnectionParams(&vt.Spec.GlobalLockserver, vt.Namespace, vt.Name)
+	if to == nil {
+		// This is an invalid config. There's no reason to request a retry. Just wait for the next mutation to trigger us.
+		r.recorder.Event(vt, corev1.EventTypeWarning, "TopoInvalid", "no global lockserver is defined to migrate to")
+		return resultBuilder.Result()
+	}
+
+	status := vt.Status.GlobalLockserverMigration
+	if status == nil || !apiequality.Semantic.DeepEqual(&status.From, from) {
+		// This is a new migration, so start from the beginning.
+		status = &planetscalev2.LockserverMigrationStatus{
+			From:  *from,
+			Phase: planetscalev2.LockserverMigrationCopying,
+		}
+		vt.Status.GlobalLockserverMigration = status
+	}
+	if from.Implementation == to.Implementation && from.Address == to.Address && from.RootPath == to.RootPath {
+		// There's nothing to move.
+		status.Phase = planetscalev2.LockserverMigrationComplete
+		status.Message = ""
+		return resultBuilder.Result()
+	}
+	if status.Phase == planetscalev2.LockserverMigrationComplete {
+		return resultBuilder.Result()
+	}
+
+	if status.Phase == planetscalev2.LockserverMigrationCopying {
+		// The old lockserver has to hold still while we copy it, so wait
+		// until vtorc can no longer reparent anything.
+		remaining, err := r.countPodsUsingLockserver(ctx, vt, nil, []string{planetscalev2.VtorcComponentName})
+		if err != nil {
+			return resultBuilder.Error(err)
+		}
+		if remaining > 0 {
+			status.Message = fmt.Sprintf("waiting for %d vtorc Pods to stop before copying topology", remaining)
+			return resultBuilder.RequeueAfter(migrationPollInterval)
+		}
+	}
+
+	if components, ok := migrationComponents[status.Phase]; ok {
+		remaining, err := r.countPodsUsingLockserver(ctx, vt, from, components)
+		if err != nil {
+			return resultBuilder.Error(err)
+		}
+		if remaining > 0 {
+			status.Message = fmt.Sprintf("waiting for %d Pods (%s) to move to the new global lockserver", remaining, strings.Join(components, ", "))
+			return resultBuilder.RequeueAfter(migrationPollInterval)
+		}
+	}
+
+	fromTS, err := openTopo(ctx, vt.Namespace, *from)
+	if err != nil {
+		status.Message = fmt.Sprintf("failed to connect to old global lockserver: %v", err)
+		return resultBuilder.RequeueAfter(topoRequeueDelay)
+	}
+	defer fromTS.Close()
+	toTS, err := openTopo(ctx, vt.Namespace, *to)
+	if err != nil {
+		status.Message = fmt.Sprintf("failed to connect to new global lockserver: %v", err)
+		// Give the new lockserver some time to come up.
+		return resultBuilder.RequeueAfter(topoRequeueDelay)
+	}
+	defer toTS.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, migrationCopyTimeout)
+	defer cancel()
+
+	switch status.Phase {
+	case planetscalev2.LockserverMigrationCopying, planetscalev2.LockserverMigrationVerifying:
+		if status.Phase == planetscalev2.LockserverMigrationCopying {
+			// Tablets are copied into the cells that the new lockserver knows
+			// about, so register cells there first.
+			if _, err := r.reconcileCellTopology(ctx, vt, toTS.Server, to.Implementation); err != nil {
+				status.Message = fmt.Sprintf("failed to register cells in new global lockserver: %v", err)
+				return resultBuilder.RequeueAfter(topoRequeueDelay)
+			}
+			if err := vitesstopo.CopyTopology(ctx, fromTS.Server, toTS.Server); err != nil {
+				r.recorder.Eventf(vt, corev1.EventTypeWarning, "TopoMigrationFailed", "failed to copy topology to new global lockserver: %v", err)
+				status.Message = fmt.Sprintf("failed to copy topology: %v", err)
+				return resultBuilder.RequeueAfter(topoRequeueDelay)
+			}
+			now := metav1.Now()
+			status.CopyTime = &now
+			status.Phase = planetscalev2.LockserverMigrationVerifying
+			r.recorder.Event(vt, corev1.EventTypeNormal, "TopoMigrationCopied", "copied topology to new global lockserver")
+		}
+
+		// Take the checksum first, so anything that changes while we compare
+		// is either caught by the compare or by a later checksum.
+		checksum, err := vitesstopo.TopologyChecksum(ctx, fromTS.Server, toTS.Server)
+		if err != nil {
+			status.Message = fmt.Sprintf("failed to read old global lockserver: %v", err)
+			return resultBuilder.RequeueAfter(topoRequeueDelay)
+		}
+		if err := vitesstopo.CompareTopology(ctx, fromTS.Server, toTS.Server); err != nil {
+			// Something changed on the old lockserver since we copied it.
+			r.recorder.Eventf(vt, corev1.EventTypeWarning, "TopoMigrationMismatch", "new global lockserver doesn't match old one, copying again: %v", err)
+			status.Phase = planetscalev2.LockserverMigrationCopying
+			status.Message = fmt.Sprintf("copy doesn't match old lockserver: %v", err)
+			return resultBuilder.RequeueAfter(topoRequeueDelay)
+		}
+		status.VerifiedChecksum = checksum
+		status.Phase = planetscalev2.LockserverMigrationSwitchingWriters
+		status.Message = ""
+		r.recorder.Event(vt, corev1.EventTypeNormal, "TopoMigrationVerified", "verified copy of topology in new global lockserver")
+		// Check on the writers once they have had a chance to roll out.
+		return resultBuilder.RequeueAfter(migrationPollInterval)
+
+	case planetscalev2.LockserverMigrationSwitchingWriters,
+		planetscalev2.LockserverMigrationSwitchingCells:
+		// Every component of this phase has left the old lockserver. Make sure
+		// nobody wrote to it after we verified the copy. We can't just copy
+		// again, since the components that already moved have been writing to
+		// the new lockserver since then, and a copy would overwrite that.
+		checksum, err := vitesstopo.TopologyChecksum(ctx, fromTS.Server, toTS.Server)
+		if err != nil {
+			status.Message = fmt.Sprintf("failed to read old global lockserver: %v", err)
+			return resultBuilder.RequeueAfter(topoRequeueDelay)
+		}
+		if checksum != status.VerifiedChecksum {
+			if !strings.HasPrefix(status.Message, oldLockserverChangedMessage) {
+				r.recorder.Event(vt, corev1.EventTypeWarning, "TopoMigrationDiverged", oldLockserverChangedMessage)
+			}
+			status.Message = oldLockserverChangedMessage + ". Those changes aren't on the new lockserver. Apply them there, then remove migrateFrom to finish moving."
+			return resultBuilder.RequeueAfter(migrationPollInterval)
+		}
+
+		components := migrationComponents[status.Phase]
+		status.Phase = status.NextPhase()
+		status.Message = ""
+		r.recorder.Eventf(vt, corev1.EventTypeNormal, "TopoMigrationProgress", "moved %s to new global lockserver", strings.Join(components, ", "))
+		if status.Phase != planetscalev2.LockserverMigrationComplete {
+			return resultBuilder.RequeueAfter(migrationPollInterval)
+		}
+	}
+
+	return resultBuilder.Result()
+}
+
+// oldLockserverChangedMessage is reported when something wrote to the old
+// global lockserver after the copy was verified.
+const oldLockserverChangedMessage = "the old global lockserver changed after its copy was verified"
+
+// countPodsUsingLockserver returns how many running Pods of the given
+// components are still configured with the given global lockserver.
+// If params is nil, it counts them no matter which lockserver they use.
+func (r *ReconcileVitessCluster) countPodsUsingLockserver(ctx context.Context, vt *planetscalev2.VitessCluster, params *planetscalev2.VitessLockserverParams, components []string) (int, error) {
+	podList := &corev1.PodList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(vt.Namespace),
+		client.MatchingLabels{planetscalev2.ClusterLabel: vt.Name},
+	}
+	if err := r.client.List(ctx, podList, listOpts...); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !slices.Contains(components, pod.Labels[planetscalev2.ComponentLabel]) {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if params == nil || podUsesLockserver(pod, params) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// podUsesLockserver returns whether any container in the Pod is configured
+// with the given global lockserver.
+func podUsesLockserver(pod *corev1.Pod, params *planetscalev2.VitessLockserverParams) bool {
+	addressArg := "--topo_global_server_address=" + params.Address
+	rootArg := "--topo_global_root=" + params.RootPath
+	for i := range pod.Spec.Containers {
+		args := pod.Spec.Containers[i].Args
+		if slices.Contains(args, addressArg) && slices.Contains(args, rootArg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscluster
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/toposerver"
+	"planetscale.dev/vitess-operator/pkg/operator/vitesstopo"
+)
+
+func TestGlobalLockserverParamsDuringMigration(t *testing.T) {
+	from := &planetscalev2.VitessLockserverParams{
+		Implementation: "etcd2",
+		Address:        "old-etcd:2379",
+		RootPath:       "/vitess/example/global",
+	}
+	vt := &planetscalev2.VitessCluster{}
+	vt.Name = "example"
+	vt.Namespace = "ns"
+	vt.Spec.GlobalLockserver.Etcd = &planetscalev2.EtcdLockserverTemplate{}
+	vt.Spec.GlobalLockserver.MigrateFrom = from
+
+	// Before the copy is verified, everything stays on the old lockserver.
+	vt.Status.GlobalLockserverMigration = &planetscalev2.LockserverMigrationStatus{
+		From:  *from,
+		Phase: planetscalev2.LockserverMigrationVerifying,
+	}
+	assert.Equal(t, from, globalLockserverParams(vt, planetscalev2.LockserverMigrationSwitchingWriters))
+	assert.True(t, vtorcPausedForMigration(vt))
+
+	// All writers move together, before the cells.
+	vt.Status.GlobalLockserverMigration.Phase = planetscalev2.LockserverMigrationSwitchingWriters
+	assert.NotEqual(t, from, globalLockserverParams(vt, planetscalev2.LockserverMigrationSwitchingWriters))
+	assert.Equal(t, from, globalLockserverParams(vt, planetscalev2.LockserverMigrationSwitchingCells))
+	assert.True(t, vtorcPausedForMigration(vt))
+
+	vt.Status.GlobalLockserverMigration.Phase = planetscalev2.LockserverMigrationSwitchingCells
+	assert.NotEqual(t, from, globalLockserverParams(vt, planetscalev2.LockserverMigrationSwitchingCells))
+	assert.False(t, vtorcPausedForMigration(vt))
+	assert.True(t, lockserverMigrationInProgress(vt))
+
+	vt.Status.GlobalLockserverMigration.Phase = planetscalev2.LockserverMigrationComplete
+	assert.NotEqual(t, from, globalLockserverParams(vt, planetscalev2.LockserverMigrationSwitchingCells))
+	assert.False(t, lockserverMigrationInProgress(vt))
+}
+
+func TestPodUsesLockserver(t *testing.T) {
+	params := &planetscalev2.VitessLockserverParams{
+		Address:  "old-etcd:2379",
+		RootPath: "/vitess/example/global",
+	}
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "vttablet",
+					Args: []string{
+						"--topo_global_root=/vitess/example/global",
+						"--topo_global_server_address=old-etcd:2379",
+					},
+				},
+			},
+		},
+	}
+	assert.True(t, podUsesLockserver(pod, params))
+
+	pod.Spec.Containers[0].Args[1] = "--topo_global_server_address=new-etcd:2379"
+	assert.False(t, podUsesLockserver(pod, params))
+}
+
+func TestReconcileGlobalLockserverMigration(t *testing.T) {
+	ctx := t.Context()
+
+	from := &planetscalev2.VitessLockserverParams{
+		Implementation: "etcd2",
+		Address:        "old-etcd:2379",
+		RootPath:       "/vitess/example/global",
+	}
+	fromTS := memorytopo.NewServer(ctx, "zone1")
+	toTS := memorytopo.NewServer(ctx, "zone1")
+	defer fromTS.Close()
+	defer toTS.Close()
+
+	// The cell's local topology lives somewhere else on the new side, so its
+	// tablets and serving graph have to move too.
+	_, err := toTS.UpdateCellInfoFields(ctx, "zone1", func(ci *topodatapb.CellInfo) error {
+		ci.Root = "/vitess/example/zone1"
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Things on the new side that differ from the old side, or that the old
+	// side doesn't have, must not survive the copy.
+	require.NoError(t, fromTS.CreateKeyspace(ctx, "commerce", &topodatapb.Keyspace{DurabilityPolicy: "semi_sync"}))
+	require.NoError(t, toTS.CreateKeyspace(ctx, "commerce", &topodatapb.Keyspace{DurabilityPolicy: "none"}))
+	require.NoError(t, toTS.CreateKeyspace(ctx, "stale", &topodatapb.Keyspace{}))
+	require.NoError(t, fromTS.CreateShard(ctx, "commerce", "-"))
+	require.NoError(t, fromTS.UpdateSrvKeyspace(ctx, "zone1", "commerce", &topodatapb.SrvKeyspace{}))
+	tabletAlias := &topodatapb.TabletAlias{Cell: "zone1", Uid: 101}
+	require.NoError(t, fromTS.CreateTablet(ctx, &topodatapb.Tablet{
+		Alias:    tabletAlias,
+		Keyspace: "commerce",
+		Shard:    "-",
+		Type:     topodatapb.TabletType_REPLICA,
+	}))
+
+	origOpenTopo := openTopo
+	t.Cleanup(func() { openTopo = origOpenTopo })
+	openTopo = func(ctx context.Context, namespace string, params planetscalev2.VitessLockserverParams) (*toposerver.Conn, error) {
+		if params.Address == from.Address {
+			return &toposerver.Conn{Server: fromTS}, nil
+		}
+		return &toposerver.Conn{Server: toTS}, nil
+	}
+
+	vt := &planetscalev2.VitessCluster{}
+	vt.Name = "example"
+	vt.Namespace = "ns"
+	vt.Spec.GlobalLockserver.Etcd = &planetscalev2.EtcdLockserverTemplate{}
+	vt.Spec.GlobalLockserver.MigrateFrom = from
+	planetscalev2.DefaultTopoReconcileConfig(&vt.Spec.TopologyReconciliation)
+	vt.Spec.TopologyReconciliation.RegisterCellsAliases = ptr.To(false)
+	vt.Spec.TopologyReconciliation.RegisterCells = ptr.To(false)
+	vt.Spec.TopologyReconciliation.PruneCells = ptr.To(false)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, planetscalev2.SchemeBuilder.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	recorder := record.NewFakeRecorder(20)
+	r := &ReconcileVitessCluster{
+		client:   fake.NewClientBuilder().WithScheme(scheme).Build(),
+		scheme:   scheme,
+		recorder: recorder,
+	}
+
+	// The copy is made, verified, and the writers are told to move.
+	_, err = r.reconcileGlobalLockserverMigration(ctx, vt)
+	require.NoError(t, err)
+	status := vt.Status.GlobalLockserverMigration
+	require.NotNil(t, status)
+	assert.Equal(t, planetscalev2.LockserverMigrationSwitchingWriters, status.Phase)
+	assert.NotEmpty(t, status.VerifiedChecksum)
+	assert.NoError(t, vitesstopo.CompareTopology(ctx, fromTS, toTS))
+
+	ks, err := toTS.GetKeyspace(ctx, "commerce")
+	require.NoError(t, err)
+	assert.Equal(t, "semi_sync", ks.DurabilityPolicy)
+	_, err = toTS.GetKeyspace(ctx, "stale")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "stale keyspace should be deleted: %v", err)
+	_, err = toTS.GetShard(ctx, "commerce", "-")
+	assert.NoError(t, err)
+	_, err = toTS.GetSrvKeyspace(ctx, "zone1", "commerce")
+	assert.NoError(t, err)
+	_, err = toTS.GetTablet(ctx, tabletAlias)
+	assert.NoError(t, err)
+
+	// Tablets rewrite their own records as they move, which is fine.
+	_, err = fromTS.UpdateTabletFields(ctx, tabletAlias, func(tablet *topodatapb.Tablet) error {
+		tablet.Hostname = "moved"
+		return nil
+	})
+	require.NoError(t, err)
+	_, err = r.reconcileGlobalLockserverMigration(ctx, vt)
+	require.NoError(t, err)
+	assert.Equal(t, planetscalev2.LockserverMigrationSwitchingCells, status.Phase)
+
+	// Anything else written to the old side after the copy was verified
+	// never reaches the new side, so the migration has to stop.
+	_, err = fromTS.UpdateShardFields(ctx, "commerce", "-", func(si *topo.ShardInfo) error {
+		si.IsPrimaryServing = !si.IsPrimaryServing
+		return nil
+	})
+	require.NoError(t, err)
+	for range 2 {
+		_, err = r.reconcileGlobalLockserverMigration(ctx, vt)
+		require.NoError(t, err)
+		assert.Equal(t, planetscalev2.LockserverMigrationSwitchingCells, status.Phase)
+		assert.True(t, strings.HasPrefix(status.Message, oldLockserverChangedMessage), status.Message)
+	}
+
+	// The divergence is only reported once.
+	diverged := 0
+	for len(recorder.Events) > 0 {
+		if strings.Contains(<-recorder.Events, "TopoMigrationDiverged") {
+			diverged++
+		}
+	}
+	assert.Equal(t, 1, diverged)
+}
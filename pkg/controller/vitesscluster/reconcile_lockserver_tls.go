@@ -0,0 +1,253 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscluster
+
+import (
+	"context"
+	"net"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/certs"
+	"planetscale.dev/vitess-operator/pkg/operator/etcd"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+)
+
+const (
+	// etcdServerCertLabel marks Secrets holding operator-managed etcd server
+	// certificates, so we can clean up the ones we no longer need.
+	etcdServerCertLabel = "planetscale.com/etcd-server-cert"
+
+	etcdClientCommonName = "vitess"
+)
+
+// managedEtcd is an etcd lockserver that uses operator-managed TLS.
+type managedEtcd struct {
+	tls *planetscalev2.OperatorManagedTLS
+	// dnsNames are the names the server certificate must be valid for.
+	dnsNames []string
+}
+
+// reconcileLockserverTLS maintains the CA, client certificate and server
+// certificates for etcd lockservers that use operator-managed TLS.
+//
+// All lockservers in a cluster share the CA and the client certificate,
+// because Vitess components only take one set of topo TLS flags.
+// Server certificates are renewed in place, and etcd picks them up without a
+// restart. Components hash the client certificate into their Pod templates,
+// so renewing it triggers a rolling restart.
+func (r *ReconcileVitessCluster) reconcileLockserverTLS(ctx context.Context, vt *planetscalev2.VitessCluster) (reconcile.Result, error) {
+	resultBuilder := results.Builder{}
+	labels := map[string]string{
+		planetscalev2.ClusterLabel:   vt.Name,
+		planetscalev2.ComponentLabel: planetscalev2.EtcdComponentName,
+	}
+	serverLabels := map[string]string{
+		planetscalev2.ClusterLabel:   vt.Name,
+		planetscalev2.ComponentLabel: planetscalev2.EtcdComponentName,
+		etcdServerCertLabel:          "true",
+	}
+
+	servers := managedEtcdServers(vt)
+	managed := etcdManagedTLS(vt)
+
+	caKey := client.ObjectKey{Namespace: vt.Namespace, Name: lockserver.EtcdCASecretName(vt.Name)}
+	ca, _, err := r.reconcileCA(ctx, vt, caKey, labels, managed != nil)
+	if err != nil {
+		return resultBuilder.Error(err)
+	}
+	if managed != nil && ca == nil {
+		// We'll try again once the CA is readable.
+		return resultBuilder.RequeueAfter(time.Minute)
+	}
+
+	var issueErr error
+	ensureCert := func(secret *corev1.Secret, tls *planetscalev2.OperatorManagedTLS, commonName string, dnsNames []string) {
+		if !certs.NeedsRenewal(certs.KeyPairFromSecret(secret), ca.CertPEM, dnsNames, tls.RenewBefore.Duration, time.Now()) {
+			return
+		}
+		kp, err := certs.IssueCert(ca, commonName, dnsNames, tls.Duration.Duration, time.Now())
+		if err != nil {
+			issueErr = err
+			return
+		}
+		secret.Data = kp.SecretData(ca.CertPEM)
+		r.recorder.Eventf(vt, corev1.EventTypeNormal, "CertificateIssued", "issued etcd TLS certificate in Secret %v", secret.Name)
+	}
+	newSecret := func(key client.ObjectKey, labels map[string]string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+				Labels:    labels,
+			},
+			Type: corev1.SecretTypeTLS,
+		}
+	}
+	requeueForRenewal := func(secret *corev1.Secret, tls *planetscalev2.OperatorManagedTLS) *planetscalev2.CertificateStatus {
+		status := &planetscalev2.CertificateStatus{SecretName: secret.Name}
+		cert, err := certs.ParseCertificate(secret.Data[certs.CertKey])
+		if err != nil {
+			return status
+		}
+		renewalTime := certs.RenewalTime(cert, tls.RenewBefore.Duration)
+		status.NotAfter = &metav1.Time{Time: cert.NotAfter}
+		status.RenewalTime = &metav1.Time{Time: renewalTime}
+		// Come back in time to renew the certificate.
+		resultBuilder.RequeueAfter(certs.RenewalDelay(cert, tls.RenewBefore.Duration, time.Now()))
+		return status
+	}
+
+	// Reconcile the client certificate shared by all components.
+	vt.Status.LockserverTLS = nil
+	clientKey := client.ObjectKey{Namespace: vt.Namespace, Name: lockserver.EtcdClientTLSSecretName(vt.Name)}
+	err = r.reconciler.ReconcileObject(ctx, vt, clientKey, labels, managed != nil, reconciler.Strategy{
+		Kind: &corev1.Secret{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			secret := newSecret(key, labels)
+			ensureCert(secret, managed, etcdClientCommonName, nil)
+			return secret
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			secret := obj.(*corev1.Secret)
+			update.Labels(&secret.Labels, labels)
+			ensureCert(secret, managed, etcdClientCommonName, nil)
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			vt.Status.LockserverTLS = requeueForRenewal(obj.(*corev1.Secret), managed)
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	// Reconcile a server certificate for each lockserver.
+	keys := make([]client.ObjectKey, 0, len(servers))
+	for name := range servers {
+		keys = append(keys, client.ObjectKey{Namespace: vt.Namespace, Name: name})
+	}
+	err = r.reconciler.ReconcileObjectSet(ctx, vt, keys, serverLabels, reconciler.Strategy{
+		Kind: &corev1.Secret{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			server := servers[key.Name]
+			secret := newSecret(key, serverLabels)
+			ensureCert(secret, server.tls, server.dnsNames[0], server.dnsNames)
+			return secret
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			server := servers[key.Name]
+			secret := obj.(*corev1.Secret)
+			update.Labels(&secret.Labels, serverLabels)
+			ensureCert(secret, server.tls, server.dnsNames[0], server.dnsNames)
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			requeueForRenewal(obj.(*corev1.Secret), servers[key.Name].tls)
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+	if issueErr != nil {
+		resultBuilder.Error(issueErr)
+	}
+
+	return resultBuilder.Result()
+}
+
+// etcdManagedTLS returns the operator-managed TLS settings to use for the
+// shared CA and client certificate, or nil if no etcd lockserver uses
+// operator-managed TLS. The global lockserver's settings take precedence,
+// followed by those of the first cell.
+func etcdManagedTLS(vt *planetscalev2.VitessCluster) *planetscalev2.OperatorManagedTLS {
+	lockSpecs := []*planetscalev2.LockserverSpec{&vt.Spec.GlobalLockserver}
+	for i := range vt.Spec.Cells {
+		lockSpecs = append(lockSpecs, &vt.Spec.Cells[i].Lockserver)
+	}
+	for _, lockSpec := range lockSpecs {
+		if lockSpec.External == nil && lockSpec.Etcd != nil && lockSpec.Etcd.TLS != nil && lockSpec.Etcd.TLS.OperatorManaged != nil {
+			return defaultedManagedTLS(lockSpec.Etcd.TLS.OperatorManaged)
+		}
+	}
+	return nil
+}
+
+// managedEtcdServers returns the etcd lockservers in a cluster that use
+// operator-managed TLS, keyed by the name of their server certificate Secret.
+func managedEtcdServers(vt *planetscalev2.VitessCluster) map[string]managedEtcd {
+	servers := map[string]managedEtcd{}
+	add := func(lockserverName string, lockSpec *planetscalev2.LockserverSpec) {
+		if lockSpec.External != nil || lockSpec.Etcd == nil || lockSpec.Etcd.TLS == nil || lockSpec.Etcd.TLS.OperatorManaged == nil {
+			return
+		}
+		tls := defaultedManagedTLS(lockSpec.Etcd.TLS.OperatorManaged)
+		servers[lockserver.EtcdServerTLSSecretName(lockserverName)] = managedEtcd{
+			tls:      tls,
+			dnsNames: etcdServerDNSNames(lockserverName, vt.Namespace, lockSpec.CellInfoAddress, tls.ExtraDNSNames),
+		}
+	}
+
+	add(lockserver.GlobalEtcdName(vt.Name), &vt.Spec.GlobalLockserver)
+	for i := range vt.Spec.Cells {
+		cell := &vt.Spec.Cells[i]
+		add(lockserver.LocalEtcdName(vt.Name, cell.Name), &cell.Lockserver)
+	}
+	return servers
+}
+
+// etcdServerDNSNames returns the names that an etcd server certificate must
+// be valid for. The client Service comes first, to use as the common name.
+//
+// Members verify each other's certificates against the names they dial, and
+// also check that a connecting peer's address resolves from one of the names
+// in its certificate, so each member must be listed explicitly rather than
+// with a wildcard.
+func etcdServerDNSNames(lockserverName, namespace, cellInfoAddress string, extraDNSNames []string) []string {
+	dnsNames := certs.ServiceDNSNames(etcd.ClientServiceName(lockserverName), namespace)
+	peerService := etcd.PeerServiceName(lockserverName)
+	// Cover every possible member, so the certificate stays valid while the
+	// lockserver is scaled.
+	for index := 1; index <= etcd.MaxReplicas; index++ {
+		member := etcd.PodName(lockserverName, index) + "." + peerService
+		dnsNames = append(dnsNames, member, member+"."+namespace, member+"."+namespace+".svc")
+	}
+	// Our own probes connect through localhost.
+	dnsNames = append(dnsNames, "localhost")
+	if host, _, err := net.SplitHostPort(cellInfoAddress); err == nil && host != "" {
+		dnsNames = append(dnsNames, host)
+	}
+	return append(dnsNames, extraDNSNames...)
+}
+
+// defaultedManagedTLS returns a copy of tls with defaults filled in. Cell
+// lockservers aren't defaulted as part of the VitessCluster, so we can't rely
+// on durations being set, or on renewBefore being less than the duration.
+func defaultedManagedTLS(tls *planetscalev2.OperatorManagedTLS) *planetscalev2.OperatorManagedTLS {
+	tls = tls.DeepCopy()
+	planetscalev2.DefaultOperatorManagedTLS(tls)
+	return tls
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
+)
+
+func TestManagedEtcdServersDefaultsCellTLS(t *testing.T) {
+	vt := &planetscalev2.VitessCluster{}
+	vt.Name = "example"
+	vt.Namespace = "ns"
+	vt.Spec.GlobalLockserver.External = &planetscalev2.VitessLockserverParams{}
+	// Cell lockservers aren't defaulted along with the VitessCluster.
+	cellTLS := &planetscalev2.OperatorManagedTLS{
+		Duration: &metav1.Duration{Duration: 24 * time.Hour},
+	}
+	vt.Spec.Cells = []planetscalev2.VitessCellTemplate{{
+		Name: "zone1",
+		Lockserver: planetscalev2.LockserverSpec{
+			Etcd: &planetscalev2.EtcdLockserverTemplate{
+				TLS: &planetscalev2.EtcdLockserverTLS{OperatorManaged: cellTLS},
+			},
+		},
+	}}
+
+	managed := etcdManagedTLS(vt)
+	require.NotNil(t, managed)
+	assert.Equal(t, 8*time.Hour, managed.RenewBefore.Duration)
+
+	servers := managedEtcdServers(vt)
+	server, ok := servers[lockserver.EtcdServerTLSSecretName(lockserver.LocalEtcdName(vt.Name, "zone1"))]
+	require.True(t, ok)
+	assert.Less(t, server.tls.RenewBefore.Duration, server.tls.Duration.Duration)
+
+	// The spec itself is left alone.
+	assert.Nil(t, cellTLS.RenewBefore)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscluster
+
+import (
+	"context"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/networkpolicy"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+)
+
+// clusterNetworkPolicies maps each component that gets a cluster-wide
+// NetworkPolicy to the function that builds it. Tablet policies are per-shard,
+// so the VitessShard controller creates those.
+var clusterNetworkPolicies = map[string]func(labels map[string]string, clusterName string, np *planetscalev2.VitessNetworkPolicySpec) *networkpolicy.Spec{
+	planetscalev2.VtgateComponentName:  networkpolicy.NewVtgateSpec,
+	planetscalev2.VtadminComponentName: networkpolicy.NewVtadminSpec,
+	planetscalev2.VtctldComponentName:  networkpolicy.NewVtctldSpec,
+	planetscalev2.VtorcComponentName:   networkpolicy.NewVtorcSpec,
+	planetscalev2.EtcdComponentName:    networkpolicy.NewEtcdSpec,
+	planetscalev2.ConsulComponentName:  networkpolicy.NewConsulSpec,
+	planetscalev2.ZkComponentName:      networkpolicy.NewZkSpec,
+}
+
+func (r *ReconcileVitessCluster) reconcileNetworkPolicies(ctx context.Context, vt *planetscalev2.VitessCluster) (reconcile.Result, error) {
+	resultBuilder := &results.Builder{}
+	enabled := networkpolicy.Enabled(vt.Spec.NetworkPolicies)
+
+	for componentName, newSpec := range clusterNetworkPolicies {
+		labels := map[string]string{
+			planetscalev2.ComponentLabel: componentName,
+			planetscalev2.ClusterLabel:   vt.Name,
+		}
+		var spec *networkpolicy.Spec
+		if enabled {
+			spec = newSpec(labels, vt.Name, vt.Spec.NetworkPolicies)
+		}
+
+		key := client.ObjectKey{Namespace: vt.Namespace, Name: networkpolicy.Name(vt.Name, componentName)}
+		err := r.reconciler.ReconcileObject(ctx, vt, key, labels, enabled, reconciler.Strategy{
+			Kind: &networkingv1.NetworkPolicy{},
+
+			New: func(key client.ObjectKey) runtime.Object {
+				return networkpolicy.NewNetworkPolicy(key, spec)
+			},
+			UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+				newObj := obj.(*networkingv1.NetworkPolicy)
+				networkpolicy.UpdateNetworkPolicy(newObj, spec)
+			},
+		})
+		if err != nil {
+			resultBuilder.Error(err)
+		}
+	}
+
+	return resultBuilder.Result()
+}
@@ -18,9 +18,12 @@ package vitesscluster
 
 import (
 	"context"
+	"maps"
+	"slices"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/topo"
@@ -52,7 +55,7 @@ func (r *ReconcileVitessCluster) reconcileTopology(ctx context.Context, vt *plan
 		r.recorder.Event(vt, corev1.EventTypeWarning, "TopoInvalid", "no global lockserver is defined")
 		return resultBuilder.Result()
 	}
-	ts, err := toposerver.Open(ctx, *globalParams)
+	ts, err := toposerver.Open(ctx, vt.Namespace, *globalParams)
 	if err != nil {
 		r.recorder.Eventf(vt, corev1.EventTypeWarning, "TopoConnectFailed", "failed to connect to global lockserver: %v", err)
 		// Give the lockserver some time to come up.
@@ -66,6 +69,9 @@ func (r *ReconcileVitessCluster) reconcileTopology(ctx context.Context, vt *plan
 	keyspaceResult, err := r.reconcileKeyspaceTopology(ctx, vt, ts.Server)
 	resultBuilder.Merge(keyspaceResult, err)
 
+	driftResult, err := r.reconcileTopologyDrift(ctx, vt, ts.Server)
+	resultBuilder.Merge(driftResult, err)
+
 	return resultBuilder.Result()
 }
 
@@ -110,19 +116,30 @@ func (r *ReconcileVitessCluster) reconcileCellTopology(ctx context.Context, vt *
 		resultBuilder.Merge(result, err)
 	}
 
-	if *vt.Spec.TopologyReconciliation.PruneCells {
+	// The status still lists what was pending last time, so we only
+	// announce newly pending cells. Clear it in case we don't prune now.
+	previouslyPending := vt.Status.PendingPruneCells
+	vt.Status.PendingPruneCells = nil
+
+	// Don't prune while a global lockserver migration is in progress, since
+	// the new lockserver might not have everything yet.
+	if *vt.Spec.TopologyReconciliation.PruneCells && !lockserverMigrationInProgress(vt) {
 		// Don't hold our slot in the reconcile work queue for too long.
 		ctx, cancel := context.WithTimeout(ctx, topoReconcileTimeout)
 		defer cancel()
 
-		result, err := vitesstopo.PruneCells(ctx, vitesstopo.PruneCellsParams{
+		status, result, err := vitesstopo.PruneCells(ctx, vitesstopo.PruneCellsParams{
 			EventObj:      vt,
 			TopoServer:    ts,
 			Recorder:      r.recorder,
 			DesiredCells:  desiredCells,
 			OrphanedCells: vt.Status.OrphanedCells,
+			Approval: vitesstopo.NewPruneApproval(vt, vitesstopo.ApprovePruneCellsAnnotation,
+				*vt.Spec.TopologyReconciliation.PruneCellsDryRun, vt.Status.UsedPruneApprovals, previouslyPending),
 		})
 		resultBuilder.Merge(result, err)
+		vt.Status.PendingPruneCells = status.Pending
+		vitesstopo.RecordUsedApprovals(&vt.Status.UsedPruneApprovals, vitesstopo.ApprovePruneCellsAnnotation, status.Used)
 	}
 
 	return resultBuilder.Result()
@@ -134,12 +151,66 @@ func (r *ReconcileVitessCluster) registerCellsAliases(ctx context.Context, vt *p
 	defer cancel()
 
 	desiredCellsAliases := buildCellsAliases(desiredCells)
+	if len(vt.Spec.CellsAliases) > 0 {
+		if err := vt.Spec.ValidateCellsAliases(); err != nil {
+			// There's no reason to request a retry. Just leave the current
+			// aliases alone until the spec is fixed.
+			r.recorder.Eventf(vt, corev1.EventTypeWarning, "InvalidSpec", "not updating cells aliases: %v", err)
+			return nil
+		}
+		desiredCellsAliases = buildCustomCellsAliases(vt.Spec.CellsAliases)
+	}
 	currentCellsAliases, err := ts.GetCellsAliases(ctx, true)
 	if err != nil {
 		r.recorder.Eventf(vt, corev1.EventTypeWarning, "TopoCellAlias",
 			"Failed to get current cell aliases: %v", err)
 		return err
 	}
+
+	// We own the aliases we registered before, as well as the default one.
+	// Remember any new ones before we create them, in case we fail partway.
+	managedCellsAliases := sets.NewString(vt.Status.CellsAliases...).Insert(defaultCellsAlias)
+	vt.Status.CellsAliases = sets.NewString(vt.Status.CellsAliases...).Insert(slices.Collect(maps.Keys(desiredCellsAliases))...).List()
+
+	// Remove our aliases that aren't wanted anymore, and shrink the ones that
+	// are losing cells, before anything else. A cell can only be in one alias,
+	// so this lets cells move from one alias to another.
+	for _, alias := range slices.Sorted(maps.Keys(currentCellsAliases)) {
+		currentCells := currentCellsAliases[alias].Cells
+		var keepCells []string
+		if desiredCellsAlias, ok := desiredCellsAliases[alias]; ok {
+			keepCells = sets.NewString(currentCells...).Intersection(sets.NewString(desiredCellsAlias.Cells...)).List()
+			if len(keepCells) == len(currentCells) {
+				continue
+			}
+		} else if !managedCellsAliases.Has(alias) {
+			// Someone else created this alias.
+			continue
+		}
+
+		if len(keepCells) == 0 {
+			if err := ts.DeleteCellsAlias(ctx, alias); err != nil && !topo.IsErrType(err, topo.NoNode) {
+				r.recorder.Eventf(vt, corev1.EventTypeWarning, "TopoCellAlias",
+					"Failed to delete cells alias: %s: %v", alias, err)
+				return err
+			}
+			delete(currentCellsAliases, alias)
+			r.recorder.Eventf(vt, corev1.EventTypeNormal, "TopoCellAlias",
+				"Deleted cells alias: %s", alias)
+			continue
+		}
+		err = ts.UpdateCellsAlias(ctx, alias, func(ca *topodatapb.CellsAlias) error {
+			ca.Cells = keepCells
+			return nil
+		})
+		if err != nil {
+			r.recorder.Eventf(vt, corev1.EventTypeWarning, "TopoCellAlias",
+				"Failed to remove cells from cells alias: %s: %v", alias, err)
+			return err
+		}
+		currentCellsAliases[alias].Cells = keepCells
+	}
+
 	for alias, desiredCellsAlias := range desiredCellsAliases {
 		// If this alias already exists and matches what we are trying to update
 		// it to, skip it.
@@ -163,25 +234,38 @@ func (r *ReconcileVitessCluster) registerCellsAliases(ctx context.Context, vt *p
 			"Created or updated cells alias: %s -> %v", alias,
 			desiredCellsAlias.Cells)
 	}
+
+	vt.Status.CellsAliases = slices.Sorted(maps.Keys(desiredCellsAliases))
 	return nil
 }
 
 func (r *ReconcileVitessCluster) reconcileKeyspaceTopology(ctx context.Context, vt *planetscalev2.VitessCluster, ts *topo.Server) (reconcile.Result, error) {
 	resultBuilder := &results.Builder{}
 
-	if *vt.Spec.TopologyReconciliation.PruneKeyspaces {
+	// The status still lists what was pending last time, so we only
+	// announce newly pending keyspaces. Clear it in case we don't prune now.
+	previouslyPending := vt.Status.PendingPruneKeyspaces
+	vt.Status.PendingPruneKeyspaces = nil
+
+	// Don't prune while a global lockserver migration is in progress, since
+	// the new lockserver might not have everything yet.
+	if *vt.Spec.TopologyReconciliation.PruneKeyspaces && !lockserverMigrationInProgress(vt) {
 		// Don't hold our slot in the reconcile work queue for too long.
 		ctx, cancel := context.WithTimeout(ctx, topoReconcileTimeout)
 		defer cancel()
 
-		result, err := vitesstopo.PruneKeyspaces(ctx, vitesstopo.PruneKeyspacesParams{
+		status, result, err := vitesstopo.PruneKeyspaces(ctx, vitesstopo.PruneKeyspacesParams{
 			EventObj:          vt,
 			TopoServer:        ts,
 			Recorder:          r.recorder,
 			Keyspaces:         vt.Spec.Keyspaces,
 			OrphanedKeyspaces: vt.Status.OrphanedKeyspaces,
+			Approval: vitesstopo.NewPruneApproval(vt, vitesstopo.ApprovePruneKeyspacesAnnotation,
+				*vt.Spec.TopologyReconciliation.PruneKeyspacesDryRun, vt.Status.UsedPruneApprovals, previouslyPending),
 		})
 		resultBuilder.Merge(result, err)
+		vt.Status.PendingPruneKeyspaces = status.Pending
+		vitesstopo.RecordUsedApprovals(&vt.Status.UsedPruneApprovals, vitesstopo.ApprovePruneKeyspacesAnnotation, status.Used)
 	}
 
 	return resultBuilder.Result()
@@ -0,0 +1,268 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"vitess.io/vitess/go/vt/topo"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/metrics"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/vitesstopo"
+)
+
+const (
+	// topoDriftAuditTimeout bounds a single topology drift audit, which has
+	// to read every shard's tablet records.
+	topoDriftAuditTimeout = 30 * time.Second
+	// maxTopoDriftRecords is the most entries kept in each status list.
+	maxTopoDriftRecords = 50
+)
+
+// topoDriftRecords collects drifted topology records of each type.
+type topoDriftRecords struct {
+	cells        []string
+	keyspaces    []string
+	shards       []string
+	tablets      []string
+	srvKeyspaces []string
+}
+
+// status returns the sorted and truncated lists for VitessCluster status.
+func (d *topoDriftRecords) status() planetscalev2.TopologyDriftRecords {
+	var out planetscalev2.TopologyDriftRecords
+	limit := func(list []string) []string {
+		if len(list) == 0 {
+			return nil
+		}
+		list = append([]string(nil), list...)
+		sort.Strings(list)
+		if len(list) > maxTopoDriftRecords {
+			out.Truncated = true
+			list = list[:maxTopoDriftRecords]
+		}
+		return list
+	}
+	out.Cells = limit(d.cells)
+	out.Keyspaces = limit(d.keyspaces)
+	out.Shards = limit(d.shards)
+	out.Tablets = limit(d.tablets)
+	out.SrvKeyspaces = limit(d.srvKeyspaces)
+	return out
+}
+
+// updateMetrics exports the full count of each record type.
+func (d *topoDriftRecords) updateMetrics(clusterName, drift string) {
+	topologyDriftRecords.WithLabelValues(clusterName, "cell", drift).Set(float64(len(d.cells)))
+	topologyDriftRecords.WithLabelValues(clusterName, "keyspace", drift).Set(float64(len(d.keyspaces)))
+	topologyDriftRecords.WithLabelValues(clusterName, "shard", drift).Set(float64(len(d.shards)))
+	topologyDriftRecords.WithLabelValues(clusterName, "tablet", drift).Set(float64(len(d.tablets)))
+	topologyDriftRecords.WithLabelValues(clusterName, "srv_keyspace", drift).Set(float64(len(d.srvKeyspaces)))
+}
+
+// missingRecords returns the desired names that aren't in the current list.
+func missingRecords(desired sets.String, current []string) []string {
+	return desired.Difference(sets.NewString(current...)).List()
+}
+
+// reconcileTopologyDrift periodically compares the global topology with the
+// VitessCluster and the VitessKeyspaces and VitessShards it manages, and
+// reports any differences in status and metrics. It never changes topology.
+func (r *ReconcileVitessCluster) reconcileTopologyDrift(ctx context.Context, vt *planetscalev2.VitessCluster, ts *topo.Server) (reconcile.Result, error) {
+	resultBuilder := &results.Builder{}
+
+	if *topoDriftAuditInterval <= 0 {
+		vt.Status.TopologyDrift = nil
+		return resultBuilder.Result()
+	}
+	// The spec points at the new lockserver while a migration is in
+	// progress, so keep the last report until it's done.
+	if lockserverMigrationInProgress(vt) {
+		return resultBuilder.Result()
+	}
+	// The previous report was carried over from the old status.
+	if drift := vt.Status.TopologyDrift; drift != nil && drift.AuditTime != nil {
+		if wait := *topoDriftAuditInterval - time.Since(drift.AuditTime.Time); wait > 0 {
+			return resultBuilder.RequeueAfter(wait)
+		}
+	}
+
+	// Don't hold our slot in the reconcile work queue for too long.
+	ctx, cancel := context.WithTimeout(ctx, topoDriftAuditTimeout)
+	defer cancel()
+
+	labels := client.MatchingLabels{planetscalev2.ClusterLabel: vt.Name}
+	vtkList := &planetscalev2.VitessKeyspaceList{}
+	if err := r.client.List(ctx, vtkList, client.InNamespace(vt.Namespace), labels); err != nil {
+		return resultBuilder.Error(err)
+	}
+	vtsList := &planetscalev2.VitessShardList{}
+	if err := r.client.List(ctx, vtsList, client.InNamespace(vt.Namespace), labels); err != nil {
+		return resultBuilder.Error(err)
+	}
+
+	unknown, missing, err := auditTopology(ctx, ts, vt, vtkList.Items, vtsList.Items)
+
+	drift := &planetscalev2.TopologyDriftStatus{
+		AuditTime: &metav1.Time{Time: time.Now()},
+		Unknown:   unknown.status(),
+		Missing:   missing.status(),
+	}
+	if err != nil {
+		drift.Error = err.Error()
+	}
+	vt.Status.TopologyDrift = drift
+
+	unknown.updateMetrics(vt.Name, "unknown")
+	missing.updateMetrics(vt.Name, "missing")
+	topologyDriftAuditCount.WithLabelValues(vt.Name, metrics.Result(err)).Inc()
+
+	return resultBuilder.RequeueAfter(*topoDriftAuditInterval)
+}
+
+// auditTopology compares topology records with what the given objects want.
+// It keeps going if some records can't be read, and returns the first error.
+func auditTopology(ctx context.Context, ts *topo.Server, vt *planetscalev2.VitessCluster, keyspaces []planetscalev2.VitessKeyspace, shards []planetscalev2.VitessShard) (unknown, missing *topoDriftRecords, err error) {
+	unknown = &topoDriftRecords{}
+	missing = &topoDriftRecords{}
+	setErr := func(e error) {
+		if err == nil {
+			err = e
+		}
+	}
+
+	// Cells.
+	desiredCells := make(map[string]*planetscalev2.LockserverSpec, len(vt.Spec.Cells))
+	desiredCellNames := sets.NewString()
+	for i := range vt.Spec.Cells {
+		cell := &vt.Spec.Cells[i]
+		desiredCells[cell.Name] = &cell.Lockserver
+		desiredCellNames.Insert(cell.Name)
+	}
+	cellNames, cellErr := ts.GetCellInfoNames(ctx)
+	if cellErr != nil {
+		setErr(fmt.Errorf("failed to list cells: %w", cellErr))
+	} else {
+		unknown.cells = vitesstopo.CellsToPrune(cellNames, desiredCells, vt.Status.OrphanedCells)
+		missing.cells = missingRecords(desiredCellNames, cellNames)
+	}
+	topoCells := sets.NewString(cellNames...)
+
+	// Keyspaces.
+	desiredKeyspaces := sets.NewString()
+	for i := range vt.Spec.Keyspaces {
+		desiredKeyspaces.Insert(vt.Spec.Keyspaces[i].Name)
+	}
+	keyspaceNames, keyspaceErr := ts.GetKeyspaces(ctx)
+	if keyspaceErr != nil {
+		setErr(fmt.Errorf("failed to list keyspaces: %w", keyspaceErr))
+	} else {
+		unknown.keyspaces = vitesstopo.KeyspacesToPrune(keyspaceNames, desiredKeyspaces, vt.Status.OrphanedKeyspaces)
+		missing.keyspaces = missingRecords(desiredKeyspaces, keyspaceNames)
+	}
+	topoKeyspaces := sets.NewString(keyspaceNames...)
+
+	// Shards, as wanted by each VitessKeyspace.
+	for i := range keyspaces {
+		vtk := &keyspaces[i]
+		keyspaceName := vtk.Spec.Name
+		desiredShards := sets.NewString()
+		for name := range vtk.Status.Shards {
+			desiredShards.Insert(name)
+		}
+		var shardNames []string
+		if keyspaceErr == nil && topoKeyspaces.Has(keyspaceName) {
+			names, err := ts.GetShardNames(ctx, keyspaceName)
+			if err != nil && !topo.IsErrType(err, topo.NoNode) {
+				setErr(fmt.Errorf("failed to list shards in keyspace %v: %w", keyspaceName, err))
+				continue
+			}
+			shardNames = names
+		}
+		for _, name := range vitesstopo.ShardsToPrune(shardNames, desiredShards, vtk.Status.OrphanedShards) {
+			unknown.shards = append(unknown.shards, keyspaceName+"/"+name)
+		}
+		for _, name := range missingRecords(desiredShards, shardNames) {
+			missing.shards = append(missing.shards, keyspaceName+"/"+name)
+		}
+	}
+
+	// Tablets, as wanted by each VitessShard.
+	for i := range shards {
+		vts := &shards[i]
+		keyspaceName := vts.Labels[planetscalev2.KeyspaceLabel]
+		tablets, err := ts.GetTabletMapForShard(ctx, keyspaceName, vts.Spec.Name)
+		if err != nil && !topo.IsErrType(err, topo.NoNode) {
+			setErr(fmt.Errorf("failed to get tablets for shard %v/%v: %w", keyspaceName, vts.Spec.Name, err))
+			continue
+		}
+		for name, tabletInfo := range tablets {
+			// Tablets in other cells might be externally managed.
+			if !vts.Spec.CellInCluster(tabletInfo.Alias.GetCell()) {
+				continue
+			}
+			_, desired := vts.Status.Tablets[name]
+			_, orphaned := vts.Status.OrphanedTablets[name]
+			if !desired && !orphaned {
+				unknown.tablets = append(unknown.tablets, name)
+			}
+		}
+		for name := range vts.Status.Tablets {
+			if _, ok := tablets[name]; !ok {
+				missing.tablets = append(missing.tablets, name)
+			}
+		}
+	}
+
+	// SrvKeyspaces in each cell of this cluster that's registered in topology.
+	keyspaceCells := make(map[string]sets.String, len(keyspaces))
+	for i := range keyspaces {
+		keyspaceCells[keyspaces[i].Spec.Name] = sets.NewString(keyspaces[i].Spec.CellNames()...)
+	}
+	for _, cellName := range desiredCellNames.List() {
+		if !topoCells.Has(cellName) {
+			continue
+		}
+		srvKeyspaceNames, err := ts.GetSrvKeyspaceNames(ctx, cellName)
+		if err != nil && !topo.IsErrType(err, topo.NoNode) {
+			setErr(fmt.Errorf("failed to list keyspaces in cell %v: %w", cellName, err))
+			continue
+		}
+		served := sets.NewString(srvKeyspaceNames...)
+		for _, name := range srvKeyspaceNames {
+			if !desiredKeyspaces.Has(name) {
+				unknown.srvKeyspaces = append(unknown.srvKeyspaces, cellName+"/"+name)
+			}
+		}
+		for name, cells := range keyspaceCells {
+			if cells.Has(cellName) && !served.Has(name) {
+				missing.srvKeyspaces = append(missing.srvKeyspaces, cellName+"/"+name)
+			}
+		}
+	}
+
+	return unknown, missing, err
+}
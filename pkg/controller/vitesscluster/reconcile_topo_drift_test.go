@@ -0,0 +1,162 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscluster
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+func TestMissingRecords(t *testing.T) {
+	desired := sets.NewString("commerce", "customer", "lookup")
+	assert.Equal(t, []string{"lookup"}, missingRecords(desired, []string{"customer", "commerce", "unknown"}))
+	assert.Empty(t, missingRecords(desired, []string{"lookup", "customer", "commerce"}))
+}
+
+func TestTopoDriftRecordsStatus(t *testing.T) {
+	records := &topoDriftRecords{
+		cells: []string{"zone2", "zone1"},
+	}
+	for i := 0; i < maxTopoDriftRecords+10; i++ {
+		records.tablets = append(records.tablets, fmt.Sprintf("zone1-%04d", i))
+	}
+
+	status := records.status()
+	assert.Equal(t, []string{"zone1", "zone2"}, status.Cells)
+	assert.Len(t, status.Tablets, maxTopoDriftRecords)
+	assert.Equal(t, "zone1-0000", status.Tablets[0])
+	assert.True(t, status.Truncated)
+	assert.Nil(t, status.Shards)
+	// The original lists are left alone so metrics see the full counts.
+	assert.Equal(t, "zone2", records.cells[0])
+	assert.Len(t, records.tablets, maxTopoDriftRecords+10)
+}
+
+func TestAuditTopology(t *testing.T) {
+	ctx := t.Context()
+
+	// zone3 is registered in topology, but isn't part of the cluster.
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2", "zone3")
+	defer ts.Close()
+
+	vt := &planetscalev2.VitessCluster{}
+	vt.Name = "example"
+	for _, cell := range []string{"zone1", "zone2", "zone4"} {
+		vt.Spec.Cells = append(vt.Spec.Cells, planetscalev2.VitessCellTemplate{Name: cell})
+	}
+	for _, keyspace := range []string{"commerce", "customer"} {
+		vt.Spec.Keyspaces = append(vt.Spec.Keyspaces, planetscalev2.VitessKeyspaceTemplate{Name: keyspace})
+	}
+
+	keyspace := func(name string, shards []string, cells ...string) planetscalev2.VitessKeyspace {
+		vtk := planetscalev2.VitessKeyspace{}
+		vtk.Spec.Name = name
+		var pools []planetscalev2.VitessShardTabletPool
+		for _, cell := range cells {
+			pools = append(pools, planetscalev2.VitessShardTabletPool{Cell: cell})
+		}
+		vtk.Spec.Partitionings = []planetscalev2.VitessKeyspacePartitioning{{
+			Equal: &planetscalev2.VitessKeyspaceEqualPartitioning{
+				ShardTemplate: planetscalev2.VitessShardTemplate{TabletPools: pools},
+			},
+		}}
+		vtk.Status.Shards = map[string]planetscalev2.VitessKeyspaceShardStatus{}
+		for _, shard := range shards {
+			vtk.Status.Shards[shard] = planetscalev2.VitessKeyspaceShardStatus{}
+		}
+		return vtk
+	}
+	keyspaces := []planetscalev2.VitessKeyspace{
+		keyspace("commerce", []string{"-80", "80-"}, "zone1", "zone2"),
+		keyspace("customer", []string{"-"}, "zone1"),
+	}
+
+	vts := planetscalev2.VitessShard{}
+	vts.Labels = map[string]string{planetscalev2.KeyspaceLabel: "commerce"}
+	vts.Spec.Name = "-80"
+	vts.Spec.ZoneMap = map[string]string{"zone1": "", "zone2": "", "zone4": ""}
+	vts.Status.Tablets = map[string]planetscalev2.VitessTabletStatus{
+		"zone1-0000000100": {},
+		"zone1-0000000102": {},
+	}
+	shards := []planetscalev2.VitessShard{vts}
+
+	// Keyspaces and shards.
+	require.NoError(t, ts.CreateKeyspace(ctx, "commerce", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateKeyspace(ctx, "legacy", &topodatapb.Keyspace{}))
+	require.NoError(t, ts.CreateShard(ctx, "commerce", "-80"))
+	require.NoError(t, ts.CreateShard(ctx, "commerce", "-"))
+
+	// Tablets. The one in zone3 is managed outside this cluster, so it's
+	// not reported.
+	for _, alias := range []*topodatapb.TabletAlias{
+		{Cell: "zone1", Uid: 100},
+		{Cell: "zone1", Uid: 101},
+		{Cell: "zone3", Uid: 200},
+	} {
+		require.NoError(t, ts.CreateTablet(ctx, &topodatapb.Tablet{
+			Alias:    alias,
+			Keyspace: "commerce",
+			Shard:    "-80",
+			Type:     topodatapb.TabletType_REPLICA,
+		}))
+	}
+
+	// SrvKeyspaces.
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone1", "commerce", &topodatapb.SrvKeyspace{}))
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone1", "legacy", &topodatapb.SrvKeyspace{}))
+	// zone3 isn't part of the cluster, so its SrvKeyspaces aren't audited.
+	require.NoError(t, ts.UpdateSrvKeyspace(ctx, "zone3", "legacy", &topodatapb.SrvKeyspace{}))
+
+	unknown, missing, err := auditTopology(ctx, ts, vt, keyspaces, shards)
+	require.NoError(t, err)
+
+	assert.Equal(t, planetscalev2.TopologyDriftRecords{
+		Cells:        []string{"zone3"},
+		Keyspaces:    []string{"legacy"},
+		Shards:       []string{"commerce/-"},
+		Tablets:      []string{"zone1-0000000101"},
+		SrvKeyspaces: []string{"zone1/legacy"},
+	}, unknown.status())
+	assert.Equal(t, planetscalev2.TopologyDriftRecords{
+		Cells:        []string{"zone4"},
+		Keyspaces:    []string{"customer"},
+		Shards:       []string{"commerce/80-", "customer/-"},
+		Tablets:      []string{"zone1-0000000102"},
+		SrvKeyspaces: []string{"zone1/customer", "zone2/commerce"},
+	}, missing.status())
+
+	// Records that are being turned down aren't unknown.
+	vt.Status.OrphanedCells = map[string]planetscalev2.OrphanStatus{"zone3": {}}
+	vt.Status.OrphanedKeyspaces = map[string]planetscalev2.OrphanStatus{"legacy": {}}
+	keyspaces[0].Status.OrphanedShards = map[string]planetscalev2.OrphanStatus{"-": {}}
+	shards[0].Status.OrphanedTablets = map[string]planetscalev2.OrphanStatus{"zone1-0000000101": {}}
+
+	unknown, _, err = auditTopology(ctx, ts, vt, keyspaces, shards)
+	require.NoError(t, err)
+	assert.Equal(t, planetscalev2.TopologyDriftRecords{
+		SrvKeyspaces: []string{"zone1/legacy"},
+	}, unknown.status())
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+func TestRegisterCellsAliases(t *testing.T) {
+	ctx := t.Context()
+
+	ts := memorytopo.NewServer(ctx, "zone1", "zone2", "zone3", "zone4")
+	defer ts.Close()
+
+	// Someone else registered an alias for a cell we don't manage.
+	require.NoError(t, ts.CreateCellsAlias(ctx, "external", &topodatapb.CellsAlias{Cells: []string{"zone4"}}))
+
+	vt := &planetscalev2.VitessCluster{}
+	vt.Name = "example"
+	vt.Namespace = "ns"
+	desiredCells := map[string]*planetscalev2.LockserverSpec{}
+	for _, cell := range []string{"zone1", "zone2", "zone3"} {
+		vt.Spec.Cells = append(vt.Spec.Cells, planetscalev2.VitessCellTemplate{Name: cell})
+		desiredCells[cell] = nil
+	}
+	r := &ReconcileVitessCluster{recorder: record.NewFakeRecorder(100)}
+
+	// aliases returns the cells in each registered alias.
+	aliases := func() map[string][]string {
+		current, err := ts.GetCellsAliases(ctx, true)
+		require.NoError(t, err)
+		result := make(map[string][]string, len(current))
+		for alias, cellsAlias := range current {
+			result[alias] = cellsAlias.Cells
+		}
+		return result
+	}
+
+	// Without custom aliases, all cells go in the default alias.
+	require.NoError(t, r.registerCellsAliases(ctx, vt, ts, desiredCells))
+	got := aliases()
+	assert.ElementsMatch(t, []string{"zone1", "zone2", "zone3"}, got[defaultCellsAlias])
+	assert.Equal(t, []string{"zone4"}, got["external"])
+	assert.Equal(t, []string{defaultCellsAlias}, vt.Status.CellsAliases)
+
+	// Custom aliases replace the default alias, but leave the one that
+	// someone else created alone.
+	vt.Spec.CellsAliases = map[string][]string{
+		"east": {"zone1", "zone2"},
+		"west": {"zone3"},
+	}
+	require.NoError(t, r.registerCellsAliases(ctx, vt, ts, desiredCells))
+	assert.Equal(t, map[string][]string{
+		"east":     {"zone1", "zone2"},
+		"west":     {"zone3"},
+		"external": {"zone4"},
+	}, aliases())
+	assert.Equal(t, []string{"east", "west"}, vt.Status.CellsAliases)
+
+	// A cell can move from one alias to another.
+	vt.Spec.CellsAliases = map[string][]string{
+		"east": {"zone1"},
+		"west": {"zone2", "zone3"},
+	}
+	require.NoError(t, r.registerCellsAliases(ctx, vt, ts, desiredCells))
+	assert.Equal(t, map[string][]string{
+		"east":     {"zone1"},
+		"west":     {"zone2", "zone3"},
+		"external": {"zone4"},
+	}, aliases())
+
+	// An alias that's removed from the spec is deleted.
+	vt.Spec.CellsAliases = map[string][]string{
+		"west": {"zone2", "zone3"},
+	}
+	require.NoError(t, r.registerCellsAliases(ctx, vt, ts, desiredCells))
+	assert.Equal(t, map[string][]string{
+		"west":     {"zone2", "zone3"},
+		"external": {"zone4"},
+	}, aliases())
+	assert.Equal(t, []string{"west"}, vt.Status.CellsAliases)
+	_, err := ts.GetCellsAlias(ctx, "east", true)
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "GetCellsAlias(east) error = %v; want NoNode", err)
+}
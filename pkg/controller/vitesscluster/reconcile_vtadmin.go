@@ -30,6 +30,7 @@ import (
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
 	"planetscale.dev/vitess-operator/pkg/operator/conditions"
+	"planetscale.dev/vitess-operator/pkg/operator/internaltls"
 	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
 	"planetscale.dev/vitess-operator/pkg/operator/results"
 	"planetscale.dev/vitess-operator/pkg/operator/update"
@@ -169,6 +170,12 @@ func (r *ReconcileVitessCluster) vtadminSpecs(ctx context.Context, vt *planetsca
 		}
 	}
 
+	internalTLS := internalTLSSpec(vt)
+	internalTLSHash, err := internaltls.ContentHash(ctx, r.client, vt.Namespace, internalTLS)
+	if err != nil {
+		return nil, err
+	}
+
 	// Make a vtadmin Deployment spec for each cell.
 	specs := make([]*vtadmin.Spec, 0, len(cells))
 	for idx, cell := range cells {
@@ -219,9 +226,10 @@ func (r *ReconcileVitessCluster) vtadminSpecs(ctx context.Context, vt *planetsca
 			ExtraVolumeMounts: vt.Spec.VtAdmin.ExtraVolumeMounts,
 			InitContainers:    vt.Spec.VtAdmin.InitContainers,
 			SidecarContainers: vt.Spec.VtAdmin.SidecarContainers,
-			Annotations:       vt.Spec.VtAdmin.Annotations,
+			Annotations:       internaltls.Annotations(vt.Spec.VtAdmin.Annotations, internalTLSHash),
 			ExtraLabels:       vt.Spec.VtAdmin.ExtraLabels,
 			Tolerations:       vt.Spec.VtAdmin.Tolerations,
+			InternalTLS:       internalTLS,
 		})
 	}
 	return specs, nil
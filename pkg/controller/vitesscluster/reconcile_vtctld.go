@@ -23,13 +23,16 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
 	"planetscale.dev/vitess-operator/pkg/operator/conditions"
+	"planetscale.dev/vitess-operator/pkg/operator/internaltls"
 	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
+	"planetscale.dev/vitess-operator/pkg/operator/pdb"
 	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
 	"planetscale.dev/vitess-operator/pkg/operator/results"
 	"planetscale.dev/vitess-operator/pkg/operator/vtctld"
@@ -67,8 +70,36 @@ func (r *ReconcileVitessCluster) reconcileVtctld(ctx context.Context, vt *planet
 		resultBuilder.Error(err)
 	}
 
+	// Reconcile vtctld PodDisruptionBudget, which covers vtctld in all cells.
+	key = client.ObjectKey{Namespace: vt.Namespace, Name: vtctld.PDBName(vt.Name)}
+	wantPDB := pdb.Enabled(vt.Spec.VitessDashboard.PodDisruptionBudget)
+	err = r.reconciler.ReconcileObject(ctx, vt, key, labels, wantPDB, reconciler.Strategy{
+		Kind: &policyv1.PodDisruptionBudget{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return pdb.NewPDB(key, pdb.NewSpec(labels, vt.Spec.VitessDashboard.PodDisruptionBudget))
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			newObj := obj.(*policyv1.PodDisruptionBudget)
+			pdb.UpdatePDB(newObj, pdb.NewSpec(labels, vt.Spec.VitessDashboard.PodDisruptionBudget))
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
 	// Reconcile vtctld Deployments.
-	specs := r.vtctldSpecs(vt, labels)
+	internalTLS := internalTLSSpec(vt)
+	internalTLSHash, err := internaltls.ContentHash(ctx, r.client, vt.Namespace, internalTLS)
+	if err != nil {
+		// Record error and return, to avoid generating a Deployment based on incomplete information.
+		return resultBuilder.Error(err)
+	}
+	topoTLSHash, err := lockserver.ClientTLSContentHash(ctx, r.client, vt.Namespace, globalLockserverParams(vt, planetscalev2.LockserverMigrationSwitchingWriters))
+	if err != nil {
+		return resultBuilder.Error(err)
+	}
+	specs := r.vtctldSpecs(vt, labels, internalTLS, internalTLSHash, topoTLSHash)
 
 	// Generate keys (object names) for all desired vtctld Deployments.
 	// Keep a map back from generated names to the vtctld specs.
@@ -122,7 +153,7 @@ func (r *ReconcileVitessCluster) reconcileVtctld(ctx context.Context, vt *planet
 	return resultBuilder.Result()
 }
 
-func (r *ReconcileVitessCluster) vtctldSpecs(vt *planetscalev2.VitessCluster, parentLabels map[string]string) []*vtctld.Spec {
+func (r *ReconcileVitessCluster) vtctldSpecs(vt *planetscalev2.VitessCluster, parentLabels map[string]string, internalTLS *planetscalev2.VitessInternalTLSSpec, internalTLSHash, topoTLSHash string) []*vtctld.Spec {
 	var cells []*planetscalev2.VitessCellTemplate
 	if len(vt.Spec.VitessDashboard.Cells) != 0 {
 		// Deploy only to the specified cells.
@@ -141,7 +172,7 @@ func (r *ReconcileVitessCluster) vtctldSpecs(vt *planetscalev2.VitessCluster, pa
 		}
 	}
 
-	glsParams := lockserver.GlobalConnectionParams(&vt.Spec.GlobalLockserver, vt.Namespace, vt.Name)
+	glsParams := globalLockserverParams(vt, planetscalev2.LockserverMigrationSwitchingWriters)
 
 	// Make a vtctld Deployment spec for each cell.
 	specs := make([]*vtctld.Spec, 0, len(cells))
@@ -186,11 +217,12 @@ func (r *ReconcileVitessCluster) vtctldSpecs(vt *planetscalev2.VitessCluster, pa
 			ExtraVolumeMounts: vt.Spec.VitessDashboard.ExtraVolumeMounts,
 			InitContainers:    vt.Spec.VitessDashboard.InitContainers,
 			SidecarContainers: vt.Spec.VitessDashboard.SidecarContainers,
-			Annotations:       vt.Spec.VitessDashboard.Annotations,
+			Annotations:       lockserver.ClientTLSAnnotations(internaltls.Annotations(vt.Spec.VitessDashboard.Annotations, internalTLSHash), topoTLSHash),
 			ExtraLabels:       vt.Spec.VitessDashboard.ExtraLabels,
 			Tolerations:       vt.Spec.VitessDashboard.Tolerations,
 			BackupEngine:      backupEngine,
 			BackupLocation:    backupLocation,
+			InternalTLS:       internalTLS,
 		})
 
 	}
@@ -45,12 +45,14 @@ func (r *ReconcileVitessCluster) reconcileVtgate(ctx context.Context, vt *planet
 
 		New: func(key client.ObjectKey) runtime.Object {
 			svc := vtgate.NewService(key, labels)
+			vtgate.UpdateServiceTopology(svc, vt.Spec.GatewayTopology)
 			update.ServiceOverrides(svc, vt.Spec.GatewayService)
 			return svc
 		},
 		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
 			svc := obj.(*corev1.Service)
 			vtgate.UpdateService(svc, labels)
+			vtgate.UpdateServiceTopology(svc, vt.Spec.GatewayTopology)
 			update.InPlaceServiceOverrides(svc, vt.Spec.GatewayService)
 		},
 		Status: func(key client.ObjectKey, obj runtime.Object) {
@@ -63,5 +65,62 @@ func (r *ReconcileVitessCluster) reconcileVtgate(ctx context.Context, vt *planet
 		resultBuilder.Error(err)
 	}
 
+	// Reconcile per-zone vtgate Services.
+	if err := r.reconcileVtgateZoneServices(ctx, vt); err != nil {
+		resultBuilder.Error(err)
+	}
+
 	return resultBuilder.Result()
 }
+
+// reconcileVtgateZoneServices maintains a headless Service for each zone that
+// selects only the vtgates of cells in that zone.
+func (r *ReconcileVitessCluster) reconcileVtgateZoneServices(ctx context.Context, vt *planetscalev2.VitessCluster) error {
+	labels := map[string]string{
+		planetscalev2.ClusterLabel:   vt.Name,
+		planetscalev2.ComponentLabel: planetscalev2.VtgateZoneComponentName,
+	}
+
+	// Map each Service to its zone. Several cells may share a zone.
+	keys := []client.ObjectKey{}
+	zones := map[client.ObjectKey]string{}
+	if vtgate.ZoneServicesEnabled(vt.Spec.GatewayTopology) {
+		for i := range vt.Spec.Cells {
+			zone := vt.Spec.Cells[i].Zone
+			if zone == "" {
+				continue
+			}
+			key := client.ObjectKey{Namespace: vt.Namespace, Name: vtgate.ZoneServiceName(vt.Name, zone)}
+			if _, ok := zones[key]; ok {
+				continue
+			}
+			keys = append(keys, key)
+			zones[key] = zone
+		}
+	}
+	selector := func(key client.ObjectKey) map[string]string {
+		return map[string]string{
+			planetscalev2.ClusterLabel:   vt.Name,
+			planetscalev2.ComponentLabel: planetscalev2.VtgateComponentName,
+			planetscalev2.ZoneLabel:      zones[key],
+		}
+	}
+
+	return r.reconciler.ReconcileObjectSet(ctx, vt, keys, labels, reconciler.Strategy{
+		Kind: &corev1.Service{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return vtgate.NewZoneService(key, labels, selector(key))
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			svc := obj.(*corev1.Service)
+			vtgate.UpdateZoneService(svc, labels, selector(key))
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			if vt.Status.GatewayZoneServiceNames == nil {
+				vt.Status.GatewayZoneServiceNames = make(map[string]string, len(keys))
+			}
+			vt.Status.GatewayZoneServiceNames[zones[key]] = key.Name
+		},
+	})
+}
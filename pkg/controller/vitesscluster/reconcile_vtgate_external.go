@@ -0,0 +1,106 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesscluster
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/vtgate"
+)
+
+// reconcileVtgateExternal maintains the objects that expose the vtgates of
+// all cells outside the Kubernetes cluster through a single endpoint, if any
+// are requested.
+func (r *ReconcileVitessCluster) reconcileVtgateExternal(ctx context.Context, vt *planetscalev2.VitessCluster) (reconcile.Result, error) {
+	resultBuilder := &results.Builder{}
+	external := vt.Spec.GatewayExternal
+
+	key := client.ObjectKey{Namespace: vt.Namespace, Name: vtgate.ClusterExternalServiceName(vt.Name)}
+	labels := map[string]string{
+		planetscalev2.ClusterLabel:   vt.Name,
+		planetscalev2.ComponentLabel: planetscalev2.VtgateComponentName,
+	}
+
+	status := &planetscalev2.VitessGatewayExternalStatus{}
+	if external != nil {
+		vt.Status.GatewayExternal = status
+	}
+
+	wantService := external != nil && external.Service != nil
+	err := r.reconciler.ReconcileObject(ctx, vt, key, labels, wantService, reconciler.Strategy{
+		Kind: &corev1.Service{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return vtgate.NewExternalService(key, labels, external.Service)
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			svc := obj.(*corev1.Service)
+			vtgate.UpdateExternalService(svc, labels, external.Service)
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			svc := obj.(*corev1.Service)
+			vtgate.UpdateExternalStatus(status, svc, external.Service)
+		},
+	})
+	if err != nil {
+		// Record error but continue.
+		resultBuilder.Error(err)
+	}
+
+	// Only look for a TCPRoute to clean up if the CRD is installed.
+	wantRoute := external != nil && external.TCPRoute != nil
+	if !wantRoute {
+		installed, err := vtgate.TCPRouteInstalled(r.client.RESTMapper())
+		if err != nil {
+			return resultBuilder.Error(err)
+		}
+		if !installed {
+			return resultBuilder.Result()
+		}
+	}
+	// The route targets the regular vtgate Service, which doesn't depend on
+	// whether an external Service exists.
+	serviceName := vtgate.ClusterServiceName(vt.Name)
+	err = r.reconciler.ReconcileObject(ctx, vt, key, labels, wantRoute, reconciler.Strategy{
+		Kind: vtgate.NewTCPRouteKind(),
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return vtgate.NewTCPRoute(key, labels, external.TCPRoute, serviceName)
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			route := obj.(*unstructured.Unstructured)
+			vtgate.UpdateTCPRoute(route, labels, external.TCPRoute, serviceName)
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			status.TCPRouteName = key.Name
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	return resultBuilder.Result()
+}
@@ -25,6 +25,8 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -38,6 +40,7 @@ import (
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
 	"planetscale.dev/vitess-operator/pkg/operator/environment"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
 	"planetscale.dev/vitess-operator/pkg/operator/metrics"
 	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
 	"planetscale.dev/vitess-operator/pkg/operator/results"
@@ -51,6 +54,7 @@ const (
 var (
 	maxConcurrentReconciles = flag.Int("vitesscluster_concurrent_reconciles", 10, "the maximum number of different VitessClusters to reconcile concurrently")
 	resyncPeriod            = flag.Duration("vitesscluster_resync_period", 30*time.Minute, "reconcile vitessclusters with this period even if no Kubernetes events occur")
+	topoDriftAuditInterval  = flag.Duration("vitesscluster_topo_drift_audit_interval", 10*time.Minute, "compare Vitess topology with each vitesscluster this often and report differences in status; 0 disables the audit")
 )
 
 var log = logrus.WithField("controller", "VitessCluster")
@@ -58,12 +62,17 @@ var log = logrus.WithField("controller", "VitessCluster")
 // watchResources should contain all the resource types that this controller creates.
 var watchResources = []client.Object{
 	&corev1.Service{},
+	&corev1.Secret{},
 	&appsv1.Deployment{},
+	&policyv1.PodDisruptionBudget{},
+	&networkingv1.NetworkPolicy{},
 
 	&planetscalev2.VitessCell{},
 	&planetscalev2.VitessKeyspace{},
 	&planetscalev2.VitessBackupStorage{},
 	&planetscalev2.EtcdLockserver{},
+	&planetscalev2.ConsulLockserver{},
+	&planetscalev2.ZkLockserver{},
 }
 
 // Add creates a new VitessCluster Controller and adds it to the Manager. The Manager will set fields on the Controller
@@ -116,6 +125,14 @@ func add(mgr manager.Manager, r *ReconcileVitessCluster) error {
 		}
 	}
 
+	// Watch for changes in Secrets, which we don't own, and requeue associated VitessClusters.
+	scm := &secretClustersMapper{
+		client: mgr.GetClient(),
+	}
+	if err := c.Watch(source.Kind(mgr.GetCache(), &corev1.Secret{}, handler.TypedEnqueueRequestsFromMapFunc[*corev1.Secret](scm.Map))); err != nil {
+		return err
+	}
+
 	// Periodically resync even when no Kubernetes events have come in.
 	if err := c.Watch(r.resync.WatchSource()); err != nil {
 		return err
@@ -170,17 +187,48 @@ func (r *ReconcileVitessCluster) Reconcile(cctx context.Context, request reconci
 	// Reset status, since that's all out of date info that we will recompute now.
 	oldStatus := vt.Status
 	vt.Status = planetscalev2.NewVitessClusterStatus()
+	// Remember how far a global lockserver migration has progressed.
+	vt.Status.GlobalLockserverMigration = oldStatus.GlobalLockserverMigration.DeepCopy()
+	// Remember which cells aliases we registered, so we can remove them later.
+	vt.Status.CellsAliases = oldStatus.CellsAliases
+	// Keep the last topology drift report until the next audit.
+	vt.Status.TopologyDrift = oldStatus.TopologyDrift.DeepCopy()
+	// Remember which topology records were already waiting for prune
+	// approval, so we only announce new ones, and which approvals were
+	// already used up.
+	vt.Status.PendingPruneCells = oldStatus.PendingPruneCells
+	vt.Status.PendingPruneKeyspaces = oldStatus.PendingPruneKeyspaces
+	vt.Status.UsedPruneApprovals = oldStatus.UsedPruneApprovals
 
 	// Materialize all hard-coded default values into the object.
 	// TODO(enisoc): Use versioned defaults when operator-sdk supports mutating webhooks.
 	planetscalev2.DefaultVitessCluster(vt)
 
+	// Create/update certificates for etcd lockservers, if managed by the
+	// operator. This comes before anything that mounts them.
+	lockserverTLSResult, err := r.reconcileLockserverTLS(ctx, vt)
+	resultBuilder.Merge(lockserverTLSResult, err)
+
 	// Create/update global etcd, if requested.
 	if err := r.reconcileGlobalEtcd(ctx, vt); err != nil {
 		// Record result but continue to reconcile cells.
 		resultBuilder.Error(err)
 	}
 
+	// Create/update global Consul or ZooKeeper, if requested.
+	// Each kind is reconciled even if it's not wanted, so we notice if
+	// the cluster switched away from it.
+	for _, ls := range lockserver.ServerSets(&vt.Spec.GlobalLockserver) {
+		if err := r.reconcileGlobalServerSet(ctx, vt, ls); err != nil {
+			resultBuilder.Error(err)
+		}
+	}
+
+	// Move components to a new global lockserver, if requested.
+	// This comes before anything that passes the global lockserver params on.
+	migrationResult, err := r.reconcileGlobalLockserverMigration(ctx, vt)
+	resultBuilder.Merge(migrationResult, err)
+
 	// Create/update VitessBackupStorage objects.
 	if err := r.reconcileBackupStorage(ctx, vt); err != nil {
 		resultBuilder.Error(err)
@@ -191,6 +239,11 @@ func (r *ReconcileVitessCluster) Reconcile(cctx context.Context, request reconci
 		resultBuilder.Error(err)
 	}
 
+	// Create/update the certificate for gRPC between components, if managed
+	// by the operator. This comes before anything that mounts it.
+	internalTLSResult, err := r.reconcileInternalTLS(ctx, vt)
+	resultBuilder.Merge(internalTLSResult, err)
+
 	// Create/update desired VitessCells.
 	if err := r.reconcileCells(ctx, vt); err != nil {
 		resultBuilder.Error(err)
@@ -205,6 +258,14 @@ func (r *ReconcileVitessCluster) Reconcile(cctx context.Context, request reconci
 	vtgateResult, err := r.reconcileVtgate(ctx, vt)
 	resultBuilder.Merge(vtgateResult, err)
 
+	// Create/update objects that expose vtgate outside the Kubernetes cluster.
+	vtgateExternalResult, err := r.reconcileVtgateExternal(ctx, vt)
+	resultBuilder.Merge(vtgateExternalResult, err)
+
+	// Create/update the CA for operator-managed vtgate certificates.
+	gatewayCAResult, err := r.reconcileGatewayCA(ctx, vt)
+	resultBuilder.Merge(gatewayCAResult, err)
+
 	// Create/update vttablet service.
 	vttabletResult, err := r.reconcileVttablet(ctx, vt)
 	resultBuilder.Merge(vttabletResult, err)
@@ -217,6 +278,10 @@ func (r *ReconcileVitessCluster) Reconcile(cctx context.Context, request reconci
 	vtadminResult, err := r.reconcileVtadmin(ctx, vt)
 	resultBuilder.Merge(vtadminResult, err)
 
+	// Create/update NetworkPolicies for cluster-wide components, if enabled.
+	networkPolicyResult, err := r.reconcileNetworkPolicies(ctx, vt)
+	resultBuilder.Merge(networkPolicyResult, err)
+
 	// Create/update Vitess topology records for cells as needed.
 	topoResult, err := r.reconcileTopology(ctx, vt)
 	resultBuilder.Merge(topoResult, err)
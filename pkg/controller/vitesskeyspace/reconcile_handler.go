@@ -62,7 +62,7 @@ func (r *reconcileHandler) tsInit(ctx context.Context) error {
 	}
 
 	// We need to initialize for the first time if we got here.
-	ts, err := toposerver.Open(ctx, r.vtk.Spec.GlobalLockserver)
+	ts, err := toposerver.Open(ctx, r.vtk.Namespace, r.vtk.Spec.GlobalLockserver)
 	if err != nil {
 		r.recorder.Eventf(r.vtk, v1.EventTypeWarning, "TopoConnectFailed", "failed to connect to global lockserver: %v", err)
 		// Give the lockserver some time to come up.
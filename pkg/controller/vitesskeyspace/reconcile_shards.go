@@ -267,21 +267,24 @@ func newVitessShard(key client.ObjectKey, vtk *planetscalev2.VitessKeyspace, par
 			Annotations: template.Annotations,
 		},
 		Spec: planetscalev2.VitessShardSpec{
-			VitessShardTemplate:    *template,
-			GlobalLockserver:       vtk.Spec.GlobalLockserver,
-			VitessOrchestrator:     vtk.Spec.VitessOrchestrator,
-			Images:                 vtk.Spec.Images,
-			ImagePullPolicies:      vtk.Spec.ImagePullPolicies,
-			ImagePullSecrets:       vtk.Spec.ImagePullSecrets,
-			Name:                   shard.KeyRange.String(),
-			DatabaseName:           vtk.Spec.DatabaseName,
-			KeyRange:               shard.KeyRange,
-			ZoneMap:                vtk.Spec.ZoneMap,
-			BackupLocations:        vtk.Spec.BackupLocations,
-			BackupEngine:           vtk.Spec.BackupEngine,
-			ExtraVitessFlags:       vtk.Spec.ExtraVitessFlags,
-			TopologyReconciliation: vtk.Spec.TopologyReconciliation,
-			UpdateStrategy:         vtk.Spec.UpdateStrategy,
+			VitessShardTemplate:     *template,
+			GlobalLockserver:        vtk.Spec.GlobalLockserver,
+			VitessOrchestrator:      vtk.Spec.VitessOrchestrator,
+			PauseVitessOrchestrator: vtk.Spec.PauseVitessOrchestrator,
+			Images:                  vtk.Spec.Images,
+			ImagePullPolicies:       vtk.Spec.ImagePullPolicies,
+			ImagePullSecrets:        vtk.Spec.ImagePullSecrets,
+			Name:                    shard.KeyRange.String(),
+			DatabaseName:            vtk.Spec.DatabaseName,
+			KeyRange:                shard.KeyRange,
+			ZoneMap:                 vtk.Spec.ZoneMap,
+			BackupLocations:         vtk.Spec.BackupLocations,
+			BackupEngine:            vtk.Spec.BackupEngine,
+			ExtraVitessFlags:        vtk.Spec.ExtraVitessFlags,
+			InternalTLS:             vtk.Spec.InternalTLS,
+			NetworkPolicies:         vtk.Spec.NetworkPolicies,
+			TopologyReconciliation:  vtk.Spec.TopologyReconciliation,
+			UpdateStrategy:          vtk.Spec.UpdateStrategy,
 		},
 	}
 }
@@ -306,6 +309,13 @@ func updateVitessShardInPlace(key client.ObjectKey, vts *planetscalev2.VitessSha
 	// Switching update strategies should always take effect immediately.
 	vts.Spec.UpdateStrategy = newShard.Spec.UpdateStrategy
 
+	// NetworkPolicies don't touch any Pods, so they're always safe to update.
+	vts.Spec.NetworkPolicies = newShard.Spec.NetworkPolicies
+
+	// vtorc must stop as soon as a lockserver migration needs it to, and come
+	// back as soon as it's done.
+	vts.Spec.PauseVitessOrchestrator = newShard.Spec.PauseVitessOrchestrator
+
 	// For now, only disk size & annotations are safe to update in place.
 	// However, only update disk size immediately if specified to.
 	if *vts.Spec.UpdateStrategy.Type == planetscalev2.ExternalVitessClusterUpdateStrategyType {
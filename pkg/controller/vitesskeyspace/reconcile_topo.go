@@ -39,6 +39,9 @@ const (
 func (r *reconcileHandler) reconcileTopology(ctx context.Context) (reconcile.Result, error) {
 	resultBuilder := &results.Builder{}
 
+	// Keep the used approvals even if we don't prune this time.
+	r.vtk.Status.UsedPruneApprovals = r.oldStatus.UsedPruneApprovals
+
 	if *r.vtk.Spec.TopologyReconciliation.PruneShards {
 		err := r.tsInit(ctx)
 		if err != nil {
@@ -54,15 +57,19 @@ func (r *reconcileHandler) reconcileTopology(ctx context.Context) (reconcile.Res
 			desiredShards.Insert(k)
 		}
 
-		result, err := vitesstopo.PruneShards(ctx, vitesstopo.PruneShardsParams{
+		status, result, err := vitesstopo.PruneShards(ctx, vitesstopo.PruneShardsParams{
 			EventObj:       r.vtk,
 			TopoServer:     r.ts.Server,
 			Recorder:       r.recorder,
 			KeyspaceName:   r.vtk.Spec.Name,
 			DesiredShards:  desiredShards,
 			OrphanedShards: r.vtk.Status.OrphanedShards,
+			Approval: vitesstopo.NewPruneApproval(r.vtk, vitesstopo.ApprovePruneShardsAnnotation,
+				*r.vtk.Spec.TopologyReconciliation.PruneShardsDryRun, r.oldStatus.UsedPruneApprovals, r.oldStatus.PendingPruneShards),
 		})
 		resultBuilder.Merge(result, err)
+		r.vtk.Status.PendingPruneShards = status.Pending
+		vitesstopo.RecordUsedApprovals(&r.vtk.Status.UsedPruneApprovals, vitesstopo.ApprovePruneShardsAnnotation, status.Used)
 	}
 
 	return resultBuilder.Result()
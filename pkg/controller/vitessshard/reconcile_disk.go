@@ -48,7 +48,7 @@ func (r *ReconcileVitessShard) reconcileDisk(ctx context.Context, vts *planetsca
 			continue
 		}
 
-		requestedDiskQuantity, ok := tabletPool.DataVolumeClaimTemplate.Resources.Requests[v1.ResourceStorage]
+		poolDiskQuantity, ok := tabletPool.DataVolumeClaimTemplate.Resources.Requests[v1.ResourceStorage]
 		if !ok {
 			continue
 		}
@@ -74,6 +74,9 @@ func (r *ReconcileVitessShard) reconcileDisk(ctx context.Context, vts *planetsca
 				return resultBuilder.Error(err)
 			}
 
+			// The disk autoscaler may have grown this PVC beyond the pool's requested size.
+			requestedDiskQuantity := desiredDataVolumeSize(poolDiskQuantity, pvc)
+
 			// If the PVC's current size is the same as the requested size, continue.
 			currentDisk := pvc.Status.Capacity[v1.ResourceStorage]
 			if currentDisk.Value() == requestedDiskQuantity.Value() {
@@ -0,0 +1,220 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitessshard
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/volumestats"
+	"planetscale.dev/vitess-operator/pkg/operator/vttablet"
+)
+
+const (
+	// pvcAutoscaledSizeAnnotation records the data volume size chosen by the
+	// disk autoscaler. It takes precedence over the tablet pool's requested
+	// size whenever it's larger.
+	pvcAutoscaledSizeAnnotation = "planetscale.com/autoscaled-size"
+	// pvcAutoscaledTimeAnnotation records when the disk autoscaler last grew
+	// the data volume, so we can enforce the cooldown period.
+	pvcAutoscaledTimeAnnotation = "planetscale.com/autoscaled-time"
+)
+
+/*
+reconcileDiskAutoscaler grows tablet data volumes whose filesystem usage has
+crossed the threshold configured in the tablet pool's dataVolumeAutoscaler.
+
+We only raise the storage request on the PVC and record the new size in an
+annotation. From there, the volume goes through the same expansion path as a
+change to the pool's dataVolumeClaimTemplate: reconcileTablets treats the
+autoscaled size as the desired size, and reconcileDisk waits for the filesystem
+resize and cascades the tablet restarts if they're needed.
+*/
+func (r *ReconcileVitessShard) reconcileDiskAutoscaler(ctx context.Context, vts *planetscalev2.VitessShard) (reconcile.Result, error) {
+	resultBuilder := &results.Builder{}
+
+	// Growing a volume is a disk change, so it follows the same update strategy as disk resizes.
+	if *vts.Spec.UpdateStrategy.Type != planetscalev2.ImmediateVitessClusterUpdateStrategyType {
+		if vts.Spec.UpdateStrategy.External == nil {
+			return resultBuilder.Result()
+		} else if !vts.Spec.UpdateStrategy.External.ResourceChangesAllowed(corev1.ResourceStorage) {
+			return resultBuilder.Result()
+		}
+	}
+
+	// Usage is fetched once for the whole namespace, and only if we need it.
+	// If the custom metrics API doesn't serve it, we fall back to asking the
+	// kubelet of each node that runs one of our tablets.
+	var usage map[string]volumestats.Usage
+	var nodeUsage map[string]map[string]volumestats.Usage
+	var tabletPods map[string]*corev1.Pod
+
+	for i := range vts.Spec.TabletPools {
+		tabletPool := &vts.Spec.TabletPools[i]
+		autoscaler := tabletPool.DataVolumeAutoscaler
+		if autoscaler == nil || tabletPool.DataVolumeClaimTemplate == nil {
+			continue
+		}
+
+		if tabletPods == nil {
+			var err error
+			tabletPods, err = r.tabletPodsFromShard(ctx, vts)
+			if err != nil {
+				return resultBuilder.Error(err)
+			}
+		}
+
+		// As in reconcileDisk, pools with the same type in the same cell may
+		// see each other's tablets. We accept that for simplicity.
+		poolTablets, err := tabletKeysForPool(vts, tabletPool.Cell, tabletPool.Type)
+		if err != nil {
+			return resultBuilder.Error(err)
+		}
+
+		for _, tabletKey := range poolTablets {
+			pod, ok := tabletPods[tabletKey]
+			if !ok {
+				continue
+			}
+
+			pvc, err := r.claimForTabletPod(ctx, pod)
+			if apierrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return resultBuilder.Error(err)
+			}
+			if pvc.DeletionTimestamp != nil {
+				continue
+			}
+
+			if usage == nil {
+				usage, err = r.volumeStats.PVCUsage(ctx, vts.Namespace)
+				if err != nil {
+					// Remember the failure so we don't retry for every tablet.
+					usage = map[string]volumestats.Usage{}
+					nodeUsage = map[string]map[string]volumestats.Usage{}
+				}
+			}
+			pvcUsage, ok := usage[pvc.Name]
+			if !ok && nodeUsage != nil && pod.Spec.NodeName != "" {
+				if _, fetched := nodeUsage[pod.Spec.NodeName]; !fetched {
+					nodeUsage[pod.Spec.NodeName], err = r.volumeStats.NodePVCUsage(ctx, pod.Spec.NodeName, vts.Namespace)
+					if err != nil {
+						r.recorder.Eventf(vts, corev1.EventTypeWarning, "DiskUsageUnknown", "Failed to get volume usage for tablet %v: %v", tabletKey, err)
+					}
+				}
+				pvcUsage, ok = nodeUsage[pod.Spec.NodeName][pvc.Name]
+			}
+			if !ok || pvcUsage.Percent() < int64(*autoscaler.UsageThresholdPercent) {
+				continue
+			}
+
+			// Don't stack a new expansion on top of one that's still in progress.
+			requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+			if requested.Cmp(capacity) > 0 {
+				continue
+			}
+
+			// Give the last expansion time to show up in the usage we observe.
+			if lastScaled, err := time.Parse(time.RFC3339, pvc.Annotations[pvcAutoscaledTimeAnnotation]); err == nil {
+				if time.Since(lastScaled) < time.Duration(*autoscaler.CooldownSeconds)*time.Second {
+					continue
+				}
+			}
+
+			newSize, ok := nextAutoscaledSize(capacity, autoscaler.Increment, autoscaler.MaxSize)
+			if !ok {
+				r.recorder.Eventf(vts, corev1.EventTypeWarning, "DiskAutoscalerAtMaxSize", "Data volume %v is %v%% full, but it's already at the autoscaler's max size %v.", pvc.Name, pvcUsage.Percent(), autoscaler.MaxSize.String())
+				continue
+			}
+
+			if err := r.growDataVolume(ctx, pvc, newSize); err != nil {
+				r.recorder.Eventf(vts, corev1.EventTypeWarning, "UpdateFailed", "failed to grow data volume %v: %v", pvc.Name, err)
+				resultBuilder.Error(err)
+				continue
+			}
+			r.recorder.Eventf(vts, corev1.EventTypeNormal, "DiskAutoscaled", "Growing data volume %v from %v to %v because it's %v%% full.", pvc.Name, capacity.String(), newSize.String(), pvcUsage.Percent())
+		}
+	}
+
+	return resultBuilder.Result()
+}
+
+func (r *ReconcileVitessShard) growDataVolume(ctx context.Context, pvc *corev1.PersistentVolumeClaim, newSize resource.Quantity) error {
+	if pvc.Annotations == nil {
+		pvc.Annotations = map[string]string{}
+	}
+	pvc.Annotations[pvcAutoscaledSizeAnnotation] = newSize.String()
+	pvc.Annotations[pvcAutoscaledTimeAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = newSize
+	return r.client.Update(ctx, pvc)
+}
+
+// nextAutoscaledSize returns the size to grow a volume to from its current
+// capacity, and false if it can't be grown any further.
+func nextAutoscaledSize(capacity, increment, maxSize resource.Quantity) (resource.Quantity, bool) {
+	newSize := capacity.DeepCopy()
+	newSize.Add(increment)
+	if newSize.Cmp(maxSize) > 0 {
+		newSize = maxSize.DeepCopy()
+	}
+	if newSize.Cmp(capacity) <= 0 {
+		return resource.Quantity{}, false
+	}
+	return newSize, true
+}
+
+// desiredDataVolumeSize returns the larger of the requested data volume size
+// and the size previously chosen for this PVC by the disk autoscaler.
+func desiredDataVolumeSize(requested resource.Quantity, pvc *corev1.PersistentVolumeClaim) resource.Quantity {
+	autoscaled, err := resource.ParseQuantity(pvc.Annotations[pvcAutoscaledSizeAnnotation])
+	if err != nil || autoscaled.Cmp(requested) <= 0 {
+		return requested
+	}
+	return autoscaled
+}
+
+// applyAutoscaledDataVolumeSize raises the data volume size in a tablet spec
+// to the size chosen by the disk autoscaler, if there is one.
+func (r *ReconcileVitessShard) applyAutoscaledDataVolumeSize(ctx context.Context, key client.ObjectKey, tablet *vttablet.Spec) {
+	requested, ok := tablet.DataVolumePVCSpec.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return
+	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.client.Get(ctx, key, pvc); err != nil {
+		return
+	}
+	desired := desiredDataVolumeSize(requested, pvc)
+	if desired.Cmp(requested) == 0 {
+		return
+	}
+
+	// The spec is shared with the tablet pool, so make a copy before changing it.
+	pvcSpec := tablet.DataVolumePVCSpec.DeepCopy()
+	pvcSpec.Resources.Requests[corev1.ResourceStorage] = desired
+	tablet.DataVolumePVCSpec = pvcSpec
+}
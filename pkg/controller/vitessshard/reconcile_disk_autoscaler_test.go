@@ -0,0 +1,247 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitessshard
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/volumestats"
+)
+
+// fakeVolumeStats returns the same usage for every namespace. If metrics is
+// nil, it behaves as if no custom metrics adapter was installed.
+type fakeVolumeStats struct {
+	metrics map[string]volumestats.Usage
+	nodes   map[string]map[string]volumestats.Usage
+}
+
+func (f fakeVolumeStats) PVCUsage(ctx context.Context, namespace string) (map[string]volumestats.Usage, error) {
+	if f.metrics == nil {
+		return nil, errors.New("the server could not find the requested resource")
+	}
+	return f.metrics, nil
+}
+
+func (f fakeVolumeStats) NodePVCUsage(ctx context.Context, nodeName, namespace string) (map[string]volumestats.Usage, error) {
+	usage, ok := f.nodes[nodeName]
+	if !ok {
+		return nil, errors.New("node not found")
+	}
+	return usage, nil
+}
+
+func TestNextAutoscaledSize(t *testing.T) {
+	table := []struct {
+		name     string
+		capacity string
+		want     string
+		wantOK   bool
+	}{
+		{name: "full increment", capacity: "100Gi", want: "110Gi", wantOK: true},
+		{name: "capped at max", capacity: "195Gi", want: "200Gi", wantOK: true},
+		{name: "already at max", capacity: "200Gi", wantOK: false},
+		{name: "beyond max", capacity: "250Gi", wantOK: false},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := nextAutoscaledSize(resource.MustParse(test.capacity), resource.MustParse("10Gi"), resource.MustParse("200Gi"))
+			if ok != test.wantOK {
+				t.Fatalf("nextAutoscaledSize() ok = %v; want %v", ok, test.wantOK)
+			}
+			if ok && got.Cmp(resource.MustParse(test.want)) != 0 {
+				t.Errorf("nextAutoscaledSize() = %v; want %v", got.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestDesiredDataVolumeSize(t *testing.T) {
+	requested := resource.MustParse("100Gi")
+	table := []struct {
+		name       string
+		annotation string
+		want       string
+	}{
+		{name: "not autoscaled", annotation: "", want: "100Gi"},
+		{name: "autoscaled larger", annotation: "120Gi", want: "120Gi"},
+		{name: "request raised past autoscaled", annotation: "90Gi", want: "100Gi"},
+		{name: "invalid annotation", annotation: "lots", want: "100Gi"},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{pvcAutoscaledSizeAnnotation: test.annotation},
+				},
+			}
+			got := desiredDataVolumeSize(requested, pvc)
+			if got.Cmp(resource.MustParse(test.want)) != 0 {
+				t.Errorf("desiredDataVolumeSize() = %v; want %v", got.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestReconcileDiskAutoscalerUpdateStrategy(t *testing.T) {
+	table := []struct {
+		name        string
+		strategy    planetscalev2.VitessClusterUpdateStrategy
+		fromKubelet bool
+		want        string
+	}{
+		{
+			name: "immediate",
+			strategy: planetscalev2.VitessClusterUpdateStrategy{
+				Type: ptr.To(planetscalev2.ImmediateVitessClusterUpdateStrategyType),
+			},
+			want: "11Gi",
+		},
+		{
+			name: "immediate without custom metrics",
+			strategy: planetscalev2.VitessClusterUpdateStrategy{
+				Type: ptr.To(planetscalev2.ImmediateVitessClusterUpdateStrategyType),
+			},
+			fromKubelet: true,
+			want:        "11Gi",
+		},
+		{
+			name: "external without storage changes",
+			strategy: planetscalev2.VitessClusterUpdateStrategy{
+				Type:     ptr.To(planetscalev2.ExternalVitessClusterUpdateStrategyType),
+				External: &planetscalev2.ExternalVitessClusterUpdateStrategyOptions{},
+			},
+			want: "10Gi",
+		},
+		{
+			name: "external with storage changes",
+			strategy: planetscalev2.VitessClusterUpdateStrategy{
+				Type: ptr.To(planetscalev2.ExternalVitessClusterUpdateStrategyType),
+				External: &planetscalev2.ExternalVitessClusterUpdateStrategyOptions{
+					AllowResourceChanges: []corev1.ResourceName{corev1.ResourceStorage},
+				},
+			},
+			want: "11Gi",
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, planetscalev2.SchemeBuilder.AddToScheme(scheme))
+			require.NoError(t, corev1.AddToScheme(scheme))
+
+			size := resource.MustParse("10Gi")
+			vts := newVitessShard("commerce", []planetscalev2.VitessShardTabletPool{
+				{
+					Cell:     "zone1",
+					Type:     planetscalev2.ReplicaPoolType,
+					Replicas: 1,
+					DataVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{
+						Resources: corev1.VolumeResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+						},
+					},
+					DataVolumeAutoscaler: &planetscalev2.DataVolumeAutoscalerSpec{
+						UsageThresholdPercent: ptr.To[int32](80),
+						Increment:             resource.MustParse("1Gi"),
+						MaxSize:               resource.MustParse("20Gi"),
+						CooldownSeconds:       ptr.To[int64](3600),
+					},
+				},
+			})
+			vts.Namespace = "default"
+			vts.Labels[planetscalev2.ClusterLabel] = "example"
+			vts.Spec.UpdateStrategy = &test.strategy
+			vts.Status = planetscalev2.NewVitessShardStatus()
+			vts.Status.Tablets["zone1-0000000101"] = planetscalev2.VitessTabletStatus{
+				PoolType: string(planetscalev2.ReplicaPoolType),
+			}
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: vts.Namespace,
+					Name:      "example-vttablet-zone1-0000000101",
+					Labels: map[string]string{
+						planetscalev2.ComponentLabel: planetscalev2.VttabletComponentName,
+						planetscalev2.ClusterLabel:   "example",
+						planetscalev2.KeyspaceLabel:  "commerce",
+						planetscalev2.ShardLabel:     vts.Spec.KeyRange.SafeName(),
+						planetscalev2.CellLabel:      "zone1",
+						planetscalev2.TabletUidLabel: "101",
+					},
+				},
+				Spec: corev1.PodSpec{
+					NodeName: "node-1",
+				},
+			}
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: pod.Namespace,
+					Name:      pod.Name,
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+					},
+				},
+				Status: corev1.PersistentVolumeClaimStatus{
+					Capacity: corev1.ResourceList{corev1.ResourceStorage: size},
+				},
+			}
+
+			usage := map[string]volumestats.Usage{
+				pvc.Name: {UsedBytes: 9, CapacityBytes: 10},
+			}
+			volumeStats := fakeVolumeStats{metrics: usage}
+			if test.fromKubelet {
+				volumeStats = fakeVolumeStats{nodes: map[string]map[string]volumestats.Usage{"node-1": usage}}
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod, pvc).Build()
+			r := &ReconcileVitessShard{
+				client:      c,
+				scheme:      scheme,
+				recorder:    record.NewFakeRecorder(20),
+				volumeStats: volumeStats,
+			}
+
+			_, err := r.reconcileDiskAutoscaler(t.Context(), vts)
+			require.NoError(t, err)
+
+			got := &corev1.PersistentVolumeClaim{}
+			require.NoError(t, c.Get(t.Context(), client.ObjectKeyFromObject(pvc), got))
+			requested := got.Spec.Resources.Requests[corev1.ResourceStorage]
+			assert.Equal(t, test.want, requested.String())
+		})
+	}
+}
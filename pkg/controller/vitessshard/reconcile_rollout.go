@@ -169,7 +169,7 @@ func getNextScheduledTablet(tabletKeys []string, tabletPods map[string]*corev1.P
 }
 
 func getPrimaryTabletAlias(ctx context.Context, vts *planetscalev2.VitessShard) (string, error) {
-	ts, err := toposerver.Open(ctx, vts.Spec.GlobalLockserver)
+	ts, err := toposerver.Open(ctx, vts.Namespace, vts.Spec.GlobalLockserver)
 	if err != nil {
 		return "", err
 	}
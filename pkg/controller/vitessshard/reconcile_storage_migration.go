@@ -0,0 +1,260 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitessshard
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/drain"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/rollout"
+)
+
+const (
+	// storageMigrationRequeueDelay is how often to check on a tablet that's
+	// being drained or replaced as part of a storage migration.
+	storageMigrationRequeueDelay = 10 * time.Second
+)
+
+// storageMigrationTablet is a tablet whose data volume PVC has a different
+// StorageClass than the one requested by its tablet pool.
+type storageMigrationTablet struct {
+	aliasStr     string
+	storageClass string
+	pod          *corev1.Pod
+	pvc          *corev1.PersistentVolumeClaim
+	canRestore   bool
+}
+
+/*
+reconcileStorageMigration moves tablets onto new data volumes when the
+storageClassName in a tablet pool's dataVolumeClaimTemplate changes.
+
+PVCs can't change StorageClass in place, so each affected tablet is replaced:
+
+ 1. Drain the tablet Pod through the usual drain protocol. If the tablet is the
+    primary, the drain controller reparents away from it first.
+ 2. Once the drain is finished, delete the old PVC and the Pod.
+ 3. reconcileTablets recreates the PVC from the new template and then the Pod,
+    which restores its data from the latest backup.
+
+Only one tablet is migrated at a time, and we wait for every tablet in the
+shard to be Available before starting the next one. The primary goes last.
+
+A replaced tablet can only get its data back from a backup, so we refuse to
+start on a tablet unless its pool has a backup location and the shard has a
+complete backup. We don't clone it from another replica instead, since CSI
+volume clones and snapshots can't change StorageClass. The StorageMigration condition reports where we're at, and
+Events are only emitted when that changes.
+
+NOTE: This must always be done after reconcileTopology and reconcileBackupJob,
+so Status.MasterAlias and Status.HasInitialBackup are populated.
+*/
+func (r *ReconcileVitessShard) reconcileStorageMigration(ctx context.Context, vts *planetscalev2.VitessShard) (reconcile.Result, error) {
+	resultBuilder := &results.Builder{}
+
+	// Replacing tablets is a disk change, so it follows the same update strategy as disk resizes.
+	if *vts.Spec.UpdateStrategy.Type != planetscalev2.ImmediateVitessClusterUpdateStrategyType {
+		if vts.Spec.UpdateStrategy.External == nil {
+			return resultBuilder.Result()
+		} else if !vts.Spec.UpdateStrategy.External.ResourceChangesAllowed(corev1.ResourceStorage) {
+			return resultBuilder.Result()
+		}
+	}
+
+	tabletPods, err := r.tabletPodsFromShard(ctx, vts)
+	if err != nil {
+		return resultBuilder.Error(err)
+	}
+
+	var migrations []*storageMigrationTablet
+	for _, tablet := range vttabletSpecs(vts, nil) {
+		if tablet.DataVolumePVCSpec == nil || tablet.DataVolumePVCSpec.StorageClassName == nil {
+			// Without an explicit StorageClass there's nothing to compare against.
+			continue
+		}
+		pod, ok := tabletPods[tablet.AliasStr]
+		if !ok {
+			continue
+		}
+		pvc, err := r.claimForTabletPod(ctx, pod)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return resultBuilder.Error(err)
+		}
+
+		if pvc.DeletionTimestamp == nil && pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName == *tablet.DataVolumePVCSpec.StorageClassName {
+			continue
+		}
+		migrations = append(migrations, &storageMigrationTablet{
+			aliasStr:     tablet.AliasStr,
+			storageClass: *tablet.DataVolumePVCSpec.StorageClassName,
+			pod:          pod,
+			pvc:          pvc,
+			canRestore:   tablet.BackupLocation != nil,
+		})
+	}
+	if len(migrations) == 0 {
+		if _, ok := vts.Status.Conditions[planetscalev2.VitessShardStorageMigration]; ok {
+			r.setStorageMigrationCondition(vts, corev1.ConditionFalse, "Complete", corev1.EventTypeNormal, "StorageMigrationComplete", "All data volumes use the requested StorageClass.")
+		}
+		return resultBuilder.Result()
+	}
+
+	// Report progress for every tablet that needs to move.
+	var current *storageMigrationTablet
+	for _, tablet := range migrations {
+		phase := storageMigrationPhase(tablet.pod, tablet.pvc)
+		status := vts.Status.Tablets[tablet.aliasStr]
+		status.DataVolumeMigration = phase
+		vts.Status.Tablets[tablet.aliasStr] = status
+
+		if phase != planetscalev2.DataVolumeMigrationPending {
+			current = tablet
+		}
+	}
+
+	// Don't interfere with a rolling restart that's in progress.
+	if rollout.Cascading(vts) {
+		return resultBuilder.Result()
+	}
+
+	if current != nil {
+		if !drain.Finished(current.pod) || current.pod.DeletionTimestamp != nil {
+			// Either the drain is still in progress or the Pod is already on
+			// its way down. Wait for it.
+			r.setStorageMigrationCondition(vts, corev1.ConditionTrue, "Draining", corev1.EventTypeNormal, "StorageMigrationStarted",
+				fmt.Sprintf("Draining tablet %v to migrate its data volume to StorageClass %v.", current.aliasStr, current.storageClass))
+			return resultBuilder.RequeueAfter(storageMigrationRequeueDelay)
+		}
+		if err := r.replaceTabletDataVolume(ctx, vts, current); err != nil {
+			return resultBuilder.Error(err)
+		}
+		return resultBuilder.Result()
+	}
+
+	// Only start on a new tablet once the shard is back at full strength.
+	for _, tabletKey := range vts.Status.TabletAliases() {
+		if vts.Status.Tablets[tabletKey].Available != corev1.ConditionTrue {
+			r.setStorageMigrationCondition(vts, corev1.ConditionTrue, "Paused", corev1.EventTypeNormal, "StorageMigrationPaused",
+				fmt.Sprintf("Waiting for tablet %v to be Available.", tabletKey))
+			return resultBuilder.Result()
+		}
+	}
+	// Leave other drains alone, since only one tablet may be finished at a time.
+	for _, pod := range tabletPods {
+		if drain.Started(pod) {
+			r.setStorageMigrationCondition(vts, corev1.ConditionTrue, "Paused", corev1.EventTypeNormal, "StorageMigrationPaused",
+				fmt.Sprintf("Waiting for drain of Pod %v to complete.", pod.Name))
+			return resultBuilder.Result()
+		}
+	}
+
+	next := nextStorageMigrationTablet(migrations, vts.Status.MasterAlias)
+	if !next.canRestore || vts.Status.HasInitialBackup != corev1.ConditionTrue {
+		// Replacing the tablet would leave it with an empty data volume and
+		// nothing to restore from, so don't start at all.
+		status := vts.Status.Tablets[next.aliasStr]
+		status.DataVolumeMigration = planetscalev2.DataVolumeMigrationBlocked
+		vts.Status.Tablets[next.aliasStr] = status
+
+		r.setStorageMigrationCondition(vts, corev1.ConditionFalse, "BackupRequired", corev1.EventTypeWarning, "StorageMigrationBlocked",
+			fmt.Sprintf("Can't replace data volume of tablet %v without a complete backup to restore from.", next.aliasStr))
+		return resultBuilder.Result()
+	}
+
+	drain.Start(next.pod, "migrating data volume to StorageClass "+next.storageClass)
+	if err := r.client.Update(ctx, next.pod); err != nil {
+		r.recorder.Eventf(vts, corev1.EventTypeWarning, "UpdateFailed", "failed to request drain of Pod %v: %v", next.pod.Name, err)
+		return resultBuilder.Error(err)
+	}
+	r.setStorageMigrationCondition(vts, corev1.ConditionTrue, "Draining", corev1.EventTypeNormal, "StorageMigrationStarted",
+		fmt.Sprintf("Draining tablet %v to migrate its data volume to StorageClass %v.", next.aliasStr, next.storageClass))
+
+	return resultBuilder.RequeueAfter(storageMigrationRequeueDelay)
+}
+
+// replaceTabletDataVolume deletes a drained tablet's old PVC and its Pod, so
+// reconcileTablets can recreate both with the new StorageClass.
+func (r *ReconcileVitessShard) replaceTabletDataVolume(ctx context.Context, vts *planetscalev2.VitessShard, tablet *storageMigrationTablet) error {
+	// Delete the PVC first. It stays around until the Pod is gone, and
+	// reconcileTablets won't create a new Pod while the old PVC is terminating.
+	if tablet.pvc.DeletionTimestamp == nil {
+		if err := r.client.Delete(ctx, tablet.pvc, client.Preconditions{UID: &tablet.pvc.UID}); err != nil && !apierrors.IsNotFound(err) {
+			r.recorder.Eventf(vts, corev1.EventTypeWarning, "DeleteFailed", "failed to delete PersistentVolumeClaim %v: %v", tablet.pvc.Name, err)
+			return err
+		}
+	}
+	if err := r.client.Delete(ctx, tablet.pod, client.Preconditions{UID: &tablet.pod.UID}); err != nil && !apierrors.IsNotFound(err) {
+		r.recorder.Eventf(vts, corev1.EventTypeWarning, "DeleteFailed", "failed to delete Pod %v: %v", tablet.pod.Name, err)
+		return err
+	}
+	r.setStorageMigrationCondition(vts, corev1.ConditionTrue, "Replacing", corev1.EventTypeNormal, "StorageMigrationReplacing",
+		fmt.Sprintf("Replacing tablet %v with a new data volume in StorageClass %v.", tablet.aliasStr, tablet.storageClass))
+	return nil
+}
+
+// setStorageMigrationCondition records the state of the storage migration in
+// the StorageMigration condition. The Event is only emitted if the state
+// differs from what the last reconcile recorded, since we get here on every
+// resync while a migration is waiting on something.
+func (r *ReconcileVitessShard) setStorageMigrationCondition(vts *planetscalev2.VitessShard, status corev1.ConditionStatus, reason, eventType, eventReason, message string) {
+	prev, hadPrev := vts.Status.Conditions[planetscalev2.VitessShardStorageMigration]
+	vts.Status.SetConditionStatus(planetscalev2.VitessShardStorageMigration, status, reason, message)
+	if hadPrev && prev.Status == status && prev.Reason == reason && prev.Message == message {
+		return
+	}
+	r.recorder.Event(vts, eventType, eventReason, message)
+}
+
+// storageMigrationPhase returns how far along a tablet is in replacing its data volume.
+func storageMigrationPhase(pod *corev1.Pod, pvc *corev1.PersistentVolumeClaim) planetscalev2.VitessTabletDataVolumeMigrationPhase {
+	switch {
+	case pvc.DeletionTimestamp != nil || drain.Finished(pod):
+		return planetscalev2.DataVolumeMigrationReplacing
+	case drain.Started(pod):
+		return planetscalev2.DataVolumeMigrationDraining
+	default:
+		return planetscalev2.DataVolumeMigrationPending
+	}
+}
+
+// nextStorageMigrationTablet picks the next tablet to migrate, in a stable
+// order with the primary last.
+func nextStorageMigrationTablet(migrations []*storageMigrationTablet, primaryAlias string) *storageMigrationTablet {
+	sorted := make([]*storageMigrationTablet, len(migrations))
+	copy(sorted, migrations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		iPrimary := sorted[i].aliasStr == primaryAlias
+		jPrimary := sorted[j].aliasStr == primaryAlias
+		if iPrimary != jPrimary {
+			return jPrimary
+		}
+		return sorted[i].aliasStr < sorted[j].aliasStr
+	})
+	return sorted[0]
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitessshard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/drain"
+)
+
+func TestNextStorageMigrationTablet(t *testing.T) {
+	table := []struct {
+		name    string
+		aliases []string
+		primary string
+		want    string
+	}{
+		{
+			name:    "lowest alias first",
+			aliases: []string{"zone1-0000000003", "zone1-0000000001", "zone1-0000000002"},
+			primary: "zone1-0000000009",
+			want:    "zone1-0000000001",
+		},
+		{
+			name:    "primary last",
+			aliases: []string{"zone1-0000000001", "zone1-0000000002"},
+			primary: "zone1-0000000001",
+			want:    "zone1-0000000002",
+		},
+		{
+			name:    "only primary left",
+			aliases: []string{"zone1-0000000001"},
+			primary: "zone1-0000000001",
+			want:    "zone1-0000000001",
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			var migrations []*storageMigrationTablet
+			for _, alias := range test.aliases {
+				migrations = append(migrations, &storageMigrationTablet{aliasStr: alias})
+			}
+			if got := nextStorageMigrationTablet(migrations, test.primary).aliasStr; got != test.want {
+				t.Errorf("nextStorageMigrationTablet() = %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStorageMigrationPhase(t *testing.T) {
+	now := metav1.Now()
+
+	drainingPod := &corev1.Pod{}
+	drain.Start(drainingPod, "test")
+
+	finishedPod := &corev1.Pod{}
+	drain.Start(finishedPod, "test")
+	drain.Finish(finishedPod)
+
+	table := []struct {
+		name string
+		pod  *corev1.Pod
+		pvc  *corev1.PersistentVolumeClaim
+		want planetscalev2.VitessTabletDataVolumeMigrationPhase
+	}{
+		{
+			name: "not started",
+			pod:  &corev1.Pod{},
+			pvc:  &corev1.PersistentVolumeClaim{},
+			want: planetscalev2.DataVolumeMigrationPending,
+		},
+		{
+			name: "draining",
+			pod:  drainingPod,
+			pvc:  &corev1.PersistentVolumeClaim{},
+			want: planetscalev2.DataVolumeMigrationDraining,
+		},
+		{
+			name: "drain finished",
+			pod:  finishedPod,
+			pvc:  &corev1.PersistentVolumeClaim{},
+			want: planetscalev2.DataVolumeMigrationReplacing,
+		},
+		{
+			name: "pvc deleting",
+			pod:  &corev1.Pod{},
+			pvc:  &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}},
+			want: planetscalev2.DataVolumeMigrationReplacing,
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			if got := storageMigrationPhase(test.pod, test.pvc); got != test.want {
+				t.Errorf("storageMigrationPhase() = %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetStorageMigrationConditionEventsOnChange(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileVitessShard{recorder: recorder}
+	vts := &planetscalev2.VitessShard{Status: planetscalev2.NewVitessShardStatus()}
+
+	blocked := func() {
+		r.setStorageMigrationCondition(vts, corev1.ConditionFalse, "BackupRequired", corev1.EventTypeWarning, "StorageMigrationBlocked", "no backup")
+	}
+
+	// Repeated reconciles in the same state only emit one Event.
+	blocked()
+	blocked()
+	assert.Len(t, recorder.Events, 1)
+	cond := vts.Status.Conditions[planetscalev2.VitessShardStorageMigration]
+	assert.Equal(t, corev1.ConditionFalse, cond.Status)
+	assert.Equal(t, "BackupRequired", cond.Reason)
+
+	// A new state emits a new Event.
+	r.setStorageMigrationCondition(vts, corev1.ConditionTrue, "Draining", corev1.EventTypeNormal, "StorageMigrationStarted", "draining")
+	assert.Len(t, recorder.Events, 2)
+}
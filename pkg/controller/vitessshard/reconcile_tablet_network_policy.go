@@ -0,0 +1,70 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitessshard
+
+import (
+	"context"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/networkpolicy"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+)
+
+// reconcileTabletNetworkPolicy maintains the NetworkPolicy that restricts
+// ingress to the tablets in the shard.
+func (r *ReconcileVitessShard) reconcileTabletNetworkPolicy(ctx context.Context, vts *planetscalev2.VitessShard) (reconcile.Result, error) {
+	resultBuilder := &results.Builder{}
+	clusterName := vts.Labels[planetscalev2.ClusterLabel]
+	keyspaceName := vts.Labels[planetscalev2.KeyspaceLabel]
+
+	labels := map[string]string{
+		planetscalev2.ComponentLabel: planetscalev2.VttabletComponentName,
+		planetscalev2.ClusterLabel:   clusterName,
+		planetscalev2.KeyspaceLabel:  keyspaceName,
+		planetscalev2.ShardLabel:     vts.Spec.KeyRange.SafeName(),
+	}
+
+	var spec *networkpolicy.Spec
+	enabled := networkpolicy.Enabled(vts.Spec.NetworkPolicies)
+	if enabled {
+		spec = networkpolicy.NewVttabletSpec(labels, clusterName, keyspaceName, vts.Spec.KeyRange.SafeName(), vts.Spec.NetworkPolicies)
+	}
+
+	key := client.ObjectKey{Namespace: vts.Namespace, Name: networkpolicy.TabletName(clusterName, keyspaceName, vts.Spec.KeyRange)}
+	err := r.reconciler.ReconcileObject(ctx, vts, key, labels, enabled, reconciler.Strategy{
+		Kind: &networkingv1.NetworkPolicy{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return networkpolicy.NewNetworkPolicy(key, spec)
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			newObj := obj.(*networkingv1.NetworkPolicy)
+			networkpolicy.UpdateNetworkPolicy(newObj, spec)
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	return resultBuilder.Result()
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitessshard
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/drain"
+	"planetscale.dev/vitess-operator/pkg/operator/pdb"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/vttablet"
+)
+
+/*
+reconcileTabletPDB maintains the PodDisruptionBudget that covers all tablets in
+the shard.
+
+The PDB cooperates with the drain annotations, which are how we take a tablet
+out of service safely (e.g. by reparenting away from it first):
+
+  - While any tablet in the shard has a drain in progress, no other tablet may
+    be evicted, because the shard is already losing one.
+  - Once a tablet's drain is finished, we stop covering that tablet with the
+    PDB, so it can be evicted even though it's still Ready.
+*/
+func (r *ReconcileVitessShard) reconcileTabletPDB(ctx context.Context, vts *planetscalev2.VitessShard) (reconcile.Result, error) {
+	resultBuilder := &results.Builder{}
+	clusterName := vts.Labels[planetscalev2.ClusterLabel]
+	keyspaceName := vts.Labels[planetscalev2.KeyspaceLabel]
+
+	labels := map[string]string{
+		planetscalev2.ComponentLabel: planetscalev2.VttabletComponentName,
+		planetscalev2.ClusterLabel:   clusterName,
+		planetscalev2.KeyspaceLabel:  keyspaceName,
+		planetscalev2.ShardLabel:     vts.Spec.KeyRange.SafeName(),
+	}
+
+	var spec *pdb.Spec
+	wantPDB := pdb.Enabled(vts.Spec.TabletPodDisruptionBudget)
+	if wantPDB {
+		tabletPods, err := r.tabletPodsFromShard(ctx, vts)
+		if err != nil {
+			return resultBuilder.Error(err)
+		}
+		spec = tabletPDBSpec(labels, *vts.Spec.TabletPodDisruptionBudget.MaxUnavailable, tabletPods)
+	}
+
+	key := client.ObjectKey{Namespace: vts.Namespace, Name: vttablet.PDBName(clusterName, keyspaceName, vts.Spec.KeyRange)}
+	err := r.reconciler.ReconcileObject(ctx, vts, key, labels, wantPDB, reconciler.Strategy{
+		Kind: &policyv1.PodDisruptionBudget{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return pdb.NewPDB(key, spec)
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			newObj := obj.(*policyv1.PodDisruptionBudget)
+			pdb.UpdatePDB(newObj, spec)
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	return resultBuilder.Result()
+}
+
+// tabletPDBSpec returns the PDB spec for the tablets in a shard, given the
+// current tablet Pods.
+func tabletPDBSpec(labels map[string]string, maxUnavailable intstr.IntOrString, tabletPods map[string]*corev1.Pod) *pdb.Spec {
+	spec := &pdb.Spec{
+		Labels:         labels,
+		Selector:       &metav1.LabelSelector{MatchLabels: labels},
+		MaxUnavailable: maxUnavailable,
+	}
+
+	var drainedUIDs []string
+	for _, pod := range tabletPods {
+		if !drain.Started(pod) {
+			continue
+		}
+		if drain.Finished(pod) {
+			drainedUIDs = append(drainedUIDs, pod.Labels[planetscalev2.TabletUidLabel])
+		}
+		// Another tablet is already on its way out.
+		spec.MaxUnavailable = intstr.FromInt32(0)
+	}
+
+	if len(drainedUIDs) > 0 {
+		// Keep the selector stable across reconciles.
+		sort.Strings(drainedUIDs)
+		spec.Selector.MatchExpressions = []metav1.LabelSelectorRequirement{
+			{
+				Key:      planetscalev2.TabletUidLabel,
+				Operator: metav1.LabelSelectorOpNotIn,
+				Values:   drainedUIDs,
+			},
+		}
+	}
+	return spec
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitessshard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/drain"
+)
+
+func TestTabletPDBSpec(t *testing.T) {
+	labels := map[string]string{planetscalev2.ComponentLabel: planetscalev2.VttabletComponentName}
+	tabletPod := func(uid string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{planetscalev2.TabletUidLabel: uid}}}
+	}
+
+	draining := tabletPod("0000000002")
+	drain.Start(draining, "test")
+
+	drained := tabletPod("0000000003")
+	drain.Start(drained, "test")
+	drain.Finish(drained)
+
+	table := []struct {
+		name               string
+		pods               map[string]*corev1.Pod
+		wantMaxUnavailable intstr.IntOrString
+		wantExcluded       []string
+	}{
+		{
+			name: "no drains",
+			pods: map[string]*corev1.Pod{
+				"zone1-0000000001": tabletPod("0000000001"),
+				"zone1-0000000002": tabletPod("0000000002"),
+			},
+			wantMaxUnavailable: intstr.FromInt32(1),
+		},
+		{
+			name: "drain in progress",
+			pods: map[string]*corev1.Pod{
+				"zone1-0000000001": tabletPod("0000000001"),
+				"zone1-0000000002": draining,
+			},
+			wantMaxUnavailable: intstr.FromInt32(0),
+		},
+		{
+			name: "drain finished",
+			pods: map[string]*corev1.Pod{
+				"zone1-0000000001": tabletPod("0000000001"),
+				"zone1-0000000003": drained,
+			},
+			wantMaxUnavailable: intstr.FromInt32(0),
+			wantExcluded:       []string{"0000000003"},
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			spec := tabletPDBSpec(labels, intstr.FromInt32(1), test.pods)
+			assert.Equal(t, test.wantMaxUnavailable, spec.MaxUnavailable)
+			assert.Equal(t, labels, spec.Selector.MatchLabels)
+			if test.wantExcluded == nil {
+				assert.Empty(t, spec.Selector.MatchExpressions)
+				return
+			}
+			assert.Equal(t, []metav1.LabelSelectorRequirement{
+				{Key: planetscalev2.TabletUidLabel, Operator: metav1.LabelSelectorOpNotIn, Values: test.wantExcluded},
+			}, spec.Selector.MatchExpressions)
+		})
+	}
+}
@@ -18,6 +18,8 @@ package vitessshard
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"sort"
 	"strconv"
 	"time"
@@ -27,7 +29,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/kubectl/pkg/util/podutils"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,12 +39,15 @@ import (
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
 	"planetscale.dev/vitess-operator/pkg/operator/drain"
+	"planetscale.dev/vitess-operator/pkg/operator/internaltls"
 	"planetscale.dev/vitess-operator/pkg/operator/k8s"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
 	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
 	"planetscale.dev/vitess-operator/pkg/operator/results"
 	"planetscale.dev/vitess-operator/pkg/operator/rollout"
 	"planetscale.dev/vitess-operator/pkg/operator/toposerver"
 	"planetscale.dev/vitess-operator/pkg/operator/update"
+	"planetscale.dev/vitess-operator/pkg/operator/vitessbackup"
 	"planetscale.dev/vitess-operator/pkg/operator/vttablet"
 )
 
@@ -68,6 +75,16 @@ func (r *ReconcileVitessShard) reconcileTablets(ctx context.Context, vts *planet
 		planetscalev2.ShardLabel:     vts.Spec.KeyRange.SafeName(),
 	}
 
+	internalTLSHash, err := internaltls.ContentHash(ctx, r.client, vts.Namespace, vts.Spec.InternalTLS)
+	if err != nil {
+		// Record error and return, to avoid recreating tablets based on incomplete information.
+		return resultBuilder.Error(err)
+	}
+	topoTLSHash, err := lockserver.ClientTLSContentHash(ctx, r.client, vts.Namespace, &vts.Spec.GlobalLockserver)
+	if err != nil {
+		return resultBuilder.Error(err)
+	}
+
 	// Remember which cells we deploy any tablets in.
 	deployedCells := map[string]struct{}{}
 	defer func() {
@@ -81,6 +98,16 @@ func (r *ReconcileVitessShard) reconcileTablets(ctx context.Context, vts *planet
 
 	// Compute the set of all desired tablets based on the config.
 	tablets := vttabletSpecs(vts, labels)
+	if internalTLSHash != "" {
+		for _, tablet := range tablets {
+			tablet.Annotations[internaltls.HashAnnotation] = internalTLSHash
+		}
+	}
+	if topoTLSHash != "" {
+		for _, tablet := range tablets {
+			tablet.Annotations[lockserver.ClientTLSHashAnnotation] = topoTLSHash
+		}
+	}
 
 	// Generate podKeys (object names) for all desired tablet pods and pvcKeys for desired PVCs.
 	//
@@ -88,30 +115,67 @@ func (r *ReconcileVitessShard) reconcileTablets(ctx context.Context, vts *planet
 	pvcKeys := make([]client.ObjectKey, 0, len(tablets))
 	podKeys := make([]client.ObjectKey, 0, len(tablets))
 	tabletMap := make(map[client.ObjectKey]*vttablet.Spec, len(tablets))
+	var latestSnapshot string
+	var latestSnapshotSize *resource.Quantity
+	var latestSnapshotKnown bool
 	for _, tablet := range tablets {
 		podName := vttablet.PodName(clusterName, tablet.Alias)
 		key := client.ObjectKey{Namespace: vts.Namespace, Name: podName}
 
+		tabletMap[key] = tablet
+
+		deployedCells[tablet.Alias.Cell] = struct{}{}
+
+		// Initialize a status entry for every desired tablet, so it will be
+		// listed even if we end up not having anything to report about it.
+		vts.Status.Tablets[tablet.AliasStr] = planetscalev2.NewVitessTabletStatus(tablet.Type, tablet.Index)
+
 		if tablet.DataVolumePVCSpec != nil {
 			// We use the same name for the Pod and the main data volume PVC.
 			tablet.DataVolumePVCName = podName
 
+			// Keep any growth applied by the disk autoscaler.
+			r.applyAutoscaledDataVolumeSize(ctx, key, tablet)
+
+			// New data volumes may start from the latest snapshot of the shard.
+			if tablet.DataVolumeFromSnapshot {
+				if !latestSnapshotKnown {
+					latestSnapshot, latestSnapshotSize, err = r.latestSnapshot(ctx, vts)
+					if err != nil {
+						return resultBuilder.Error(err)
+					}
+					latestSnapshotKnown = true
+				}
+				tablet.DataVolumeSnapshotName = latestSnapshot
+				tablet.DataVolumeSnapshotSize = latestSnapshotSize
+			}
+
 			pvcKeys = append(pvcKeys, key)
+
+			// Don't create a new Pod while the old data volume is still being
+			// deleted (e.g. during a storage migration). The new Pod would
+			// keep the old PVC from ever going away.
+			pvcDeleting, err := r.tabletPVCDeleting(ctx, key)
+			if err != nil {
+				return resultBuilder.Error(err)
+			}
+			if pvcDeleting {
+				podExists, err := r.tabletPodExists(ctx, key)
+				if err != nil {
+					return resultBuilder.Error(err)
+				}
+				if !podExists {
+					resultBuilder.RequeueAfter(storageMigrationRequeueDelay)
+					continue
+				}
+			}
 		}
 
 		podKeys = append(podKeys, key)
-
-		tabletMap[key] = tablet
-
-		deployedCells[tablet.Alias.Cell] = struct{}{}
-
-		// Initialize a status entry for every desired tablet, so it will be
-		// listed even if we end up not having anything to report about it.
-		vts.Status.Tablets[tablet.AliasStr] = planetscalev2.NewVitessTabletStatus(tablet.Type, tablet.Index)
 	}
 
 	// Reconcile vttablet PVCs. Note that we use the same keys as the corresponding Pods.
-	err := r.reconciler.ReconcileObjectSet(ctx, vts, pvcKeys, labels, reconciler.Strategy{
+	err = r.reconciler.ReconcileObjectSet(ctx, vts, pvcKeys, labels, reconciler.Strategy{
 		Kind: &corev1.PersistentVolumeClaim{},
 
 		New: func(key client.ObjectKey) runtime.Object {
@@ -154,6 +218,14 @@ func (r *ReconcileVitessShard) reconcileTablets(ctx context.Context, vts *planet
 		resultBuilder.Error(err)
 	}
 
+	// Remember the gRPC addresses of the tablets we can reach.
+	var tabletAddrs []string
+	addTabletAddr := func(pod *corev1.Pod) {
+		if pod.Status.PodIP != "" {
+			tabletAddrs = append(tabletAddrs, net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(planetscalev2.DefaultGrpcPort)))
+		}
+	}
+
 	// Reconcile vttablet Pods.
 	err = r.reconciler.ReconcileObjectSet(ctx, vts, podKeys, labels, reconciler.Strategy{
 		Kind: &corev1.Pod{},
@@ -188,6 +260,7 @@ func (r *ReconcileVitessShard) reconcileTablets(ctx context.Context, vts *planet
 		Status: func(key client.ObjectKey, obj runtime.Object) {
 			pod := obj.(*corev1.Pod)
 			tablet := tabletMap[key]
+			addTabletAddr(pod)
 
 			tabletStatus := vts.Status.Tablets[tablet.AliasStr]
 			tabletStatus.Running = k8s.ConditionStatus(pod.Status.Phase == corev1.PodRunning)
@@ -215,6 +288,7 @@ func (r *ReconcileVitessShard) reconcileTablets(ctx context.Context, vts *planet
 			curObj := obj.(*corev1.Pod)
 			tabletAlias := vttablet.AliasFromPod(curObj)
 			tabletAliasStr := topoproto.TabletAliasString(&tabletAlias)
+			addTabletAddr(curObj)
 
 			vts.Status.OrphanedTablets[tabletAliasStr] = *orphanStatus
 
@@ -262,6 +336,15 @@ func (r *ReconcileVitessShard) reconcileTablets(ctx context.Context, vts *planet
 		resultBuilder.Error(err)
 	}
 
+	// The operator's own calls to these tablets need the cluster's internal
+	// client certificate, too.
+	operatorTLS, err := internaltls.OperatorClientConfig(ctx, r.client, vts.Namespace, vts.Spec.InternalTLS)
+	if err != nil {
+		resultBuilder.Error(err)
+	} else {
+		internaltls.RegisterTablets(client.ObjectKeyFromObject(vts).String(), tabletAddrs, operatorTLS)
+	}
+
 	return resultBuilder.Result()
 }
 
@@ -335,6 +418,7 @@ func vttabletSpecs(vts *planetscalev2.VitessShard, parentLabels map[string]strin
 				ExternalDatastore:         pool.ExternalDatastore,
 				Type:                      pool.Type,
 				DataVolumePVCSpec:         pool.DataVolumeClaimTemplate,
+				DataVolumeFromSnapshot:    pool.DataVolumeFromSnapshot,
 				KeyspaceName:              keyspaceName,
 				DatabaseName:              vts.Spec.DatabaseName,
 				DatabaseInitScriptSecret:  vts.Spec.DatabaseInitScriptSecret,
@@ -350,6 +434,7 @@ func vttabletSpecs(vts *planetscalev2.VitessShard, parentLabels map[string]strin
 				ExtraVolumeMounts:         pool.ExtraVolumeMounts,
 				Tolerations:               pool.Tolerations,
 				TopologySpreadConstraints: pool.TopologySpreadConstraints,
+				InternalTLS:               vts.Spec.InternalTLS,
 			})
 		}
 	}
@@ -358,7 +443,7 @@ func vttabletSpecs(vts *planetscalev2.VitessShard, parentLabels map[string]strin
 }
 
 func isTabletPrimary(ctx context.Context, vts *planetscalev2.VitessShard, tabletAlias topodatapb.TabletAlias) (bool, error) {
-	ts, err := toposerver.Open(ctx, vts.Spec.GlobalLockserver)
+	ts, err := toposerver.Open(ctx, vts.Namespace, vts.Spec.GlobalLockserver)
 	if err != nil {
 		return true, err
 	}
@@ -449,3 +534,57 @@ func checkPVCFileSystemResizeCondition(pvc *corev1.PersistentVolumeClaim) bool {
 
 	return false
 }
+
+// tabletPVCDeleting returns whether the data volume PVC with the given key
+// exists and is being deleted.
+func (r *ReconcileVitessShard) tabletPVCDeleting(ctx context.Context, key client.ObjectKey) (bool, error) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.client.Get(ctx, key, pvc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("can't check if data volume PVC %v is being deleted: %w", key, err)
+	}
+	return pvc.DeletionTimestamp != nil, nil
+}
+
+// tabletPodExists returns whether the tablet Pod with the given key exists.
+func (r *ReconcileVitessShard) tabletPodExists(ctx context.Context, key client.ObjectKey) (bool, error) {
+	err := r.client.Get(ctx, key, &corev1.Pod{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("can't check if tablet Pod %v exists: %w", key, err)
+	}
+	return true, nil
+}
+
+// latestSnapshot returns the name of the VolumeSnapshot of the newest
+// complete snapshot backup for the shard, or "" if there isn't one, along with
+// the snapshot's restore size if it reports one.
+func (r *ReconcileVitessShard) latestSnapshot(ctx context.Context, vts *planetscalev2.VitessShard) (string, *resource.Quantity, error) {
+	snapshots, err := vitessbackup.GetSnapshots(ctx, vts.Namespace, vts.Labels[planetscalev2.ClusterLabel], vts.Labels[planetscalev2.KeyspaceLabel], vts.Spec.KeyRange.SafeName(),
+		func(ctx context.Context, allBackupsList *planetscalev2.VitessBackupList, listOpts *client.ListOptions) error {
+			return r.client.List(ctx, allBackupsList, listOpts)
+		},
+	)
+	if err != nil {
+		return "", nil, err
+	}
+	latest := vitessbackup.LatestSnapshot(snapshots)
+	if latest == nil {
+		return "", nil, nil
+	}
+
+	volumeSnapshot := &unstructured.Unstructured{}
+	volumeSnapshot.SetGroupVersionKind(vitessbackup.VolumeSnapshotGVK)
+	key := client.ObjectKey{Namespace: vts.Namespace, Name: latest.Status.VolumeSnapshotName}
+	if err := r.client.Get(ctx, key, volumeSnapshot); err != nil {
+		return "", nil, fmt.Errorf("can't get VolumeSnapshot %v: %w", key, err)
+	}
+	if size, ok := vitessbackup.VolumeSnapshotRestoreSize(volumeSnapshot); ok {
+		return latest.Status.VolumeSnapshotName, &size, nil
+	}
+	return latest.Status.VolumeSnapshotName, nil, nil
+}
@@ -17,11 +17,23 @@ limitations under the License.
 package vitessshard
 
 import (
+	"context"
+	"errors"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
 	"planetscale.dev/vitess-operator/pkg/operator/vttablet"
 )
 
@@ -86,3 +98,123 @@ func TestTabletUidLabelZeroPadded(t *testing.T) {
 		}
 	}
 }
+
+func TestReconcileTabletsTerminatingPVCWithoutPod(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, planetscalev2.SchemeBuilder.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	cluster := "example"
+	keyspace := "commerce"
+	vts := newVitessShard(keyspace, []planetscalev2.VitessShardTabletPool{
+		{
+			Cell:     "zone1",
+			Type:     planetscalev2.ReplicaPoolType,
+			Replicas: 1,
+			DataVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				},
+			},
+		},
+	})
+	vts.Name = "example-commerce-x-x"
+	vts.Namespace = "default"
+	vts.UID = "vts-uid"
+	vts.Labels[planetscalev2.ClusterLabel] = cluster
+	vts.Status = planetscalev2.NewVitessShardStatus()
+
+	tablet := vttabletSpecs(vts, nil)[0]
+	key := client.ObjectKey{Namespace: vts.Namespace, Name: vttablet.PodName(cluster, tablet.Alias)}
+
+	// The old data volume is on its way down (e.g. during a storage
+	// migration), and its Pod is already gone.
+	now := metav1.Now()
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         key.Namespace,
+			Name:              key.Name,
+			DeletionTimestamp: &now,
+			Finalizers:        []string{"kubernetes.io/pvc-protection"},
+			Labels: map[string]string{
+				planetscalev2.ComponentLabel: planetscalev2.VttabletComponentName,
+				planetscalev2.ClusterLabel:   cluster,
+				planetscalev2.KeyspaceLabel:  keyspace,
+				planetscalev2.ShardLabel:     vts.Spec.KeyRange.SafeName(),
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pvc).Build()
+	recorder := record.NewFakeRecorder(20)
+	r := &ReconcileVitessShard{
+		client:     c,
+		scheme:     scheme,
+		recorder:   recorder,
+		reconciler: reconciler.New(c, scheme, recorder),
+	}
+
+	result, err := r.reconcileTablets(t.Context(), vts)
+	require.NoError(t, err)
+	assert.Equal(t, storageMigrationRequeueDelay, result.RequeueAfter)
+
+	// The terminating PVC still reports status for its tablet.
+	assert.Equal(t, corev1.ConditionTrue, vts.Status.Tablets[tablet.AliasStr].DataVolumeBound)
+	assert.Equal(t, []string{"zone1"}, vts.Status.Cells)
+
+	// No new Pod may be created until the old PVC is gone.
+	pods := &corev1.PodList{}
+	require.NoError(t, c.List(t.Context(), pods))
+	assert.Empty(t, pods.Items)
+}
+
+func TestReconcileTabletsPVCGetError(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, planetscalev2.SchemeBuilder.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	vts := newVitessShard("commerce", []planetscalev2.VitessShardTabletPool{
+		{
+			Cell:     "zone1",
+			Type:     planetscalev2.ReplicaPoolType,
+			Replicas: 1,
+			DataVolumeClaimTemplate: &corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				},
+			},
+		},
+	})
+	vts.Name = "example-commerce-x-x"
+	vts.Namespace = "default"
+	vts.UID = "vts-uid"
+	vts.Labels[planetscalev2.ClusterLabel] = "example"
+	vts.Status = planetscalev2.NewVitessShardStatus()
+
+	// We can't tell whether the data volume is being deleted.
+	getErr := errors.New("injected error")
+	c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if _, ok := obj.(*corev1.PersistentVolumeClaim); ok {
+				return getErr
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	}).Build()
+	recorder := record.NewFakeRecorder(20)
+	r := &ReconcileVitessShard{
+		client:     c,
+		scheme:     scheme,
+		recorder:   recorder,
+		reconciler: reconciler.New(c, scheme, recorder),
+	}
+
+	_, err := r.reconcileTablets(t.Context(), vts)
+	assert.ErrorIs(t, err, getErr)
+
+	// No Pod may be created until we know the PVC isn't going away.
+	pods := &corev1.PodList{}
+	require.NoError(t, c.List(t.Context(), pods))
+	assert.Empty(t, pods.Items)
+}
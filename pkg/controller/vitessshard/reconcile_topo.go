@@ -18,6 +18,7 @@ package vitessshard
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"time"
 
@@ -35,6 +36,7 @@ import (
 	"planetscale.dev/vitess-operator/pkg/operator/k8s"
 	"planetscale.dev/vitess-operator/pkg/operator/results"
 	"planetscale.dev/vitess-operator/pkg/operator/toposerver"
+	"planetscale.dev/vitess-operator/pkg/operator/vitesstopo"
 
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
@@ -57,7 +59,7 @@ func (r *ReconcileVitessShard) reconcileTopology(ctx context.Context, vts *plane
 	ctx, cancel := context.WithTimeout(ctx, topoReconcileTimeout)
 	defer cancel()
 
-	ts, err := toposerver.Open(ctx, vts.Spec.GlobalLockserver)
+	ts, err := toposerver.Open(ctx, vts.Namespace, vts.Spec.GlobalLockserver)
 	if err != nil {
 		r.recorder.Eventf(vts, corev1.EventTypeWarning, "TopoConnectFailed", "failed to connect to global lockserver: %v", err)
 		// Give the lockserver some time to come up.
@@ -82,8 +84,13 @@ func (r *ReconcileVitessShard) reconcileTopology(ctx context.Context, vts *plane
 		if servingCells, err := ts.GetShardServingCells(ctx, shard); err == nil {
 			vts.Status.Idle = k8s.ConditionStatus(len(servingCells) == 0)
 
+			// The status still lists what was pending last time, so we only
+			// announce newly pending cells. Clear it in case we don't prune now.
+			previouslyPending := vts.Status.PendingPruneShardCells
+			vts.Status.PendingPruneShardCells = nil
+
 			if *vts.Spec.TopologyReconciliation.PruneShardCells {
-				result, err := r.pruneShardCells(ctx, vts, keyspaceName, servingCells, wr)
+				result, err := r.pruneShardCells(ctx, vts, keyspaceName, servingCells, wr, previouslyPending)
 				resultBuilder.Merge(result, err)
 			}
 		} else {
@@ -107,8 +114,13 @@ func (r *ReconcileVitessShard) reconcileTopology(ctx context.Context, vts *plane
 			vts.Status.Tablets[name] = status
 		}
 
+		// The status still lists what was pending last time, so we only
+		// announce newly pending tablets. Clear it in case we don't prune now.
+		previouslyPending := vts.Status.PendingPruneTablets
+		vts.Status.PendingPruneTablets = nil
+
 		if *vts.Spec.TopologyReconciliation.PruneTablets {
-			result, err := r.pruneTablets(ctx, vts, tablets, wr)
+			result, err := r.pruneTablets(ctx, vts, tablets, wr, previouslyPending)
 			resultBuilder.Merge(result, err)
 		}
 	} else {
@@ -119,10 +131,11 @@ func (r *ReconcileVitessShard) reconcileTopology(ctx context.Context, vts *plane
 	return resultBuilder.Result()
 }
 
-func (r *ReconcileVitessShard) pruneTablets(ctx context.Context, vts *planetscalev2.VitessShard, tablets map[string]*topo.TabletInfo, wr *wrangler.Wrangler) (reconcile.Result, error) {
+func (r *ReconcileVitessShard) pruneTablets(ctx context.Context, vts *planetscalev2.VitessShard, tablets map[string]*topo.TabletInfo, wr *wrangler.Wrangler, previouslyPending []string) (reconcile.Result, error) {
 	resultBuilder := &results.Builder{}
 
-	// Clean up tablets that exist but shouldn't.
+	// Find tablets that exist but shouldn't.
+	var candidates []string
 	for name, tabletInfo := range tablets {
 		if !vts.Spec.CellInCluster(tabletInfo.Alias.GetCell()) {
 			// Skip tablets whose cell is not defined in the VitessCluster.
@@ -136,24 +149,40 @@ func (r *ReconcileVitessShard) pruneTablets(ctx context.Context, vts *planetscal
 		if !desired && !orphaned {
 			// The tablet exists in topo, but not in the VitessShard spec.
 			// It's also not being kept around by a blocked turn-down.
-			// We use the Vitess wrangler (multi-step command executor) to delete the tablet.
-			// This is equivalent to `vtctl DeleteTablet`.
-			if err := wr.DeleteTablet(ctx, tabletInfo.Alias, false /* allowPrimary */); err != nil {
-				r.recorder.Eventf(vts, corev1.EventTypeWarning, "TopoCleanupFailed", "unable to remove tablet %s from topology: %v", name, err)
-				resultBuilder.RequeueAfter(topoRequeueDelay)
-			} else {
-				r.recorder.Eventf(vts, corev1.EventTypeNormal, "TopoCleanup", "removed unwanted tablet %s from topology", name)
-			}
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates)
+
+	approval := vitesstopo.NewPruneApproval(vts, vitesstopo.ApprovePruneTabletsAnnotation,
+		*vts.Spec.TopologyReconciliation.PruneTabletsDryRun, vts.Status.UsedPruneApprovals, previouslyPending)
+	release, pending := approval.Hold(r.recorder, vts, "tablet", candidates)
+
+	// Clean up the tablets that we may prune now.
+	var pruned []string
+	for _, name := range release {
+		// We use the Vitess wrangler (multi-step command executor) to delete the tablet.
+		// This is equivalent to `vtctl DeleteTablet`.
+		if err := wr.DeleteTablet(ctx, tablets[name].Alias, false /* allowPrimary */); err != nil {
+			r.recorder.Eventf(vts, corev1.EventTypeWarning, "TopoCleanupFailed", "unable to remove tablet %s from topology: %v", name, err)
+			resultBuilder.RequeueAfter(topoRequeueDelay)
+		} else {
+			r.recorder.Eventf(vts, corev1.EventTypeNormal, "TopoCleanup", "removed unwanted tablet %s from topology", name)
+			pruned = append(pruned, name)
 		}
 	}
 
+	vts.Status.PendingPruneTablets = pending
+	vitesstopo.RecordUsedApprovals(&vts.Status.UsedPruneApprovals, vitesstopo.ApprovePruneTabletsAnnotation, approval.UsedAfter(pruned))
+
 	return resultBuilder.Result()
 }
 
-func (r *ReconcileVitessShard) pruneShardCells(ctx context.Context, vts *planetscalev2.VitessShard, keyspaceName string, servingCells []string, wr *wrangler.Wrangler) (reconcile.Result, error) {
+func (r *ReconcileVitessShard) pruneShardCells(ctx context.Context, vts *planetscalev2.VitessShard, keyspaceName string, servingCells []string, wr *wrangler.Wrangler, previouslyPending []string) (reconcile.Result, error) {
 	resultBuilder := &results.Builder{}
 
-	// Clean up cells from the shard record that we don't deploy to anymore.
+	// Find cells in the shard record that we don't deploy to anymore.
+	var candidates []string
 	for _, cellName := range servingCells {
 		if !vts.Spec.CellInCluster(cellName) {
 			// Skip cells that are not even present in the VitessCluster.
@@ -167,6 +196,16 @@ func (r *ReconcileVitessShard) pruneShardCells(ctx context.Context, vts *planets
 		}
 
 		// The cell is listed in topo, but we don't deploy there anymore.
+		candidates = append(candidates, cellName)
+	}
+
+	approval := vitesstopo.NewPruneApproval(vts, vitesstopo.ApprovePruneShardCellsAnnotation,
+		*vts.Spec.TopologyReconciliation.PruneShardCellsDryRun, vts.Status.UsedPruneApprovals, previouslyPending)
+	release, pending := approval.Hold(r.recorder, vts, "shard cell", candidates)
+
+	// Clean up the cells that we may prune now.
+	var pruned []string
+	for _, cellName := range release {
 		// We use the Vitess wrangler (multi-step command executor) to remove the cell from that shard.
 		// This is equivalent to `vtctl RemoveShardCell`.
 		if _, err := wr.VtctldServer().RemoveShardCell(ctx, &vtctldatapb.RemoveShardCellRequest{
@@ -180,8 +219,12 @@ func (r *ReconcileVitessShard) pruneShardCells(ctx context.Context, vts *planets
 			resultBuilder.RequeueAfter(topoRequeueDelay)
 		} else {
 			r.recorder.Eventf(vts, corev1.EventTypeNormal, "TopoCleanup", "removed unwanted cell %s from shard", cellName)
+			pruned = append(pruned, cellName)
 		}
 	}
 
+	vts.Status.PendingPruneShardCells = pending
+	vitesstopo.RecordUsedApprovals(&vts.Status.UsedPruneApprovals, vitesstopo.ApprovePruneShardCellsAnnotation, approval.UsedAfter(pruned))
+
 	return resultBuilder.Result()
 }
@@ -23,12 +23,16 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
 	"planetscale.dev/vitess-operator/pkg/operator/conditions"
+	"planetscale.dev/vitess-operator/pkg/operator/internaltls"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
+	"planetscale.dev/vitess-operator/pkg/operator/pdb"
 	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
 	"planetscale.dev/vitess-operator/pkg/operator/results"
 	"planetscale.dev/vitess-operator/pkg/operator/vtorc"
@@ -46,7 +50,20 @@ func (r *ReconcileVitessShard) reconcileVtorc(ctx context.Context, vts *planetsc
 	}
 
 	// Reconcile vtorc Deployments.
+	internalTLSHash, err := internaltls.ContentHash(ctx, r.client, vts.Namespace, vts.Spec.InternalTLS)
+	if err != nil {
+		// Record error and return, to avoid generating a Deployment based on incomplete information.
+		return resultBuilder.Error(err)
+	}
+	topoTLSHash, err := lockserver.ClientTLSContentHash(ctx, r.client, vts.Namespace, &vts.Spec.GlobalLockserver)
+	if err != nil {
+		return resultBuilder.Error(err)
+	}
 	specs := r.vtorcSpecs(vts, labels)
+	for _, spec := range specs {
+		spec.Annotations = internaltls.Annotations(spec.Annotations, internalTLSHash)
+		spec.Annotations = lockserver.ClientTLSAnnotations(spec.Annotations, topoTLSHash)
+	}
 
 	// Generate keys (object names) for all desired vtorc Deployments.
 	// Keep a map back from generated names to the vtorc specs.
@@ -63,7 +80,7 @@ func (r *ReconcileVitessShard) reconcileVtorc(ctx context.Context, vts *planetsc
 		specMap[key] = spec
 	}
 
-	err := r.reconciler.ReconcileObjectSet(ctx, vts, keys, labels, reconciler.Strategy{
+	err = r.reconciler.ReconcileObjectSet(ctx, vts, keys, labels, reconciler.Strategy{
 		Kind: &appsv1.Deployment{},
 
 		New: func(key client.ObjectKey) runtime.Object {
@@ -100,6 +117,24 @@ func (r *ReconcileVitessShard) reconcileVtorc(ctx context.Context, vts *planetsc
 		resultBuilder.Error(err)
 	}
 
+	// Reconcile the vtorc PodDisruptionBudget, which covers vtorc in all cells.
+	key := client.ObjectKey{Namespace: vts.Namespace, Name: vtorc.PDBName(clusterName, labels[planetscalev2.KeyspaceLabel], vts.Spec.KeyRange)}
+	wantPDB := len(specs) > 0 && pdb.Enabled(vts.Spec.VitessOrchestrator.PodDisruptionBudget)
+	err = r.reconciler.ReconcileObject(ctx, vts, key, labels, wantPDB, reconciler.Strategy{
+		Kind: &policyv1.PodDisruptionBudget{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return pdb.NewPDB(key, pdb.NewSpec(labels, vts.Spec.VitessOrchestrator.PodDisruptionBudget))
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			newObj := obj.(*policyv1.PodDisruptionBudget)
+			pdb.UpdatePDB(newObj, pdb.NewSpec(labels, vts.Spec.VitessOrchestrator.PodDisruptionBudget))
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
 	return resultBuilder.Result()
 }
 
@@ -107,6 +142,11 @@ func (r *ReconcileVitessShard) vtorcSpecs(vts *planetscalev2.VitessShard, parent
 	if vts.Spec.VitessOrchestrator == nil {
 		return nil
 	}
+	if vts.Spec.PauseVitessOrchestrator {
+		// A global lockserver migration needs vtorc to stay out of the way.
+		// Turning it down is safe, since it keeps no state of its own.
+		return nil
+	}
 
 	specs := make([]*vtorc.Spec, 0, len(vts.Spec.TabletPools))
 
@@ -156,6 +196,7 @@ func (r *ReconcileVitessShard) vtorcSpecs(vts *planetscalev2.VitessShard, parent
 			Annotations:       vts.Spec.VitessOrchestrator.Annotations,
 			ExtraLabels:       vts.Spec.VitessOrchestrator.ExtraLabels,
 			Tolerations:       vts.Spec.VitessOrchestrator.Tolerations,
+			InternalTLS:       vts.Spec.InternalTLS,
 		})
 	}
 	return specs
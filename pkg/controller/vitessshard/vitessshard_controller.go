@@ -24,9 +24,12 @@ import (
 	"github.com/sirupsen/logrus"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -37,10 +40,12 @@ import (
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
 	"planetscale.dev/vitess-operator/pkg/operator/environment"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
 	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
 	"planetscale.dev/vitess-operator/pkg/operator/results"
 	"planetscale.dev/vitess-operator/pkg/operator/resync"
 	"planetscale.dev/vitess-operator/pkg/operator/vitessshard"
+	"planetscale.dev/vitess-operator/pkg/operator/volumestats"
 )
 
 const (
@@ -58,27 +63,39 @@ var log = logrus.WithField("controller", "VitessShard")
 var watchResources = []client.Object{
 	&corev1.Pod{},
 	&corev1.PersistentVolumeClaim{},
+	&policyv1.PodDisruptionBudget{},
+	&networkingv1.NetworkPolicy{},
 }
 
 // Add creates a new VitessShard Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) *ReconcileVitessShard {
+func newReconciler(mgr manager.Manager) (*ReconcileVitessShard, error) {
 	c := mgr.GetClient()
 	scheme := mgr.GetScheme()
 	recorder := mgr.GetEventRecorderFor(controllerName)
 
-	return &ReconcileVitessShard{
-		client:     c,
-		scheme:     scheme,
-		resync:     resync.NewPeriodic(controllerName, *resyncPeriod),
-		recorder:   recorder,
-		reconciler: reconciler.New(c, scheme, recorder),
+	coreClient, err := corev1client.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
 	}
+
+	return &ReconcileVitessShard{
+		client:      c,
+		scheme:      scheme,
+		resync:      resync.NewPeriodic(controllerName, *resyncPeriod),
+		recorder:    recorder,
+		reconciler:  reconciler.New(c, scheme, recorder),
+		volumeStats: volumestats.NewGetter(coreClient.RESTClient()),
+	}, nil
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -116,6 +133,15 @@ func add(mgr manager.Manager, r *ReconcileVitessShard) error {
 		return err
 	}
 
+	// Watch for changes in Secrets, which we don't own, and requeue associated VitessShards.
+	ssm := &secretShardsMapper{
+		client: mgr.GetClient(),
+	}
+	err = c.Watch(source.Kind(mgr.GetCache(), &corev1.Secret{}, handler.TypedEnqueueRequestsFromMapFunc[*corev1.Secret](ssm.Map)))
+	if err != nil {
+		return err
+	}
+
 	// Periodically resync even when no Kubernetes events have come in.
 	if err := c.Watch(r.resync.WatchSource()); err != nil {
 		return err
@@ -130,11 +156,12 @@ var _ reconcile.Reconciler = &ReconcileVitessShard{}
 type ReconcileVitessShard struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client     client.Client
-	scheme     *runtime.Scheme
-	resync     *resync.Periodic
-	recorder   record.EventRecorder
-	reconciler *reconciler.Reconciler
+	client      client.Client
+	scheme      *runtime.Scheme
+	resync      *resync.Periodic
+	recorder    record.EventRecorder
+	reconciler  *reconciler.Reconciler
+	volumeStats volumestats.Getter
 }
 
 // Reconcile reads that state of the cluster for a VitessShard object and makes changes based on the state read
@@ -179,6 +206,11 @@ func (r *ReconcileVitessShard) Reconcile(cctx context.Context, request reconcile
 	if oldStatus.Conditions != nil {
 		vts.Status.Conditions = oldStatus.DeepCopyConditions()
 	}
+	// Topology reconciliation needs to know which prunes were already pending
+	// or approved. It updates these itself when it gets that far.
+	vts.Status.PendingPruneTablets = oldStatus.PendingPruneTablets
+	vts.Status.PendingPruneShardCells = oldStatus.PendingPruneShardCells
+	vts.Status.UsedPruneApprovals = oldStatus.UsedPruneApprovals
 
 	// Create/update vtorc.
 	vtorcResult, err := r.reconcileVtorc(ctx, vts)
@@ -188,6 +220,19 @@ func (r *ReconcileVitessShard) Reconcile(cctx context.Context, request reconcile
 	tabletResult, err := r.reconcileTablets(ctx, vts)
 	resultBuilder.Merge(tabletResult, err)
 
+	// Keep node drains from evicting too many tablets of this shard at once.
+	tabletPDBResult, err := r.reconcileTabletPDB(ctx, vts)
+	resultBuilder.Merge(tabletPDBResult, err)
+
+	// Restrict which Pods may reach this shard's tablets, if enabled.
+	tabletNetworkPolicyResult, err := r.reconcileTabletNetworkPolicy(ctx, vts)
+	resultBuilder.Merge(tabletNetworkPolicyResult, err)
+
+	// Grow data volumes that are running out of space, if autoscaling is enabled.
+	// NOTE: This must always be done after reconcileTablets, so Status.Tablets is populated.
+	diskAutoscalerResult, err := r.reconcileDiskAutoscaler(ctx, vts)
+	resultBuilder.Merge(diskAutoscalerResult, err)
+
 	// Mark tablet pods for disk size updates if needed.
 	// NOTE: This must always be done after reconcileTablets, so Status.Tablets is populated
 	diskUpdateResult, err := r.reconcileDisk(ctx, vts)
@@ -207,6 +252,11 @@ func (r *ReconcileVitessShard) Reconcile(cctx context.Context, request reconcile
 	backupResult, err := r.reconcileBackupJob(ctx, vts)
 	resultBuilder.Merge(backupResult, err)
 
+	// Replace tablets whose data volumes need to move to a new StorageClass.
+	// NOTE: This must always be done after reconcileTopology and reconcileBackupJob.
+	storageMigrationResult, err := r.reconcileStorageMigration(ctx, vts)
+	resultBuilder.Merge(storageMigrationResult, err)
+
 	// Update status if needed.
 	vts.Status.ObservedGeneration = vts.Generation
 	if !apiequality.Semantic.DeepEqual(&vts.Status, &oldStatus) {
@@ -239,3 +289,34 @@ func shardBackupMapper(ctx context.Context, vtb *planetscalev2.VitessBackup) []r
 		},
 	}
 }
+
+type secretShardsMapper struct {
+	client client.Client
+}
+
+// Map maps a Secret to a list of requests for VitessShards
+// that use it for internal TLS.
+func (m *secretShardsMapper) Map(ctx context.Context, secret *corev1.Secret) []reconcile.Request {
+	shardList := &planetscalev2.VitessShardList{}
+	opts := &client.ListOptions{
+		Namespace: secret.Namespace,
+	}
+	if err := m.client.List(ctx, shardList, opts); err != nil {
+		log.WithError(err).Error("failed to list VitessShards; unable to map Secrets to matching VitessShards")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range shardList.Items {
+		vts := &shardList.Items[i]
+		if vts.Spec.InternalTLS.SecretNames().Has(secret.Name) || lockserver.ClientTLSSecretNames(&vts.Spec.GlobalLockserver).Has(secret.Name) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKey{
+					Namespace: vts.Namespace,
+					Name:      vts.Name,
+				},
+			})
+		}
+	}
+	return requests
+}
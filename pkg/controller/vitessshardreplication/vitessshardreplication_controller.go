@@ -184,7 +184,7 @@ func (r *ReconcileVitessShard) Reconcile(cctx context.Context, request reconcile
 	}
 
 	// Get a connection to Vitess topology for this cluster.
-	ts, err := toposerver.Open(ctx, vts.Spec.GlobalLockserver)
+	ts, err := toposerver.Open(ctx, vts.Namespace, vts.Spec.GlobalLockserver)
 	if err != nil {
 		r.recorder.Eventf(vts, corev1.EventTypeWarning, "TopoConnectFailed", "failed to connect to global lockserver: %v", err)
 		// Give the lockserver some time to come up.
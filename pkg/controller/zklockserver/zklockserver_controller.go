@@ -0,0 +1,219 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zklockserver
+
+import (
+	"context"
+	"flag"
+
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/environment"
+	"planetscale.dev/vitess-operator/pkg/operator/metrics"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/serverset"
+	"planetscale.dev/vitess-operator/pkg/operator/zookeeper"
+)
+
+const (
+	controllerName = "zklockserver-controller"
+)
+
+var (
+	maxConcurrentReconciles = flag.Int("zklockserver_concurrent_reconciles", 10, "the maximum number of different zklockservers to reconcile concurrently")
+)
+
+var log = logrus.WithField("controller", "ZkLockserver")
+
+// watchResources should contain all the resource types that this controller creates.
+var watchResources = []client.Object{
+	&corev1.Pod{},
+	&corev1.Service{},
+	&corev1.PersistentVolumeClaim{},
+	&policyv1.PodDisruptionBudget{},
+}
+
+// Add creates a new ZkLockserver Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	c := mgr.GetClient()
+	scheme := mgr.GetScheme()
+	recorder := mgr.GetEventRecorderFor(controllerName)
+
+	return &ReconcileZkLockserver{
+		client:     c,
+		scheme:     scheme,
+		recorder:   recorder,
+		reconciler: reconciler.New(c, scheme, recorder),
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New(controllerName, mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: *maxConcurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource ZkLockserver
+	if err := c.Watch(source.Kind(mgr.GetCache(), &planetscalev2.ZkLockserver{}, &handler.TypedEnqueueRequestForObject[*planetscalev2.ZkLockserver]{})); err != nil {
+		return err
+	}
+
+	// Watch for changes to secondary resources and requeue the owner ZkLockserver.
+	for _, resource := range watchResources {
+		err := c.Watch(source.Kind(mgr.GetCache(), resource, handler.EnqueueRequestForOwner(
+			mgr.GetScheme(),
+			mgr.GetRESTMapper(),
+			&planetscalev2.ZkLockserver{},
+			handler.OnlyControllerOwner(),
+		)))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileZkLockserver{}
+
+// ReconcileZkLockserver reconciles a ZkLockserver object
+type ReconcileZkLockserver struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client     client.Client
+	scheme     *runtime.Scheme
+	recorder   record.EventRecorder
+	reconciler *reconciler.Reconciler
+}
+
+// Reconcile reads that state of the cluster for a ZkLockserver object and makes changes based on the state read
+// and what is in the ZkLockserver.Spec
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileZkLockserver) Reconcile(cctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	ctx, cancel := context.WithTimeout(cctx, environment.ReconcileTimeout())
+	defer cancel()
+
+	resultBuilder := &results.Builder{}
+	log := log.WithFields(logrus.Fields{
+		"namespace":    request.Namespace,
+		"zklockserver": request.Name,
+	})
+	log.Info("Reconciling ZkLockserver")
+
+	// Fetch the ZkLockserver instance.
+	ls := &planetscalev2.ZkLockserver{}
+	err := r.client.Get(ctx, request.NamespacedName, ls)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			return resultBuilder.Result()
+		}
+		// Error reading the object - requeue the request.
+		return resultBuilder.Error(err)
+	}
+
+	// Materialize defaults.
+	planetscalev2.DefaultZkLockserver(ls)
+
+	// Reset status, since that's all out of date info that we will recompute now.
+	oldStatus := ls.Status
+	ls.Status = *planetscalev2.NewZkLockserverStatus()
+
+	// Create/update Services, servers and PDB.
+	status, err := serverset.Reconcile(ctx, r.reconciler, zookeeper.Backend, serverSet(ls))
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+	ls.Status.Available = status.Available
+	ls.Status.ClientServiceName = status.ClientServiceName
+
+	// Update status if needed.
+	ls.Status.ObservedGeneration = ls.Generation
+	if !apiequality.Semantic.DeepEqual(&ls.Status, &oldStatus) {
+		if err := r.client.Status().Update(ctx, ls); err != nil {
+			if !apierrors.IsConflict(err) {
+				r.recorder.Eventf(ls, corev1.EventTypeWarning, "StatusUpdateFailed", "failed to update status: %v", err)
+			}
+			resultBuilder.Error(err)
+		}
+	}
+
+	// Update metrics.
+	available := float64(0)
+	if ls.Status.Available == corev1.ConditionTrue {
+		available = 1
+	}
+	clusterAvailable.WithLabelValues(ls.Name).Set(available)
+
+	result, err := resultBuilder.Result()
+	reconcileCount.WithLabelValues(ls.Name, metrics.Result(err)).Inc()
+	return result, err
+}
+
+// serverSet returns the server set for a ZkLockserver with defaults filled in.
+func serverSet(ls *planetscalev2.ZkLockserver) *serverset.Lockserver {
+	return &serverset.Lockserver{
+		Object: ls,
+		Server: serverset.Spec{
+			Image:             ls.Spec.Image,
+			ImagePullPolicy:   ls.Spec.ImagePullPolicy,
+			ImagePullSecrets:  ls.Spec.ImagePullSecrets,
+			Resources:         ls.Spec.Resources,
+			Zone:              ls.Spec.Zone,
+			DataVolumePVCSpec: &ls.Spec.DataVolumeClaimTemplate,
+			ExtraEnv:          ls.Spec.ExtraEnv,
+			Affinity:          ls.Spec.Affinity,
+			Annotations:       ls.Spec.Annotations,
+			ExtraLabels:       ls.Spec.ExtraLabels,
+			Tolerations:       ls.Spec.Tolerations,
+			Extra:             ls.Spec.ExtraConfig,
+			Replicas:          int(*ls.Spec.Replicas),
+		},
+		CreatePDB:     *ls.Spec.CreatePDB,
+		ClientService: ls.Spec.ClientService,
+	}
+}
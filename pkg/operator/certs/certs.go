@@ -0,0 +1,233 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package certs issues and renews the X.509 certificates for operator-managed TLS.
+
+The operator keeps a self-signed CA in a Secret and uses it to sign serving
+certificates, which are stored in Secrets of type kubernetes.io/tls along with
+a copy of the CA certificate. Certificates are renewed when they get close to
+expiry, or when the names they need to cover change.
+*/
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// CertKey is the Secret data key for the PEM-encoded certificate.
+	CertKey = corev1.TLSCertKey
+	// PrivateKeyKey is the Secret data key for the PEM-encoded private key.
+	PrivateKeyKey = corev1.TLSPrivateKeyKey
+	// CACertKey is the Secret data key for the PEM-encoded CA certificate.
+	CACertKey = "ca.crt"
+
+	// CAValidity is how long an operator-managed CA is valid.
+	CAValidity = 10 * 365 * 24 * time.Hour
+
+	// clockSkew is how far back we date the start of validity, to tolerate
+	// clients whose clocks are slightly behind ours.
+	clockSkew = 5 * time.Minute
+
+	// minRenewalDelay is the shortest time we wait before checking again on a
+	// certificate that is already due for renewal.
+	minRenewalDelay = 1 * time.Minute
+)
+
+// KeyPair is a PEM-encoded certificate and private key.
+type KeyPair struct {
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// KeyPairFromSecret returns the certificate and key stored in a Secret.
+// It returns nil if either one is missing.
+func KeyPairFromSecret(secret *corev1.Secret) *KeyPair {
+	if secret == nil || len(secret.Data[CertKey]) == 0 || len(secret.Data[PrivateKeyKey]) == 0 {
+		return nil
+	}
+	return &KeyPair{
+		CertPEM: secret.Data[CertKey],
+		KeyPEM:  secret.Data[PrivateKeyKey],
+	}
+}
+
+// SecretData returns the Secret data for this key pair, along with the
+// certificate of the CA that issued it, if any.
+func (kp *KeyPair) SecretData(caCertPEM []byte) map[string][]byte {
+	data := map[string][]byte{
+		CertKey:       kp.CertPEM,
+		PrivateKeyKey: kp.KeyPEM,
+	}
+	if len(caCertPEM) > 0 {
+		data[CACertKey] = caCertPEM
+	}
+	return data
+}
+
+// NewCA generates a new self-signed CA.
+func NewCA(commonName string, now time.Time) (*KeyPair, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-clockSkew),
+		NotAfter:              now.Add(CAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return issue(template, nil)
+}
+
+// IssueCert signs a new certificate for the given DNS names with the CA.
+// The certificate can be used for both server and client authentication.
+func IssueCert(ca *KeyPair, commonName string, dnsNames []string, validity time.Duration, now time.Time) (*KeyPair, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		DNSNames:    dnsNames,
+		NotBefore:   now.Add(-clockSkew),
+		NotAfter:    now.Add(validity),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	return issue(template, ca)
+}
+
+// issue generates a key and signs the template with the CA, or self-signs if
+// the CA is nil.
+func issue(template *x509.Certificate, ca *KeyPair) (*KeyPair, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("can't generate serial number: %v", err)
+	}
+	template.SerialNumber = serial
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("can't generate private key: %v", err)
+	}
+
+	parent, signer := template, any(key)
+	if ca != nil {
+		parent, err = ParseCertificate(ca.CertPEM)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse CA certificate: %v", err)
+		}
+		signer, err = parsePrivateKey(ca.KeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse CA private key: %v", err)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		return nil, fmt.Errorf("can't sign certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode private key: %v", err)
+	}
+	return &KeyPair{
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+// ParseCertificate parses the first certificate in a PEM bundle.
+func ParseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM-encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parsePrivateKey(keyPEM []byte) (any, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM-encoded private key found")
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+// RenewalTime returns when a certificate should be renewed.
+func RenewalTime(cert *x509.Certificate, renewBefore time.Duration) time.Time {
+	return cert.NotAfter.Add(-renewBefore)
+}
+
+// RenewalDelay returns how long to wait before checking again whether a
+// certificate needs to be renewed. If it's already due, for example because
+// the last attempt failed, it returns minRenewalDelay instead of a delay that
+// is zero or negative.
+func RenewalDelay(cert *x509.Certificate, renewBefore time.Duration, now time.Time) time.Duration {
+	return max(RenewalTime(cert, renewBefore).Sub(now), minRenewalDelay)
+}
+
+// NeedsRenewal returns whether a certificate must be reissued, either because
+// it's unreadable, it's due for renewal, it doesn't cover exactly the given
+// DNS names, or it wasn't signed by the given CA.
+func NeedsRenewal(kp *KeyPair, caCertPEM []byte, dnsNames []string, renewBefore time.Duration, now time.Time) bool {
+	if kp == nil {
+		return true
+	}
+	cert, err := ParseCertificate(kp.CertPEM)
+	if err != nil {
+		return true
+	}
+	if _, err := parsePrivateKey(kp.KeyPEM); err != nil {
+		return true
+	}
+	if !now.Before(RenewalTime(cert, renewBefore)) {
+		return true
+	}
+	if !sameNames(cert.DNSNames, dnsNames) {
+		return true
+	}
+	caCert, err := ParseCertificate(caCertPEM)
+	if err != nil {
+		return true
+	}
+	return cert.CheckSignatureFrom(caCert) != nil
+}
+
+func sameNames(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// ServiceDNSNames returns the DNS names by which a Service can be reached
+// from within the cluster.
+func ServiceDNSNames(serviceName, namespace string) []string {
+	return []string{
+		serviceName,
+		serviceName + "." + namespace,
+		serviceName + "." + namespace + ".svc",
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestIssueCert(t *testing.T) {
+	now := time.Now()
+	ca, err := NewCA("test-ca", now)
+	if err != nil {
+		t.Fatalf("NewCA() error: %v", err)
+	}
+	dnsNames := []string{"svc", "svc.ns", "svc.ns.svc"}
+	kp, err := IssueCert(ca, "svc", dnsNames, 24*time.Hour, now)
+	if err != nil {
+		t.Fatalf("IssueCert() error: %v", err)
+	}
+
+	cert, err := ParseCertificate(kp.CertPEM)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error: %v", err)
+	}
+	caCert, err := ParseCertificate(ca.CertPEM)
+	if err != nil {
+		t.Fatalf("ParseCertificate(CA) error: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	if _, err := cert.Verify(x509.VerifyOptions{DNSName: "svc.ns.svc", Roots: roots, CurrentTime: now}); err != nil {
+		t.Errorf("issued certificate doesn't verify against CA: %v", err)
+	}
+}
+
+func TestNeedsRenewal(t *testing.T) {
+	now := time.Now()
+	ca, err := NewCA("test-ca", now)
+	if err != nil {
+		t.Fatalf("NewCA() error: %v", err)
+	}
+	otherCA, err := NewCA("other-ca", now)
+	if err != nil {
+		t.Fatalf("NewCA() error: %v", err)
+	}
+	dnsNames := []string{"a", "b"}
+	kp, err := IssueCert(ca, "a", dnsNames, 10*24*time.Hour, now)
+	if err != nil {
+		t.Fatalf("IssueCert() error: %v", err)
+	}
+
+	tests := map[string]struct {
+		kp          *KeyPair
+		caCertPEM   []byte
+		dnsNames    []string
+		renewBefore time.Duration
+		now         time.Time
+		want        bool
+	}{
+		"valid": {
+			kp: kp, caCertPEM: ca.CertPEM, dnsNames: dnsNames, renewBefore: 3 * 24 * time.Hour, now: now,
+			want: false,
+		},
+		"names in a different order": {
+			kp: kp, caCertPEM: ca.CertPEM, dnsNames: []string{"b", "a"}, renewBefore: 3 * 24 * time.Hour, now: now,
+			want: false,
+		},
+		"missing": {
+			kp: nil, caCertPEM: ca.CertPEM, dnsNames: dnsNames, renewBefore: 3 * 24 * time.Hour, now: now,
+			want: true,
+		},
+		"garbage": {
+			kp: &KeyPair{CertPEM: []byte("nope"), KeyPEM: kp.KeyPEM}, caCertPEM: ca.CertPEM, dnsNames: dnsNames, renewBefore: 3 * 24 * time.Hour, now: now,
+			want: true,
+		},
+		"due for renewal": {
+			kp: kp, caCertPEM: ca.CertPEM, dnsNames: dnsNames, renewBefore: 3 * 24 * time.Hour, now: now.Add(8 * 24 * time.Hour),
+			want: true,
+		},
+		"names changed": {
+			kp: kp, caCertPEM: ca.CertPEM, dnsNames: []string{"a", "b", "c"}, renewBefore: 3 * 24 * time.Hour, now: now,
+			want: true,
+		},
+		"different CA": {
+			kp: kp, caCertPEM: otherCA.CertPEM, dnsNames: dnsNames, renewBefore: 3 * 24 * time.Hour, now: now,
+			want: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := NeedsRenewal(test.kp, test.caCertPEM, test.dnsNames, test.renewBefore, test.now); got != test.want {
+				t.Errorf("NeedsRenewal() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenewalDelay(t *testing.T) {
+	now := time.Now()
+	cert := &x509.Certificate{NotAfter: now.Add(10 * time.Hour)}
+
+	if got, want := RenewalDelay(cert, 4*time.Hour, now), 6*time.Hour; got != want {
+		t.Errorf("RenewalDelay() = %v, want %v", got, want)
+	}
+	// A certificate that's already due must not turn into a busy loop or a
+	// negative delay.
+	if got := RenewalDelay(cert, 12*time.Hour, now); got != minRenewalDelay {
+		t.Errorf("RenewalDelay() = %v, want %v", got, minRenewalDelay)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consul
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/serverset"
+	"planetscale.dev/vitess-operator/pkg/operator/vitess"
+)
+
+const (
+	// LockserverLabel is the label that should be added to Pods to identify
+	// which lockserver cluster they belong to.
+	LockserverLabel = "consul.planetscale.com/lockserver"
+	// IndexLabel is the label used to identify the index of a server.
+	IndexLabel = "consul.planetscale.com/index"
+
+	// ClientPortName is the name of the Consul HTTP API port.
+	ClientPortName = "http"
+	// ClientPortNumber is the port number of the Consul HTTP API.
+	ClientPortNumber = 8500
+
+	// ServerPortName is the name of the Consul server RPC port.
+	ServerPortName = "server"
+	// ServerPortNumber is the port number for Consul server RPC.
+	ServerPortNumber = 8300
+
+	// SerfPortName is the name of the Consul LAN gossip port.
+	SerfPortName = "serf"
+	// SerfPortNumber is the port number for Consul LAN gossip.
+	SerfPortNumber = 8301
+)
+
+// Backend runs a Consul cluster as a server set.
+var Backend = &serverset.Backend{
+	ComponentName:   planetscalev2.ConsulComponentName,
+	LockserverLabel: LockserverLabel,
+	IndexLabel:      IndexLabel,
+	PDBNamePrefix:   "consul-lockserver-",
+
+	ContainerName: "consul",
+	ClientPort:    serverset.Port{Name: ClientPortName, Number: ClientPortNumber},
+	PeerPorts: []serverset.Port{
+		{Name: ServerPortName, Number: ServerPortNumber},
+		{Name: SerfPortName, Number: SerfPortNumber},
+	},
+	// The official image's entrypoint adds the data and config dir flags,
+	// and fixes up ownership of the data dir, when the first arg is "agent".
+	DataVolumeMountPath: "/consul/data",
+	// This only succeeds once the cluster has elected a leader.
+	ReadinessCommand: []string{"consul", "operator", "raft", "list-peers"},
+
+	Args: Args,
+	Env: func(spec *serverset.Spec) []corev1.EnvVar {
+		return []corev1.EnvVar{
+			{
+				// Consul servers must advertise an address that the other
+				// servers can reach directly, which is the Pod IP.
+				Name: "POD_IP",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "status.podIP",
+					},
+				},
+			},
+		}
+	},
+}
+
+// Args returns the Consul agent args. The extra settings of the spec are
+// extra agent flags.
+func Args(spec *serverset.Spec) []string {
+	flags := vitess.Flags{
+		"server":           true,
+		"ui":               false,
+		"bootstrap-expect": spec.Replicas,
+		"node":             serverset.PodName(spec.LockserverName, spec.Index),
+		"client":           "0.0.0.0",
+		"bind":             "0.0.0.0",
+		"advertise":        "$(POD_IP)",
+	}
+
+	// Apply user-supplied extra flags last so they take precedence.
+	for key, value := range spec.Extra {
+		// We told users in the CRD API field doc not to put any leading '-',
+		// but we are liberal in what we accept.
+		key = strings.TrimLeft(key, "-")
+		flags[key] = value
+	}
+
+	args := append([]string{"agent"}, flags.FormatArgs()...)
+
+	// Every server retries joining every other server, so it doesn't matter
+	// which of them come up first. This flag may be repeated, so it can't go
+	// in the map.
+	subdomain := serverset.PeerServiceName(spec.LockserverName)
+	for i := 1; i <= spec.Replicas; i++ {
+		if i == spec.Index {
+			continue
+		}
+		args = append(args, fmt.Sprintf("--retry-join=%s.%s", serverset.PodName(spec.LockserverName, i), subdomain))
+	}
+	return args
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consul
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"planetscale.dev/vitess-operator/pkg/operator/serverset"
+)
+
+func TestArgs(t *testing.T) {
+	spec := &serverset.Spec{
+		LockserverName: "example-consul",
+		Index:          2,
+		Replicas:       3,
+		Extra:          map[string]string{"-log-level": "debug", "ui": "true"},
+	}
+	args := Args(spec)
+
+	assert.Equal(t, "agent", args[0])
+	assert.Contains(t, args, "--bootstrap-expect=3")
+	assert.Contains(t, args, "--node=example-consul-2")
+	// User flags override ours, with or without a leading '-'.
+	assert.Contains(t, args, "--log-level=debug")
+	assert.Contains(t, args, "--ui=true")
+	assert.NotContains(t, args, "--ui=false")
+	// Each server joins every other server, but not itself.
+	assert.Contains(t, args, "--retry-join=example-consul-1.example-consul-peer")
+	assert.Contains(t, args, "--retry-join=example-consul-3.example-consul-peer")
+	assert.NotContains(t, args, "--retry-join=example-consul-2.example-consul-peer")
+}
@@ -12,6 +12,10 @@ import (
 
 	"planetscale.dev/vitess-operator/pkg/controller"
 	vbssubcontroller "planetscale.dev/vitess-operator/pkg/controller/vitessbackupstorage/subcontroller"
+	"planetscale.dev/vitess-operator/pkg/operator/environment"
+	"planetscale.dev/vitess-operator/pkg/operator/toposerver"
+	"planetscale.dev/vitess-operator/pkg/operator/vtgatemetrics"
+	"planetscale.dev/vitess-operator/pkg/webhook"
 )
 
 var log = logf.Log.WithName("controller-manager")
@@ -30,6 +34,9 @@ func New(forkPath string, cfg *rest.Config, opts manager.Options) (manager.Manag
 		return nil, err
 	}
 
+	// Let the topo connection pool read client TLS Secrets for lockservers.
+	toposerver.SetSecretReader(mgr.GetClient())
+
 	log.Info("Registering Components.")
 
 	// We use the fork path primarily to decide which controllers to run in this
@@ -41,6 +48,18 @@ func New(forkPath string, cfg *rest.Config, opts manager.Options) (manager.Manag
 		if err := controller.AddToManager(mgr); err != nil {
 			return nil, err
 		}
+		// Webhooks and the external metrics API also only run in the root
+		// process, since their registrations point at the operator's Service.
+		if environment.EvictionWebhookEnabled() {
+			if err := webhook.AddToManager(mgr); err != nil {
+				return nil, err
+			}
+		}
+		if environment.ExternalMetricsEnabled() {
+			if err := vtgatemetrics.Add(mgr); err != nil {
+				return nil, err
+			}
+		}
 	case vbssubcontroller.ForkPath:
 		// Run only the vitessbackupstorage subcontroller.
 		if err := vbssubcontroller.Add(mgr); err != nil {
@@ -32,6 +32,8 @@ import (
 
 var (
 	reconcileTimeout   time.Duration
+	evictionWebhook    bool
+	externalMetrics    bool
 	MySQLServerVersion = "8.0.40-Vitess"
 	// truncateUILen truncate queries in debug UIs to the given length. 0 means unlimited.
 	truncateUILen = 512
@@ -44,6 +46,8 @@ func FlagSet() *pflag.FlagSet {
 	operatorFlagSet := pflag.NewFlagSet("operator", pflag.ExitOnError)
 
 	operatorFlagSet.DurationVar(&reconcileTimeout, "reconcile_timeout", 10*time.Minute, "Maximum time that any controller will spend trying to reconcile a single object before giving up.")
+	operatorFlagSet.BoolVar(&evictionWebhook, "enable_eviction_webhook", false, "Serve the admission webhook that turns evictions of vttablet Pods into drain requests. Off by default because it requires a serving certificate and a ValidatingWebhookConfiguration (see deploy/eviction_webhook.yaml).")
+	operatorFlagSet.BoolVar(&externalMetrics, "enable_external_metrics", false, "Serve vtgate metrics such as QPS and connection count through the Kubernetes external metrics API, for use by vtgate autoscaling. Requires a serving certificate and an APIService (see deploy/external_metrics.yaml).")
 
 	operatorFlagSet.StringVar(&planetscalev2.DefaultVitessPriorityClass, "default_vitess_priority_class", planetscalev2.DefaultVitessPriorityClass, "Default PriorityClass to use for Pods that run Vitess components. An empty value means don't use any PriorityClass.")
 	operatorFlagSet.StringVar(&planetscalev2.DefaultVitessServiceAccount, "default_vitess_service_account", planetscalev2.DefaultVitessServiceAccount, "Default ServiceAccount to use for Pods that run Vitess components. An empty value means let Kubernetes fill in a default.")
@@ -55,6 +59,8 @@ func FlagSet() *pflag.FlagSet {
 	operatorFlagSet.Int64Var(&planetscalev2.DefaultEtcdFSGroup, "default_etcd_fs_group", planetscalev2.DefaultEtcdFSGroup, "Default GID to use for etcd Pods. A value less than 0 means don't set fsGroup at all.")
 
 	operatorFlagSet.StringVar(&planetscalev2.DefaultEtcdImage, "default_etcd_image", planetscalev2.DefaultEtcdImage, "Default etcd image to use when not specified in the CRD.")
+	operatorFlagSet.StringVar(&planetscalev2.DefaultConsulImage, "default_consul_image", planetscalev2.DefaultConsulImage, "Default Consul image to use when not specified in the CRD.")
+	operatorFlagSet.StringVar(&planetscalev2.DefaultZkImage, "default_zk_image", planetscalev2.DefaultZkImage, "Default ZooKeeper image to use when not specified in the CRD.")
 	operatorFlagSet.StringVar(&planetscalev2.DefaultImages.MysqldExporter, "default_mysqld_exporter_image", planetscalev2.DefaultImages.MysqldExporter, "Default mysqld-exporter image to use when not specified in the CRD.")
 
 	return operatorFlagSet
@@ -65,6 +71,16 @@ func ReconcileTimeout() time.Duration {
 	return reconcileTimeout
 }
 
+// EvictionWebhookEnabled returns whether the tablet eviction webhook should be served.
+func EvictionWebhookEnabled() bool {
+	return evictionWebhook
+}
+
+// ExternalMetricsEnabled returns whether the vtgate external metrics API should be served.
+func ExternalMetricsEnabled() bool {
+	return externalMetrics
+}
+
 // VtEnvironment gets the vitess environment to be used in the operator.
 func VtEnvironment() (*vtenv.Environment, error) {
 	return vtenv.New(vtenv.Options{
@@ -25,11 +25,11 @@ import (
 	"planetscale.dev/vitess-operator/pkg/operator/update"
 )
 
-const (
-	// QuorumSize is the number of replicas that must be available for the etcd
-	// lockserver to respond to queries.
-	QuorumSize = NumReplicas/2 + 1
-)
+// QuorumSize returns the number of members that must be available for an
+// etcd cluster with the given number of members to respond to queries.
+func QuorumSize(replicas int) int {
+	return replicas/2 + 1
+}
 
 // PDBName returns the name of the PDB for an EtcdLockserver.
 func PDBName(lockserverName string) string {
@@ -37,10 +37,10 @@ func PDBName(lockserverName string) string {
 }
 
 // NewPDB creates a new PDB.
-func NewPDB(key client.ObjectKey, labels map[string]string) *policyv1.PodDisruptionBudget {
+func NewPDB(key client.ObjectKey, labels map[string]string, replicas int) *policyv1.PodDisruptionBudget {
 	// This tells `kubectl drain` not to delete one of the members unless the
 	// number of remaining members will still be at least QuorumSize.
-	minAvailable := intstr.FromInt(QuorumSize)
+	minAvailable := intstr.FromInt(QuorumSize(replicas))
 
 	return &policyv1.PodDisruptionBudget{
 		ObjectMeta: metav1.ObjectMeta{
@@ -58,7 +58,11 @@ func NewPDB(key client.ObjectKey, labels map[string]string) *policyv1.PodDisrupt
 }
 
 // UpdatePDBInPlace updates an existing PDB in-place.
-func UpdatePDBInPlace(obj *policyv1.PodDisruptionBudget, labels map[string]string) {
+func UpdatePDBInPlace(obj *policyv1.PodDisruptionBudget, labels map[string]string, replicas int) {
 	// Update labels, but ignore existing ones we don't set.
 	update.Labels(&obj.Labels, labels)
+
+	// Keep the quorum size in sync with the number of members.
+	minAvailable := intstr.FromInt(QuorumSize(replicas))
+	obj.Spec.MinAvailable = &minAvailable
 }
@@ -26,6 +26,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/certs"
 	"planetscale.dev/vitess-operator/pkg/operator/desiredstatehash"
 	"planetscale.dev/vitess-operator/pkg/operator/k8s"
 	"planetscale.dev/vitess-operator/pkg/operator/update"
@@ -38,18 +39,32 @@ const (
 	LockserverLabel = "etcd.planetscale.com/lockserver"
 	// IndexLabel is the label used to identify the index of a member.
 	IndexLabel = "etcd.planetscale.com/index"
-
-	// NumReplicas is the number of members per etcd cluster.
-	//
-	// This is currently hard-coded because it doesn't really make sense to
-	// allow it to be customized. Anything less than 3 cannot maintain quorum
-	// if a single member becomes unavailable. Anything more than 3 adds latency
-	// without providing significant benefit to Vitess.
+	// BackupLockserverLabel identifies the lockserver that snapshot and restore
+	// Pods belong to. It's distinct from LockserverLabel so these Pods are never
+	// selected as members.
+	BackupLockserverLabel = "etcd.planetscale.com/backup-lockserver"
+
+	// JoinExistingAnnotation is set on the data volume PVC of a member that
+	// replaced a previous member with the same index. Such a member must join
+	// the existing cluster rather than bootstrap a new one.
+	JoinExistingAnnotation = "etcd.planetscale.com/join-existing"
+
+	// InitialClusterSizeAnnotation is set on the data volume PVC of a member
+	// to record how many members were in the cluster when that member was
+	// bootstrapped. The member's initial cluster flags must stay the same for
+	// as long as its data volume exists, even if the cluster is later scaled.
+	InitialClusterSizeAnnotation = "etcd.planetscale.com/initial-cluster-size"
+
+	// DefaultReplicas is the number of members that an etcd cluster was
+	// bootstrapped with if its data volume doesn't say otherwise.
 	//
-	// WARNING: DO NOT change this value. That would break all existing EtcdLockservers.
-	//          The only way to change this is to implement a new feature to support
-	//          having different sizes for different EtcdLockserver objects.
-	NumReplicas = 3
+	// WARNING: DO NOT change this value. Members created before the member count
+	//          was configurable were always bootstrapped with 3 members, and their
+	//          data volumes don't record that.
+	DefaultReplicas = 3
+
+	// MaxReplicas is the largest supported number of members per etcd cluster.
+	MaxReplicas = 7
 
 	etcdContainerName = "etcd"
 	etcdCommand       = "/usr/local/bin/etcd"
@@ -57,6 +72,9 @@ const (
 	dataVolumeName      = "data"
 	dataVolumeMountPath = "/var/etcd"
 	dataVolumeSubPath   = "etcd"
+
+	tlsVolumeName      = "tls"
+	tlsVolumeMountPath = "/etc/etcd/tls"
 )
 
 // PodName returns the name of the Pod for a given etcd member.
@@ -87,6 +105,43 @@ type Spec struct {
 	ExtraLabels       map[string]string
 	AdvertisePeerURLs []string
 	Tolerations       []corev1.Toleration
+	TLS               *planetscalev2.EtcdLockserverTLS
+	// Replicas is the desired number of members in the cluster.
+	Replicas int
+	// InitialClusterSize is the number of members, with indexes starting at 1,
+	// that were in the cluster when this member was bootstrapped, including
+	// the member itself.
+	InitialClusterSize int
+	// JoinExisting is whether the member was added to an existing cluster,
+	// rather than being part of the initial cluster.
+	JoinExisting bool
+	// AutoCompactionMode and AutoCompactionRetention configure how etcd
+	// discards old revisions.
+	AutoCompactionMode      string
+	AutoCompactionRetention string
+}
+
+// tlsEnabled returns whether etcd should serve clients and peers with TLS.
+func (spec *Spec) tlsEnabled() bool {
+	return spec.TLS != nil && spec.TLS.ServerSecret != ""
+}
+
+// scheme returns the URL scheme for etcd client and peer URLs.
+func (spec *Spec) scheme() string {
+	if spec.tlsEnabled() {
+		return "https"
+	}
+	return "http"
+}
+
+// ClientURL returns the URL at which clients in any namespace can reach the member.
+func (spec *Spec) ClientURL(namespace string) string {
+	return fmt.Sprintf("%s://%s.%s.%s.svc:%d", spec.scheme(), PodName(spec.LockserverName, spec.Index), PeerServiceName(spec.LockserverName), namespace, ClientPortNumber)
+}
+
+// PeerURL returns the URL that the member advertises to its peers.
+func (spec *Spec) PeerURL() string {
+	return spec.peerURLs()[spec.Index-1]
 }
 
 // NewPod creates a new etcd Pod.
@@ -127,11 +182,11 @@ func UpdatePod(obj *corev1.Pod, spec *Spec) {
 		// Reference Values: https://github.com/etcd-io/etcd/blob/master/Documentation/op-guide/maintenance.md#auto-compaction
 		{
 			Name:  "ETCD_AUTO_COMPACTION_MODE",
-			Value: "revision",
+			Value: spec.AutoCompactionMode,
 		},
 		{
 			Name:  "ETCD_AUTO_COMPACTION_RETENTION",
-			Value: "1000",
+			Value: spec.AutoCompactionRetention,
 		},
 		{
 			Name:  "ETCD_QUOTA_BACKEND_BYTES",
@@ -146,6 +201,16 @@ func UpdatePod(obj *corev1.Pod, spec *Spec) {
 			Value: "3",
 		},
 	}
+	if spec.tlsEnabled() {
+		// Let etcdctl in our probes talk to the local member over TLS.
+		// The server certificate must be valid for "localhost".
+		env = append(env,
+			corev1.EnvVar{Name: "ETCDCTL_ENDPOINTS", Value: fmt.Sprintf("https://localhost:%d", ClientPortNumber)},
+			corev1.EnvVar{Name: "ETCDCTL_CACERT", Value: tlsFilePath(certs.CACertKey)},
+			corev1.EnvVar{Name: "ETCDCTL_CERT", Value: tlsFilePath(certs.CertKey)},
+			corev1.EnvVar{Name: "ETCDCTL_KEY", Value: tlsFilePath(certs.PrivateKeyKey)},
+		)
+	}
 	// Apply user-provided environment variable overrides.
 	update.Env(&env, spec.ExtraEnv)
 
@@ -156,14 +221,14 @@ func UpdatePod(obj *corev1.Pod, spec *Spec) {
 			SubPath:   dataVolumeSubPath,
 		},
 	}
-	update.VolumeMounts(&volumeMounts, spec.ExtraVolumeMounts)
-
-	var securityContext *corev1.SecurityContext
-	if planetscalev2.DefaultEtcdRunAsUser >= 0 {
-		securityContext = &corev1.SecurityContext{
-			RunAsUser: ptr.To(planetscalev2.DefaultEtcdRunAsUser),
-		}
+	if spec.tlsEnabled() {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      tlsVolumeName,
+			MountPath: tlsVolumeMountPath,
+			ReadOnly:  true,
+		})
 	}
+	update.VolumeMounts(&volumeMounts, spec.ExtraVolumeMounts)
 
 	etcdContainer := &corev1.Container{
 		Name:            etcdContainerName,
@@ -171,7 +236,7 @@ func UpdatePod(obj *corev1.Pod, spec *Spec) {
 		ImagePullPolicy: spec.ImagePullPolicy,
 		Command:         []string{etcdCommand},
 		Args:            spec.Args(),
-		SecurityContext: securityContext,
+		SecurityContext: etcdSecurityContext(),
 		Ports: []corev1.ContainerPort{
 			{
 				Name:          ClientPortName,
@@ -224,6 +289,20 @@ func UpdatePod(obj *corev1.Pod, spec *Spec) {
 			},
 		},
 	})
+	if spec.tlsEnabled() {
+		// etcd reloads certificates from disk for new connections, so
+		// renewals don't require a restart.
+		update.Volumes(&obj.Spec.Volumes, []corev1.Volume{
+			{
+				Name: tlsVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: spec.TLS.ServerSecret,
+					},
+				},
+			},
+		})
+	}
 	update.Volumes(&obj.Spec.Volumes, spec.ExtraVolumes)
 
 	obj.Spec.Hostname = PodName(spec.LockserverName, spec.Index)
@@ -346,61 +425,115 @@ func UpdatePod(obj *corev1.Pod, spec *Spec) {
 	update.PodContainers(&obj.Spec.Containers, containers)
 }
 
+// tlsFilePath returns the path to a file in the mounted server TLS Secret.
+func tlsFilePath(key string) string {
+	return tlsVolumeMountPath + "/" + key
+}
+
+// etcdSecurityContext returns the security context for containers that
+// run etcd binaries.
+func etcdSecurityContext() *corev1.SecurityContext {
+	if planetscalev2.DefaultEtcdRunAsUser < 0 {
+		return nil
+	}
+	return &corev1.SecurityContext{
+		RunAsUser: ptr.To(planetscalev2.DefaultEtcdRunAsUser),
+	}
+}
+
 // Args returns the etcd args.
 func (spec *Spec) Args() []string {
 	hostname := PodName(spec.LockserverName, spec.Index)
 	subdomain := PeerServiceName(spec.LockserverName)
 
-	listenPeerURLs := fmt.Sprintf("http://0.0.0.0:%d", PeerPortNumber)
-	listenClientURLs := fmt.Sprintf("http://0.0.0.0:%d", ClientPortNumber)
-	advertiseClientURLs := fmt.Sprintf("http://%s.%s:%d", hostname, subdomain, ClientPortNumber)
+	scheme := spec.scheme()
+	listenPeerURLs := fmt.Sprintf("%s://0.0.0.0:%d", scheme, PeerPortNumber)
+	listenClientURLs := fmt.Sprintf("%s://0.0.0.0:%d", scheme, ClientPortNumber)
+	advertiseClientURLs := fmt.Sprintf("%s://%s.%s:%d", scheme, hostname, subdomain, ClientPortNumber)
+
+	flags := vitess.Flags{
+		"data-dir":              dataVolumeMountPath,
+		"listen-peer-urls":      listenPeerURLs,
+		"listen-client-urls":    listenClientURLs,
+		"advertise-client-urls": advertiseClientURLs,
+
+		// All "initial-*" flags are ignored after bootstrapping.
+		"initial-cluster-state": "new",
+	}
+	flags.Merge(spec.bootstrapFlags())
+	if spec.JoinExisting {
+		flags["initial-cluster-state"] = "existing"
+	}
+
+	if spec.tlsEnabled() {
+		// Require both clients and peers to present a certificate signed by
+		// the same CA that signed ours.
+		flags.Merge(vitess.Flags{
+			"cert-file":             tlsFilePath(certs.CertKey),
+			"key-file":              tlsFilePath(certs.PrivateKeyKey),
+			"trusted-ca-file":       tlsFilePath(certs.CACertKey),
+			"client-cert-auth":      true,
+			"peer-cert-file":        tlsFilePath(certs.CertKey),
+			"peer-key-file":         tlsFilePath(certs.PrivateKeyKey),
+			"peer-trusted-ca-file":  tlsFilePath(certs.CACertKey),
+			"peer-client-cert-auth": true,
+		})
+	}
 
+	// Apply user-supplied extra flags last so they take precedence.
+	for key, value := range spec.ExtraFlags {
+		// We told users in the CRD API field doc not to put any leading '-',
+		// but we are liberal in what we accept.
+		key = strings.TrimLeft(key, "-")
+		flags[key] = value
+	}
+
+	return flags.FormatArgs()
+}
+
+// bootstrapFlags returns the flags that determine the identity of a member
+// and the initial membership of the cluster. Restoring a snapshot requires the
+// same values that the member will later be started with.
+func (spec *Spec) bootstrapFlags() vitess.Flags {
 	// Use static bootstrapping.
 	initialClusterToken := spec.LockserverName
-	advertisePeerURLs := spec.AdvertisePeerURLs
-
-	// If peer URLs were not explicitly specified, generate them.
-	if len(advertisePeerURLs) != NumReplicas {
-		advertisePeerURLs = make([]string, 0, NumReplicas)
-		for i := 0; i < NumReplicas; i++ {
-			peerIndex := i + 1
-			peerName := PodName(spec.LockserverName, peerIndex)
-			advertisePeerURLs = append(advertisePeerURLs, fmt.Sprintf("http://%s.%s:%d", peerName, subdomain, PeerPortNumber))
-		}
-	}
+	advertisePeerURLs := spec.peerURLs()
 
 	// Set the address that this peer will advertise for itself.
 	initialAdvertisePeerURLs := advertisePeerURLs[spec.Index-1]
 
 	// Create list of peer addresses.
-	initialCluster := make([]string, 0, NumReplicas)
-	for i := 0; i < NumReplicas; i++ {
+	initialCluster := make([]string, 0, spec.InitialClusterSize)
+	for i := 0; i < spec.InitialClusterSize; i++ {
 		peerIndex := i + 1
 		peerName := PodName(spec.LockserverName, peerIndex)
 		initialCluster = append(initialCluster, fmt.Sprintf("%s=%s", peerName, advertisePeerURLs[i]))
 	}
 
-	flags := vitess.Flags{
-		"data-dir":              dataVolumeMountPath,
-		"name":                  hostname,
-		"listen-peer-urls":      listenPeerURLs,
-		"listen-client-urls":    listenClientURLs,
-		"advertise-client-urls": advertiseClientURLs,
-
-		// All "initial-*" flags are ignored after bootstrapping.
-		"initial-cluster-state":       "new",
+	return vitess.Flags{
+		"name":                        PodName(spec.LockserverName, spec.Index),
 		"initial-cluster-token":       initialClusterToken,
 		"initial-advertise-peer-urls": initialAdvertisePeerURLs,
 		"initial-cluster":             strings.Join(initialCluster, ","),
 	}
+}
 
-	// Apply user-supplied extra flags last so they take precedence.
-	for key, value := range spec.ExtraFlags {
-		// We told users in the CRD API field doc not to put any leading '-',
-		// but we are liberal in what we accept.
-		key = strings.TrimLeft(key, "-")
-		flags[key] = value
+// peerURLs returns the peer URLs of all members, in order of member index.
+func (spec *Spec) peerURLs() []string {
+	numPeers := max(spec.Replicas, spec.InitialClusterSize, spec.Index)
+
+	// If peer URLs were explicitly specified, use them.
+	if len(spec.AdvertisePeerURLs) >= numPeers {
+		return spec.AdvertisePeerURLs
 	}
 
-	return flags.FormatArgs()
+	subdomain := PeerServiceName(spec.LockserverName)
+	scheme := spec.scheme()
+	peerURLs := make([]string, 0, numPeers)
+	for i := 0; i < numPeers; i++ {
+		peerIndex := i + 1
+		peerName := PodName(spec.LockserverName, peerIndex)
+		peerURLs = append(peerURLs, fmt.Sprintf("%s://%s.%s:%d", scheme, peerName, subdomain, PeerPortNumber))
+	}
+	return peerURLs
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package etcd
 
 import (
+	"strconv"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,14 +33,23 @@ func NewPVC(key client.ObjectKey, spec *Spec) *corev1.PersistentVolumeClaim {
 	update.Labels(&labels, spec.Labels)
 	update.Labels(&labels, spec.ExtraLabels)
 
-	return &corev1.PersistentVolumeClaim{
+	obj := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: key.Namespace,
 			Name:      key.Name,
 			Labels:    labels,
+			Annotations: map[string]string{
+				InitialClusterSizeAnnotation: strconv.Itoa(spec.InitialClusterSize),
+			},
 		},
 		Spec: *spec.DataVolumePVCSpec,
 	}
+	if spec.JoinExisting {
+		// Remember that this member must join the existing cluster,
+		// even after it has started.
+		obj.Annotations[JoinExistingAnnotation] = "true"
+	}
+	return obj
 }
 
 // UpdatePVCInPlace updates an existing PVC in-place.
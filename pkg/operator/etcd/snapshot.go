@@ -0,0 +1,372 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/certs"
+	"planetscale.dev/vitess-operator/pkg/operator/etcdsnapshot"
+	"planetscale.dev/vitess-operator/pkg/operator/k8s"
+	"planetscale.dev/vitess-operator/pkg/operator/names"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+	"planetscale.dev/vitess-operator/pkg/operator/vitessbackup"
+)
+
+const (
+	etcdctlCommand = "/usr/local/bin/etcdctl"
+	etcdutlCommand = "/usr/local/bin/etcdutl"
+
+	saveContainerName     = "save"
+	uploadContainerName   = "upload"
+	downloadContainerName = "download"
+	restoreContainerName  = "restore"
+
+	snapshotVolumeName      = "snapshot"
+	snapshotVolumeMountPath = "/snapshot"
+	snapshotFilePath        = snapshotVolumeMountPath + "/snapshot.db"
+
+	// restoreDataMountPath is where the restore Pod mounts the root of the
+	// member's data volume. The etcd container mounts only a subPath, so the
+	// restored data dir has to be created inside it.
+	restoreDataMountPath = "/var/etcd"
+	restoreDataDir       = restoreDataMountPath + "/" + dataVolumeSubPath
+)
+
+var (
+	// cronJobNameConstraints leave room for the suffix that the CronJob
+	// controller appends to the name of each Job.
+	cronJobNameConstraints = names.Constraints{
+		MaxLength:      52,
+		ValidFirstChar: names.DefaultConstraints.ValidFirstChar,
+	}
+	// jobNameConstraints keep Job names short enough to be used as the value
+	// of the "job-name" label on their Pods.
+	jobNameConstraints = names.Constraints{
+		MaxLength:      63,
+		ValidFirstChar: names.DefaultConstraints.ValidFirstChar,
+	}
+)
+
+// SnapshotCronJobName returns the name of the CronJob that saves snapshots of
+// a given lockserver.
+func SnapshotCronJobName(lockserverName string) string {
+	return names.JoinWithConstraints(cronJobNameConstraints, lockserverName, "snapshot")
+}
+
+// RestoreJobName returns the name of the Job that restores a snapshot into
+// the data volume of a given member.
+func RestoreJobName(lockserverName string, index int) string {
+	return names.JoinWithConstraints(jobNameConstraints, PodName(lockserverName, index), "restore")
+}
+
+// SnapshotSpec specifies the parameters needed to save or restore etcd
+// snapshots in a backup location.
+type SnapshotSpec struct {
+	LockserverName     string
+	Image              string
+	ImagePullPolicy    corev1.PullPolicy
+	ImagePullSecrets   []corev1.LocalObjectReference
+	Labels             map[string]string
+	Annotations        map[string]string
+	TLS                *planetscalev2.EtcdLockserverTLS
+	ServiceAccountName string
+	// Location is the backup location to transfer snapshots to or from.
+	Location *planetscalev2.VitessBackupLocation
+	// ClusterName determines the root of the backup location.
+	ClusterName string
+	// Dir is the directory within the root of the backup location.
+	Dir string
+	// Transfer is a copy of the operator's own container, to be run as an
+	// etcdsnapshot fork.
+	Transfer *corev1.Container
+}
+
+// tlsEnabled returns whether the lockserver serves clients with TLS.
+func (spec *SnapshotSpec) tlsEnabled() bool {
+	return spec.TLS != nil && spec.TLS.ServerSecret != ""
+}
+
+// NewSnapshotCronJob creates a new CronJob that periodically saves snapshots.
+func NewSnapshotCronJob(key client.ObjectKey, spec *SnapshotSpec, backup *planetscalev2.EtcdLockserverBackup) *batchv1.CronJob {
+	obj := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+		},
+	}
+	UpdateSnapshotCronJob(obj, spec, backup)
+	return obj
+}
+
+// UpdateSnapshotCronJob updates an existing snapshot CronJob in-place. Jobs
+// that have already been created are not affected.
+func UpdateSnapshotCronJob(obj *batchv1.CronJob, spec *SnapshotSpec, backup *planetscalev2.EtcdLockserverBackup) {
+	update.Labels(&obj.Labels, spec.Labels)
+
+	obj.Spec.Schedule = backup.Schedule
+	obj.Spec.Suspend = ptr.To(backup.Suspend)
+	// Never let snapshots pile up behind a slow upload.
+	obj.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+
+	jobTemplate := &obj.Spec.JobTemplate
+	update.Labels(&jobTemplate.Labels, spec.Labels)
+
+	podTemplate := &jobTemplate.Spec.Template
+	update.Labels(&podTemplate.Labels, spec.Labels)
+	update.Annotations(&podTemplate.Annotations, spec.Annotations)
+
+	podSpec := &podTemplate.Spec
+	podSpec.RestartPolicy = corev1.RestartPolicyNever
+	podSpec.ServiceAccountName = spec.ServiceAccountName
+	podSpec.ImagePullSecrets = spec.ImagePullSecrets
+	if planetscalev2.DefaultVitessPriorityClass != "" {
+		podSpec.PriorityClassName = planetscalev2.DefaultVitessPriorityClass
+	}
+
+	// etcdctl saves the snapshot in an init container, and then the operator
+	// uploads it once that succeeds.
+	saveEnv := []corev1.EnvVar{
+		{
+			Name:  "ETCDCTL_API",
+			Value: "3",
+		},
+		{
+			Name:  "ETCDCTL_ENDPOINTS",
+			Value: spec.clientURL(),
+		},
+	}
+	saveMounts := []corev1.VolumeMount{
+		{
+			Name:      snapshotVolumeName,
+			MountPath: snapshotVolumeMountPath,
+		},
+	}
+	if spec.tlsEnabled() {
+		// The server certificate is also valid for client authentication.
+		saveEnv = append(saveEnv,
+			corev1.EnvVar{Name: "ETCDCTL_CACERT", Value: tlsFilePath(certs.CACertKey)},
+			corev1.EnvVar{Name: "ETCDCTL_CERT", Value: tlsFilePath(certs.CertKey)},
+			corev1.EnvVar{Name: "ETCDCTL_KEY", Value: tlsFilePath(certs.PrivateKeyKey)},
+		)
+		saveMounts = append(saveMounts, corev1.VolumeMount{
+			Name:      tlsVolumeName,
+			MountPath: tlsVolumeMountPath,
+			ReadOnly:  true,
+		})
+	}
+	update.PodContainers(&podSpec.InitContainers, []corev1.Container{
+		{
+			Name:            saveContainerName,
+			Image:           spec.Image,
+			ImagePullPolicy: spec.ImagePullPolicy,
+			Command:         []string{etcdctlCommand},
+			Args:            []string{"snapshot", "save", snapshotFilePath},
+			SecurityContext: etcdSecurityContext(),
+			Env:             saveEnv,
+			VolumeMounts:    saveMounts,
+		},
+	})
+	update.PodContainers(&podSpec.Containers, []corev1.Container{
+		*spec.transferContainer(uploadContainerName, etcdsnapshot.ActionUpload, ""),
+	})
+
+	update.Volumes(&podSpec.Volumes, spec.volumes())
+	if spec.tlsEnabled() {
+		update.Volumes(&podSpec.Volumes, []corev1.Volume{
+			{
+				Name: tlsVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: spec.TLS.ServerSecret,
+					},
+				},
+			},
+		})
+	}
+}
+
+// NewRestoreJob creates a new Job that restores a snapshot into the data
+// volume of one member, before that member is started for the first time.
+func NewRestoreJob(key client.ObjectKey, member *Spec, spec *SnapshotSpec, snapshotName string) *batchv1.Job {
+	obj := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Labels:    map[string]string{},
+		},
+	}
+	update.Labels(&obj.Labels, spec.Labels)
+	obj.Labels[IndexLabel] = strconv.Itoa(member.Index)
+
+	podTemplate := &obj.Spec.Template
+	update.Labels(&podTemplate.Labels, obj.Labels)
+	update.Annotations(&podTemplate.Annotations, spec.Annotations)
+
+	podSpec := &podTemplate.Spec
+	podSpec.RestartPolicy = corev1.RestartPolicyNever
+	podSpec.ServiceAccountName = spec.ServiceAccountName
+	podSpec.ImagePullSecrets = spec.ImagePullSecrets
+	podSpec.Tolerations = member.Tolerations
+	if planetscalev2.DefaultEtcdFSGroup >= 0 {
+		podSpec.SecurityContext = &corev1.PodSecurityContext{
+			FSGroup: ptr.To(planetscalev2.DefaultEtcdFSGroup),
+		}
+	}
+	if member.Zone != "" {
+		// Bind the data volume in the same zone the member will run in.
+		podSpec.Affinity = &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{
+									Key:      k8s.ZoneFailureDomainLabel,
+									Operator: corev1.NodeSelectorOpIn,
+									Values:   []string{member.Zone},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// The restored member must have the same identity and initial cluster
+	// that it will later be started with, including any user overrides.
+	flags := member.bootstrapFlags()
+	for key, value := range member.ExtraFlags {
+		key = strings.TrimLeft(key, "-")
+		if _, ok := flags[key]; ok {
+			flags[key] = value
+		}
+	}
+	flags["data-dir"] = restoreDataDir
+
+	podSpec.InitContainers = []corev1.Container{
+		*spec.transferContainer(downloadContainerName, etcdsnapshot.ActionDownload, snapshotName),
+	}
+	podSpec.Containers = []corev1.Container{
+		{
+			Name:            restoreContainerName,
+			Image:           spec.Image,
+			ImagePullPolicy: spec.ImagePullPolicy,
+			Command:         []string{etcdutlCommand},
+			Args:            append([]string{"snapshot", "restore", snapshotFilePath}, flags.FormatArgs()...),
+			SecurityContext: etcdSecurityContext(),
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      snapshotVolumeName,
+					MountPath: snapshotVolumeMountPath,
+					ReadOnly:  true,
+				},
+				{
+					Name:      dataVolumeName,
+					MountPath: restoreDataMountPath,
+				},
+			},
+		},
+	}
+
+	podSpec.Volumes = spec.volumes()
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: dataVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: member.DataVolumePVCName,
+			},
+		},
+	})
+
+	return obj
+}
+
+// clientURL returns the URL at which etcdctl can reach the lockserver.
+func (spec *SnapshotSpec) clientURL() string {
+	scheme := "http"
+	if spec.tlsEnabled() {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, ClientServiceName(spec.LockserverName), ClientPortNumber)
+}
+
+// volumes returns the volumes shared by snapshot and restore Pods.
+func (spec *SnapshotSpec) volumes() []corev1.Volume {
+	volumes := []corev1.Volume{
+		{
+			Name: snapshotVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+	return append(volumes, vitessbackup.StorageVolumes(spec.Location)...)
+}
+
+// transferContainer returns the operator container that moves the snapshot
+// file between the snapshot volume and the backup location.
+func (spec *SnapshotSpec) transferContainer(name, action, snapshotName string) *corev1.Container {
+	container := spec.Transfer.DeepCopy()
+	container.Name = name
+	container.Ports = nil
+	container.ReadinessProbe = nil
+	container.LivenessProbe = nil
+
+	update.Env(&container.Env, []corev1.EnvVar{
+		{
+			Name:  etcdsnapshot.ActionEnvVar,
+			Value: action,
+		},
+		{
+			Name:  etcdsnapshot.DirEnvVar,
+			Value: spec.Dir,
+		},
+		{
+			Name:  etcdsnapshot.NameEnvVar,
+			Value: snapshotName,
+		},
+		{
+			Name:  etcdsnapshot.FileEnvVar,
+			Value: snapshotFilePath,
+		},
+	})
+	update.Env(&container.Env, vitessbackup.StorageEnvVars(spec.Location))
+
+	backupFlags := vitessbackup.StorageFlags(spec.Location, spec.ClusterName)
+	container.Args = append(container.Args, backupFlags.FormatArgs()...)
+
+	container.VolumeMounts = []corev1.VolumeMount{
+		{
+			Name:      snapshotVolumeName,
+			MountPath: snapshotVolumeMountPath,
+		},
+	}
+	update.VolumeMounts(&container.VolumeMounts, vitessbackup.StorageVolumeMounts(spec.Location))
+
+	return container
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package etcdclient lets the operator inspect, maintain, and change the
+membership of the etcd clusters it deploys.
+*/
+package etcdclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	corev1 "k8s.io/api/core/v1"
+
+	"planetscale.dev/vitess-operator/pkg/operator/certs"
+)
+
+// dialTimeout is how long to wait for a connection to any endpoint.
+const dialTimeout = 5 * time.Second
+
+// Member is a member of an etcd cluster.
+type Member struct {
+	ID       uint64
+	Name     string
+	PeerURLs []string
+	// IsLearner is whether the member is a non-voting learner that hasn't
+	// been promoted yet.
+	IsLearner bool
+}
+
+// Started returns whether the member has ever joined the cluster. Members
+// that were added but haven't started yet have no name.
+func (m *Member) Started() bool {
+	return m.Name != ""
+}
+
+// Status is the status of one etcd member, as reported by that member.
+type Status struct {
+	ClusterID uint64
+	MemberID  uint64
+	Leader    uint64
+	DBSize    int64
+	// DBSizeInUse is the part of DBSize that's not free space left behind by
+	// compaction, which defragmentation would reclaim.
+	DBSizeInUse int64
+	// Errors are any alarms raised by the member, such as data corruption.
+	Errors []string
+}
+
+// IsLeader returns whether the member that reported this status is the leader.
+func (s *Status) IsLeader() bool {
+	return s.Leader != 0 && s.Leader == s.MemberID
+}
+
+// Client is a connection to an etcd cluster.
+type Client struct {
+	client *clientv3.Client
+}
+
+// New connects to an etcd cluster through the given client endpoints.
+// tlsConfig may be nil if the cluster doesn't use TLS.
+func New(ctx context.Context, endpoints []string, tlsConfig *tls.Config) (*Client, error) {
+	c, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: dialTimeout,
+		TLS:         tlsConfig,
+		Context:     ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{client: c}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// Members returns the current members of the cluster, along with the ID of the
+// cluster as reported by the member that answered.
+func (c *Client) Members(ctx context.Context) ([]Member, uint64, error) {
+	resp, err := c.client.MemberList(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.Header == nil {
+		return nil, 0, fmt.Errorf("member list response has no header")
+	}
+	members := make([]Member, 0, len(resp.Members))
+	for _, m := range resp.Members {
+		members = append(members, Member{
+			ID:        m.ID,
+			Name:      m.Name,
+			PeerURLs:  m.PeerURLs,
+			IsLearner: m.IsLearner,
+		})
+	}
+	return members, resp.Header.ClusterId, nil
+}
+
+// Status asks the member at the given client endpoint for its status.
+func (c *Client) Status(ctx context.Context, endpoint string) (*Status, error) {
+	resp, err := c.client.Status(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Header == nil {
+		return nil, fmt.Errorf("status response from %v has no header", endpoint)
+	}
+	return &Status{
+		ClusterID:   resp.Header.ClusterId,
+		MemberID:    resp.Header.MemberId,
+		Leader:      resp.Leader,
+		DBSize:      resp.DbSize,
+		DBSizeInUse: resp.DbSizeInUse,
+		Errors:      resp.Errors,
+	}, nil
+}
+
+// Defragment rewrites the backend database of the member at the given client
+// endpoint to reclaim free space. The member can't serve requests until
+// it's done.
+func (c *Client) Defragment(ctx context.Context, endpoint string) error {
+	_, err := c.client.Defragment(ctx, endpoint)
+	return err
+}
+
+// RemoveMember removes a member from the cluster.
+func (c *Client) RemoveMember(ctx context.Context, id uint64) error {
+	_, err := c.client.MemberRemove(ctx, id)
+	return err
+}
+
+// AddLearner adds a new non-voting member with the given peer URL. The member
+// must then be started with an empty data dir and
+// "--initial-cluster-state=existing". A learner doesn't count towards quorum,
+// so adding one can't make the cluster unavailable while it's catching up.
+func (c *Client) AddLearner(ctx context.Context, peerURL string) (uint64, error) {
+	resp, err := c.client.MemberAddAsLearner(ctx, []string{peerURL})
+	if err != nil {
+		return 0, err
+	}
+	return resp.Member.ID, nil
+}
+
+// PromoteMember turns a learner into a voting member. It returns an error
+// for which IsLearnerNotReady is true if the learner hasn't caught up with
+// the leader yet.
+func (c *Client) PromoteMember(ctx context.Context, id uint64) error {
+	_, err := c.client.MemberPromote(ctx, id)
+	return err
+}
+
+// IsLearnerNotReady returns whether err means that a learner can't be
+// promoted yet because it hasn't caught up with the leader.
+func IsLearnerNotReady(err error) bool {
+	return errors.Is(err, rpctypes.ErrLearnerNotReady)
+}
+
+// TLSConfig returns the client TLS config for a Secret with the keys
+// "tls.crt", "tls.key" and "ca.crt".
+func TLSConfig(secret *corev1.Secret) (*tls.Config, error) {
+	cert, err := tls.X509KeyPair(secret.Data[certs.CertKey], secret.Data[certs.PrivateKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid client certificate in Secret %v: %v", secret.Name, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(secret.Data[certs.CACertKey]) {
+		return nil, fmt.Errorf("no valid CA certificates in Secret %v", secret.Name)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package etcdsnapshot copies etcd snapshot files to and from Vitess backup
+storage.
+
+It runs as a forked operator container alongside etcdctl or etcdutl in the
+snapshot and restore Pods created by the EtcdLockserver controller, so it can
+reuse the backup storage flags, volumes and credentials of any
+VitessBackupLocation. See pkg/operator/fork for details.
+*/
+package etcdsnapshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	_ "vitess.io/vitess/go/vt/mysqlctl/azblobbackupstorage"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	_ "vitess.io/vitess/go/vt/mysqlctl/cephbackupstorage"
+	_ "vitess.io/vitess/go/vt/mysqlctl/filebackupstorage"
+	_ "vitess.io/vitess/go/vt/mysqlctl/gcsbackupstorage"
+	_ "vitess.io/vitess/go/vt/mysqlctl/s3backupstorage"
+
+	"planetscale.dev/vitess-operator/pkg/operator/vitessbackup"
+)
+
+const (
+	// ForkPath is the fork path for transferring a single snapshot.
+	// See cmd/manager/main.go for details.
+	ForkPath = "etcd-snapshot"
+
+	// ActionEnvVar selects whether to upload or download a snapshot.
+	ActionEnvVar = "PS_OPERATOR_ETCD_SNAPSHOT_ACTION"
+	// DirEnvVar is the directory in backup storage that holds the snapshots
+	// of one lockserver.
+	DirEnvVar = "PS_OPERATOR_ETCD_SNAPSHOT_DIR"
+	// NameEnvVar is the name of the snapshot to download.
+	NameEnvVar = "PS_OPERATOR_ETCD_SNAPSHOT_NAME"
+	// FileEnvVar is the local path of the snapshot file.
+	FileEnvVar = "PS_OPERATOR_ETCD_SNAPSHOT_FILE"
+
+	// ActionUpload uploads the local snapshot file as a new snapshot named
+	// after the current time.
+	ActionUpload = "upload"
+	// ActionDownload downloads the named snapshot to the local file.
+	ActionDownload = "download"
+
+	// fileName is the name of the snapshot file within each backup.
+	fileName = "snapshot.db"
+)
+
+var log = logrus.WithField("fork", ForkPath)
+
+var getBackupStorage = backupstorage.GetBackupStorage
+
+// Dir returns the directory in backup storage, relative to the cluster's
+// root, that holds the snapshots of a given lockserver.
+func Dir(lockserverName string) string {
+	return path.Join("etcd", lockserverName)
+}
+
+// Run transfers one snapshot as specified by the env vars above.
+func Run(ctx context.Context) error {
+	action := os.Getenv(ActionEnvVar)
+	dir := os.Getenv(DirEnvVar)
+	if dir == "" {
+		return fmt.Errorf("etcd snapshot transfer requires %v env var to be set", DirEnvVar)
+	}
+	file := os.Getenv(FileEnvVar)
+	if file == "" {
+		return fmt.Errorf("etcd snapshot transfer requires %v env var to be set", FileEnvVar)
+	}
+
+	bs, err := getBackupStorage()
+	if err != nil {
+		return fmt.Errorf("failed to open backup storage client: %v", err)
+	}
+	defer bs.Close()
+
+	switch action {
+	case ActionUpload:
+		name := time.Now().UTC().Format(vitessbackup.TimestampFormat)
+		return upload(ctx, bs, dir, name, file)
+	case ActionDownload:
+		name := os.Getenv(NameEnvVar)
+		if name == "" {
+			return fmt.Errorf("etcd snapshot download requires %v env var to be set", NameEnvVar)
+		}
+		return download(ctx, bs, dir, name, file)
+	default:
+		return fmt.Errorf("unknown etcd snapshot action %q", action)
+	}
+}
+
+func upload(ctx context.Context, bs backupstorage.BackupStorage, dir, name, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Uploading etcd snapshot %v/%v (%v bytes)", dir, name, info.Size())
+	bh, err := bs.StartBackup(ctx, dir, name)
+	if err != nil {
+		return fmt.Errorf("failed to start backup %v/%v: %v", dir, name, err)
+	}
+	if err := writeFile(ctx, bh, f, info.Size()); err != nil {
+		if abortErr := bh.AbortBackup(ctx); abortErr != nil {
+			log.Warningf("Failed to abort backup %v/%v: %v", dir, name, abortErr)
+		}
+		return fmt.Errorf("failed to upload backup %v/%v: %v", dir, name, err)
+	}
+	if err := bh.EndBackup(ctx); err != nil {
+		return fmt.Errorf("failed to finish backup %v/%v: %v", dir, name, err)
+	}
+	log.Infof("Uploaded etcd snapshot %v/%v", dir, name)
+	return nil
+}
+
+func writeFile(ctx context.Context, bh backupstorage.BackupHandle, r io.Reader, size int64) error {
+	w, err := bh.AddFile(ctx, fileName, size)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func download(ctx context.Context, bs backupstorage.BackupStorage, dir, name, file string) error {
+	backups, err := bs.ListBackups(ctx, dir)
+	if err != nil {
+		return fmt.Errorf("failed to list backups in %v: %v", dir, err)
+	}
+	var bh backupstorage.BackupHandle
+	for _, backup := range backups {
+		if backup.Name() == name {
+			bh = backup
+			break
+		}
+	}
+	if bh == nil {
+		return fmt.Errorf("etcd snapshot %v/%v not found", dir, name)
+	}
+
+	log.Infof("Downloading etcd snapshot %v/%v", dir, name)
+	r, err := bh.ReadFile(ctx, fileName)
+	if err != nil {
+		return fmt.Errorf("failed to read backup %v/%v: %v", dir, name, err)
+	}
+	defer r.Close()
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to download backup %v/%v: %v", dir, name, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Infof("Downloaded etcd snapshot %v/%v", dir, name)
+	return nil
+}
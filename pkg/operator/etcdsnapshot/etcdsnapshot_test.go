@@ -0,0 +1,133 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdsnapshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
+	mysqlctlerrors "vitess.io/vitess/go/vt/mysqlctl/errors"
+)
+
+// fakeBackupStorage keeps the files of finished backups in memory.
+type fakeBackupStorage struct {
+	backups map[string]*fakeBackupHandle
+}
+
+func (f *fakeBackupStorage) ListBackups(_ context.Context, dir string) ([]backupstorage.BackupHandle, error) {
+	var handles []backupstorage.BackupHandle
+	for _, bh := range f.backups {
+		if bh.dir == dir && bh.done {
+			handles = append(handles, bh)
+		}
+	}
+	return handles, nil
+}
+
+func (f *fakeBackupStorage) StartBackup(_ context.Context, dir, name string) (backupstorage.BackupHandle, error) {
+	bh := &fakeBackupHandle{dir: dir, name: name, files: map[string]*bytes.Buffer{}}
+	f.backups[dir+"/"+name] = bh
+	return bh, nil
+}
+
+func (f *fakeBackupStorage) RemoveBackup(context.Context, string, string) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (f *fakeBackupStorage) Close() error {
+	return nil
+}
+
+func (f *fakeBackupStorage) WithParams(backupstorage.Params) backupstorage.BackupStorage {
+	return f
+}
+
+type fakeBackupHandle struct {
+	dir   string
+	name  string
+	files map[string]*bytes.Buffer
+	done  bool
+	mysqlctlerrors.PerFileErrorRecorder
+}
+
+func (f *fakeBackupHandle) Directory() string {
+	return f.dir
+}
+
+func (f *fakeBackupHandle) Name() string {
+	return f.name
+}
+
+func (f *fakeBackupHandle) AddFile(_ context.Context, filename string, _ int64) (io.WriteCloser, error) {
+	buf := &bytes.Buffer{}
+	f.files[filename] = buf
+	return nopWriteCloser{buf}, nil
+}
+
+func (f *fakeBackupHandle) EndBackup(context.Context) error {
+	f.done = true
+	return nil
+}
+
+func (f *fakeBackupHandle) AbortBackup(context.Context) error {
+	return nil
+}
+
+func (f *fakeBackupHandle) ReadFile(_ context.Context, filename string) (io.ReadCloser, error) {
+	buf, ok := f.files[filename]
+	if !ok {
+		return nil, fmt.Errorf("file %v not found", filename)
+	}
+	return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestUploadDownload(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+	bs := &fakeBackupStorage{backups: map[string]*fakeBackupHandle{}}
+	dir := Dir("example-etcd")
+
+	src := filepath.Join(tmp, "src.db")
+	require.NoError(t, os.WriteFile(src, []byte("snapshot data"), 0o600))
+	require.NoError(t, upload(ctx, bs, dir, "2026-01-02.030405", src))
+
+	dst := filepath.Join(tmp, "dst.db")
+	require.NoError(t, download(ctx, bs, dir, "2026-01-02.030405", dst))
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	require.Equal(t, "snapshot data", string(data))
+
+	// Snapshots of other lockservers must not be found.
+	require.Error(t, download(ctx, bs, Dir("other-etcd"), "2026-01-02.030405", dst))
+	// Neither should snapshots that don't exist.
+	require.Error(t, download(ctx, bs, dir, "2026-01-02.030406", dst))
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package internaltls configures mutual TLS for gRPC traffic between Vitess
+components.
+
+All components use the same certificate as both a server and a client
+certificate. The flags each component needs depend on which gRPC services it
+calls: vtgate and vtctld call the vttablet query service ("tablet_grpc_*"),
+while vtctld, vtorc and vttablet call the tablet manager service
+("tablet_manager_grpc_*"). vtadmin calls vtctld ("vtctld_grpc_*") and vtgate
+("vtgate_grpc_*").
+*/
+package internaltls
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/names"
+	"planetscale.dev/vitess-operator/pkg/operator/secrets"
+	"planetscale.dev/vitess-operator/pkg/operator/vitess"
+)
+
+const (
+	// HashAnnotation is the Pod annotation containing a hash of the contents
+	// of the internal TLS Secrets. Changing it triggers a rolling restart.
+	HashAnnotation = "planetscale.com/internal-tls-hash"
+
+	certDirName   = "internal-tls-cert"
+	keyDirName    = "internal-tls-key"
+	caCertDirName = "internal-tls-ca"
+)
+
+// SecretName returns the name of the Secret holding the operator-managed
+// internal certificate for a cluster.
+func SecretName(clusterName string) string {
+	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, "internal", "tls")
+}
+
+// CASecretName returns the name of the Secret holding the operator-managed
+// CA that signs the internal certificate for a cluster.
+func CASecretName(clusterName string) string {
+	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, "internal", "ca")
+}
+
+// Enabled returns whether internal TLS is configured with everything it needs.
+func Enabled(tls *planetscalev2.VitessInternalTLSSpec) bool {
+	return tls != nil && tls.CertSecret != nil && tls.KeySecret != nil && tls.CACertSecret != nil
+}
+
+// ContentHash returns a hash of the contents of the internal TLS Secrets, or
+// "" if internal TLS isn't enabled. Components put this in HashAnnotation so
+// they get restarted when the certificate is rotated.
+func ContentHash(ctx context.Context, cl client.Client, namespace string, tls *planetscalev2.VitessInternalTLSSpec) (string, error) {
+	if !Enabled(tls) {
+		return "", nil
+	}
+	tlsSecrets, err := secrets.GetByNames(ctx, cl, namespace, tls.SecretNames())
+	if err != nil {
+		return "", err
+	}
+	return secrets.ContentHash(tlsSecrets...), nil
+}
+
+// Annotations returns the given Pod annotations with HashAnnotation added,
+// if the hash is non-empty. The input map is not modified.
+func Annotations(annotations map[string]string, hash string) map[string]string {
+	if hash == "" {
+		return annotations
+	}
+	result := map[string]string{HashAnnotation: hash}
+	for key, value := range annotations {
+		result[key] = value
+	}
+	return result
+}
+
+func mounts(tls *planetscalev2.VitessInternalTLSSpec) (cert, key, caCert *secrets.VolumeMount) {
+	return secrets.Mount(tls.CertSecret, certDirName),
+		secrets.Mount(tls.KeySecret, keyDirName),
+		secrets.Mount(tls.CACertSecret, caCertDirName)
+}
+
+// Volumes returns the Pod Volumes needed for internal TLS, if enabled.
+func Volumes(tls *planetscalev2.VitessInternalTLSSpec) []corev1.Volume {
+	if !Enabled(tls) {
+		return nil
+	}
+	cert, key, caCert := mounts(tls)
+	var vols []corev1.Volume
+	vols = append(vols, cert.PodVolumes()...)
+	vols = append(vols, key.PodVolumes()...)
+	vols = append(vols, caCert.PodVolumes()...)
+	return vols
+}
+
+// VolumeMounts returns the container VolumeMounts needed for internal TLS,
+// if enabled.
+func VolumeMounts(tls *planetscalev2.VitessInternalTLSSpec) []corev1.VolumeMount {
+	if !Enabled(tls) {
+		return nil
+	}
+	cert, key, caCert := mounts(tls)
+	return []corev1.VolumeMount{
+		cert.ContainerVolumeMount(),
+		key.ContainerVolumeMount(),
+		caCert.ContainerVolumeMount(),
+	}
+}
+
+// ServerFlags returns the flags that make a component serve gRPC over TLS
+// and require clients to present a certificate signed by the CA.
+func ServerFlags(tls *planetscalev2.VitessInternalTLSSpec) vitess.Flags {
+	if !Enabled(tls) {
+		return nil
+	}
+	cert, key, caCert := mounts(tls)
+	return vitess.Flags{
+		"grpc_cert": cert.FilePath(),
+		"grpc_key":  key.FilePath(),
+		"grpc_ca":   caCert.FilePath(),
+	}
+}
+
+// TabletClientFlags returns the flags for connecting to the vttablet query
+// service over TLS.
+func TabletClientFlags(tls *planetscalev2.VitessInternalTLSSpec) vitess.Flags {
+	return clientFlags(tls, "tablet_grpc")
+}
+
+// TabletManagerClientFlags returns the flags for connecting to the vttablet
+// tablet manager service over TLS.
+func TabletManagerClientFlags(tls *planetscalev2.VitessInternalTLSSpec) vitess.Flags {
+	return clientFlags(tls, "tablet_manager_grpc")
+}
+
+// VtctldClientFlags returns the flags for connecting to vtctld over TLS.
+func VtctldClientFlags(tls *planetscalev2.VitessInternalTLSSpec) vitess.Flags {
+	return clientFlags(tls, "vtctld_grpc")
+}
+
+// VtgateClientFlags returns the flags for connecting to the vtgate gRPC
+// service over TLS.
+func VtgateClientFlags(tls *planetscalev2.VitessInternalTLSSpec) vitess.Flags {
+	return clientFlags(tls, "vtgate_grpc")
+}
+
+func clientFlags(tls *planetscalev2.VitessInternalTLSSpec, prefix string) vitess.Flags {
+	if !Enabled(tls) {
+		return nil
+	}
+	cert, key, caCert := mounts(tls)
+	return vitess.Flags{
+		prefix + "_cert":        cert.FilePath(),
+		prefix + "_key":         key.FilePath(),
+		prefix + "_ca":          caCert.FilePath(),
+		prefix + "_server_name": tls.ServerName,
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internaltls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/certs"
+)
+
+func TestClientFlags(t *testing.T) {
+	tls := &planetscalev2.VitessInternalTLSSpec{
+		CertSecret:   &planetscalev2.SecretSource{Name: "internal", Key: "tls.crt"},
+		KeySecret:    &planetscalev2.SecretSource{Name: "internal", Key: "tls.key"},
+		CACertSecret: &planetscalev2.SecretSource{Name: "internal", Key: "ca.crt"},
+		ServerName:   "vitess-internal",
+	}
+
+	flags := TabletManagerClientFlags(tls)
+	want := map[string]string{
+		"tablet_manager_grpc_cert":        "/vt/secrets/internal-tls-cert/tls.crt",
+		"tablet_manager_grpc_key":         "/vt/secrets/internal-tls-key/tls.key",
+		"tablet_manager_grpc_ca":          "/vt/secrets/internal-tls-ca/ca.crt",
+		"tablet_manager_grpc_server_name": "vitess-internal",
+	}
+	if len(flags) != len(want) {
+		t.Errorf("TabletManagerClientFlags() = %v; want %v", flags, want)
+	}
+	for key, value := range want {
+		if got := flags[key]; got != value {
+			t.Errorf("TabletManagerClientFlags()[%q] = %v; want %v", key, got, value)
+		}
+	}
+
+	if got := len(Volumes(tls)); got != 3 {
+		t.Errorf("len(Volumes()) = %v; want 3", got)
+	}
+
+	// Nothing should be configured unless all the Secrets are set.
+	tls.CACertSecret = nil
+	if flags := ServerFlags(tls); len(flags) != 0 {
+		t.Errorf("ServerFlags() without CA = %v; want none", flags)
+	}
+	if vols := Volumes(tls); len(vols) != 0 {
+		t.Errorf("Volumes() without CA = %v; want none", vols)
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	user := map[string]string{"a": "b"}
+
+	if got := Annotations(user, ""); len(got) != 1 {
+		t.Errorf("Annotations() with no hash = %v; want %v", got, user)
+	}
+	got := Annotations(user, "abc")
+	if got[HashAnnotation] != "abc" || got["a"] != "b" {
+		t.Errorf("Annotations() = %v; want hash and user annotations", got)
+	}
+	if _, ok := user[HashAnnotation]; ok {
+		t.Errorf("Annotations() modified its input")
+	}
+}
+
+func TestVtadminClientFlags(t *testing.T) {
+	tls := &planetscalev2.VitessInternalTLSSpec{
+		CertSecret:   &planetscalev2.SecretSource{Name: "internal", Key: "tls.crt"},
+		KeySecret:    &planetscalev2.SecretSource{Name: "internal", Key: "tls.key"},
+		CACertSecret: &planetscalev2.SecretSource{Name: "internal", Key: "ca.crt"},
+		ServerName:   "vitess-internal",
+	}
+	if got, want := VtctldClientFlags(tls)["vtctld_grpc_cert"], "/vt/secrets/internal-tls-cert/tls.crt"; got != want {
+		t.Errorf("VtctldClientFlags()[vtctld_grpc_cert] = %v; want %v", got, want)
+	}
+	if got, want := VtgateClientFlags(tls)["vtgate_grpc_server_name"], "vitess-internal"; got != want {
+		t.Errorf("VtgateClientFlags()[vtgate_grpc_server_name] = %v; want %v", got, want)
+	}
+}
+
+func TestOperatorClientConfig(t *testing.T) {
+	ctx := t.Context()
+	now := time.Now()
+	ca, err := certs.NewCA("internal-ca", now)
+	if err != nil {
+		t.Fatalf("NewCA() error: %v", err)
+	}
+	kp, err := certs.IssueCert(ca, "vitess-internal", []string{"vitess-internal"}, time.Hour, now)
+	if err != nil {
+		t.Fatalf("IssueCert() error: %v", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "example-internal-tls"},
+		Data:       kp.SecretData(ca.CertPEM),
+	}
+	cl := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	spec := &planetscalev2.VitessInternalTLSSpec{
+		CertSecret:   &planetscalev2.SecretSource{Name: secret.Name, Key: certs.CertKey},
+		KeySecret:    &planetscalev2.SecretSource{Name: secret.Name, Key: certs.PrivateKeyKey},
+		CACertSecret: &planetscalev2.SecretSource{Name: secret.Name, Key: certs.CACertKey},
+		ServerName:   "vitess-internal",
+	}
+	config, err := OperatorClientConfig(ctx, cl, "ns", spec)
+	if err != nil {
+		t.Fatalf("OperatorClientConfig() error: %v", err)
+	}
+	if config == nil || len(config.Certificates) != 1 || config.ServerName != "vitess-internal" {
+		t.Fatalf("OperatorClientConfig() = %v; want the internal certificate", config)
+	}
+
+	RegisterTablets("ns/shard", []string{"10.0.0.1:15999", "10.0.0.2:15999"}, config)
+	if tablets.config("10.0.0.1:15999") != config {
+		t.Errorf("registered tablet has no TLS config")
+	}
+	// Registering again replaces the old addresses.
+	RegisterTablets("ns/shard", []string{"10.0.0.2:15999"}, config)
+	if tablets.config("10.0.0.1:15999") != nil {
+		t.Errorf("tablet that went away still has a TLS config")
+	}
+	RegisterTablets("ns/shard", []string{"10.0.0.2:15999"}, nil)
+	if tablets.config("10.0.0.2:15999") != nil {
+		t.Errorf("tablet still has a TLS config after internal TLS was turned off")
+	}
+
+	// Certificates that are only mounted as volumes can't be read.
+	spec.CertSecret = &planetscalev2.SecretSource{VolumeName: "internal-tls", Key: certs.CertKey}
+	if config, err := OperatorClientConfig(ctx, cl, "ns", spec); err != nil || config != nil {
+		t.Errorf("OperatorClientConfig() with volumeName = %v, %v; want nil, nil", config, err)
+	}
+}
+
+func TestDialTablet(t *testing.T) {
+	ctx := t.Context()
+	now := time.Now()
+	ca, err := certs.NewCA("internal-ca", now)
+	if err != nil {
+		t.Fatalf("NewCA() error: %v", err)
+	}
+	kp, err := certs.IssueCert(ca, "vitess-internal", []string{"vitess-internal"}, time.Hour, now)
+	if err != nil {
+		t.Fatalf("IssueCert() error: %v", err)
+	}
+	cert, err := tls.X509KeyPair(kp.CertPEM, kp.KeyPEM)
+	if err != nil {
+		t.Fatalf("X509KeyPair() error: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.CertPEM)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"h2"},
+	})
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	addr := listener.Addr().String()
+
+	// Addresses that aren't registered are left to the caller's credentials.
+	conn, err := dialTablet(ctx, addr)
+	if err != nil {
+		t.Fatalf("dialTablet() error: %v", err)
+	}
+	if _, ok := conn.(*tls.Conn); ok {
+		t.Errorf("dialTablet() to an unregistered address did a TLS handshake")
+	}
+	conn.Close()
+
+	RegisterTablets("ns/dial", []string{addr}, &tls.Config{RootCAs: roots, ServerName: "vitess-internal"})
+	defer RegisterTablets("ns/dial", nil, nil)
+	conn, err = dialTablet(ctx, addr)
+	if err != nil {
+		t.Fatalf("dialTablet() to a registered address error: %v", err)
+	}
+	defer conn.Close()
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		t.Fatalf("dialTablet() to a registered address didn't do a TLS handshake")
+	}
+	if got := tlsConn.ConnectionState().NegotiatedProtocol; got != "h2" {
+		t.Errorf("NegotiatedProtocol = %q; want h2", got)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internaltls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"vitess.io/vitess/go/vt/grpcclient"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+/*
+The operator calls the tablet manager service of vttablets in every cluster it
+manages, through the process-wide Vitess gRPC client. That client only takes
+one set of TLS flags (--tablet_manager_grpc_ca and friends), but each cluster
+has its own CA. So each VitessShard registers the addresses of its tablets
+along with the client TLS config of its cluster, and we add a dialer that does
+the TLS handshake itself for those addresses only.
+
+Connections to any other address are dialed as usual, so they keep the
+credentials that the caller derived from the process-wide flags. If those flags
+turn on TLS, they take precedence for every address, since wrapping the
+connection in a second TLS layer would only break it.
+*/
+
+// processTLSFlags are the process-wide flags that turn on TLS for the
+// tablet manager client.
+var processTLSFlags = []string{
+	"tablet-manager-grpc-ca",
+	"tablet-manager-grpc-cert",
+	"tablet-manager-grpc-key",
+}
+
+func init() {
+	grpcclient.RegisterGRPCDialOptions(func(opts []grpc.DialOption) ([]grpc.DialOption, error) {
+		return append(opts, grpc.WithContextDialer(dialTablet)), nil
+	})
+}
+
+// tablets is the process-wide registry of tablet addresses that need a
+// client certificate.
+var tablets = &tabletRegistry{
+	configs: make(map[string]*tls.Config),
+	owners:  make(map[string][]string),
+}
+
+type tabletRegistry struct {
+	mu sync.RWMutex
+	// configs maps from tablet address to the TLS config to dial it with.
+	configs map[string]*tls.Config
+	// owners maps from the owner of a set of addresses to the addresses it
+	// last registered, so we can forget the ones that went away.
+	owners map[string][]string
+}
+
+// RegisterTablets sets the TLS config used by the operator to connect to the
+// tablets at the given gRPC addresses ("host:port"). It replaces whatever the
+// same owner registered before. If config is nil, the addresses are dialed
+// with whatever credentials the caller derived from the process-wide flags.
+func RegisterTablets(owner string, addrs []string, config *tls.Config) {
+	tablets.mu.Lock()
+	defer tablets.mu.Unlock()
+
+	for _, addr := range tablets.owners[owner] {
+		delete(tablets.configs, addr)
+	}
+	delete(tablets.owners, owner)
+	if config == nil || len(addrs) == 0 {
+		return
+	}
+	for _, addr := range addrs {
+		tablets.configs[addr] = config
+	}
+	tablets.owners[owner] = addrs
+}
+
+func (r *tabletRegistry) config(addr string) *tls.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.configs[addr]
+}
+
+// OperatorClientConfig returns the TLS config for the operator's own
+// connections to tablets, built from the internal TLS Secrets. It returns
+// nil if internal TLS isn't enabled, or if the certificate is only given as
+// a Pod Volume, which the operator can't read.
+func OperatorClientConfig(ctx context.Context, cl client.Client, namespace string, spec *planetscalev2.VitessInternalTLSSpec) (*tls.Config, error) {
+	if !Enabled(spec) {
+		return nil, nil
+	}
+	for _, source := range []*planetscalev2.SecretSource{spec.CertSecret, spec.KeySecret, spec.CACertSecret} {
+		if source.VolumeName != "" || source.Name == "" {
+			return nil, nil
+		}
+	}
+
+	read := func(source *planetscalev2.SecretSource) ([]byte, error) {
+		secret := &corev1.Secret{}
+		if err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: source.Name}, secret); err != nil {
+			return nil, err
+		}
+		data, ok := secret.Data[source.Key]
+		if !ok {
+			return nil, fmt.Errorf("internal TLS Secret %v has no %q key", source.Name, source.Key)
+		}
+		return data, nil
+	}
+	certPEM, err := read(spec.CertSecret)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := read(spec.KeySecret)
+	if err != nil {
+		return nil, err
+	}
+	caPEM, err := read(spec.CACertSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid internal TLS certificate: %v", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("invalid internal TLS CA certificate")
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      roots,
+		ServerName:   spec.ServerName,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// dialTablet connects to addr, and does the TLS handshake with the client
+// certificate of its cluster if it's a registered tablet address.
+func dialTablet(ctx context.Context, addr string) (net.Conn, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	config := tablets.config(addr)
+	if config == nil || processTLSEnabled() {
+		return conn, nil
+	}
+
+	config = config.Clone()
+	// gRPC servers require HTTP/2 to be negotiated during the handshake.
+	config.NextProtos = []string{"h2"}
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		config.ServerName = host
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("TLS handshake with tablet %v failed: %w", addr, err)
+	}
+	return tlsConn, nil
+}
+
+// processTLSEnabled returns whether the process-wide tablet manager TLS flags
+// are set, in which case the caller's credentials already use TLS.
+func processTLSEnabled() bool {
+	for _, name := range processTLSFlags {
+		if f := pflag.CommandLine.Lookup(name); f != nil && f.Value.String() != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lockserver
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/names"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+)
+
+// ConsulLockserverNameConstraints are special constraints for the generated
+// name of a ConsulLockserver. The ConsulLockserver controller creates Service
+// names by appending "-client" and "-peer" to the object name, so the name
+// must leave space for the extra suffix.
+var ConsulLockserverNameConstraints = names.Constraints{
+	MaxLength:      names.ServiceConstraints.MaxLength - len("-client"),
+	ValidFirstChar: names.ServiceConstraints.ValidFirstChar,
+}
+
+// LocalConsulName returns the name of the ConsulLockserver object used for a cell-local lockserver.
+func LocalConsulName(clusterName, cellName string) string {
+	return names.JoinWithConstraints(ConsulLockserverNameConstraints, clusterName, cellName, planetscalev2.ConsulComponentName)
+}
+
+// GlobalConsulName returns the name of the ConsulLockserver object used for a global lockserver.
+func GlobalConsulName(clusterName string) string {
+	return names.JoinWithConstraints(ConsulLockserverNameConstraints, clusterName, planetscalev2.ConsulComponentName)
+}
+
+// NewConsulLockserver generates a ConsulLockserver object for the given ConsulLockserverTemplate.
+// The ConsulLockserverTemplate must have already had defaults filled in.
+func NewConsulLockserver(key client.ObjectKey, tpl *planetscalev2.ConsulLockserverTemplate, labels map[string]string, zone string) *planetscalev2.ConsulLockserver {
+	ls := &planetscalev2.ConsulLockserver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+		},
+	}
+	UpdateConsulLockserver(ls, tpl, labels, zone)
+	return ls
+}
+
+// UpdateConsulLockserver updates parts of an existing ConsulLockserver that are allowed to change in-place.
+// The ConsulLockserverTemplate must have already had defaults filled in.
+func UpdateConsulLockserver(obj *planetscalev2.ConsulLockserver, tpl *planetscalev2.ConsulLockserverTemplate, labels map[string]string, zone string) {
+	update.Labels(&obj.Labels, labels)
+	obj.Spec.Zone = zone
+	obj.Spec.ConsulLockserverTemplate = *tpl
+}
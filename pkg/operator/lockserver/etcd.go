@@ -51,21 +51,30 @@ func GlobalEtcdName(clusterName string) string {
 
 // NewEtcdLockserver generates an EtcdLockserver object for the given EtcdLockserverTemplate.
 // The EtcdLockserverTemplate must have already had defaults filled in.
-func NewEtcdLockserver(key client.ObjectKey, tpl *planetscalev2.EtcdLockserverTemplate, labels map[string]string, zone string) *planetscalev2.EtcdLockserver {
+func NewEtcdLockserver(key client.ObjectKey, tpl *planetscalev2.EtcdLockserverTemplate, labels map[string]string, zone string, backupLocations []planetscalev2.VitessBackupLocation) *planetscalev2.EtcdLockserver {
 	ls := &planetscalev2.EtcdLockserver{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      key.Name,
 			Namespace: key.Namespace,
 		},
 	}
-	UpdateEtcdLockserver(ls, tpl, labels, zone)
+	UpdateEtcdLockserver(ls, tpl, labels, zone, backupLocations)
 	return ls
 }
 
 // UpdateEtcdLockserver updates parts of an existing EtcdLockserver that are allowed to change in-place.
 // The EtcdLockserverTemplate must have already had defaults filled in.
-func UpdateEtcdLockserver(obj *planetscalev2.EtcdLockserver, tpl *planetscalev2.EtcdLockserverTemplate, labels map[string]string, zone string) {
+func UpdateEtcdLockserver(obj *planetscalev2.EtcdLockserver, tpl *planetscalev2.EtcdLockserverTemplate, labels map[string]string, zone string, backupLocations []planetscalev2.VitessBackupLocation) {
 	update.Labels(&obj.Labels, labels)
 	obj.Spec.Zone = zone
+	obj.Spec.BackupLocations = backupLocations
 	obj.Spec.EtcdLockserverTemplate = *tpl
+
+	// Point operator-managed TLS at the Secrets that the VitessCluster
+	// controller maintains for this lockserver.
+	if tls := tpl.TLS; tls != nil && tls.OperatorManaged != nil {
+		obj.Spec.TLS = tls.DeepCopy()
+		obj.Spec.TLS.ServerSecret = EtcdServerTLSSecretName(obj.Name)
+		obj.Spec.TLS.ClientSecret = EtcdClientTLSSecretName(labels[planetscalev2.ClusterLabel])
+	}
 }
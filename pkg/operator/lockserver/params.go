@@ -18,6 +18,7 @@ package lockserver
 
 import (
 	"fmt"
+	"strings"
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
 )
@@ -29,6 +30,20 @@ const (
 	// VitessEtcdImplementationName is the topo plugin name to give Vitess to tell it to
 	// connect to an etcd cluster as the lockserver (topology store).
 	VitessEtcdImplementationName = "etcd2"
+
+	// ConsulClientPort is the port for clients to connect to Consul.
+	ConsulClientPort = 8500
+
+	// VitessConsulImplementationName is the topo plugin name to give Vitess to tell it to
+	// connect to a Consul cluster as the lockserver (topology store).
+	VitessConsulImplementationName = "consul"
+
+	// ZkClientPort is the port for clients to connect to ZooKeeper.
+	ZkClientPort = 2181
+
+	// VitessZkImplementationName is the topo plugin name to give Vitess to tell it to
+	// connect to a ZooKeeper ensemble as the lockserver (topology store).
+	VitessZkImplementationName = "zk2"
 )
 
 // GlobalConnectionParams returns the Vitess connection parameters for a
@@ -43,7 +58,28 @@ func GlobalConnectionParams(lockSpec *planetscalev2.LockserverSpec, namespace, c
 			address = fmt.Sprintf("%s-client.%s.svc:%d", GlobalEtcdName(clusterName), namespace, EtcdClientPort)
 		}
 		return &planetscalev2.VitessLockserverParams{
-			Implementation: VitessEtcdImplementationName,
+			Implementation:  VitessEtcdImplementationName,
+			Address:         address,
+			RootPath:        fmt.Sprintf("/vitess/%s/global", clusterName),
+			ClientTLSSecret: etcdClientTLSSecret(lockSpec.Etcd, clusterName),
+		}
+	case lockSpec.Consul != nil:
+		address := lockSpec.CellInfoAddress
+		if len(address) == 0 {
+			address = fmt.Sprintf("%s-client.%s.svc:%d", GlobalConsulName(clusterName), namespace, ConsulClientPort)
+		}
+		return &planetscalev2.VitessLockserverParams{
+			Implementation: VitessConsulImplementationName,
+			Address:        address,
+			RootPath:       consulRootPath(fmt.Sprintf("/vitess/%s/global", clusterName)),
+		}
+	case lockSpec.Zk != nil:
+		address := lockSpec.CellInfoAddress
+		if len(address) == 0 {
+			address = fmt.Sprintf("%s-client.%s.svc:%d", GlobalZkName(clusterName), namespace, ZkClientPort)
+		}
+		return &planetscalev2.VitessLockserverParams{
+			Implementation: VitessZkImplementationName,
 			Address:        address,
 			RootPath:       fmt.Sprintf("/vitess/%s/global", clusterName),
 		}
@@ -68,7 +104,30 @@ func LocalConnectionParams(globalLockserverSpec, cellLockserverSpec *planetscale
 			address = fmt.Sprintf("%s-client.%s.svc:%d", LocalEtcdName(clusterName, cellName), namespace, EtcdClientPort)
 		}
 		return &planetscalev2.VitessLockserverParams{
-			Implementation: VitessEtcdImplementationName,
+			Implementation:  VitessEtcdImplementationName,
+			Address:         address,
+			RootPath:        rootPath,
+			ClientTLSSecret: etcdClientTLSSecret(cellLockserverSpec.Etcd, clusterName),
+		}
+	case cellLockserverSpec.Consul != nil:
+		address := cellLockserverSpec.CellInfoAddress
+		if len(address) == 0 {
+			// Point to the client Service created by the local ConsulLockserver.
+			address = fmt.Sprintf("%s-client.%s.svc:%d", LocalConsulName(clusterName, cellName), namespace, ConsulClientPort)
+		}
+		return &planetscalev2.VitessLockserverParams{
+			Implementation: VitessConsulImplementationName,
+			Address:        address,
+			RootPath:       consulRootPath(rootPath),
+		}
+	case cellLockserverSpec.Zk != nil:
+		address := cellLockserverSpec.CellInfoAddress
+		if len(address) == 0 {
+			// Point to the client Service created by the local ZkLockserver.
+			address = fmt.Sprintf("%s-client.%s.svc:%d", LocalZkName(clusterName, cellName), namespace, ZkClientPort)
+		}
+		return &planetscalev2.VitessLockserverParams{
+			Implementation: VitessZkImplementationName,
 			Address:        address,
 			RootPath:       rootPath,
 		}
@@ -79,10 +138,21 @@ func LocalConnectionParams(globalLockserverSpec, cellLockserverSpec *planetscale
 		if globalParams == nil {
 			return nil
 		}
+		// This applies to any Consul lockserver, whether we deploy it or not.
+		if globalParams.Implementation == VitessConsulImplementationName {
+			rootPath = consulRootPath(rootPath)
+		}
 		return &planetscalev2.VitessLockserverParams{
-			Implementation: globalParams.Implementation,
-			Address:        globalParams.Address,
-			RootPath:       rootPath,
+			Implementation:  globalParams.Implementation,
+			Address:         globalParams.Address,
+			RootPath:        rootPath,
+			ClientTLSSecret: globalParams.ClientTLSSecret,
 		}
 	}
 }
+
+// consulRootPath converts a topo root path into a Consul KV prefix.
+// Consul KV keys don't start with a slash.
+func consulRootPath(rootPath string) string {
+	return strings.TrimPrefix(rootPath, "/")
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lockserver
+
+import (
+	"testing"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+func TestConnectionParamsManagedLockservers(t *testing.T) {
+	table := []struct {
+		name           string
+		spec           *planetscalev2.LockserverSpec
+		implementation string
+		address        string
+		globalRoot     string
+		localRoot      string
+	}{
+		{
+			name:           "consul",
+			spec:           &planetscalev2.LockserverSpec{Consul: &planetscalev2.ConsulLockserverTemplate{}},
+			implementation: VitessConsulImplementationName,
+			address:        GlobalConsulName("example") + "-client.ns.svc:8500",
+			globalRoot:     "vitess/example/global",
+			localRoot:      "vitess/example/local/zone1",
+		},
+		{
+			name:           "zk",
+			spec:           &planetscalev2.LockserverSpec{Zk: &planetscalev2.ZkLockserverTemplate{}},
+			implementation: VitessZkImplementationName,
+			address:        GlobalZkName("example") + "-client.ns.svc:2181",
+			globalRoot:     "/vitess/example/global",
+			localRoot:      "/vitess/example/local/zone1",
+		},
+		{
+			name: "external consul",
+			spec: &planetscalev2.LockserverSpec{External: &planetscalev2.VitessLockserverParams{
+				Implementation: VitessConsulImplementationName,
+				Address:        "consul.example.com:8500",
+				RootPath:       "vitess/example/global",
+			}},
+			implementation: VitessConsulImplementationName,
+			address:        "consul.example.com:8500",
+			globalRoot:     "vitess/example/global",
+			localRoot:      "vitess/example/local/zone1",
+		},
+		{
+			name: "etcd takes precedence",
+			spec: &planetscalev2.LockserverSpec{
+				Etcd:   &planetscalev2.EtcdLockserverTemplate{},
+				Consul: &planetscalev2.ConsulLockserverTemplate{},
+				Zk:     &planetscalev2.ZkLockserverTemplate{},
+			},
+			implementation: VitessEtcdImplementationName,
+			globalRoot:     "/vitess/example/global",
+			localRoot:      "/vitess/example/local/zone1",
+		},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			global := GlobalConnectionParams(test.spec, "ns", "example")
+			if global.Implementation != test.implementation {
+				t.Errorf("GlobalConnectionParams().Implementation = %q; want %q", global.Implementation, test.implementation)
+			}
+			if test.address != "" && global.Address != test.address {
+				t.Errorf("GlobalConnectionParams().Address = %q; want %q", global.Address, test.address)
+			}
+			if global.RootPath != test.globalRoot {
+				t.Errorf("GlobalConnectionParams().RootPath = %q; want %q", global.RootPath, test.globalRoot)
+			}
+
+			// A cell without its own lockserver shares the global one.
+			local := LocalConnectionParams(test.spec, &planetscalev2.LockserverSpec{}, "ns", "example", "zone1")
+			if local.Implementation != test.implementation {
+				t.Errorf("LocalConnectionParams().Implementation = %q; want %q", local.Implementation, test.implementation)
+			}
+			if local.RootPath != test.localRoot {
+				t.Errorf("LocalConnectionParams().RootPath = %q; want %q", local.RootPath, test.localRoot)
+			}
+		})
+	}
+}
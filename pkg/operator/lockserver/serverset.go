@@ -0,0 +1,145 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lockserver
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+)
+
+// ServerSet is a kind of lockserver that we deploy as a fixed set of server
+// Pods, like Consul or ZooKeeper. Parent controllers use it to deploy each
+// kind the same way.
+type ServerSet struct {
+	// ComponentName is the value of the component label of the lockserver.
+	ComponentName string
+	// Enabled is whether the LockserverSpec asks us to deploy this kind.
+	Enabled bool
+
+	kind        client.Object
+	kindName    string
+	displayName string
+	localName   func(clusterName, cellName string) string
+	globalName  func(clusterName string) string
+	newObject   func(key client.ObjectKey, labels map[string]string, zone string) runtime.Object
+	update      func(obj runtime.Object, labels map[string]string, zone string)
+	initStatus  func(status *planetscalev2.LockserverStatus)
+	setStatus   func(status *planetscalev2.LockserverStatus, obj runtime.Object)
+}
+
+// ServerSets returns every kind of ServerSet, with Enabled set for the one
+// that lockSpec asks for, if any.
+func ServerSets(lockSpec *planetscalev2.LockserverSpec) []*ServerSet {
+	consulTpl := lockSpec.ManagedConsul()
+	zkTpl := lockSpec.ManagedZk()
+
+	return []*ServerSet{
+		{
+			ComponentName: planetscalev2.ConsulComponentName,
+			Enabled:       consulTpl != nil,
+			kind:          &planetscalev2.ConsulLockserver{},
+			kindName:      "ConsulLockserver",
+			displayName:   "Consul",
+			localName:     LocalConsulName,
+			globalName:    GlobalConsulName,
+			newObject: func(key client.ObjectKey, labels map[string]string, zone string) runtime.Object {
+				return NewConsulLockserver(key, consulTpl, labels, zone)
+			},
+			update: func(obj runtime.Object, labels map[string]string, zone string) {
+				UpdateConsulLockserver(obj.(*planetscalev2.ConsulLockserver), consulTpl, labels, zone)
+			},
+			initStatus: func(status *planetscalev2.LockserverStatus) {
+				status.Consul = planetscalev2.NewConsulLockserverStatus()
+			},
+			setStatus: func(status *planetscalev2.LockserverStatus, obj runtime.Object) {
+				// Make a copy of status and erase things we don't care about.
+				curStatus := obj.(*planetscalev2.ConsulLockserver).Status
+				curStatus.ObservedGeneration = 0
+				status.Consul = &curStatus
+			},
+		},
+		{
+			ComponentName: planetscalev2.ZkComponentName,
+			Enabled:       zkTpl != nil,
+			kind:          &planetscalev2.ZkLockserver{},
+			kindName:      "ZkLockserver",
+			displayName:   "ZooKeeper",
+			localName:     LocalZkName,
+			globalName:    GlobalZkName,
+			newObject: func(key client.ObjectKey, labels map[string]string, zone string) runtime.Object {
+				return NewZkLockserver(key, zkTpl, labels, zone)
+			},
+			update: func(obj runtime.Object, labels map[string]string, zone string) {
+				UpdateZkLockserver(obj.(*planetscalev2.ZkLockserver), zkTpl, labels, zone)
+			},
+			initStatus: func(status *planetscalev2.LockserverStatus) {
+				status.Zk = planetscalev2.NewZkLockserverStatus()
+			},
+			setStatus: func(status *planetscalev2.LockserverStatus, obj runtime.Object) {
+				// Make a copy of status and erase things we don't care about.
+				curStatus := obj.(*planetscalev2.ZkLockserver).Status
+				curStatus.ObservedGeneration = 0
+				status.Zk = &curStatus
+			},
+		},
+	}
+}
+
+// LocalName returns the name of the object used for a cell-local lockserver.
+func (s *ServerSet) LocalName(clusterName, cellName string) string {
+	return s.localName(clusterName, cellName)
+}
+
+// GlobalName returns the name of the object used for a global lockserver.
+func (s *ServerSet) GlobalName(clusterName string) string {
+	return s.globalName(clusterName)
+}
+
+// Strategy returns the reconciler Strategy that deploys the lockserver in
+// zone, and records its status in status.
+//
+// If the lockserver is enabled, this also initializes its status, so it's
+// set even before the object exists.
+func (s *ServerSet) Strategy(labels map[string]string, zone string, status *planetscalev2.LockserverStatus) reconciler.Strategy {
+	if s.Enabled {
+		s.initStatus(status)
+	}
+
+	return reconciler.Strategy{
+		Kind: s.kind,
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return s.newObject(key, labels, zone)
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			s.update(obj, labels, zone)
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			s.setStatus(status, obj)
+		},
+		PrepareForTurndown: func(key client.ObjectKey, obj runtime.Object) *planetscalev2.OrphanStatus {
+			// Like etcd, losing the lockserver can be very disruptive,
+			// so we require manual deletion.
+			return planetscalev2.NewOrphanStatus("NotSupported", fmt.Sprintf("Automatic turndown is not supported for %s for safety reasons. The %s instance must be deleted manually.", s.displayName, s.kindName))
+		},
+	}
+}
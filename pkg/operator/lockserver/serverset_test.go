@@ -0,0 +1,86 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lockserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+)
+
+func TestServerSets(t *testing.T) {
+	ctx := t.Context()
+	scheme := runtime.NewScheme()
+	require.NoError(t, planetscalev2.SchemeBuilder.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := reconciler.New(c, scheme, record.NewFakeRecorder(100))
+
+	vt := &planetscalev2.VitessCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "example", UID: "uid"},
+		Spec: planetscalev2.VitessClusterSpec{
+			GlobalLockserver: planetscalev2.LockserverSpec{
+				Zk: &planetscalev2.ZkLockserverTemplate{Image: "zookeeper:latest"},
+			},
+		},
+	}
+	reconcileAll := func() {
+		for _, ls := range ServerSets(&vt.Spec.GlobalLockserver) {
+			key := client.ObjectKey{Namespace: vt.Namespace, Name: ls.GlobalName(vt.Name)}
+			labels := map[string]string{
+				planetscalev2.ClusterLabel:   vt.Name,
+				planetscalev2.ComponentLabel: ls.ComponentName,
+			}
+			err := r.ReconcileObject(ctx, vt, key, labels, ls.Enabled, ls.Strategy(labels, "", &vt.Status.GlobalLockserver))
+			require.NoError(t, err)
+		}
+	}
+
+	reconcileAll()
+	zk := &planetscalev2.ZkLockserver{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: GlobalZkName("example")}, zk))
+	assert.Equal(t, "zookeeper:latest", zk.Spec.Image)
+	assert.Equal(t, planetscalev2.ZkComponentName, zk.Labels[planetscalev2.ComponentLabel])
+	assert.NotNil(t, vt.Status.GlobalLockserver.Zk)
+	assert.Nil(t, vt.Status.GlobalLockserver.Consul)
+
+	consul := &planetscalev2.ConsulLockserver{}
+	err := c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: GlobalConsulName("example")}, consul)
+	assert.True(t, apierrors.IsNotFound(err), "ConsulLockserver should not exist: %v", err)
+
+	// Consul takes precedence over ZooKeeper. The old ZkLockserver isn't
+	// deleted automatically.
+	vt.Spec.GlobalLockserver.Consul = &planetscalev2.ConsulLockserverTemplate{Image: "consul:latest"}
+	vt.Status.GlobalLockserver = planetscalev2.LockserverStatus{}
+	reconcileAll()
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: GlobalConsulName("example")}, consul))
+	assert.Equal(t, "consul:latest", consul.Spec.Image)
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: GlobalZkName("example")}, zk))
+	assert.NotNil(t, vt.Status.GlobalLockserver.Consul)
+}
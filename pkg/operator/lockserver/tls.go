@@ -0,0 +1,170 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lockserver
+
+import (
+	"context"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/certs"
+	"planetscale.dev/vitess-operator/pkg/operator/names"
+	"planetscale.dev/vitess-operator/pkg/operator/secrets"
+	"planetscale.dev/vitess-operator/pkg/operator/vitess"
+)
+
+const (
+	// ClientTLSHashAnnotation is the Pod annotation containing a hash of the
+	// contents of the lockserver client TLS Secret. Changing it triggers a
+	// rolling restart, since Vitess only loads the certificate on startup.
+	ClientTLSHashAnnotation = "planetscale.com/topo-tls-hash"
+
+	clientTLSVolumeName = "topo-tls"
+	clientTLSDirName    = "topo-tls"
+)
+
+// EtcdCASecretName returns the name of the Secret holding the operator-managed
+// CA that signs etcd certificates for a cluster.
+func EtcdCASecretName(clusterName string) string {
+	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, planetscalev2.EtcdComponentName, "ca")
+}
+
+// EtcdClientTLSSecretName returns the name of the Secret holding the
+// operator-managed etcd client certificate for a cluster.
+func EtcdClientTLSSecretName(clusterName string) string {
+	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, planetscalev2.EtcdComponentName, "client", "tls")
+}
+
+// EtcdServerTLSSecretName returns the name of the Secret holding the
+// operator-managed server certificate for an EtcdLockserver.
+func EtcdServerTLSSecretName(lockserverName string) string {
+	return names.JoinWithConstraints(names.DefaultConstraints, lockserverName, "server", "tls")
+}
+
+// etcdClientTLSSecret returns the name of the client TLS Secret for an etcd
+// lockserver deployed as part of the given cluster, or "" if TLS is disabled.
+func etcdClientTLSSecret(tpl *planetscalev2.EtcdLockserverTemplate, clusterName string) string {
+	switch {
+	case tpl.TLS == nil:
+		return ""
+	case tpl.TLS.OperatorManaged != nil:
+		return EtcdClientTLSSecretName(clusterName)
+	default:
+		return tpl.TLS.ClientSecret
+	}
+}
+
+// ClientTLSSecretNames returns the set of Secrets that contain lockserver
+// client TLS credentials for the given params.
+// It's safe to call this on a nil object.
+func ClientTLSSecretNames(params *planetscalev2.VitessLockserverParams) sets.String {
+	if params == nil || params.ClientTLSSecret == "" {
+		return nil
+	}
+	return sets.NewString(params.ClientTLSSecret)
+}
+
+// ClientTLSContentHash returns a hash of the contents of the lockserver client
+// TLS Secret, or "" if TLS isn't enabled. Components put this in
+// ClientTLSHashAnnotation so they get restarted when the certificate is rotated.
+func ClientTLSContentHash(ctx context.Context, cl client.Client, namespace string, params *planetscalev2.VitessLockserverParams) (string, error) {
+	secretNames := ClientTLSSecretNames(params)
+	if secretNames.Len() == 0 {
+		return "", nil
+	}
+	tlsSecrets, err := secrets.GetByNames(ctx, cl, namespace, secretNames)
+	if err != nil {
+		return "", err
+	}
+	return secrets.ContentHash(tlsSecrets...), nil
+}
+
+// ClientTLSAnnotations returns the given Pod annotations with
+// ClientTLSHashAnnotation added, if the hash is non-empty.
+// The input map is not modified.
+func ClientTLSAnnotations(annotations map[string]string, hash string) map[string]string {
+	if hash == "" {
+		return annotations
+	}
+	result := map[string]string{ClientTLSHashAnnotation: hash}
+	for key, value := range annotations {
+		result[key] = value
+	}
+	return result
+}
+
+func clientTLSDirPath() string {
+	return filepath.Join(secrets.VolumeMountRootDir, clientTLSDirName)
+}
+
+// ClientTLSVolumes returns the Pod Volumes needed to connect to the lockserver
+// with TLS, if enabled.
+func ClientTLSVolumes(params *planetscalev2.VitessLockserverParams) []corev1.Volume {
+	if params == nil || params.ClientTLSSecret == "" {
+		return nil
+	}
+	return []corev1.Volume{
+		{
+			Name: clientTLSVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  params.ClientTLSSecret,
+					DefaultMode: ptr.To(int32(0444)),
+					Items: []corev1.KeyToPath{
+						{Key: certs.CertKey, Path: certs.CertKey},
+						{Key: certs.PrivateKeyKey, Path: certs.PrivateKeyKey},
+						{Key: certs.CACertKey, Path: certs.CACertKey},
+					},
+				},
+			},
+		},
+	}
+}
+
+// ClientTLSVolumeMounts returns the container VolumeMounts needed to connect
+// to the lockserver with TLS, if enabled.
+func ClientTLSVolumeMounts(params *planetscalev2.VitessLockserverParams) []corev1.VolumeMount {
+	if params == nil || params.ClientTLSSecret == "" {
+		return nil
+	}
+	return []corev1.VolumeMount{
+		{
+			Name:      clientTLSVolumeName,
+			MountPath: clientTLSDirPath(),
+			ReadOnly:  true,
+		},
+	}
+}
+
+// ClientTLSFlags returns the Vitess topo flags needed to connect to the
+// lockserver with TLS, if enabled.
+func ClientTLSFlags(params *planetscalev2.VitessLockserverParams) vitess.Flags {
+	if params == nil || params.ClientTLSSecret == "" {
+		return nil
+	}
+	dir := clientTLSDirPath()
+	return vitess.Flags{
+		"topo_etcd_tls_cert": filepath.Join(dir, certs.CertKey),
+		"topo_etcd_tls_key":  filepath.Join(dir, certs.PrivateKeyKey),
+		"topo_etcd_tls_ca":   filepath.Join(dir, certs.CACertKey),
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lockserver
+
+import (
+	"testing"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+func TestConnectionParamsClientTLSSecret(t *testing.T) {
+	global := &planetscalev2.LockserverSpec{
+		Etcd: &planetscalev2.EtcdLockserverTemplate{
+			TLS: &planetscalev2.EtcdLockserverTLS{
+				OperatorManaged: &planetscalev2.OperatorManagedTLS{},
+			},
+		},
+	}
+	want := EtcdClientTLSSecretName("example")
+	if got := GlobalConnectionParams(global, "ns", "example").ClientTLSSecret; got != want {
+		t.Errorf("GlobalConnectionParams().ClientTLSSecret = %q; want %q", got, want)
+	}
+
+	// A cell that shares the global lockserver also shares its client certificate.
+	if got := LocalConnectionParams(global, &planetscalev2.LockserverSpec{}, "ns", "example", "zone1").ClientTLSSecret; got != want {
+		t.Errorf("LocalConnectionParams().ClientTLSSecret = %q; want %q", got, want)
+	}
+
+	// User-supplied Secrets are passed through as-is.
+	global.Etcd.TLS = &planetscalev2.EtcdLockserverTLS{ClientSecret: "my-etcd-client"}
+	if got := GlobalConnectionParams(global, "ns", "example").ClientTLSSecret; got != "my-etcd-client" {
+		t.Errorf("GlobalConnectionParams().ClientTLSSecret = %q; want %q", got, "my-etcd-client")
+	}
+
+	global.Etcd.TLS = nil
+	if got := GlobalConnectionParams(global, "ns", "example").ClientTLSSecret; got != "" {
+		t.Errorf("GlobalConnectionParams().ClientTLSSecret without TLS = %q; want none", got)
+	}
+}
+
+func TestClientTLSFlags(t *testing.T) {
+	params := &planetscalev2.VitessLockserverParams{
+		Implementation:  VitessEtcdImplementationName,
+		ClientTLSSecret: "etcd-client",
+	}
+
+	flags := ClientTLSFlags(params)
+	want := map[string]string{
+		"topo_etcd_tls_cert": "/vt/secrets/topo-tls/tls.crt",
+		"topo_etcd_tls_key":  "/vt/secrets/topo-tls/tls.key",
+		"topo_etcd_tls_ca":   "/vt/secrets/topo-tls/ca.crt",
+	}
+	if len(flags) != len(want) {
+		t.Errorf("ClientTLSFlags() = %v; want %v", flags, want)
+	}
+	for key, value := range want {
+		if got := flags[key]; got != value {
+			t.Errorf("ClientTLSFlags()[%q] = %v; want %v", key, got, value)
+		}
+	}
+	if got := len(ClientTLSVolumes(params)); got != 1 {
+		t.Errorf("len(ClientTLSVolumes()) = %v; want 1", got)
+	}
+
+	params.ClientTLSSecret = ""
+	if flags := ClientTLSFlags(params); len(flags) != 0 {
+		t.Errorf("ClientTLSFlags() without TLS = %v; want none", flags)
+	}
+	if vols := ClientTLSVolumes(nil); len(vols) != 0 {
+		t.Errorf("ClientTLSVolumes(nil) = %v; want none", vols)
+	}
+}
+
+func TestUpdateEtcdLockserverOperatorManagedTLS(t *testing.T) {
+	tpl := &planetscalev2.EtcdLockserverTemplate{
+		TLS: &planetscalev2.EtcdLockserverTLS{
+			OperatorManaged: &planetscalev2.OperatorManagedTLS{},
+		},
+	}
+	labels := map[string]string{planetscalev2.ClusterLabel: "example"}
+	ls := &planetscalev2.EtcdLockserver{}
+	ls.Name = GlobalEtcdName("example")
+
+	UpdateEtcdLockserver(ls, tpl, labels, "", nil)
+	if got, want := ls.Spec.TLS.ServerSecret, EtcdServerTLSSecretName(ls.Name); got != want {
+		t.Errorf("ServerSecret = %q; want %q", got, want)
+	}
+	if got, want := ls.Spec.TLS.ClientSecret, EtcdClientTLSSecretName("example"); got != want {
+		t.Errorf("ClientSecret = %q; want %q", got, want)
+	}
+	if tpl.TLS.ServerSecret != "" {
+		t.Errorf("UpdateEtcdLockserver() modified the template")
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lockserver
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/names"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+)
+
+// ZkLockserverNameConstraints are special constraints for the generated
+// name of a ZkLockserver. The ZkLockserver controller creates Service
+// names by appending "-client" and "-peer" to the object name, so the name
+// must leave space for the extra suffix.
+var ZkLockserverNameConstraints = names.Constraints{
+	MaxLength:      names.ServiceConstraints.MaxLength - len("-client"),
+	ValidFirstChar: names.ServiceConstraints.ValidFirstChar,
+}
+
+// LocalZkName returns the name of the ZkLockserver object used for a cell-local lockserver.
+func LocalZkName(clusterName, cellName string) string {
+	return names.JoinWithConstraints(ZkLockserverNameConstraints, clusterName, cellName, planetscalev2.ZkComponentName)
+}
+
+// GlobalZkName returns the name of the ZkLockserver object used for a global lockserver.
+func GlobalZkName(clusterName string) string {
+	return names.JoinWithConstraints(ZkLockserverNameConstraints, clusterName, planetscalev2.ZkComponentName)
+}
+
+// NewZkLockserver generates a ZkLockserver object for the given ZkLockserverTemplate.
+// The ZkLockserverTemplate must have already had defaults filled in.
+func NewZkLockserver(key client.ObjectKey, tpl *planetscalev2.ZkLockserverTemplate, labels map[string]string, zone string) *planetscalev2.ZkLockserver {
+	ls := &planetscalev2.ZkLockserver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+		},
+	}
+	UpdateZkLockserver(ls, tpl, labels, zone)
+	return ls
+}
+
+// UpdateZkLockserver updates parts of an existing ZkLockserver that are allowed to change in-place.
+// The ZkLockserverTemplate must have already had defaults filled in.
+func UpdateZkLockserver(obj *planetscalev2.ZkLockserver, tpl *planetscalev2.ZkLockserverTemplate, labels map[string]string, zone string) {
+	update.Labels(&obj.Labels, labels)
+	obj.Spec.Zone = zone
+	obj.Spec.ZkLockserverTemplate = *tpl
+}
@@ -0,0 +1,339 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package networkpolicy builds NetworkPolicies for Vitess components.
+
+Each policy selects the Pods of one component and allows ingress only from the
+other components that need to reach it, plus any sources the user listed.
+*/
+package networkpolicy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/consul"
+	"planetscale.dev/vitess-operator/pkg/operator/etcd"
+	"planetscale.dev/vitess-operator/pkg/operator/names"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+	"planetscale.dev/vitess-operator/pkg/operator/zookeeper"
+)
+
+// Spec specifies the parameters of a NetworkPolicy.
+type Spec struct {
+	// Labels are the labels to put on the NetworkPolicy itself.
+	Labels map[string]string
+	// PodSelector selects the Pods the policy applies to.
+	PodSelector metav1.LabelSelector
+	// Ingress is the list of allowed ingress rules.
+	Ingress []networkingv1.NetworkPolicyIngressRule
+}
+
+// Enabled returns whether NetworkPolicies should be created.
+func Enabled(np *planetscalev2.VitessNetworkPolicySpec) bool {
+	return np != nil
+}
+
+// Name returns the name of the NetworkPolicy for a cluster-wide component.
+func Name(clusterName, componentName string) string {
+	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, componentName)
+}
+
+// TabletName returns the name of the NetworkPolicy for the tablets of a shard.
+func TabletName(clusterName, keyspaceName string, keyRange planetscalev2.VitessKeyRange) string {
+	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, keyspaceName, keyRange.SafeName(), planetscalev2.VttabletComponentName)
+}
+
+// NewNetworkPolicy creates a new NetworkPolicy.
+func NewNetworkPolicy(key client.ObjectKey, spec *Spec) *networkingv1.NetworkPolicy {
+	obj := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+		},
+	}
+	UpdateNetworkPolicy(obj, spec)
+	return obj
+}
+
+// UpdateNetworkPolicy updates the mutable parts of a NetworkPolicy.
+func UpdateNetworkPolicy(obj *networkingv1.NetworkPolicy, spec *Spec) {
+	// Update labels, but ignore existing ones we don't set.
+	update.Labels(&obj.Labels, spec.Labels)
+
+	obj.Spec.PodSelector = spec.PodSelector
+	obj.Spec.Ingress = spec.Ingress
+	obj.Spec.Egress = nil
+	obj.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+}
+
+// NewVtgateSpec returns the policy for all vtgates in a cluster, including
+// those in named pools.
+//
+// Clients may reach the MySQL and gRPC ports. Other Vitess components
+// (e.g. vtadmin) and the operator may reach gRPC and the web port, and
+// metrics scrapers may reach the web port.
+func NewVtgateSpec(labels map[string]string, clusterName string, np *planetscalev2.VitessNetworkPolicySpec) *Spec {
+	spec := &Spec{
+		Labels: labels,
+		PodSelector: metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				planetscalev2.ClusterLabel: clusterName,
+			},
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      planetscalev2.ComponentLabel,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   []string{planetscalev2.VtgateComponentName, planetscalev2.VtgatePoolComponentName},
+				},
+			},
+		},
+	}
+	spec.Ingress = append(spec.Ingress, clientRule(np, planetscalev2.DefaultMysqlPortName, planetscalev2.DefaultGrpcPortName))
+	spec.Ingress = appendRule(spec.Ingress, internalPeers(clusterName, np), planetscalev2.DefaultGrpcPortName, planetscalev2.DefaultWebPortName)
+	spec.Ingress = appendRule(spec.Ingress, np.MetricsIngress, planetscalev2.DefaultWebPortName)
+	spec.Ingress = appendRule(spec.Ingress, np.ExtraIngress)
+	return spec
+}
+
+// NewVtadminSpec returns the policy for vtadmin. Clients may reach both the
+// web UI and the API it calls from the browser, and metrics scrapers may
+// reach the web port.
+func NewVtadminSpec(labels map[string]string, clusterName string, np *planetscalev2.VitessNetworkPolicySpec) *Spec {
+	spec := &Spec{
+		Labels:      labels,
+		PodSelector: componentSelector(clusterName, planetscalev2.VtadminComponentName),
+	}
+	spec.Ingress = append(spec.Ingress, clientRule(np, planetscalev2.DefaultWebPortName, planetscalev2.DefaultAPIPortName))
+	spec.Ingress = appendRule(spec.Ingress, np.MetricsIngress, planetscalev2.DefaultWebPortName)
+	spec.Ingress = appendRule(spec.Ingress, np.ExtraIngress)
+	return spec
+}
+
+// NewVtctldSpec returns the policy for vtctld. Only other Vitess components
+// (e.g. vtadmin and backup schedule Jobs) and the operator may reach it,
+// besides metrics scrapers on the web port.
+func NewVtctldSpec(labels map[string]string, clusterName string, np *planetscalev2.VitessNetworkPolicySpec) *Spec {
+	spec := &Spec{
+		Labels:      labels,
+		PodSelector: componentSelector(clusterName, planetscalev2.VtctldComponentName),
+	}
+	spec.Ingress = appendRule(spec.Ingress, internalPeers(clusterName, np), planetscalev2.DefaultWebPortName, planetscalev2.DefaultGrpcPortName)
+	spec.Ingress = appendRule(spec.Ingress, np.MetricsIngress, planetscalev2.DefaultWebPortName)
+	spec.Ingress = appendRule(spec.Ingress, np.ExtraIngress)
+	return spec
+}
+
+// NewVtorcSpec returns the policy for vtorc, which only serves its web port.
+// Other Vitess components, the operator and metrics scrapers may reach it.
+func NewVtorcSpec(labels map[string]string, clusterName string, np *planetscalev2.VitessNetworkPolicySpec) *Spec {
+	spec := &Spec{
+		Labels:      labels,
+		PodSelector: componentSelector(clusterName, planetscalev2.VtorcComponentName),
+	}
+	spec.Ingress = appendRule(spec.Ingress, internalPeers(clusterName, np), planetscalev2.DefaultWebPortName)
+	spec.Ingress = appendRule(spec.Ingress, np.MetricsIngress, planetscalev2.DefaultWebPortName)
+	spec.Ingress = appendRule(spec.Ingress, np.ExtraIngress)
+	return spec
+}
+
+// NewEtcdSpec returns the policy for the etcd members of all lockservers the
+// cluster manages. Vitess components and the operator may reach the client
+// port. Only other etcd members may reach the peer port.
+func NewEtcdSpec(labels map[string]string, clusterName string, np *planetscalev2.VitessNetworkPolicySpec) *Spec {
+	selector := componentSelector(clusterName, planetscalev2.EtcdComponentName)
+	spec := &Spec{
+		Labels:      labels,
+		PodSelector: selector,
+	}
+	spec.Ingress = appendRule(spec.Ingress, internalPeers(clusterName, np), etcd.ClientPortName)
+	spec.Ingress = appendRule(spec.Ingress, []networkingv1.NetworkPolicyPeer{{PodSelector: &selector}}, etcd.PeerPortName)
+	spec.Ingress = appendRule(spec.Ingress, np.ExtraIngress)
+	return spec
+}
+
+// NewConsulSpec returns the policy for the Consul servers of all lockservers
+// the cluster manages. Vitess components and the operator may reach the HTTP
+// API. Only other Consul servers may reach the server RPC and gossip ports.
+func NewConsulSpec(labels map[string]string, clusterName string, np *planetscalev2.VitessNetworkPolicySpec) *Spec {
+	selector := componentSelector(clusterName, planetscalev2.ConsulComponentName)
+	spec := &Spec{
+		Labels:      labels,
+		PodSelector: selector,
+	}
+	spec.Ingress = appendRule(spec.Ingress, internalPeers(clusterName, np), consul.ClientPortName)
+	spec.Ingress = appendRule(spec.Ingress, []networkingv1.NetworkPolicyPeer{{PodSelector: &selector}}, consul.ServerPortName, consul.SerfPortName)
+	// Gossip also uses UDP, which the Pods don't declare as a named port.
+	udp := corev1.ProtocolUDP
+	serf := intstr.FromInt32(consul.SerfPortNumber)
+	peerRule := &spec.Ingress[len(spec.Ingress)-1]
+	peerRule.Ports = append(peerRule.Ports, networkingv1.NetworkPolicyPort{Protocol: &udp, Port: &serf})
+	spec.Ingress = appendRule(spec.Ingress, np.ExtraIngress)
+	return spec
+}
+
+// NewZkSpec returns the policy for the ZooKeeper servers of all lockservers
+// the cluster manages. Vitess components and the operator may reach the
+// client port. Only other ZooKeeper servers may reach the peer and leader
+// election ports.
+func NewZkSpec(labels map[string]string, clusterName string, np *planetscalev2.VitessNetworkPolicySpec) *Spec {
+	selector := componentSelector(clusterName, planetscalev2.ZkComponentName)
+	spec := &Spec{
+		Labels:      labels,
+		PodSelector: selector,
+	}
+	spec.Ingress = appendRule(spec.Ingress, internalPeers(clusterName, np), zookeeper.ClientPortName)
+	spec.Ingress = appendRule(spec.Ingress, []networkingv1.NetworkPolicyPeer{{PodSelector: &selector}}, zookeeper.PeerPortName, zookeeper.ElectionPortName)
+	spec.Ingress = appendRule(spec.Ingress, np.ExtraIngress)
+	return spec
+}
+
+// NewVttabletSpec returns the policy for the tablets of one shard.
+//
+// The gRPC port must be reachable from everything that talks to tablets:
+// vtgate for queries, vtctld and vtorc for tablet management, the operator,
+// and tablets in other shards and keyspaces for VReplication. MySQL is only
+// reachable from the tablets and vtbackup Pods of the same shard, for
+// replication and restores. The web port serves status pages and metrics, so
+// metrics scrapers may reach it too.
+func NewVttabletSpec(labels map[string]string, clusterName, keyspaceName, shardSafeName string, np *planetscalev2.VitessNetworkPolicySpec) *Spec {
+	spec := &Spec{
+		Labels: labels,
+		PodSelector: metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				planetscalev2.ComponentLabel: planetscalev2.VttabletComponentName,
+				planetscalev2.ClusterLabel:   clusterName,
+				planetscalev2.KeyspaceLabel:  keyspaceName,
+				planetscalev2.ShardLabel:     shardSafeName,
+			},
+		},
+	}
+
+	grpcPeers := []networkingv1.NetworkPolicyPeer{
+		componentsPeer(clusterName,
+			planetscalev2.VtgateComponentName,
+			planetscalev2.VtgatePoolComponentName,
+			planetscalev2.VtctldComponentName,
+			planetscalev2.VtorcComponentName,
+			planetscalev2.VttabletComponentName,
+		),
+	}
+	grpcPeers = append(grpcPeers, np.OperatorIngress...)
+	spec.Ingress = appendRule(spec.Ingress, grpcPeers, planetscalev2.DefaultGrpcPortName, planetscalev2.DefaultWebPortName)
+
+	shardPeer := networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				planetscalev2.ClusterLabel:  clusterName,
+				planetscalev2.KeyspaceLabel: keyspaceName,
+				planetscalev2.ShardLabel:    shardSafeName,
+			},
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      planetscalev2.ComponentLabel,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   []string{planetscalev2.VttabletComponentName, planetscalev2.VtbackupComponentName},
+				},
+			},
+		},
+	}
+	spec.Ingress = appendRule(spec.Ingress, []networkingv1.NetworkPolicyPeer{shardPeer}, planetscalev2.DefaultMysqlPortName)
+	spec.Ingress = appendRule(spec.Ingress, np.MetricsIngress, planetscalev2.DefaultWebPortName)
+	spec.Ingress = appendRule(spec.Ingress, np.ExtraIngress)
+	return spec
+}
+
+// componentSelector selects the Pods of one component in a cluster.
+func componentSelector(clusterName, componentName string) metav1.LabelSelector {
+	return metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			planetscalev2.ComponentLabel: componentName,
+			planetscalev2.ClusterLabel:   clusterName,
+		},
+	}
+}
+
+// componentsPeer matches the Pods of any of the given components in a cluster.
+func componentsPeer(clusterName string, componentNames ...string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				planetscalev2.ClusterLabel: clusterName,
+			},
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      planetscalev2.ComponentLabel,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   componentNames,
+				},
+			},
+		},
+	}
+}
+
+// internalPeers matches every Pod in the cluster, plus the operator.
+func internalPeers(clusterName string, np *planetscalev2.VitessNetworkPolicySpec) []networkingv1.NetworkPolicyPeer {
+	peers := []networkingv1.NetworkPolicyPeer{
+		{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					planetscalev2.ClusterLabel: clusterName,
+				},
+			},
+		},
+	}
+	return append(peers, np.OperatorIngress...)
+}
+
+// clientRule allows clients to reach the given ports. If no client sources
+// are listed, any source may reach them.
+func clientRule(np *planetscalev2.VitessNetworkPolicySpec, portNames ...string) networkingv1.NetworkPolicyIngressRule {
+	return networkingv1.NetworkPolicyIngressRule{
+		From:  np.ClientIngress,
+		Ports: ports(portNames...),
+	}
+}
+
+// appendRule appends a rule that allows the given peers to reach the given
+// ports, or all ports if none are given. An empty list of peers would allow
+// any source, so in that case no rule is added.
+func appendRule(rules []networkingv1.NetworkPolicyIngressRule, peers []networkingv1.NetworkPolicyPeer, portNames ...string) []networkingv1.NetworkPolicyIngressRule {
+	if len(peers) == 0 {
+		return rules
+	}
+	return append(rules, networkingv1.NetworkPolicyIngressRule{
+		From:  peers,
+		Ports: ports(portNames...),
+	})
+}
+
+func ports(portNames ...string) []networkingv1.NetworkPolicyPort {
+	var result []networkingv1.NetworkPolicyPort
+	for _, name := range portNames {
+		protocol := corev1.ProtocolTCP
+		port := intstr.FromString(name)
+		result = append(result, networkingv1.NetworkPolicyPort{
+			Protocol: &protocol,
+			Port:     &port,
+		})
+	}
+	return result
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apilabels "k8s.io/apimachinery/pkg/labels"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+func TestVtgateSpecClientIngress(t *testing.T) {
+	np := &planetscalev2.VitessNetworkPolicySpec{}
+
+	// Without an explicit list, any client may reach vtgate, but only on the
+	// client ports.
+	spec := NewVtgateSpec(nil, "example", np)
+	if len(spec.Ingress) != 2 {
+		t.Fatalf("len(Ingress) = %v; want 2", len(spec.Ingress))
+	}
+	if from := spec.Ingress[0].From; from != nil {
+		t.Errorf("client rule From = %v; want any source", from)
+	}
+	if got := len(spec.Ingress[0].Ports); got != 2 {
+		t.Errorf("len(client rule Ports) = %v; want 2", got)
+	}
+
+	app := networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "frontend"}},
+	}
+	np.ClientIngress = []networkingv1.NetworkPolicyPeer{app}
+	np.ExtraIngress = []networkingv1.NetworkPolicyPeer{{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "monitoring"}},
+	}}
+	spec = NewVtgateSpec(nil, "example", np)
+	if len(spec.Ingress) != 3 {
+		t.Fatalf("len(Ingress) = %v; want 3", len(spec.Ingress))
+	}
+	if from := spec.Ingress[0].From; len(from) != 1 || from[0].PodSelector.MatchLabels["app"] != "frontend" {
+		t.Errorf("client rule From = %v; want %v", from, app)
+	}
+	if ports := spec.Ingress[2].Ports; ports != nil {
+		t.Errorf("extra rule Ports = %v; want all ports", ports)
+	}
+}
+
+func TestVttabletSpecMysqlIngress(t *testing.T) {
+	np := &planetscalev2.VitessNetworkPolicySpec{}
+	planetscalev2.DefaultVitessNetworkPolicies(np)
+
+	spec := NewVttabletSpec(nil, "example", "commerce", "x-80", np)
+	var mysqlRule *networkingv1.NetworkPolicyIngressRule
+	for i := range spec.Ingress {
+		for _, port := range spec.Ingress[i].Ports {
+			if port.Port.StrVal == planetscalev2.DefaultMysqlPortName {
+				mysqlRule = &spec.Ingress[i]
+			}
+		}
+	}
+	if mysqlRule == nil {
+		t.Fatalf("no rule allows the MySQL port: %v", spec.Ingress)
+	}
+	if len(mysqlRule.From) != 1 {
+		t.Fatalf("MySQL rule From = %v; want only the shard's own Pods", mysqlRule.From)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(mysqlRule.From[0].PodSelector)
+	if err != nil {
+		t.Fatalf("LabelSelectorAsSelector() error: %v", err)
+	}
+	shardLabels := map[string]string{
+		planetscalev2.ClusterLabel:  "example",
+		planetscalev2.KeyspaceLabel: "commerce",
+		planetscalev2.ShardLabel:    "x-80",
+	}
+
+	for component, want := range map[string]bool{
+		planetscalev2.VttabletComponentName: true,
+		planetscalev2.VtbackupComponentName: true,
+		planetscalev2.VtgateComponentName:   false,
+		planetscalev2.VtctldComponentName:   false,
+	} {
+		podLabels := map[string]string{planetscalev2.ComponentLabel: component}
+		for k, v := range shardLabels {
+			podLabels[k] = v
+		}
+		if got := selector.Matches(apilabels.Set(podLabels)); got != want {
+			t.Errorf("MySQL rule allows %v = %v; want %v", component, got, want)
+		}
+	}
+
+	// Tablets in another shard can't reach MySQL.
+	otherShard := map[string]string{
+		planetscalev2.ComponentLabel: planetscalev2.VttabletComponentName,
+		planetscalev2.ClusterLabel:   "example",
+		planetscalev2.KeyspaceLabel:  "commerce",
+		planetscalev2.ShardLabel:     "80-x",
+	}
+	if selector.Matches(apilabels.Set(otherShard)) {
+		t.Errorf("MySQL rule allows tablets from another shard")
+	}
+}
+
+func TestMetricsIngress(t *testing.T) {
+	np := &planetscalev2.VitessNetworkPolicySpec{
+		MetricsIngress: []networkingv1.NetworkPolicyPeer{{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "monitoring"}},
+		}},
+	}
+	planetscalev2.DefaultVitessNetworkPolicies(np)
+
+	for component, spec := range map[string]*Spec{
+		planetscalev2.VtgateComponentName:   NewVtgateSpec(nil, "example", np),
+		planetscalev2.VtadminComponentName:  NewVtadminSpec(nil, "example", np),
+		planetscalev2.VtctldComponentName:   NewVtctldSpec(nil, "example", np),
+		planetscalev2.VtorcComponentName:    NewVtorcSpec(nil, "example", np),
+		planetscalev2.VttabletComponentName: NewVttabletSpec(nil, "example", "commerce", "x-80", np),
+	} {
+		var metricsRule *networkingv1.NetworkPolicyIngressRule
+		for i := range spec.Ingress {
+			from := spec.Ingress[i].From
+			if len(from) == 1 && from[0].NamespaceSelector != nil && from[0].NamespaceSelector.MatchLabels["name"] == "monitoring" {
+				metricsRule = &spec.Ingress[i]
+			}
+		}
+		if metricsRule == nil {
+			t.Errorf("%v: no rule allows metricsIngress: %v", component, spec.Ingress)
+			continue
+		}
+		if ports := metricsRule.Ports; len(ports) != 1 || ports[0].Port.StrVal != planetscalev2.DefaultWebPortName {
+			t.Errorf("%v: metrics rule Ports = %v; want only the web port", component, ports)
+		}
+	}
+}
+
+func TestLockserverPeerIngress(t *testing.T) {
+	np := &planetscalev2.VitessNetworkPolicySpec{}
+	planetscalev2.DefaultVitessNetworkPolicies(np)
+
+	for component, spec := range map[string]*Spec{
+		planetscalev2.EtcdComponentName:   NewEtcdSpec(nil, "example", np),
+		planetscalev2.ConsulComponentName: NewConsulSpec(nil, "example", np),
+		planetscalev2.ZkComponentName:     NewZkSpec(nil, "example", np),
+	} {
+		if got := spec.PodSelector.MatchLabels[planetscalev2.ComponentLabel]; got != component {
+			t.Errorf("%v: PodSelector component = %q; want %q", component, got, component)
+		}
+		if len(spec.Ingress) != 2 {
+			t.Errorf("%v: len(Ingress) = %v; want 2", component, len(spec.Ingress))
+			continue
+		}
+		// Everything in the cluster may reach only the client port.
+		clientRule := spec.Ingress[0]
+		if got := clientRule.From[0].PodSelector.MatchLabels; len(got) != 1 || got[planetscalev2.ClusterLabel] != "example" {
+			t.Errorf("%v: client rule From = %v; want the whole cluster", component, clientRule.From)
+		}
+		if len(clientRule.Ports) != 1 {
+			t.Errorf("%v: client rule Ports = %v; want only the client port", component, clientRule.Ports)
+		}
+		// Only the servers themselves may reach the peer ports.
+		peerRule := spec.Ingress[1]
+		if len(peerRule.From) != 1 || peerRule.From[0].PodSelector.MatchLabels[planetscalev2.ComponentLabel] != component {
+			t.Errorf("%v: peer rule From = %v; want only %v Pods", component, peerRule.From, component)
+		}
+		if len(peerRule.Ports) < 2 {
+			t.Errorf("%v: peer rule Ports = %v; want the peer ports", component, peerRule.Ports)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package pdb builds PodDisruptionBudgets for Vitess components.
+
+A PDB tells `kubectl drain` and the cluster autoscaler how many Pods of a
+component they may evict at the same time.
+*/
+package pdb
+
+import (
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+)
+
+// Spec specifies the parameters of a PDB.
+type Spec struct {
+	// Labels are the labels to put on the PDB itself.
+	Labels map[string]string
+	// Selector selects the Pods covered by the PDB.
+	Selector *metav1.LabelSelector
+	// MaxUnavailable is how many of those Pods may be evicted at once.
+	MaxUnavailable intstr.IntOrString
+}
+
+// NewSpec returns a Spec that covers the Pods with the given labels, as
+// configured by the user-specified PodDisruptionBudgetSpec.
+func NewSpec(labels map[string]string, pdbSpec *planetscalev2.PodDisruptionBudgetSpec) *Spec {
+	return &Spec{
+		Labels:         labels,
+		Selector:       &metav1.LabelSelector{MatchLabels: labels},
+		MaxUnavailable: *pdbSpec.MaxUnavailable,
+	}
+}
+
+// Enabled returns whether a PDB should be created for the given spec.
+func Enabled(pdbSpec *planetscalev2.PodDisruptionBudgetSpec) bool {
+	return pdbSpec != nil && pdbSpec.Create != nil && *pdbSpec.Create
+}
+
+// NewPDB creates a new PDB.
+func NewPDB(key client.ObjectKey, spec *Spec) *policyv1.PodDisruptionBudget {
+	obj := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+		},
+	}
+	UpdatePDB(obj, spec)
+	return obj
+}
+
+// UpdatePDB updates the mutable parts of a PDB.
+func UpdatePDB(obj *policyv1.PodDisruptionBudget, spec *Spec) {
+	// Update labels, but ignore existing ones we don't set.
+	update.Labels(&obj.Labels, spec.Labels)
+
+	obj.Spec.Selector = spec.Selector
+	maxUnavailable := spec.MaxUnavailable
+	obj.Spec.MaxUnavailable = &maxUnavailable
+	obj.Spec.MinAvailable = nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serverset
+
+import (
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+)
+
+// PDBName returns the name of the PDB for a lockserver.
+func (b *Backend) PDBName(lockserverName string) string {
+	return b.PDBNamePrefix + lockserverName
+}
+
+// NewPDB creates a new PDB.
+func NewPDB(key client.ObjectKey, labels map[string]string, replicas int) *policyv1.PodDisruptionBudget {
+	// This tells `kubectl drain` not to delete one of the servers unless the
+	// number of remaining servers will still be at least QuorumSize.
+	minAvailable := intstr.FromInt(QuorumSize(replicas))
+
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Labels:    labels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			MinAvailable: &minAvailable,
+		},
+	}
+}
+
+// UpdatePDBInPlace updates an existing PDB in-place.
+func UpdatePDBInPlace(obj *policyv1.PodDisruptionBudget, labels map[string]string, replicas int) {
+	// Update labels, but ignore existing ones we don't set.
+	update.Labels(&obj.Labels, labels)
+
+	// Keep the quorum size in sync with the number of servers.
+	minAvailable := intstr.FromInt(QuorumSize(replicas))
+	obj.Spec.MinAvailable = &minAvailable
+}
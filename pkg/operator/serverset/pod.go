@@ -0,0 +1,239 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serverset
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/desiredstatehash"
+	"planetscale.dev/vitess-operator/pkg/operator/k8s"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+)
+
+const dataVolumeName = "data"
+
+// NewPod creates a new server Pod.
+func (b *Backend) NewPod(key client.ObjectKey, spec *Spec) *corev1.Pod {
+	obj := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+		},
+	}
+
+	b.UpdatePod(obj, spec)
+	return obj
+}
+
+// UpdatePodInPlace updates only the parts of a server Pod that can be changed
+// immediately by an in-place update.
+func UpdatePodInPlace(obj *corev1.Pod, spec *Spec) {
+	// Update labels and annotations, but ignore existing ones we don't set.
+	update.Labels(&obj.Labels, spec.Labels)
+}
+
+// UpdatePod updates all parts of a server Pod to match the desired state,
+// including parts that are immutable.
+// If anything actually changes, the Pod must be deleted and recreated as
+// part of a rolling update in order to converge to the desired state.
+func (b *Backend) UpdatePod(obj *corev1.Pod, spec *Spec) {
+	// Update our own labels, but ignore existing ones we don't set.
+	update.Labels(&obj.Labels, spec.Labels)
+
+	// Update desired user labels.
+	update.Labels(&obj.Labels, spec.ExtraLabels)
+	// Update desired annotations.
+	update.Annotations(&obj.Annotations, spec.Annotations)
+
+	var env []corev1.EnvVar
+	if b.Env != nil {
+		env = b.Env(spec)
+	}
+	// Apply user-provided environment variable overrides.
+	update.Env(&env, spec.ExtraEnv)
+
+	var args []string
+	if b.Args != nil {
+		args = b.Args(spec)
+	}
+
+	ports := make([]corev1.ContainerPort, 0, 1+len(b.PeerPorts))
+	for _, port := range append([]Port{b.ClientPort}, b.PeerPorts...) {
+		ports = append(ports, corev1.ContainerPort{
+			Name:          port.Name,
+			Protocol:      corev1.ProtocolTCP,
+			ContainerPort: port.Number,
+		})
+	}
+
+	serverContainer := &corev1.Container{
+		Name:            b.ContainerName,
+		Image:           spec.Image,
+		ImagePullPolicy: spec.ImagePullPolicy,
+		Args:            args,
+		Ports:           ports,
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				Exec: &corev1.ExecAction{
+					Command: b.ReadinessCommand,
+				},
+			},
+			FailureThreshold:    3,
+			InitialDelaySeconds: 1,
+			PeriodSeconds:       5,
+			SuccessThreshold:    1,
+			TimeoutSeconds:      5,
+		},
+		LivenessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{
+					Port: intstr.FromString(b.ClientPort.Name),
+				},
+			},
+			FailureThreshold:    30,
+			InitialDelaySeconds: 300,
+			PeriodSeconds:       5,
+			SuccessThreshold:    1,
+			TimeoutSeconds:      5,
+		},
+		Env: env,
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      dataVolumeName,
+				MountPath: b.DataVolumeMountPath,
+			},
+		},
+	}
+	// Make a copy of Resources since it contains pointers.
+	update.ResourceRequirements(&serverContainer.Resources, &spec.Resources)
+
+	update.Volumes(&obj.Spec.Volumes, []corev1.Volume{
+		{
+			Name: dataVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: spec.DataVolumePVCName,
+				},
+			},
+		},
+	})
+
+	obj.Spec.Hostname = PodName(spec.LockserverName, spec.Index)
+	obj.Spec.Subdomain = PeerServiceName(spec.LockserverName)
+	obj.Spec.ImagePullSecrets = spec.ImagePullSecrets
+
+	// In both the case of the user injecting their own affinity and the default, we
+	// simply override the pod's existing affinity configuration.
+	if spec.Affinity != nil {
+		obj.Spec.Affinity = spec.Affinity
+	} else {
+		obj.Spec.Affinity = b.defaultAffinity(spec)
+	}
+
+	update.Tolerations(&obj.Spec.Tolerations, spec.Tolerations)
+
+	// Use the same PriorityClass as other Vitess components,
+	// or a custom value if overridden in the operator command line.
+	if planetscalev2.DefaultVitessPriorityClass != "" {
+		obj.Spec.PriorityClassName = planetscalev2.DefaultVitessPriorityClass
+	}
+
+	containers := []corev1.Container{
+		*serverContainer,
+	}
+
+	// Record hashes of desired label and annotation keys to force the Pod
+	// to be recreated if a key disappears from the desired list.
+	desiredStateHash := desiredstatehash.NewBuilder()
+	desiredStateHash.AddStringMapKeys("labels-keys", spec.ExtraLabels)
+	desiredStateHash.AddStringMapKeys("annotations-keys", spec.Annotations)
+
+	// Record a hash of desired containers to force the Pod to be recreated if
+	// something is removed from our desired state that we otherwise might
+	// mistake for an item added by the API server and leave behind.
+	desiredStateHash.AddContainersUpdates("containers", containers)
+
+	// Record a hash of desired tolerations to force the Pod to be recreated if
+	// one disappears from the desired list.
+	desiredStateHash.AddTolerations("tolerations", spec.Tolerations)
+
+	// Add the final desired state hash annotation.
+	update.Annotations(&obj.Annotations, map[string]string{
+		desiredstatehash.Annotation: desiredStateHash.String(),
+	})
+
+	// Update the containers we care about in the Pod template,
+	// ignoring other containers that may have been injected.
+	update.PodContainers(&obj.Spec.Containers, containers)
+}
+
+// defaultAffinity returns the affinity we use if the user didn't specify any.
+func (b *Backend) defaultAffinity(spec *Spec) *corev1.Affinity {
+	selector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			b.LockserverLabel: spec.LockserverName,
+		},
+	}
+	affinity := &corev1.Affinity{
+		// Try to spread the servers across Nodes if possible.
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: 2,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: selector,
+						TopologyKey:   k8s.HostnameLabel,
+					},
+				},
+			},
+		},
+	}
+	if spec.Zone != "" {
+		// Limit to a specific zone.
+		affinity.NodeAffinity = &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{
+								Key:      k8s.ZoneFailureDomainLabel,
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{spec.Zone},
+							},
+						},
+					},
+				},
+			},
+		}
+	} else {
+		// If we're not limited to one zone, try to spread across zones.
+		paa := &affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+		*paa = append(*paa, corev1.WeightedPodAffinityTerm{
+			// Weight zone spreading as less important than node spreading.
+			Weight: 1,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				LabelSelector: selector,
+				TopologyKey:   k8s.ZoneFailureDomainLabel,
+			},
+		})
+	}
+	return affinity
+}
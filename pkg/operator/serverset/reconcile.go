@@ -0,0 +1,280 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serverset
+
+import (
+	"context"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubectl/pkg/util/podutils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/k8s"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+	"planetscale.dev/vitess-operator/pkg/operator/results"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+)
+
+// Lockserver is the desired state of a lockserver object, like a
+// ConsulLockserver or a ZkLockserver, with defaults filled in.
+type Lockserver struct {
+	// Object is the lockserver object, which owns everything we create.
+	Object client.Object
+	// Server has the settings shared by every server. Reconcile fills in
+	// LockserverName from Object, and the per-server fields: Labels, Index
+	// and DataVolumePVCName.
+	Server Spec
+	// CreatePDB sets whether to create a PodDisruptionBudget.
+	CreatePDB bool
+	// ClientService customizes the client Service.
+	ClientService *planetscalev2.ServiceOverrides
+}
+
+// Status is the observed state of a lockserver.
+type Status struct {
+	// Available is whether enough servers are Ready to reach quorum.
+	Available corev1.ConditionStatus
+	// ClientServiceName is the name of the Service for client connections.
+	ClientServiceName string
+}
+
+// Reconcile creates or updates the Services, server Pods, PVCs and PDB of a
+// lockserver.
+func Reconcile(ctx context.Context, r *reconciler.Reconciler, b *Backend, ls *Lockserver) (Status, error) {
+	resultBuilder := &results.Builder{}
+	status := Status{}
+
+	if err := b.reconcileServices(ctx, r, ls, &status); err != nil {
+		resultBuilder.Error(err)
+	}
+	if err := b.reconcileServers(ctx, r, ls, &status); err != nil {
+		resultBuilder.Error(err)
+	}
+	if err := b.reconcilePodDisruptionBudget(ctx, r, ls); err != nil {
+		resultBuilder.Error(err)
+	}
+
+	_, err := resultBuilder.Result()
+	return status, err
+}
+
+func (b *Backend) reconcileServices(ctx context.Context, r *reconciler.Reconciler, ls *Lockserver, status *Status) error {
+	resultBuilder := &results.Builder{}
+	lockserverName := ls.Object.GetName()
+
+	labels := map[string]string{
+		b.LockserverLabel: lockserverName,
+	}
+	// The reconciler compares labels, so we only use the extended labels
+	// inside the Strategy for creation/update.
+	extendedLabels := map[string]string{
+		b.LockserverLabel:            lockserverName,
+		planetscalev2.ComponentLabel: b.ComponentName,
+	}
+	if clusterName, hasClusterLabel := ls.Object.GetLabels()[planetscalev2.ClusterLabel]; hasClusterLabel {
+		extendedLabels[planetscalev2.ClusterLabel] = clusterName
+	}
+
+	// Reconcile the client Service.
+	clientSvcKey := client.ObjectKey{
+		Namespace: ls.Object.GetNamespace(),
+		Name:      ClientServiceName(lockserverName),
+	}
+	err := r.ReconcileObject(ctx, ls.Object, clientSvcKey, labels, true, reconciler.Strategy{
+		Kind: &corev1.Service{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			svc := b.NewClientService(key, extendedLabels)
+			update.ServiceOverrides(svc, ls.ClientService)
+			return svc
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			svc := obj.(*corev1.Service)
+			b.UpdateClientService(svc, extendedLabels)
+			update.InPlaceServiceOverrides(svc, ls.ClientService)
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+	status.ClientServiceName = clientSvcKey.Name
+
+	// Reconcile the peer Service.
+	peerSvcKey := client.ObjectKey{
+		Namespace: ls.Object.GetNamespace(),
+		Name:      PeerServiceName(lockserverName),
+	}
+	err = r.ReconcileObject(ctx, ls.Object, peerSvcKey, labels, true, reconciler.Strategy{
+		Kind: &corev1.Service{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return b.NewPeerService(key, extendedLabels)
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			svc := obj.(*corev1.Service)
+			b.UpdatePeerService(svc, extendedLabels)
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	_, err = resultBuilder.Result()
+	return err
+}
+
+func (b *Backend) reconcileServers(ctx context.Context, r *reconciler.Reconciler, ls *Lockserver, status *Status) error {
+	resultBuilder := &results.Builder{}
+	lockserverName := ls.Object.GetName()
+
+	labels := map[string]string{
+		b.LockserverLabel: lockserverName,
+	}
+
+	// Generate spec for each desired server.
+	servers := b.serverSpecs(ls, labels)
+
+	// Generate keys (object names) for all desired servers.
+	// Keep a map back from generated names to the server specs.
+	keys := make([]client.ObjectKey, 0, len(servers))
+	serverMap := make(map[client.ObjectKey]*Spec, len(servers))
+	for _, server := range servers {
+		// We use the same name for the Pod and the data volume PVC.
+		podName := PodName(lockserverName, server.Index)
+		server.DataVolumePVCName = podName
+
+		key := client.ObjectKey{Namespace: ls.Object.GetNamespace(), Name: podName}
+		keys = append(keys, key)
+		serverMap[key] = server
+	}
+
+	// Reconcile server PVCs. Note that we use the same keys as the corresponding Pods.
+	err := r.ReconcileObjectSet(ctx, ls.Object, keys, labels, reconciler.Strategy{
+		Kind: &corev1.PersistentVolumeClaim{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return NewPVC(key, serverMap[key])
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			curObj := obj.(*corev1.PersistentVolumeClaim)
+			UpdatePVCInPlace(curObj, serverMap[key])
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	// Reconcile server Pods.
+	numPodsReady := 0
+	err = r.ReconcileObjectSet(ctx, ls.Object, keys, labels, reconciler.Strategy{
+		Kind: &corev1.Pod{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return b.NewPod(key, serverMap[key])
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			newObj := obj.(*corev1.Pod)
+			UpdatePodInPlace(newObj, serverMap[key])
+		},
+		UpdateRollingRecreate: func(key client.ObjectKey, obj runtime.Object) {
+			newObj := obj.(*corev1.Pod)
+			b.UpdatePod(newObj, serverMap[key])
+		},
+		Status: func(key client.ObjectKey, obj runtime.Object) {
+			curObj := obj.(*corev1.Pod)
+			if podutils.IsPodReady(curObj) {
+				numPodsReady++
+			}
+		},
+	})
+	if err != nil {
+		resultBuilder.Error(err)
+	}
+
+	// We should be available for queries if the number of Ready servers is
+	// enough to reach quorum.
+	status.Available = k8s.ConditionStatus(numPodsReady >= QuorumSize(len(servers)))
+
+	_, err = resultBuilder.Result()
+	return err
+}
+
+// serverSpecs creates a list of Specs for desired servers.
+func (b *Backend) serverSpecs(ls *Lockserver, parentLabels map[string]string) []*Spec {
+	replicas := ls.Server.Replicas
+
+	servers := make([]*Spec, 0, replicas)
+	for i := 1; i <= replicas; i++ {
+		// Set server-specific labels and copy parent labels.
+		labels := map[string]string{
+			b.IndexLabel: strconv.FormatInt(int64(i), 10),
+		}
+		for k, v := range parentLabels {
+			labels[k] = v
+		}
+		// Also add some extra labels used by other components to identify
+		// our objects, even though this controller doesn't use those labels
+		// in its selector.
+		labels[planetscalev2.ComponentLabel] = b.ComponentName
+		// Only add the cluster label if the lockserver object has it.
+		if clusterName, hasClusterLabel := ls.Object.GetLabels()[planetscalev2.ClusterLabel]; hasClusterLabel {
+			labels[planetscalev2.ClusterLabel] = clusterName
+		}
+
+		server := ls.Server
+		server.LockserverName = ls.Object.GetName()
+		server.Labels = labels
+		server.Index = i
+		servers = append(servers, &server)
+	}
+	return servers
+}
+
+func (b *Backend) reconcilePodDisruptionBudget(ctx context.Context, r *reconciler.Reconciler, ls *Lockserver) error {
+	if !ls.CreatePDB {
+		return nil
+	}
+
+	lockserverName := ls.Object.GetName()
+	replicas := ls.Server.Replicas
+
+	labels := map[string]string{
+		b.LockserverLabel: lockserverName,
+	}
+
+	// Reconcile the PDB.
+	// This tells `kubectl drain` not to delete a Pod if it would take the lockserver below quorum.
+	key := client.ObjectKey{
+		Namespace: ls.Object.GetNamespace(),
+		Name:      b.PDBName(lockserverName),
+	}
+	return r.ReconcileObject(ctx, ls.Object, key, labels, true, reconciler.Strategy{
+		Kind: &policyv1.PodDisruptionBudget{},
+
+		New: func(key client.ObjectKey) runtime.Object {
+			return NewPDB(key, labels, replicas)
+		},
+		UpdateInPlace: func(key client.ObjectKey, obj runtime.Object) {
+			curObj := obj.(*policyv1.PodDisruptionBudget)
+			UpdatePDBInPlace(curObj, labels, replicas)
+		},
+	})
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serverset
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/reconciler"
+)
+
+var testBackend = &Backend{
+	ComponentName:       "test",
+	LockserverLabel:     "test.planetscale.com/lockserver",
+	IndexLabel:          "test.planetscale.com/index",
+	PDBNamePrefix:       "test-lockserver-",
+	ContainerName:       "server",
+	ClientPort:          Port{Name: "client", Number: 1000},
+	PeerPorts:           []Port{{Name: "peer", Number: 1001}, {Name: "election", Number: 1002}},
+	DataVolumeMountPath: "/data",
+	ReadinessCommand:    []string{"ready"},
+	Args: func(spec *Spec) []string {
+		return []string{"--replicas", strconv.Itoa(spec.Replicas)}
+	},
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := t.Context()
+	scheme := runtime.NewScheme()
+	require.NoError(t, planetscalev2.SchemeBuilder.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, policyv1.AddToScheme(scheme))
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := reconciler.New(c, scheme, record.NewFakeRecorder(100))
+
+	owner := &planetscalev2.ZkLockserver{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "example-zk",
+			UID:       "uid",
+			Labels:    map[string]string{planetscalev2.ClusterLabel: "example"},
+		},
+	}
+	ls := &Lockserver{
+		Object: owner,
+		Server: Spec{
+			Image: "server:latest",
+			DataVolumePVCSpec: &corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+			},
+			Replicas: 3,
+		},
+		CreatePDB: true,
+	}
+
+	status, err := Reconcile(ctx, r, testBackend, ls)
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ConditionFalse, status.Available)
+	assert.Equal(t, "example-zk-client", status.ClientServiceName)
+
+	pods := &corev1.PodList{}
+	require.NoError(t, c.List(ctx, pods))
+	require.Len(t, pods.Items, 3)
+	for _, pod := range pods.Items {
+		assert.Equal(t, "example-zk-peer", pod.Spec.Subdomain)
+		assert.Equal(t, pod.Name, pod.Spec.Hostname)
+		assert.Equal(t, pod.Name, pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+		assert.Equal(t, "example", pod.Labels[planetscalev2.ClusterLabel])
+		assert.Equal(t, "test", pod.Labels[planetscalev2.ComponentLabel])
+		assert.Equal(t, []string{"--replicas", "3"}, pod.Spec.Containers[0].Args)
+	}
+
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	require.NoError(t, c.List(ctx, pvcs))
+	assert.Len(t, pvcs.Items, 3)
+
+	peerSvc := &corev1.Service{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: "example-zk-peer"}, peerSvc))
+	assert.Equal(t, corev1.ClusterIPNone, peerSvc.Spec.ClusterIP)
+	assert.Len(t, peerSvc.Spec.Ports, 2)
+	clientSvc := &corev1.Service{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: "example-zk-client"}, clientSvc))
+	assert.Equal(t, int32(1000), clientSvc.Spec.Ports[0].Port)
+
+	pdb := &policyv1.PodDisruptionBudget{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: "test-lockserver-example-zk"}, pdb))
+	assert.Equal(t, 2, pdb.Spec.MinAvailable.IntValue())
+
+	// Once a quorum of servers is Ready, the lockserver is available.
+	for _, name := range []string{"example-zk-1", "example-zk-2"} {
+		pod := &corev1.Pod{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: name}, pod))
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		require.NoError(t, c.Update(ctx, pod))
+	}
+	status, err = Reconcile(ctx, r, testBackend, ls)
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ConditionTrue, status.Available)
+
+	// Adding servers adds Pods and PVCs, and raises the quorum size.
+	ls.Server.Replicas = 5
+	status, err = Reconcile(ctx, r, testBackend, ls)
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ConditionFalse, status.Available)
+
+	require.NoError(t, c.List(ctx, pods))
+	assert.Len(t, pods.Items, 5)
+	require.NoError(t, c.List(ctx, pvcs))
+	assert.Len(t, pvcs.Items, 5)
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "ns", Name: "test-lockserver-example-zk"}, pdb))
+	assert.Equal(t, 3, pdb.Spec.MinAvailable.IntValue())
+}
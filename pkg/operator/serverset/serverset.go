@@ -0,0 +1,103 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package serverset deploys a lockserver as a fixed set of numbered server Pods,
+each with its own data volume PVC, plus a client Service, a headless peer
+Service and a PodDisruptionBudget.
+
+Consul and ZooKeeper both run this way. What differs between them, like ports
+and how each server is told about the others, is described by a Backend.
+*/
+package serverset
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Port is a named port of a server.
+type Port struct {
+	Name   string
+	Number int32
+}
+
+// Backend describes one kind of lockserver that runs as a server set.
+type Backend struct {
+	// ComponentName is the value of the component label on all objects.
+	ComponentName string
+	// LockserverLabel is the label that identifies which lockserver an
+	// object belongs to.
+	LockserverLabel string
+	// IndexLabel is the label used to identify the index of a server.
+	IndexLabel string
+	// PDBNamePrefix is prepended to the lockserver name to name its PDB.
+	PDBNamePrefix string
+
+	// ContainerName is the name of the server container.
+	ContainerName string
+	// ClientPort is the port that clients connect to.
+	ClientPort Port
+	// PeerPorts are the ports that servers use to talk to each other.
+	PeerPorts []Port
+	// DataVolumeMountPath is where the data volume is mounted.
+	DataVolumeMountPath string
+	// ReadinessCommand is run in the server container to check whether it
+	// can serve requests.
+	ReadinessCommand []string
+
+	// Args returns the args of the server container.
+	Args func(spec *Spec) []string
+	// Env returns the environment variables of the server container,
+	// before the user-provided overrides are applied.
+	Env func(spec *Spec) []corev1.EnvVar
+}
+
+// Spec specifies all the internal parameters needed to deploy one server.
+type Spec struct {
+	LockserverName    string
+	Image             string
+	ImagePullPolicy   corev1.PullPolicy
+	ImagePullSecrets  []corev1.LocalObjectReference
+	Resources         corev1.ResourceRequirements
+	Labels            map[string]string
+	Zone              string
+	Index             int
+	DataVolumePVCName string
+	DataVolumePVCSpec *corev1.PersistentVolumeClaimSpec
+	ExtraEnv          []corev1.EnvVar
+	Affinity          *corev1.Affinity
+	Annotations       map[string]string
+	ExtraLabels       map[string]string
+	Tolerations       []corev1.Toleration
+	// Extra holds settings that only mean something to the Backend, like
+	// extra Consul flags or extra zoo.cfg settings.
+	Extra map[string]string
+	// Replicas is the desired number of servers in the set.
+	Replicas int
+}
+
+// PodName returns the name of the Pod for a given server.
+func PodName(lockserverName string, index int) string {
+	return fmt.Sprintf("%s-%d", lockserverName, index)
+}
+
+// QuorumSize returns the number of servers that must be available for a
+// set with the given number of servers to serve requests.
+func QuorumSize(replicas int) int {
+	return replicas/2 + 1
+}
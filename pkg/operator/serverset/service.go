@@ -0,0 +1,103 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serverset
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+)
+
+// ClientServiceName returns the name of the client Service of a lockserver.
+func ClientServiceName(lockserverName string) string {
+	return lockserverName + "-client"
+}
+
+// PeerServiceName returns the name of the headless peer Service of a
+// lockserver.
+func PeerServiceName(lockserverName string) string {
+	return lockserverName + "-peer"
+}
+
+// NewClientService creates a new client Service.
+func (b *Backend) NewClientService(key client.ObjectKey, labels map[string]string) *corev1.Service {
+	// Fill in the immutable parts.
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+		},
+	}
+	// Set everything else.
+	b.UpdateClientService(svc, labels)
+	return svc
+}
+
+// UpdateClientService updates the mutable parts of the client Service.
+func (b *Backend) UpdateClientService(svc *corev1.Service, labels map[string]string) {
+	update.Labels(&svc.Labels, labels)
+
+	svc.Spec.Selector = labels
+	svc.Spec.Ports = servicePorts([]Port{b.ClientPort})
+}
+
+// NewPeerService creates a new peer Service.
+func (b *Backend) NewPeerService(key client.ObjectKey, labels map[string]string) *corev1.Service {
+	// Fill in the immutable parts.
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+		},
+	}
+
+	// Use a headless service so each server gets a DNS entry that the others
+	// can use to reach it.
+	svc.Spec.Type = corev1.ServiceTypeClusterIP
+	svc.Spec.ClusterIP = corev1.ClusterIPNone
+	// Don't wait for Pods to become Ready before creating DNS entries for them.
+	// Servers can't become Ready until they've found each other.
+	svc.Spec.PublishNotReadyAddresses = true
+
+	// Set everything else.
+	b.UpdatePeerService(svc, labels)
+	return svc
+}
+
+// UpdatePeerService updates the mutable parts of the peer Service.
+func (b *Backend) UpdatePeerService(svc *corev1.Service, labels map[string]string) {
+	update.Labels(&svc.Labels, labels)
+
+	svc.Spec.Selector = labels
+	svc.Spec.Ports = servicePorts(b.PeerPorts)
+}
+
+func servicePorts(ports []Port) []corev1.ServicePort {
+	svcPorts := make([]corev1.ServicePort, 0, len(ports))
+	for _, port := range ports {
+		svcPorts = append(svcPorts, corev1.ServicePort{
+			Name:       port.Name,
+			Protocol:   corev1.ProtocolTCP,
+			Port:       port.Number,
+			TargetPort: intstr.FromString(port.Name),
+		})
+	}
+	return svcPorts
+}
@@ -20,6 +20,10 @@ Package toposerver helps with connecting to Vitess topology for many clusters at
 It maintains at most one topology connection for each global server endpoint,
 making it more efficient to talk to topology from many different controllers
 concurrently.
+
+It also keeps track of recent failures for each endpoint. After several
+failures in a row, Open returns ErrCircuitOpen right away for a while instead of
+trying again, so an unreachable lockserver doesn't hold up every reconcile.
 */
 package toposerver
 
@@ -28,6 +32,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 
 	"vitess.io/vitess/go/vt/topo"
@@ -67,7 +72,10 @@ const (
 )
 
 // pool is the process-wide shared pool of connections.
-var pool = &connPool{conns: make(map[planetscalev2.VitessLockserverParams]*Conn)}
+var pool = &connPool{
+	conns:  make(map[connKey]*Conn),
+	health: make(map[planetscalev2.VitessLockserverParams]*lockserverHealth),
+}
 
 var log = logrus.WithField("component", "toposerver.connpool")
 
@@ -81,23 +89,50 @@ func init() {
 	}()
 }
 
+// connKey identifies a connection in the pool.
+type connKey struct {
+	params planetscalev2.VitessLockserverParams
+	// tls is set if the connection uses a client certificate.
+	tls tlsFiles
+}
+
 // Open returns a topo server connection for the given params.
+// If the params name a client TLS Secret, it's read from the given namespace.
 // If the returned error is nil, you must call Close() on the returned
 // connection when you're done using it.
-func Open(ctx context.Context, params planetscalev2.VitessLockserverParams) (*Conn, error) {
+func Open(ctx context.Context, namespace string, params planetscalev2.VitessLockserverParams) (*Conn, error) {
+	health := pool.getHealth(params)
+
 	startTime := time.Now()
 	defer func() {
-		openLatency.Observe(time.Since(startTime).Seconds())
+		elapsed := time.Since(startTime).Seconds()
+		openLatency.Observe(elapsed)
+		lockserverOpenLatency.WithLabelValues(health.name).Observe(elapsed)
 	}()
 
+	// Don't keep trying a lockserver that has been failing.
+	if err := health.allow(); err != nil {
+		return nil, err
+	}
+
 	// Hold the openMu RLock for as long as we're trying to get a connection,
-	// to prevent the connection GC from closing connections.
+	// to prevent the connection GC from closing connections or removing the
+	// client TLS files we load.
 	// Other Open attempts can happen concurrently, however.
 	pool.openMu.RLock()
 	defer pool.openMu.RUnlock()
 
+	key := connKey{params: params}
+	if params.ClientTLSSecret != "" {
+		files, err := loadClientTLS(ctx, namespace, params)
+		if err != nil {
+			return nil, err
+		}
+		key.tls = files
+	}
+
 	// Get or start a connection attempt.
-	conn := pool.get(params)
+	conn := pool.get(key, health)
 
 	// Wait for the connection attempt to finish.
 	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
@@ -107,7 +142,7 @@ func Open(ctx context.Context, params planetscalev2.VitessLockserverParams) (*Co
 
 type connPool struct {
 	// conns is the set of active connections to use.
-	conns map[planetscalev2.VitessLockserverParams]*Conn
+	conns map[connKey]*Conn
 
 	// deadConns is a list of connections that have gone bad and need to be
 	// closed one no one is using them anymore. We can add conns to this list
@@ -121,46 +156,54 @@ type connPool struct {
 	// openMu blocks attempts to open connections ("reads")
 	// while the garbage collector is closing connections ("writes").
 	openMu sync.RWMutex
+
+	// health remembers recent failures for each lockserver, even after the
+	// failed connections have been removed from conns.
+	health map[planetscalev2.VitessLockserverParams]*lockserverHealth
+
+	// healthMu guards all reads and writes of the health map.
+	// If you also need mapMu, take mapMu first.
+	healthMu sync.Mutex
 }
 
 // get returns a connection attempt from the pool,
 // creating a new attempt if necessary.
-func (p *connPool) get(params planetscalev2.VitessLockserverParams) *Conn {
+func (p *connPool) get(key connKey, health *lockserverHealth) *Conn {
 	pool.mapMu.Lock()
 	defer pool.mapMu.Unlock()
 
-	conn := p.conns[params]
+	conn := p.conns[key]
 	if conn != nil {
 		if conn.failed() {
 			// If the connect attempt failed, remove it and pretend it wasn't found.
-			delete(p.conns, params)
+			delete(p.conns, key)
 			conn = nil
 		} else if conn.succeeded() {
 			cacheHits.Inc()
 			// Every time we fetch a cached conn, we also check if the connection is
 			// still alive. We do this asynchronously because we need a long timeout to
 			// avoid false negatives, but we don't want to hold up the caller.
-			go p.checkConn(params)
+			go p.checkConn(key)
 		}
 	}
 	if conn == nil {
 		cacheMisses.Inc()
 		// Start a new connection attempt.
-		conn = newConn(params)
-		p.conns[params] = conn
+		conn = newConn(key, health)
+		p.conns[key] = conn
 	}
 	return conn
 }
 
 // checkConn performs a liveness check on the conection,
 // and removes it from the cache if it fails.
-func (p *connPool) checkConn(params planetscalev2.VitessLockserverParams) {
+func (p *connPool) checkConn(key connKey) {
 	// Take the usual locks as if we are opening the connection like anyone else.
 	p.openMu.RLock()
 	defer pool.openMu.RUnlock()
 
 	p.mapMu.Lock()
-	conn := p.conns[params]
+	conn := p.conns[key]
 	p.mapMu.Unlock()
 
 	if conn == nil {
@@ -184,6 +227,7 @@ func (p *connPool) checkConn(params planetscalev2.VitessLockserverParams) {
 	if err == nil || topo.IsErrType(err, topo.NoNode) {
 		// The check passed. Nothing to do.
 		checkSuccesses.Inc()
+		conn.health.recordSuccess()
 		return
 	}
 
@@ -194,20 +238,21 @@ func (p *connPool) checkConn(params planetscalev2.VitessLockserverParams) {
 		"rootPath":       conn.params.RootPath,
 	}).Info("cached connection to Vitess topology server failed liveness check")
 	checkErrors.Inc()
+	conn.health.recordFailure(reasonCheck, err)
 
 	p.mapMu.Lock()
 	defer p.mapMu.Unlock()
 
 	// Now that we have the map lock, confirm that the entry in the cache is
 	// still the same one we checked.
-	if p.conns[params] != conn {
+	if p.conns[key] != conn {
 		// Someone else already removed or replaced it.
 		return
 	}
 
 	// Send it to the deadConns list so the GC will close it while holding the
 	// openMu write lock.
-	delete(p.conns, params)
+	delete(p.conns, key)
 	p.deadConns = append(p.deadConns, conn)
 }
 
@@ -220,16 +265,19 @@ func (p *connPool) gc() {
 	defer p.mapMu.Unlock()
 
 	var activeRefs int64
-	for params, conn := range p.conns {
+	lockserverRefs := make(map[planetscalev2.VitessLockserverParams]int64, len(p.conns))
+	for key, conn := range p.conns {
+		params := key.params
 		// We hold the openMu write lock, so no one is trying to open a connection.
 		// The only thing we might race with is callers decrementing the refCount,
 		// which is fine. What matters is that no one will race to increment it,
 		// which could reverse a decision we had already made to close the connection.
 		conn.mu.Lock()
 		activeRefs += conn.refCount
+		lockserverRefs[params] += conn.refCount
 		if conn.failed() {
 			// The connection attempt failed, so remove it without trying to close it.
-			delete(p.conns, params)
+			delete(p.conns, key)
 		} else if conn.refCount <= 0 && time.Since(conn.lastOpened) > idleTTL {
 			log.WithFields(logrus.Fields{
 				"implementation": params.Implementation,
@@ -239,7 +287,7 @@ func (p *connPool) gc() {
 			disconnects.WithLabelValues(reasonIdle).Inc()
 
 			conn.Server.Close()
-			delete(p.conns, params)
+			delete(p.conns, key)
 		}
 		conn.mu.Unlock()
 	}
@@ -253,6 +301,7 @@ func (p *connPool) gc() {
 	for _, conn := range p.deadConns {
 		conn.mu.Lock()
 		deadRefs += conn.refCount
+		lockserverRefs[conn.params] += conn.refCount
 		if conn.refCount <= 0 {
 			log.WithFields(logrus.Fields{
 				"implementation": conn.params.Implementation,
@@ -276,6 +325,55 @@ func (p *connPool) gc() {
 	p.deadConns = stillUsed
 	connCount.WithLabelValues(connStateDead).Set(float64(len(p.deadConns)))
 	connRefCount.WithLabelValues(connStateDead).Set(float64(deadRefs))
+
+	// Remove client TLS files that no remaining connection uses, such as
+	// the ones for certificates that have since been rotated.
+	tlsDirsInUse := make(map[string]bool)
+	for key := range p.conns {
+		if key.tls.dir != "" {
+			tlsDirsInUse[key.tls.dir] = true
+		}
+	}
+	for _, conn := range p.deadConns {
+		if conn.tls.dir != "" {
+			tlsDirsInUse[conn.tls.dir] = true
+		}
+	}
+	removeUnusedTLSDirs(tlsDirsInUse)
+
+	p.gcHealth(lockserverRefs)
+}
+
+// gcHealth updates per-lockserver gauges, and forgets lockservers that
+// no one has tried to open in a while. The caller must hold mapMu.
+func (p *connPool) gcHealth(lockserverRefs map[planetscalev2.VitessLockserverParams]int64) {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+
+	for params, h := range p.health {
+		refs, inUse := lockserverRefs[params]
+
+		h.mu.Lock()
+		idle := time.Since(h.lastUsed) > healthTTL
+		h.mu.Unlock()
+
+		if idle && !inUse {
+			delete(p.health, params)
+			lockserverOpenLatency.DeleteLabelValues(h.name)
+			lockserverFailures.DeletePartialMatch(prometheus.Labels{lockserverLabel: h.name})
+			lockserverLastSuccess.DeleteLabelValues(h.name)
+			lockserverRefCount.DeleteLabelValues(h.name)
+			lockserverCircuitOpen.DeleteLabelValues(h.name)
+			continue
+		}
+
+		lockserverRefCount.WithLabelValues(h.name).Set(float64(refs))
+		circuitOpen := 0.0
+		if h.circuitOpen() {
+			circuitOpen = 1
+		}
+		lockserverCircuitOpen.WithLabelValues(h.name).Set(circuitOpen)
+	}
 }
 
 // Conn represents a connection to a topology server.
@@ -296,14 +394,19 @@ type Conn struct {
 	lastOpened  time.Time
 	lastChecked time.Time
 	params      planetscalev2.VitessLockserverParams
+	tls         tlsFiles
+	health      *lockserverHealth
 }
 
 // newConn starts a new connection attempt in the background.
 // It returns a Conn, which can be used to wait for the attempt.
-func newConn(params planetscalev2.VitessLockserverParams) *Conn {
+func newConn(key connKey, health *lockserverHealth) *Conn {
+	params := key.params
 	now := time.Now()
 	c := &Conn{
 		params:      params,
+		tls:         key.tls,
+		health:      health,
 		connectDone: make(chan struct{}),
 		lastOpened:  now,
 		lastChecked: now,
@@ -324,13 +427,19 @@ func newConn(params planetscalev2.VitessLockserverParams) *Conn {
 
 		// OpenServer has a built-in timeout that's not configurable.
 		// TODO(enisoc): Upstream a change to make the timeout configurable.
-		c.Server, c.connectErr = topo.OpenServer(params.Implementation, params.Address, params.RootPath)
+		if params.ClientTLSSecret != "" {
+			c.Server, c.connectErr = openServerWithTLS(params, key.tls)
+		} else {
+			c.Server, c.connectErr = topo.OpenServer(params.Implementation, params.Address, params.RootPath)
+		}
 		if c.connectErr == nil {
 			connLog.Info("successfully connected to Vitess topology server")
 			connectSuccesses.Inc()
+			health.recordSuccess()
 		} else {
 			connLog.WithField("err", c.connectErr).Warning("failed to connect to Vitess topology server")
 			connectErrors.Inc()
+			health.recordFailure(reasonConnect, c.connectErr)
 		}
 		close(c.connectDone)
 	}()
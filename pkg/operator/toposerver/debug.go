@@ -0,0 +1,144 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package toposerver
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+// DebugPath is where the operator serves DebugHandler.
+const DebugPath = "/debug/topo"
+
+// debugLockserver is the state of one lockserver, as shown by DebugHandler.
+type debugLockserver struct {
+	Name                string      `json:"name"`
+	Implementation      string      `json:"implementation"`
+	Address             string      `json:"address"`
+	RootPath            string      `json:"rootPath"`
+	ClientTLSSecret     string      `json:"clientTLSSecret,omitempty"`
+	Connections         []debugConn `json:"connections"`
+	LastUsed            *time.Time  `json:"lastUsed,omitempty"`
+	LastSuccess         *time.Time  `json:"lastSuccess,omitempty"`
+	LastFailure         *time.Time  `json:"lastFailure,omitempty"`
+	LastError           string      `json:"lastError,omitempty"`
+	ConsecutiveFailures int         `json:"consecutiveFailures"`
+	CircuitOpenUntil    *time.Time  `json:"circuitOpenUntil,omitempty"`
+}
+
+// debugConn is the state of one pooled connection, as shown by DebugHandler.
+type debugConn struct {
+	// State is one of "connecting", "connected", "failed" or "dead".
+	State       string    `json:"state"`
+	RefCount    int64     `json:"refCount"`
+	LastOpened  time.Time `json:"lastOpened"`
+	LastChecked time.Time `json:"lastChecked"`
+}
+
+// DebugHandler returns an HTTP handler that lists the state of the
+// connection pool as JSON, including every lockserver the operator has
+// tried to reach recently and whether its circuit breaker is open.
+func DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := json.MarshalIndent(pool.debugState(), "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+// debugState returns a snapshot of the pool, sorted by lockserver name.
+func (p *connPool) debugState() []*debugLockserver {
+	p.mapMu.Lock()
+	defer p.mapMu.Unlock()
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+
+	lockservers := make([]*debugLockserver, 0, len(p.health))
+	byParams := make(map[planetscalev2.VitessLockserverParams]*debugLockserver, len(p.health))
+	for params, h := range p.health {
+		h.mu.Lock()
+		ls := &debugLockserver{
+			Name:                h.name,
+			Implementation:      h.params.Implementation,
+			Address:             h.params.Address,
+			RootPath:            h.params.RootPath,
+			ClientTLSSecret:     h.params.ClientTLSSecret,
+			Connections:         []debugConn{},
+			LastUsed:            optionalTime(h.lastUsed),
+			LastSuccess:         optionalTime(h.lastSuccess),
+			LastFailure:         optionalTime(h.lastFailure),
+			LastError:           h.lastError,
+			ConsecutiveFailures: h.consecutiveFailures,
+		}
+		if time.Now().Before(h.breakerOpenUntil) {
+			ls.CircuitOpenUntil = optionalTime(h.breakerOpenUntil)
+		}
+		h.mu.Unlock()
+
+		lockservers = append(lockservers, ls)
+		byParams[params] = ls
+	}
+
+	addConn := func(conn *Conn, state string) {
+		ls := byParams[conn.params]
+		if ls == nil {
+			return
+		}
+		conn.mu.Lock()
+		defer conn.mu.Unlock()
+		ls.Connections = append(ls.Connections, debugConn{
+			State:       state,
+			RefCount:    conn.refCount,
+			LastOpened:  conn.lastOpened,
+			LastChecked: conn.lastChecked,
+		})
+	}
+	for _, conn := range p.conns {
+		switch {
+		case conn.succeeded():
+			addConn(conn, "connected")
+		case conn.failed():
+			addConn(conn, "failed")
+		default:
+			addConn(conn, "connecting")
+		}
+	}
+	for _, conn := range p.deadConns {
+		addConn(conn, "dead")
+	}
+
+	sort.Slice(lockservers, func(i, j int) bool {
+		return lockservers[i].Name < lockservers[j].Name
+	})
+	return lockservers
+}
+
+// optionalTime returns nil for the zero time, so it's left out of JSON.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package toposerver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+const (
+	// breakerFailureThreshold is how many consecutive failures to connect to
+	// (or check) a lockserver will open the circuit breaker for it.
+	breakerFailureThreshold = 3
+
+	// breakerMinBackoff is how long the circuit breaker stays open after
+	// reaching the failure threshold. It doubles with every failure after
+	// that, up to breakerMaxBackoff.
+	breakerMinBackoff = 5 * time.Second
+	breakerMaxBackoff = 2 * time.Minute
+
+	// healthTTL is how long to remember a lockserver that no one has tried
+	// to open.
+	healthTTL = 10 * time.Minute
+)
+
+// ErrCircuitOpen is returned by Open when recent attempts to reach the
+// lockserver have failed, so we're waiting a while before trying again.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// lockserverHealth tracks recent attempts to reach a lockserver, across all
+// the connections that have been made to it.
+type lockserverHealth struct {
+	// name identifies the lockserver in metrics and logs.
+	name   string
+	params planetscalev2.VitessLockserverParams
+
+	mu                  sync.Mutex
+	lastUsed            time.Time
+	lastSuccess         time.Time
+	lastFailure         time.Time
+	lastError           string
+	consecutiveFailures int
+	breakerOpenUntil    time.Time
+}
+
+// lockserverName returns a human-readable identifier for a lockserver.
+func lockserverName(params planetscalev2.VitessLockserverParams) string {
+	name := fmt.Sprintf("%s://%s/%s", params.Implementation, params.Address, strings.TrimPrefix(params.RootPath, "/"))
+	if params.ClientTLSSecret != "" {
+		name += "?clientTLSSecret=" + params.ClientTLSSecret
+	}
+	return name
+}
+
+// getHealth returns the health record for a lockserver, creating it if necessary.
+func (p *connPool) getHealth(params planetscalev2.VitessLockserverParams) *lockserverHealth {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+
+	h := p.health[params]
+	if h == nil {
+		h = &lockserverHealth{
+			name:   lockserverName(params),
+			params: params,
+		}
+		p.health[params] = h
+	}
+	return h
+}
+
+// allow returns an error if the circuit breaker is open.
+func (h *lockserverHealth) allow() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	h.lastUsed = now
+	if now.Before(h.breakerOpenUntil) {
+		return fmt.Errorf("%w for %v after %v consecutive failures (last error: %v); will retry in %v",
+			ErrCircuitOpen, h.name, h.consecutiveFailures, h.lastError, h.breakerOpenUntil.Sub(now).Round(time.Second))
+	}
+	return nil
+}
+
+// recordSuccess notes that the lockserver was reached, closing the breaker.
+func (h *lockserverHealth) recordSuccess() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastSuccess = time.Now()
+	h.consecutiveFailures = 0
+	h.breakerOpenUntil = time.Time{}
+	lockserverLastSuccess.WithLabelValues(h.name).Set(float64(h.lastSuccess.Unix()))
+}
+
+// recordFailure notes that the lockserver couldn't be reached, and opens the
+// breaker if that has happened too many times in a row.
+func (h *lockserverHealth) recordFailure(reason string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastFailure = time.Now()
+	h.lastError = err.Error()
+	h.consecutiveFailures++
+	lockserverFailures.WithLabelValues(h.name, reason).Inc()
+
+	if h.consecutiveFailures < breakerFailureThreshold {
+		return
+	}
+	backoff := breakerBackoff(h.consecutiveFailures)
+	h.breakerOpenUntil = h.lastFailure.Add(backoff)
+	log.WithFields(logrus.Fields{
+		"lockserver": h.name,
+		"failures":   h.consecutiveFailures,
+		"backoff":    backoff,
+	}).Warning("opening circuit breaker for Vitess topology server")
+}
+
+// circuitOpen returns whether the breaker is currently open.
+func (h *lockserverHealth) circuitOpen() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return time.Now().Before(h.breakerOpenUntil)
+}
+
+// breakerBackoff returns how long to keep the breaker open after the given
+// number of consecutive failures.
+func breakerBackoff(failures int) time.Duration {
+	backoff := breakerMinBackoff
+	for i := breakerFailureThreshold; i < failures && backoff < breakerMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, breakerMaxBackoff)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package toposerver
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+func TestBreakerBackoff(t *testing.T) {
+	assert.Equal(t, breakerMinBackoff, breakerBackoff(breakerFailureThreshold))
+	assert.Equal(t, 2*breakerMinBackoff, breakerBackoff(breakerFailureThreshold+1))
+	assert.Equal(t, 4*breakerMinBackoff, breakerBackoff(breakerFailureThreshold+2))
+	assert.Equal(t, breakerMaxBackoff, breakerBackoff(breakerFailureThreshold+100))
+}
+
+func TestLockserverHealthCircuitBreaker(t *testing.T) {
+	p := &connPool{health: make(map[planetscalev2.VitessLockserverParams]*lockserverHealth)}
+	h := p.getHealth(planetscalev2.VitessLockserverParams{
+		Implementation: "etcd2",
+		Address:        "example-etcd:2379",
+		RootPath:       "/vitess/example/global",
+	})
+	assert.Equal(t, "etcd2://example-etcd:2379/vitess/example/global", h.name)
+
+	// The breaker stays closed until the failure threshold is reached.
+	for i := 1; i < breakerFailureThreshold; i++ {
+		h.recordFailure(reasonConnect, errors.New("connection refused"))
+		assert.NoError(t, h.allow())
+	}
+	h.recordFailure(reasonConnect, errors.New("connection refused"))
+	err := h.allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Contains(t, err.Error(), "connection refused")
+	assert.True(t, h.circuitOpen())
+
+	// Once the backoff has passed, one more attempt is allowed.
+	h.breakerOpenUntil = time.Now().Add(-time.Second)
+	assert.NoError(t, h.allow())
+
+	// A success closes the breaker.
+	h.recordFailure(reasonConnect, errors.New("connection refused"))
+	assert.True(t, h.circuitOpen())
+	h.recordSuccess()
+	assert.False(t, h.circuitOpen())
+	assert.NoError(t, h.allow())
+	assert.Equal(t, 0, h.consecutiveFailures)
+}
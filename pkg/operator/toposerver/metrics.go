@@ -28,9 +28,13 @@ const (
 	connStateActive = "active"
 	connStateDead   = "dead"
 
-	reasonLabel = "reason"
-	reasonIdle  = "idle"
-	reasonDead  = "dead"
+	reasonLabel   = "reason"
+	reasonIdle    = "idle"
+	reasonDead    = "dead"
+	reasonConnect = "connect"
+	reasonCheck   = "check"
+
+	lockserverLabel = "lockserver"
 )
 
 var (
@@ -104,6 +108,37 @@ var (
 		Name:      "disconnects",
 		Help:      "Closed connections",
 	}, []string{reasonLabel})
+
+	lockserverOpenLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: subsystemName,
+		Name:      "lockserver_open_latency_seconds",
+		Help:      "Time spent trying to open a connection to a given lockserver, possibly returned from the cache",
+	}, []string{lockserverLabel})
+	lockserverFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: subsystemName,
+		Name:      "lockserver_failures",
+		Help:      "Failed attempts to connect to or check a given lockserver",
+	}, []string{lockserverLabel, reasonLabel})
+	lockserverLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: subsystemName,
+		Name:      "lockserver_last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful connection or liveness check on a given lockserver",
+	}, []string{lockserverLabel})
+	lockserverRefCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: subsystemName,
+		Name:      "lockserver_conn_ref_count",
+		Help:      "Number of outstanding references to connections to a given lockserver",
+	}, []string{lockserverLabel})
+	lockserverCircuitOpen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: subsystemName,
+		Name:      "lockserver_circuit_open",
+		Help:      "Whether new connections to a given lockserver are being refused after repeated failures",
+	}, []string{lockserverLabel})
 )
 
 func init() {
@@ -119,5 +154,10 @@ func init() {
 		checkSuccesses,
 		checkErrors,
 		disconnects,
+		lockserverOpenLatency,
+		lockserverFailures,
+		lockserverLastSuccess,
+		lockserverRefCount,
+		lockserverCircuitOpen,
 	)
 }
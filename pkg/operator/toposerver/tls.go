@@ -0,0 +1,166 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package toposerver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/etcd2topo"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/certs"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
+	"planetscale.dev/vitess-operator/pkg/operator/secrets"
+)
+
+// tlsDirName is the directory under os.TempDir() in which we write the
+// contents of client TLS Secrets, since the etcd2 topo plugin only accepts
+// file paths.
+const tlsDirName = "vitess-operator-topo-tls"
+
+// secretReader is used to read the Secrets named by ClientTLSSecret.
+var secretReader client.Reader
+
+// SetSecretReader sets the client used to read the Secrets named by the
+// ClientTLSSecret field of lockserver params. It must be called before any
+// connections are opened with such params.
+func SetSecretReader(reader client.Reader) {
+	secretReader = reader
+}
+
+// tlsFiles are the paths to the files containing the contents of a client
+// TLS Secret.
+type tlsFiles struct {
+	dir      string
+	certPath string
+	keyPath  string
+	caPath   string
+}
+
+// loadClientTLS reads the client TLS Secret for the given params and writes
+// its contents to files. The files are named after a hash of the contents,
+// so rotating the certificate results in a different set of files, and
+// therefore a new connection. The files for a given hash are only written
+// once, and removeUnusedTLSDirs cleans them up once no connection uses them.
+//
+// The caller must hold the pool's openMu read lock, so the files aren't
+// removed before the connection that uses them is in the pool.
+func loadClientTLS(ctx context.Context, namespace string, params planetscalev2.VitessLockserverParams) (tlsFiles, error) {
+	if params.Implementation != lockserver.VitessEtcdImplementationName {
+		return tlsFiles{}, fmt.Errorf("client TLS is only supported for the %q topo implementation, not %q", lockserver.VitessEtcdImplementationName, params.Implementation)
+	}
+	if secretReader == nil {
+		return tlsFiles{}, fmt.Errorf("can't read client TLS Secret %v: no Secret reader configured", params.ClientTLSSecret)
+	}
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: namespace, Name: params.ClientTLSSecret}
+	if err := secretReader.Get(ctx, key, secret); err != nil {
+		return tlsFiles{}, fmt.Errorf("can't get client TLS Secret %v: %v", key, err)
+	}
+
+	parent := filepath.Join(os.TempDir(), tlsDirName)
+	dir := filepath.Join(parent, secrets.ContentHash(secret))
+	files := tlsFiles{
+		dir:      dir,
+		certPath: filepath.Join(dir, certs.CertKey),
+		keyPath:  filepath.Join(dir, certs.PrivateKeyKey),
+		caPath:   filepath.Join(dir, certs.CACertKey),
+	}
+	if _, err := os.Stat(dir); err == nil {
+		// We already wrote these contents.
+		return files, nil
+	}
+
+	// Write the files into a temporary directory and then move it into
+	// place, so concurrent callers never see a partially written directory.
+	if err := os.MkdirAll(parent, 0700); err != nil {
+		return tlsFiles{}, err
+	}
+	tmpDir, err := os.MkdirTemp(parent, ".tmp-")
+	if err != nil {
+		return tlsFiles{}, err
+	}
+	defer os.RemoveAll(tmpDir)
+	for _, dataKey := range []string{certs.CertKey, certs.PrivateKeyKey, certs.CACertKey} {
+		data, ok := secret.Data[dataKey]
+		if !ok {
+			return tlsFiles{}, fmt.Errorf("client TLS Secret %v has no %q key", key, dataKey)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, dataKey), data, 0600); err != nil {
+			return tlsFiles{}, err
+		}
+	}
+	if err := os.Rename(tmpDir, dir); err != nil {
+		// Someone else may have moved the same contents into place first.
+		if _, statErr := os.Stat(dir); statErr != nil {
+			return tlsFiles{}, err
+		}
+	}
+	return files, nil
+}
+
+// removeUnusedTLSDirs removes every directory written by loadClientTLS
+// that isn't in the given set of directories still in use. The caller must
+// hold the pool's openMu write lock, so no one is loading files meanwhile.
+func removeUnusedTLSDirs(inUse map[string]bool) {
+	parent := filepath.Join(os.TempDir(), tlsDirName)
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.WithError(err).Warning("failed to list client TLS files for topology servers")
+		}
+		return
+	}
+	for _, entry := range entries {
+		dir := filepath.Join(parent, entry.Name())
+		if inUse[dir] {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			log.WithError(err).Warningf("failed to remove unused client TLS files in %v", dir)
+		}
+	}
+}
+
+// etcdTLSFactory is a topo.Factory that connects to etcd with a client
+// certificate. It's used instead of the etcd2 plugin's registered factory,
+// which reads the certificate paths from process-wide flags.
+type etcdTLSFactory struct {
+	files tlsFiles
+}
+
+// HasGlobalReadOnlyCell implements topo.Factory.
+func (f *etcdTLSFactory) HasGlobalReadOnlyCell(serverAddr, root string) bool {
+	return false
+}
+
+// Create implements topo.Factory.
+func (f *etcdTLSFactory) Create(cell, serverAddr, root string) (topo.Conn, error) {
+	return etcd2topo.NewServerWithOpts(serverAddr, root, f.files.certPath, f.files.keyPath, f.files.caPath)
+}
+
+// openServerWithTLS connects to the etcd topo server with a client certificate.
+func openServerWithTLS(params planetscalev2.VitessLockserverParams, files tlsFiles) (*topo.Server, error) {
+	return topo.NewWithFactory(&etcdTLSFactory{files: files}, params.Address, params.RootPath)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package toposerver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/certs"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
+)
+
+func TestLoadClientTLSRotation(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "topo-client"},
+		Data: map[string][]byte{
+			certs.CertKey:       []byte("cert-1"),
+			certs.PrivateKeyKey: []byte("key-1"),
+			certs.CACertKey:     []byte("ca"),
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	SetSecretReader(c)
+	defer SetSecretReader(nil)
+
+	params := planetscalev2.VitessLockserverParams{
+		Implementation:  lockserver.VitessEtcdImplementationName,
+		Address:         "example-etcd:2379",
+		RootPath:        "/vitess/global",
+		ClientTLSSecret: secret.Name,
+	}
+
+	oldFiles, err := loadClientTLS(t.Context(), secret.Namespace, params)
+	require.NoError(t, err)
+	key, err := os.ReadFile(oldFiles.keyPath)
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", string(key))
+
+	// Loading the same contents again reuses the files.
+	again, err := loadClientTLS(t.Context(), secret.Namespace, params)
+	require.NoError(t, err)
+	assert.Equal(t, oldFiles, again)
+
+	// Rotating the certificate writes a new set of files.
+	secret.Data[certs.CertKey] = []byte("cert-2")
+	secret.Data[certs.PrivateKeyKey] = []byte("key-2")
+	require.NoError(t, c.Update(t.Context(), secret))
+	newFiles, err := loadClientTLS(t.Context(), secret.Namespace, params)
+	require.NoError(t, err)
+	assert.NotEqual(t, oldFiles.dir, newFiles.dir)
+
+	// Only the files still in use are kept.
+	removeUnusedTLSDirs(map[string]bool{newFiles.dir: true})
+	entries, err := os.ReadDir(filepath.Join(os.TempDir(), tlsDirName))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, filepath.Base(newFiles.dir), entries[0].Name())
+	key, err = os.ReadFile(newFiles.keyPath)
+	require.NoError(t, err)
+	assert.Equal(t, "key-2", string(key))
+}
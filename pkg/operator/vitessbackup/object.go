@@ -128,7 +128,14 @@ func GetBackups(
 		return nil, nil, err
 	}
 
-	allBackups = allBackupsList.Items
+	// VolumeSnapshot backups can only be used to provision new data volumes
+	// in this Kubernetes cluster, so they don't count as backups in storage.
+	allBackups = make([]planetscalev2.VitessBackup, 0, len(allBackupsList.Items))
+	for i := range allBackupsList.Items {
+		if !IsSnapshot(&allBackupsList.Items[i]) {
+			allBackups = append(allBackups, allBackupsList.Items[i])
+		}
+	}
 
 	// Filter by complete backups.
 	completeBackups = getCompleteBackups(allBackups)
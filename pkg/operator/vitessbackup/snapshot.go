@@ -0,0 +1,101 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitessbackup
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	apilabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+// SnapshotEngine is the engine reported for backups taken with the "snapshot"
+// backup method.
+const SnapshotEngine = "volumesnapshot"
+
+// VolumeSnapshotGVK is the CSI VolumeSnapshot kind. We use it through the
+// unstructured client so the operator doesn't depend on the snapshot CRDs
+// being installed unless the "snapshot" backup method is used.
+var VolumeSnapshotGVK = schema.GroupVersionKind{
+	Group:   "snapshot.storage.k8s.io",
+	Version: "v1",
+	Kind:    "VolumeSnapshot",
+}
+
+// IsSnapshot returns whether a VitessBackup represents a VolumeSnapshot
+// rather than a backup in storage.
+func IsSnapshot(backup *planetscalev2.VitessBackup) bool {
+	return backup.Labels[planetscalev2.BackupMethodLabel] == string(planetscalev2.BackupMethodSnapshot)
+}
+
+// GetSnapshots returns all VolumeSnapshot backups for the given keyspace/shard
+// in the given cluster, whether or not they're complete.
+func GetSnapshots(
+	ctx context.Context,
+	namespace, clusterName, keyspaceName, shardSafeName string,
+	listBackups func(context.Context, *planetscalev2.VitessBackupList, *client.ListOptions) error,
+) ([]planetscalev2.VitessBackup, error) {
+	list := &planetscalev2.VitessBackupList{}
+	listOpts := &client.ListOptions{
+		Namespace: namespace,
+		LabelSelector: apilabels.SelectorFromSet(apilabels.Set{
+			planetscalev2.ClusterLabel:      clusterName,
+			planetscalev2.KeyspaceLabel:     keyspaceName,
+			planetscalev2.ShardLabel:        shardSafeName,
+			planetscalev2.BackupMethodLabel: string(planetscalev2.BackupMethodSnapshot),
+		}),
+	}
+	if err := listBackups(ctx, list, listOpts); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// LatestSnapshot returns the newest complete VolumeSnapshot backup from the
+// given list. It returns nil if there are no complete snapshots.
+func LatestSnapshot(backups []planetscalev2.VitessBackup) *planetscalev2.VitessBackup {
+	var latest *planetscalev2.VitessBackup
+	for i := range backups {
+		backup := &backups[i]
+		if !IsSnapshot(backup) || !backup.Status.Complete || backup.Status.VolumeSnapshotName == "" {
+			continue
+		}
+		if latest == nil || backup.Status.StartTime.After(latest.Status.StartTime.Time) {
+			latest = backup
+		}
+	}
+	return latest
+}
+
+// VolumeSnapshotRestoreSize returns the minimum size of a volume provisioned
+// from the given VolumeSnapshot, and false if the snapshot doesn't report it.
+func VolumeSnapshotRestoreSize(volumeSnapshot *unstructured.Unstructured) (resource.Quantity, bool) {
+	restoreSize, found, _ := unstructured.NestedString(volumeSnapshot.Object, "status", "restoreSize")
+	if !found {
+		return resource.Quantity{}, false
+	}
+	size, err := resource.ParseQuantity(restoreSize)
+	if err != nil {
+		return resource.Quantity{}, false
+	}
+	return size, true
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitessbackup
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+func TestLatestSnapshot(t *testing.T) {
+	now := time.Now()
+	snapshot := func(name string, age time.Duration, complete bool) planetscalev2.VitessBackup {
+		return planetscalev2.VitessBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{planetscalev2.BackupMethodLabel: string(planetscalev2.BackupMethodSnapshot)},
+			},
+			Status: planetscalev2.VitessBackupStatus{
+				StartTime:          metav1.NewTime(now.Add(-age)),
+				Complete:           complete,
+				VolumeSnapshotName: name,
+			},
+		}
+	}
+
+	backups := []planetscalev2.VitessBackup{
+		snapshot("old", 2*time.Hour, true),
+		snapshot("new", time.Hour, true),
+		snapshot("in-progress", time.Minute, false),
+		{Status: planetscalev2.VitessBackupStatus{StartTime: metav1.NewTime(now), Complete: true}},
+	}
+	if got := LatestSnapshot(backups); got == nil || got.Name != "new" {
+		t.Errorf("LatestSnapshot() = %v; want new", got)
+	}
+	if got := LatestSnapshot(backups[2:]); got != nil {
+		t.Errorf("LatestSnapshot() = %v; want nil", got.Name)
+	}
+}
+
+func TestVolumeSnapshotRestoreSize(t *testing.T) {
+	volumeSnapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{"restoreSize": "15Gi"},
+	}}
+	size, ok := VolumeSnapshotRestoreSize(volumeSnapshot)
+	if !ok || size.String() != "15Gi" {
+		t.Errorf("VolumeSnapshotRestoreSize() = %v, %v; want 15Gi, true", size.String(), ok)
+	}
+
+	if _, ok := VolumeSnapshotRestoreSize(&unstructured.Unstructured{Object: map[string]interface{}{}}); ok {
+		t.Errorf("VolumeSnapshotRestoreSize() found a size in a snapshot without status")
+	}
+}
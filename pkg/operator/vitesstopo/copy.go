@@ -0,0 +1,330 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesstopo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/vt/topo"
+)
+
+const (
+	// topoRootPath is where we start walking a topology tree.
+	topoRootPath = "/"
+	// tabletsDir holds tablet records in a cell's topology.
+	tabletsDir = "/tablets/"
+	// maxReportedDifferences is how many differing paths CompareTopology
+	// names in its error.
+	maxReportedDifferences = 5
+)
+
+// namedObjectDirs are directories whose children are named by users, so a
+// child called "locks" or "elections" is a real object and not a lock.
+var namedObjectDirs = map[string]bool{
+	"keyspaces":     true,
+	"shards":        true,
+	"tablets":       true,
+	"cells":         true,
+	"cells_aliases": true,
+}
+
+// topoTree is one topology tree whose data moves along with the global
+// lockserver: the global topology itself, or the topology of a cell that is
+// stored in a different place on each side.
+type topoTree struct {
+	cell     string
+	from, to topo.Conn
+}
+
+/*
+CopyTopology makes the data Vitess keeps in topology on toTS match fromTS.
+
+It copies the global topology, plus the local topology of every cell that is
+registered in a different place on each side, node by node: nodes that differ
+are overwritten, and nodes that only exist on toTS are deleted. That way, a
+copy that is repeated after fromTS changed always converges, no matter which
+kind of node changed.
+
+Cell and cells alias registrations in the global topology are left alone,
+since they must point at where each cell's topology lives on the new side.
+Locks and leader elections are skipped, since they only mean something to
+the processes that hold them.
+*/
+func CopyTopology(ctx context.Context, fromTS, toTS *topo.Server) error {
+	trees, err := movingTrees(ctx, fromTS, toTS)
+	if err != nil {
+		return err
+	}
+	for _, tree := range trees {
+		if err := tree.copy(ctx); err != nil {
+			return fmt.Errorf("failed to copy %v topology: %w", tree.cell, err)
+		}
+	}
+	return nil
+}
+
+// CompareTopology returns an error naming some of the nodes that differ
+// between fromTS and toTS, out of the ones CopyTopology copies.
+func CompareTopology(ctx context.Context, fromTS, toTS *topo.Server) error {
+	trees, err := movingTrees(ctx, fromTS, toTS)
+	if err != nil {
+		return err
+	}
+	var diffs []string
+	for _, tree := range trees {
+		treeDiffs, err := tree.compare(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to compare %v topology: %w", tree.cell, err)
+		}
+		diffs = append(diffs, treeDiffs...)
+	}
+	if len(diffs) == 0 {
+		return nil
+	}
+	examples := diffs[:min(len(diffs), maxReportedDifferences)]
+	return fmt.Errorf("%d nodes differ, including %s", len(diffs), strings.Join(examples, ", "))
+}
+
+// TopologyChecksum returns a checksum of the data CopyTopology would copy
+// from fromTS, except tablet records. Tablets write their own records again
+// whenever they start, so those are expected to change as tablets move.
+func TopologyChecksum(ctx context.Context, fromTS, toTS *topo.Server) (string, error) {
+	trees, err := movingTrees(ctx, fromTS, toTS)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	for _, tree := range trees {
+		err := walkTopo(ctx, tree.from, topoRootPath, func(filePath string) error {
+			if tree.cell != topo.GlobalCell && strings.HasPrefix(filePath, tabletsDir) {
+				return nil
+			}
+			contents, _, err := tree.from.Get(ctx, filePath)
+			if topo.IsErrType(err, topo.NoNode) {
+				// It was deleted while we were walking.
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(hash, "%s:%s:%d:", tree.cell, filePath, len(contents))
+			hash.Write(contents)
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to read %v topology: %w", tree.cell, err)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// movingTrees returns the topology trees that need to be copied from fromTS
+// to toTS. Cells whose topology is registered in the same place on both
+// sides share their data, so there's nothing to copy for them.
+func movingTrees(ctx context.Context, fromTS, toTS *topo.Server) ([]topoTree, error) {
+	fromGlobal, err := fromTS.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return nil, err
+	}
+	toGlobal, err := toTS.ConnForCell(ctx, topo.GlobalCell)
+	if err != nil {
+		return nil, err
+	}
+	trees := []topoTree{{cell: topo.GlobalCell, from: fromGlobal, to: toGlobal}}
+
+	cells, err := fromTS.GetCellInfoNames(ctx)
+	if err != nil && !topo.IsErrType(err, topo.NoNode) {
+		return nil, err
+	}
+	sort.Strings(cells)
+	for _, cell := range cells {
+		fromInfo, err := fromTS.GetCellInfo(ctx, cell, true)
+		if err != nil {
+			return nil, err
+		}
+		toInfo, err := toTS.GetCellInfo(ctx, cell, true)
+		if topo.IsErrType(err, topo.NoNode) {
+			// We can't copy tablets into a cell the new side doesn't know.
+			return nil, fmt.Errorf("cell %v is not registered in the new global lockserver", cell)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if fromInfo.ServerAddress == toInfo.ServerAddress && fromInfo.Root == toInfo.Root {
+			continue
+		}
+		fromConn, err := fromTS.ConnForCell(ctx, cell)
+		if err != nil {
+			return nil, err
+		}
+		toConn, err := toTS.ConnForCell(ctx, cell)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, topoTree{cell: cell, from: fromConn, to: toConn})
+	}
+	return trees, nil
+}
+
+// copy makes the data in t.to match t.from.
+func (t *topoTree) copy(ctx context.Context) error {
+	want := make(map[string]bool)
+	err := walkTopo(ctx, t.from, topoRootPath, func(filePath string) error {
+		want[filePath] = true
+
+		contents, _, err := t.from.Get(ctx, filePath)
+		if topo.IsErrType(err, topo.NoNode) {
+			// It was deleted while we were walking.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		cur, _, err := t.to.Get(ctx, filePath)
+		switch {
+		case err == nil && bytes.Equal(cur, contents):
+			return nil
+		case err != nil && !topo.IsErrType(err, topo.NoNode):
+			return err
+		}
+		// A nil version creates the node if it doesn't exist yet.
+		_, err = t.to.Update(ctx, filePath, contents, nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Remove anything that's gone from the old side.
+	var extra []string
+	err = walkTopo(ctx, t.to, topoRootPath, func(filePath string) error {
+		if !want[filePath] {
+			extra = append(extra, filePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, filePath := range extra {
+		if err := t.to.Delete(ctx, filePath, nil); err != nil && !topo.IsErrType(err, topo.NoNode) {
+			return err
+		}
+	}
+	return nil
+}
+
+// compare returns the paths of nodes that differ between t.from and t.to.
+func (t *topoTree) compare(ctx context.Context) ([]string, error) {
+	fromFiles, err := readTopo(ctx, t.from)
+	if err != nil {
+		return nil, err
+	}
+	toFiles, err := readTopo(ctx, t.to)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []string
+	for filePath, contents := range fromFiles {
+		cur, ok := toFiles[filePath]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("%v:%v (missing)", t.cell, filePath))
+		case !bytes.Equal(cur, contents):
+			diffs = append(diffs, fmt.Sprintf("%v:%v (changed)", t.cell, filePath))
+		}
+	}
+	for filePath := range toFiles {
+		if _, ok := fromFiles[filePath]; !ok {
+			diffs = append(diffs, fmt.Sprintf("%v:%v (extra)", t.cell, filePath))
+		}
+	}
+	sort.Strings(diffs)
+	return diffs, nil
+}
+
+// readTopo returns the contents of every node walkTopo visits.
+func readTopo(ctx context.Context, conn topo.Conn) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := walkTopo(ctx, conn, topoRootPath, func(filePath string) error {
+		contents, _, err := conn.Get(ctx, filePath)
+		if topo.IsErrType(err, topo.NoNode) {
+			// It was deleted while we were walking.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		files[filePath] = contents
+		return nil
+	})
+	return files, err
+}
+
+// walkTopo calls fn with the path of every file below dirPath, in order.
+// It skips locks, leader elections, ephemeral nodes, and the cell and cells
+// alias registrations in the root of the tree.
+func walkTopo(ctx context.Context, conn topo.Conn, dirPath string, fn func(filePath string) error) error {
+	entries, err := conn.ListDir(ctx, dirPath, true)
+	if topo.IsErrType(err, topo.NoNode) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	for _, entry := range entries {
+		if skipTopoEntry(dirPath, entry) {
+			continue
+		}
+		childPath := path.Join(dirPath, entry.Name)
+		if entry.Type == topo.TypeDirectory {
+			if err := walkTopo(ctx, conn, childPath, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(childPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipTopoEntry returns whether walkTopo should leave out an entry.
+func skipTopoEntry(dirPath string, entry topo.DirEntry) bool {
+	if entry.Ephemeral {
+		return true
+	}
+	if dirPath == topoRootPath && (entry.Name == "cells" || entry.Name == "cells_aliases") {
+		return true
+	}
+	switch entry.Name {
+	case "locks", "elections":
+		return !namedObjectDirs[path.Base(dirPath)]
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesstopo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+)
+
+func TestSkipTopoEntry(t *testing.T) {
+	file := func(name string) topo.DirEntry { return topo.DirEntry{Name: name, Type: topo.TypeFile} }
+	dir := func(name string) topo.DirEntry { return topo.DirEntry{Name: name, Type: topo.TypeDirectory} }
+
+	assert.True(t, skipTopoEntry("/", dir("cells")))
+	assert.True(t, skipTopoEntry("/", dir("cells_aliases")))
+	assert.True(t, skipTopoEntry("/keyspaces/commerce", dir("locks")))
+	assert.True(t, skipTopoEntry("/keyspaces/commerce/shards/-", dir("elections")))
+	assert.True(t, skipTopoEntry("/keyspaces", topo.DirEntry{Name: "commerce", Type: topo.TypeDirectory, Ephemeral: true}))
+
+	// Objects that happen to have those names are still copied.
+	assert.False(t, skipTopoEntry("/keyspaces", dir("locks")))
+	assert.False(t, skipTopoEntry("/keyspaces/commerce", dir("shards")))
+	assert.False(t, skipTopoEntry("/keyspaces/commerce", file("Keyspace")))
+}
+
+func TestCopyTopologyConverges(t *testing.T) {
+	ctx := t.Context()
+	fromTS := memorytopo.NewServer(ctx, "zone1")
+	toTS := memorytopo.NewServer(ctx, "zone1")
+	defer fromTS.Close()
+	defer toTS.Close()
+
+	require.NoError(t, fromTS.CreateKeyspace(ctx, "commerce", &topodatapb.Keyspace{}))
+	require.NoError(t, CopyTopology(ctx, fromTS, toTS))
+	assert.NoError(t, CompareTopology(ctx, fromTS, toTS))
+
+	// Copying again after the old side changed picks up changes to nodes
+	// that were already copied, as well as deletions.
+	require.NoError(t, fromTS.DeleteKeyspace(ctx, "commerce"))
+	require.NoError(t, fromTS.CreateKeyspace(ctx, "commerce", &topodatapb.Keyspace{DurabilityPolicy: "semi_sync"}))
+	require.NoError(t, fromTS.CreateKeyspace(ctx, "customer", &topodatapb.Keyspace{}))
+	require.NoError(t, CopyTopology(ctx, fromTS, toTS))
+	require.NoError(t, fromTS.DeleteKeyspace(ctx, "customer"))
+	assert.Error(t, CompareTopology(ctx, fromTS, toTS))
+	require.NoError(t, CopyTopology(ctx, fromTS, toTS))
+	assert.NoError(t, CompareTopology(ctx, fromTS, toTS))
+
+	_, err := toTS.GetKeyspace(ctx, "customer")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), "deleted keyspace should be gone: %v", err)
+	ks, err := toTS.GetKeyspace(ctx, "commerce")
+	require.NoError(t, err)
+	assert.Equal(t, "semi_sync", ks.DurabilityPolicy)
+}
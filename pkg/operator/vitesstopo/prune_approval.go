@@ -0,0 +1,192 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesstopo
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// ApprovalAnnotationPrefix is the prefix for annotation keys that
+	// approve pruning of topology records while dry-run is enabled.
+	ApprovalAnnotationPrefix = "topo.planetscale.com"
+
+	// ApprovePruneCellsAnnotation is the VitessCluster annotation whose value
+	// is a comma-separated list of cell names that may be pruned.
+	ApprovePruneCellsAnnotation = ApprovalAnnotationPrefix + "/" + "approve-prune-cells"
+
+	// ApprovePruneKeyspacesAnnotation is the VitessCluster annotation whose
+	// value is a comma-separated list of keyspace names that may be pruned.
+	ApprovePruneKeyspacesAnnotation = ApprovalAnnotationPrefix + "/" + "approve-prune-keyspaces"
+
+	// ApprovePruneShardsAnnotation is the VitessKeyspace annotation whose
+	// value is a comma-separated list of shard names that may be pruned.
+	ApprovePruneShardsAnnotation = ApprovalAnnotationPrefix + "/" + "approve-prune-shards"
+
+	// ApprovePruneShardCellsAnnotation is the VitessShard annotation whose
+	// value is a comma-separated list of cell names that may be removed from
+	// the shard record.
+	ApprovePruneShardCellsAnnotation = ApprovalAnnotationPrefix + "/" + "approve-prune-shard-cells"
+
+	// ApprovePruneTabletsAnnotation is the VitessShard annotation whose value
+	// is a comma-separated list of tablet aliases that may be pruned.
+	ApprovePruneTabletsAnnotation = ApprovalAnnotationPrefix + "/" + "approve-prune-tablets"
+
+	// ApprovePruneSrvKeyspacesAnnotation is the VitessCell annotation whose
+	// value is a comma-separated list of keyspace names whose SrvKeyspace
+	// records may be pruned from the cell.
+	ApprovePruneSrvKeyspacesAnnotation = ApprovalAnnotationPrefix + "/" + "approve-prune-srv-keyspaces"
+)
+
+var log = logrus.WithField("component", "vitesstopo")
+
+// ApprovedPrunes returns the names listed in the given approval annotation.
+func ApprovedPrunes(obj metav1.Object, annotation string) sets.String {
+	approved := sets.NewString()
+	for _, name := range strings.Split(obj.GetAnnotations()[annotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			approved.Insert(name)
+		}
+	}
+	return approved
+}
+
+// PruneApproval is the dry-run state for pruning one kind of topology record.
+type PruneApproval struct {
+	// Annotation is the annotation that lists approved records.
+	Annotation string
+	// DryRun holds back records from being pruned until they're approved.
+	DryRun bool
+	// Approved is the set of names listed in the annotation.
+	Approved sets.String
+	// Used lists the approvals that were already used up by pruning the
+	// record they approved. They don't count anymore, so an approval can't
+	// carry over to a new record that later gets the same name.
+	Used []string
+	// AlreadyPending lists the records that were already waiting for approval
+	// the last time we checked, so we only announce newly pending ones.
+	AlreadyPending []string
+}
+
+// NewPruneApproval reads the approvals listed in the given annotation on obj.
+// usedApprovals is the status map of approvals that were already used up.
+func NewPruneApproval(obj metav1.Object, annotation string, dryRun bool, usedApprovals map[string][]string, alreadyPending []string) PruneApproval {
+	return PruneApproval{
+		Annotation:     annotation,
+		DryRun:         dryRun,
+		Approved:       ApprovedPrunes(obj, annotation),
+		Used:           usedApprovals[annotation],
+		AlreadyPending: alreadyPending,
+	}
+}
+
+// Hold splits prune candidates into the ones that may be pruned now, and the
+// ones that are held back waiting for approval. It announces each record that
+// has newly started waiting.
+func (a *PruneApproval) Hold(recorder record.EventRecorder, eventObj runtime.Object, kind string, candidates []string) (release, pending []string) {
+	release, pending = holdForApproval(candidates, a.DryRun, a.Approved.Difference(sets.NewString(a.Used...)))
+	reportPending(recorder, eventObj, kind, a.Annotation, pending, a.AlreadyPending)
+	return release, pending
+}
+
+// UsedAfter returns the approvals that are used up once the given records
+// have been pruned. Approvals that were removed from the annotation are
+// forgotten, so a name can be approved again by removing and re-adding it.
+func (a *PruneApproval) UsedAfter(pruned []string) []string {
+	used := sets.NewString(a.Used...).Insert(pruned...).Intersection(a.Approved)
+	if used.Len() == 0 {
+		return nil
+	}
+	return used.List()
+}
+
+// PruneApprovalStatus is what the caller should record in status about the
+// approvals for one kind of topology record after pruning.
+type PruneApprovalStatus struct {
+	// Pending lists the records that are waiting for approval.
+	Pending []string
+	// Used lists the approvals that have been used up.
+	Used []string
+}
+
+// RecordUsedApprovals stores the used approvals for one annotation in a status
+// map, leaving out empty lists. It never modifies the map it's given, since
+// that may still be shared with the old status.
+func RecordUsedApprovals(usedApprovals *map[string][]string, annotation string, used []string) {
+	updated := make(map[string][]string, len(*usedApprovals)+1)
+	for key, value := range *usedApprovals {
+		if key != annotation {
+			updated[key] = value
+		}
+	}
+	if len(used) > 0 {
+		updated[annotation] = used
+	}
+	if len(updated) == 0 {
+		updated = nil
+	}
+	*usedApprovals = updated
+}
+
+// holdForApproval splits prune candidates into the ones that may be deleted
+// now, and the ones that are held back because dry-run is enabled and they
+// haven't been approved yet.
+func holdForApproval(candidates []string, dryRun bool, approved sets.String) (release, pending []string) {
+	if !dryRun {
+		return candidates, nil
+	}
+	for _, name := range candidates {
+		if approved.Has(name) {
+			release = append(release, name)
+		} else {
+			pending = append(pending, name)
+		}
+	}
+	return release, pending
+}
+
+// reportPending logs and records an event for each pruning that has started
+// waiting for approval since the last time we checked. The ones that were
+// already pending are listed in status, so we don't repeat them.
+func reportPending(recorder record.EventRecorder, eventObj runtime.Object, kind, annotation string, pending, alreadyPending []string) {
+	objLog := log
+	if obj, err := meta.Accessor(eventObj); err == nil {
+		objLog = log.WithFields(logrus.Fields{
+			"namespace": obj.GetNamespace(),
+			"name":      obj.GetName(),
+		})
+	}
+	reported := sets.NewString(alreadyPending...)
+	for _, name := range pending {
+		if reported.Has(name) {
+			continue
+		}
+		objLog.WithFields(logrus.Fields{
+			"kind":   kind,
+			"record": name,
+		}).Info("dry run: not pruning unwanted topology record until approved")
+		recorder.Eventf(eventObj, corev1.EventTypeNormal, "TopoCleanupPending", "dry run: would remove unwanted %s %s from topology; add it to the %s annotation to approve", kind, name, annotation)
+	}
+}
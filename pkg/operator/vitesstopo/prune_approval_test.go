@@ -0,0 +1,115 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vitesstopo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+func TestApprovedPrunes(t *testing.T) {
+	obj := &metav1.ObjectMeta{
+		Annotations: map[string]string{
+			ApprovePruneShardsAnnotation: " -80, 80-,,",
+		},
+	}
+	assert.Equal(t, sets.NewString("-80", "80-"), ApprovedPrunes(obj, ApprovePruneShardsAnnotation))
+	assert.Empty(t, ApprovedPrunes(obj, ApprovePruneCellsAnnotation))
+}
+
+func TestHoldForApproval(t *testing.T) {
+	candidates := []string{"zone1", "zone2", "zone3"}
+
+	// Without dry-run, everything is pruned.
+	release, pending := holdForApproval(candidates, false, sets.NewString())
+	assert.Equal(t, candidates, release)
+	assert.Empty(t, pending)
+
+	// With dry-run, only approved candidates are pruned.
+	release, pending = holdForApproval(candidates, true, sets.NewString("zone2", "zone4"))
+	assert.Equal(t, []string{"zone2"}, release)
+	assert.Equal(t, []string{"zone1", "zone3"}, pending)
+}
+
+func TestReportPendingOnlyNew(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	vt := &planetscalev2.VitessCluster{}
+
+	reportPending(recorder, vt, "cell", ApprovePruneCellsAnnotation, []string{"zone1", "zone2"}, nil)
+	assert.Len(t, recorder.Events, 2)
+	<-recorder.Events
+	<-recorder.Events
+
+	// Records that were already pending aren't announced again.
+	reportPending(recorder, vt, "cell", ApprovePruneCellsAnnotation, []string{"zone1", "zone2", "zone3"}, []string{"zone1", "zone2"})
+	if assert.Len(t, recorder.Events, 1) {
+		assert.Contains(t, <-recorder.Events, "zone3")
+	}
+}
+
+func TestPruneApprovalUsedOnce(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	vts := &planetscalev2.VitessShard{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				ApprovePruneTabletsAnnotation: "zone1-0000000101,zone1-0000000102,zone1-0000000103",
+			},
+		},
+	}
+	usedApprovals := map[string][]string{
+		ApprovePruneTabletsAnnotation: {"zone1-0000000101", "zone1-0000000109"},
+	}
+	approval := NewPruneApproval(vts, ApprovePruneTabletsAnnotation, true, usedApprovals, nil)
+
+	// A tablet that reuses the alias of an already pruned one waits for a new approval.
+	release, pending := approval.Hold(recorder, vts, "tablet", []string{"zone1-0000000101", "zone1-0000000102"})
+	assert.Equal(t, []string{"zone1-0000000102"}, release)
+	assert.Equal(t, []string{"zone1-0000000101"}, pending)
+
+	// Approvals that were removed from the annotation are forgotten.
+	assert.Equal(t, []string{"zone1-0000000101", "zone1-0000000102"}, approval.UsedAfter(release))
+	assert.Equal(t, []string{"zone1-0000000101"}, approval.UsedAfter(nil))
+
+	approval = NewPruneApproval(vts, ApprovePruneCellsAnnotation, true, usedApprovals, nil)
+	assert.Nil(t, approval.UsedAfter(nil))
+}
+
+func TestRecordUsedApprovals(t *testing.T) {
+	oldStatus := map[string][]string{
+		ApprovePruneTabletsAnnotation:    {"zone1-0000000101"},
+		ApprovePruneShardCellsAnnotation: {"zone2"},
+	}
+	usedApprovals := oldStatus
+
+	RecordUsedApprovals(&usedApprovals, ApprovePruneTabletsAnnotation, []string{"zone1-0000000101", "zone1-0000000102"})
+	assert.Equal(t, map[string][]string{
+		ApprovePruneTabletsAnnotation:    {"zone1-0000000101", "zone1-0000000102"},
+		ApprovePruneShardCellsAnnotation: {"zone2"},
+	}, usedApprovals)
+	// The old status isn't modified.
+	assert.Equal(t, []string{"zone1-0000000101"}, oldStatus[ApprovePruneTabletsAnnotation])
+
+	RecordUsedApprovals(&usedApprovals, ApprovePruneTabletsAnnotation, nil)
+	RecordUsedApprovals(&usedApprovals, ApprovePruneShardCellsAnnotation, nil)
+	assert.Nil(t, usedApprovals)
+}
@@ -38,25 +38,31 @@ type PruneCellsParams struct {
 	DesiredCells map[string]*planetscalev2.LockserverSpec
 	// OrphanedCells is a list of unwanted cells that could not be turned down.
 	OrphanedCells map[string]planetscalev2.OrphanStatus
+	// Approval holds back cells from being pruned until they're approved,
+	// if dry-run is enabled.
+	Approval PruneApproval
 }
 
 // PruneCells will prune cells that exist but shouldn't anymore.
-func PruneCells(ctx context.Context, p PruneCellsParams) (reconcile.Result, error) {
+// It returns the cells that are waiting for approval because dry-run is
+// enabled, and the approvals that are used up.
+func PruneCells(ctx context.Context, p PruneCellsParams) (PruneApprovalStatus, reconcile.Result, error) {
 	resultBuilder := &results.Builder{}
 
 	// Get list of cells in topo.
 	cellNames, err := p.TopoServer.GetCellInfoNames(ctx)
 	if err != nil {
 		p.Recorder.Eventf(p.EventObj, corev1.EventTypeWarning, "TopoListFailed", "failed to list cells in topology: %v", err)
-		return resultBuilder.RequeueAfter(topoRequeueDelay)
+		result, err := resultBuilder.RequeueAfter(topoRequeueDelay)
+		return PruneApprovalStatus{Pending: p.Approval.AlreadyPending, Used: p.Approval.UsedAfter(nil)}, result, err
 	}
 
 	candidates := CellsToPrune(cellNames, p.DesiredCells, p.OrphanedCells)
 
-	result, err := DeleteCells(ctx, p.TopoServer, p.Recorder, p.EventObj, candidates)
-	resultBuilder.Merge(result, err)
+	release, pending := p.Approval.Hold(p.Recorder, p.EventObj, "cell", candidates)
 
-	return resultBuilder.Result()
+	pruned, result, err := DeleteCells(ctx, p.TopoServer, p.Recorder, p.EventObj, release)
+	return PruneApprovalStatus{Pending: pending, Used: p.Approval.UsedAfter(pruned)}, result, err
 }
 
 // CellsToPrune returns a list of cell candidates for pruning, based on a provided list of cells to consider.
@@ -77,18 +83,24 @@ func CellsToPrune(cellNames []string, desiredCells map[string]*planetscalev2.Loc
 }
 
 // DeleteCells takes in a list of cell names and deletes their CellInfo records from topology.
-func DeleteCells(ctx context.Context, ts *topo.Server, recorder record.EventRecorder, eventObj runtime.Object, cellNames []string) (reconcile.Result, error) {
+// It returns the cells that are gone now.
+func DeleteCells(ctx context.Context, ts *topo.Server, recorder record.EventRecorder, eventObj runtime.Object, cellNames []string) ([]string, reconcile.Result, error) {
 	resultBuilder := &results.Builder{}
+	var deleted []string
 
 	for _, cellName := range cellNames {
 		// topo.NoNode is the error type returned if we can't find the cell when deleting. This ensures that this operation is idempotent.
 		if err := ts.DeleteCellInfo(ctx, cellName, false /* force */); err != nil && !topo.IsErrType(err, topo.NoNode) {
 			recorder.Eventf(eventObj, corev1.EventTypeWarning, "TopoCleanupFailed", "unable to remove cell %s from topology: %v", cellName, err)
 			resultBuilder.RequeueAfter(topoRequeueDelay)
-		} else if err == nil {
-			recorder.Eventf(eventObj, corev1.EventTypeNormal, "TopoCleanup", "removed unwanted cell %s from topology", cellName)
+		} else {
+			if err == nil {
+				recorder.Eventf(eventObj, corev1.EventTypeNormal, "TopoCleanup", "removed unwanted cell %s from topology", cellName)
+			}
+			deleted = append(deleted, cellName)
 		}
 	}
 
-	return resultBuilder.Result()
+	result, err := resultBuilder.Result()
+	return deleted, result, err
 }
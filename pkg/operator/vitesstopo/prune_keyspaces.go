@@ -44,10 +44,15 @@ type PruneKeyspacesParams struct {
 	Keyspaces []planetscalev2.VitessKeyspaceTemplate
 	// OrphanedKeyspaces is a list of unwanted keyspaces that could not be turned down.
 	OrphanedKeyspaces map[string]planetscalev2.OrphanStatus
+	// Approval holds back keyspaces from being pruned until they're approved,
+	// if dry-run is enabled.
+	Approval PruneApproval
 }
 
 // PruneKeyspaces will prune keyspaces that exist but shouldn't anymore.
-func PruneKeyspaces(ctx context.Context, p PruneKeyspacesParams) (reconcile.Result, error) {
+// It returns the keyspaces that are waiting for approval because dry-run is
+// enabled, and the approvals that are used up.
+func PruneKeyspaces(ctx context.Context, p PruneKeyspacesParams) (PruneApprovalStatus, reconcile.Result, error) {
 	resultBuilder := &results.Builder{}
 
 	// Make a map from keyspace name (as Vitess calls them) back to the keyspace spec.
@@ -60,15 +65,16 @@ func PruneKeyspaces(ctx context.Context, p PruneKeyspacesParams) (reconcile.Resu
 	keyspaceNames, err := p.TopoServer.GetKeyspaces(ctx)
 	if err != nil {
 		p.Recorder.Eventf(p.EventObj, corev1.EventTypeWarning, "TopoListFailed", "failed to list keyspaces in topology: %v", err)
-		return resultBuilder.RequeueAfter(topoRequeueDelay)
+		result, err := resultBuilder.RequeueAfter(topoRequeueDelay)
+		return PruneApprovalStatus{Pending: p.Approval.AlreadyPending, Used: p.Approval.UsedAfter(nil)}, result, err
 	}
 
 	candidates := KeyspacesToPrune(keyspaceNames, desiredKeyspaces, p.OrphanedKeyspaces)
 
-	result, err := DeleteKeyspaces(ctx, p.TopoServer, p.Recorder, p.EventObj, candidates)
-	resultBuilder.Merge(result, err)
+	release, pending := p.Approval.Hold(p.Recorder, p.EventObj, "keyspace", candidates)
 
-	return resultBuilder.Result()
+	pruned, result, err := DeleteKeyspaces(ctx, p.TopoServer, p.Recorder, p.EventObj, release)
+	return PruneApprovalStatus{Pending: pending, Used: p.Approval.UsedAfter(pruned)}, result, err
 }
 
 // KeyspacesToPrune returns a list of keyspace candidates for pruning, based on a provided list of keyspaces to consider.
@@ -89,12 +95,15 @@ func KeyspacesToPrune(keyspaceNames []string, desiredKeyspaces sets.String, orph
 }
 
 // DeleteKeyspaces takes in a list of keyspace names and deletes their records from topology.
-func DeleteKeyspaces(ctx context.Context, ts *topo.Server, recorder record.EventRecorder, eventObj runtime.Object, keyspaceNames []string) (reconcile.Result, error) {
+// It returns the keyspaces that are gone now.
+func DeleteKeyspaces(ctx context.Context, ts *topo.Server, recorder record.EventRecorder, eventObj runtime.Object, keyspaceNames []string) ([]string, reconcile.Result, error) {
 	resultBuilder := &results.Builder{}
+	var deleted []string
 
 	vtEnv, err := environment.VtEnvironment()
 	if err != nil {
-		return resultBuilder.Error(err)
+		result, err := resultBuilder.Error(err)
+		return nil, result, err
 	}
 	// We use the Vitess wrangler (multi-step command executor) to recursively delete the keyspace.
 	// This is equivalent to `vtctl DeleteKeyspace -recursive`.
@@ -120,8 +129,10 @@ func DeleteKeyspaces(ctx context.Context, ts *topo.Server, recorder record.Event
 			resultBuilder.RequeueAfter(topoRequeueDelay)
 		} else {
 			recorder.Eventf(eventObj, corev1.EventTypeNormal, "TopoCleanup", "removed unwanted keyspace %s from topology", name)
+			deleted = append(deleted, name)
 		}
 	}
 
-	return resultBuilder.Result()
+	result, err := resultBuilder.Result()
+	return deleted, result, err
 }
@@ -43,25 +43,31 @@ type PruneShardsParams struct {
 	DesiredShards sets.String
 	// OrphanedShards is a list of unwanted shards that could not be turned down.
 	OrphanedShards map[string]planetscalev2.OrphanStatus
+	// Approval holds back shards from being pruned until they're approved,
+	// if dry-run is enabled.
+	Approval PruneApproval
 }
 
 // PruneShards will prune shards that exist but shouldn't anymore.
-func PruneShards(ctx context.Context, p PruneShardsParams) (reconcile.Result, error) {
+// It returns the shards that are waiting for approval because dry-run is
+// enabled, and the approvals that are used up.
+func PruneShards(ctx context.Context, p PruneShardsParams) (PruneApprovalStatus, reconcile.Result, error) {
 	resultBuilder := &results.Builder{}
 
 	// Get list of shards in topo.
 	shardNames, err := p.TopoServer.GetShardNames(ctx, p.KeyspaceName)
 	if err != nil {
 		p.Recorder.Eventf(p.EventObj, corev1.EventTypeWarning, "TopoListFailed", "failed to list shards in topology: %v", err)
-		return resultBuilder.RequeueAfter(topoRequeueDelay)
+		result, err := resultBuilder.RequeueAfter(topoRequeueDelay)
+		return PruneApprovalStatus{Pending: p.Approval.AlreadyPending, Used: p.Approval.UsedAfter(nil)}, result, err
 	}
 
 	candidates := ShardsToPrune(shardNames, p.DesiredShards, p.OrphanedShards)
 
-	result, err := DeleteShards(ctx, p.TopoServer, p.Recorder, p.EventObj, p.KeyspaceName, candidates)
-	resultBuilder.Merge(result, err)
+	release, pending := p.Approval.Hold(p.Recorder, p.EventObj, "shard", candidates)
 
-	return resultBuilder.Result()
+	pruned, result, err := DeleteShards(ctx, p.TopoServer, p.Recorder, p.EventObj, p.KeyspaceName, release)
+	return PruneApprovalStatus{Pending: pending, Used: p.Approval.UsedAfter(pruned)}, result, err
 }
 
 // ShardsToPrune returns a list of shard candidates for pruning, based on a provided list of shards to consider.
@@ -81,12 +87,15 @@ func ShardsToPrune(currentShards []string, desiredShards sets.String, orphanedSh
 }
 
 // DeleteShards takes in a list of shard names and deletes their records from topology.
-func DeleteShards(ctx context.Context, ts *topo.Server, recorder record.EventRecorder, eventObj runtime.Object, keyspaceName string, shardNames []string) (reconcile.Result, error) {
+// It returns the shards that are gone now.
+func DeleteShards(ctx context.Context, ts *topo.Server, recorder record.EventRecorder, eventObj runtime.Object, keyspaceName string, shardNames []string) ([]string, reconcile.Result, error) {
 	resultBuilder := &results.Builder{}
+	var deleted []string
 
 	vtEnv, err := environment.VtEnvironment()
 	if err != nil {
-		return resultBuilder.Error(err)
+		result, err := resultBuilder.Error(err)
+		return nil, result, err
 	}
 
 	// We use the Vitess wrangler (multi-step command executor) to recursively delete the shard.
@@ -100,8 +109,10 @@ func DeleteShards(ctx context.Context, ts *topo.Server, recorder record.EventRec
 			resultBuilder.RequeueAfter(topoRequeueDelay)
 		} else {
 			recorder.Eventf(eventObj, corev1.EventTypeNormal, "TopoCleanup", "removed unwanted shard %s from topology", name)
+			deleted = append(deleted, name)
 		}
 	}
 
-	return resultBuilder.Result()
+	result, err := resultBuilder.Result()
+	return deleted, result, err
 }
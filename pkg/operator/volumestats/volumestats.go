@@ -0,0 +1,204 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package volumestats reads filesystem usage of PersistentVolumeClaims.
+
+The kubelet measures every volume mounted by the Pods it runs. It serves the
+results in its stats summary endpoint, and exports them as the
+kubelet_volume_stats_used_bytes and kubelet_volume_stats_capacity_bytes
+metrics.
+
+If a custom metrics adapter, such as the Prometheus Adapter, serves those
+metrics for the "persistentvolumeclaims" resource under custom.metrics.k8s.io,
+usage for a whole namespace can be read in one request. Otherwise, usage is read
+from the stats summary of the kubelet that runs the Pod, through the API
+server's node proxy. That requires "get" permission on "nodes/proxy".
+*/
+package volumestats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// customMetricsPath is the root of the custom metrics API.
+	customMetricsPath = "/apis/custom.metrics.k8s.io/v1beta2"
+
+	// statsSummaryPath is the kubelet stats summary endpoint, relative to
+	// the node proxy.
+	statsSummaryPath = "stats/summary"
+
+	// UsedBytesMetric is the metric that holds the bytes in use on a volume.
+	UsedBytesMetric = "kubelet_volume_stats_used_bytes"
+	// CapacityBytesMetric is the metric that holds the total size of a volume.
+	CapacityBytesMetric = "kubelet_volume_stats_capacity_bytes"
+)
+
+// Usage is the observed filesystem usage of a volume.
+type Usage struct {
+	// UsedBytes is the number of bytes in use on the filesystem.
+	UsedBytes int64
+	// CapacityBytes is the total size of the filesystem.
+	CapacityBytes int64
+}
+
+// Percent returns the fraction of the filesystem in use, as a whole-number percentage.
+func (u Usage) Percent() int64 {
+	if u.CapacityBytes <= 0 {
+		return 0
+	}
+	return u.UsedBytes * 100 / u.CapacityBytes
+}
+
+// Getter fetches volume usage for PVCs.
+type Getter interface {
+	// PVCUsage returns the usage of each PVC in the namespace that has
+	// metrics in the custom metrics API, keyed by PVC name.
+	PVCUsage(ctx context.Context, namespace string) (map[string]Usage, error)
+	// NodePVCUsage returns the usage of each PVC in the namespace that's
+	// mounted by a Pod on the given node, as reported by that node's kubelet,
+	// keyed by PVC name.
+	NodePVCUsage(ctx context.Context, nodeName, namespace string) (map[string]Usage, error)
+}
+
+// NewGetter returns a Getter that queries the custom metrics API and the
+// kubelet node proxy through the API server with the given core REST client.
+func NewGetter(client rest.Interface) Getter {
+	return &metricsGetter{client: client}
+}
+
+type metricsGetter struct {
+	client rest.Interface
+}
+
+// PVCUsage implements Getter.
+func (g *metricsGetter) PVCUsage(ctx context.Context, namespace string) (map[string]Usage, error) {
+	used, err := g.pvcMetric(ctx, namespace, UsedBytesMetric)
+	if err != nil {
+		return nil, err
+	}
+	capacity, err := g.pvcMetric(ctx, namespace, CapacityBytesMetric)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := map[string]Usage{}
+	for name, usedBytes := range used {
+		capacityBytes, ok := capacity[name]
+		if !ok {
+			continue
+		}
+		usage[name] = Usage{UsedBytes: usedBytes, CapacityBytes: capacityBytes}
+	}
+	return usage, nil
+}
+
+// pvcMetric returns the value of a metric for every PVC in the namespace.
+func (g *metricsGetter) pvcMetric(ctx context.Context, namespace, metric string) (map[string]int64, error) {
+	raw, err := g.client.Get().
+		AbsPath(customMetricsPath, "namespaces", namespace, "persistentvolumeclaims", "*", metric).
+		DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get metric %v for PVCs in namespace %v: %w", metric, namespace, err)
+	}
+	return parseMetricValueList(raw)
+}
+
+// NodePVCUsage implements Getter.
+func (g *metricsGetter) NodePVCUsage(ctx context.Context, nodeName, namespace string) (map[string]Usage, error) {
+	raw, err := g.client.Get().
+		Resource("nodes").
+		Name(nodeName).
+		SubResource("proxy").
+		Suffix(statsSummaryPath).
+		DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get stats summary from node %v: %w", nodeName, err)
+	}
+	return parseStatsSummary(raw, namespace)
+}
+
+// metricValueList is the subset of the custom metrics API MetricValueList
+// that we need.
+type metricValueList struct {
+	Items []struct {
+		DescribedObject struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"describedObject"`
+		Value resource.Quantity `json:"value"`
+	} `json:"items"`
+}
+
+func parseMetricValueList(raw []byte) (map[string]int64, error) {
+	list := &metricValueList{}
+	if err := json.Unmarshal(raw, list); err != nil {
+		return nil, fmt.Errorf("can't parse custom metrics response: %w", err)
+	}
+
+	values := map[string]int64{}
+	for _, item := range list.Items {
+		if item.DescribedObject.Kind != "PersistentVolumeClaim" {
+			continue
+		}
+		values[item.DescribedObject.Name] = item.Value.Value()
+	}
+	return values, nil
+}
+
+// statsSummary is the subset of the kubelet stats summary that we need.
+type statsSummary struct {
+	Pods []struct {
+		Volumes []struct {
+			UsedBytes     *uint64 `json:"usedBytes"`
+			CapacityBytes *uint64 `json:"capacityBytes"`
+			PVCRef        *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+func parseStatsSummary(raw []byte, namespace string) (map[string]Usage, error) {
+	summary := &statsSummary{}
+	if err := json.Unmarshal(raw, summary); err != nil {
+		return nil, fmt.Errorf("can't parse kubelet stats summary: %w", err)
+	}
+
+	usage := map[string]Usage{}
+	for _, pod := range summary.Pods {
+		for _, volume := range pod.Volumes {
+			if volume.PVCRef == nil || volume.PVCRef.Namespace != namespace {
+				continue
+			}
+			if volume.UsedBytes == nil || volume.CapacityBytes == nil {
+				continue
+			}
+			usage[volume.PVCRef.Name] = Usage{
+				UsedBytes:     int64(*volume.UsedBytes),
+				CapacityBytes: int64(*volume.CapacityBytes),
+			}
+		}
+	}
+	return usage, nil
+}
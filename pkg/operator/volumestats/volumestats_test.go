@@ -0,0 +1,100 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volumestats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetricValueList(t *testing.T) {
+	raw := []byte(`{
+  "kind": "MetricValueList",
+  "apiVersion": "custom.metrics.k8s.io/v1beta2",
+  "metadata": {},
+  "items": [
+    {
+      "describedObject": {"kind": "PersistentVolumeClaim", "namespace": "ns", "name": "tablet-1", "apiVersion": "/v1"},
+      "metric": {"name": "kubelet_volume_stats_used_bytes", "selector": null},
+      "timestamp": "2026-01-01T00:00:00Z",
+      "value": "750"
+    },
+    {
+      "describedObject": {"kind": "PersistentVolumeClaim", "namespace": "ns", "name": "tablet-2", "apiVersion": "/v1"},
+      "metric": {"name": "kubelet_volume_stats_used_bytes", "selector": null},
+      "timestamp": "2026-01-01T00:00:00Z",
+      "value": "2Gi"
+    },
+    {
+      "describedObject": {"kind": "Pod", "namespace": "ns", "name": "tablet-3", "apiVersion": "/v1"},
+      "metric": {"name": "kubelet_volume_stats_used_bytes", "selector": null},
+      "timestamp": "2026-01-01T00:00:00Z",
+      "value": "5"
+    }
+  ]
+}`)
+
+	values, err := parseMetricValueList(raw)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{
+		"tablet-1": 750,
+		"tablet-2": 2 << 30,
+	}, values)
+}
+
+func TestParseStatsSummary(t *testing.T) {
+	raw := []byte(`{
+  "node": {"nodeName": "node-1"},
+  "pods": [
+    {
+      "podRef": {"name": "tablet-1", "namespace": "ns"},
+      "volume": [
+        {"name": "vt-data", "usedBytes": 750, "capacityBytes": 1000, "pvcRef": {"name": "tablet-1", "namespace": "ns"}},
+        {"name": "vt-root", "usedBytes": 5, "capacityBytes": 10}
+      ]
+    },
+    {
+      "podRef": {"name": "tablet-2", "namespace": "ns"},
+      "volume": [
+        {"name": "vt-data", "pvcRef": {"name": "tablet-2", "namespace": "ns"}}
+      ]
+    },
+    {
+      "podRef": {"name": "tablet-3", "namespace": "other"},
+      "volume": [
+        {"name": "vt-data", "usedBytes": 1, "capacityBytes": 2, "pvcRef": {"name": "tablet-3", "namespace": "other"}}
+      ]
+    }
+  ]
+}`)
+
+	usage, err := parseStatsSummary(raw, "ns")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Usage{
+		"tablet-1": {UsedBytes: 750, CapacityBytes: 1000},
+	}, usage)
+}
+
+func TestPercent(t *testing.T) {
+	assert.Equal(t, int64(75), Usage{UsedBytes: 750, CapacityBytes: 1000}.Percent())
+}
+
+func TestPercentZeroCapacity(t *testing.T) {
+	assert.Equal(t, int64(0), Usage{UsedBytes: 10}.Percent())
+}
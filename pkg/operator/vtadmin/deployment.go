@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/internaltls"
 	"planetscale.dev/vitess-operator/pkg/operator/k8s"
 	"planetscale.dev/vitess-operator/pkg/operator/names"
 	"planetscale.dev/vitess-operator/pkg/operator/secrets"
@@ -107,6 +108,7 @@ type Spec struct {
 	Annotations       map[string]string
 	ExtraLabels       map[string]string
 	Tolerations       []corev1.Toleration
+	InternalTLS       *planetscalev2.VitessInternalTLSSpec
 }
 
 // NewDeployment creates a new Deployment object for vtadmin.
@@ -176,6 +178,7 @@ func UpdateDeployment(obj *appsv1.Deployment, spec *Spec) {
 	obj.Spec.Template.Spec.ServiceAccountName = planetscalev2.DefaultVitessServiceAccount
 	obj.Spec.Template.Spec.Tolerations = spec.Tolerations
 	update.Volumes(&obj.Spec.Template.Spec.Volumes, spec.ExtraVolumes)
+	update.Volumes(&obj.Spec.Template.Spec.Volumes, internaltls.Volumes(spec.InternalTLS))
 
 	securityContext := &corev1.SecurityContext{}
 	if planetscalev2.DefaultVitessRunAsUser >= 0 {
@@ -222,6 +225,7 @@ func UpdateDeployment(obj *appsv1.Deployment, spec *Spec) {
 	update.ResourceRequirements(&vtadminAPIContainer.Resources, &spec.APIResources)
 	updateRbac(spec, apiFlags, vtadminAPIContainer, &obj.Spec.Template.Spec)
 	updateDiscoveryAndClusterConfig(spec, apiFlags, vtadminAPIContainer, &obj.Spec.Template.Spec)
+	vtadminAPIContainer.VolumeMounts = append(vtadminAPIContainer.VolumeMounts, internaltls.VolumeMounts(spec.InternalTLS)...)
 	vtadminAPIContainer.Args = apiFlags.FormatArgs()
 
 	vtadminWebContainer := &corev1.Container{
@@ -292,7 +296,7 @@ func UpdateDeployment(obj *appsv1.Deployment, spec *Spec) {
 }
 
 func (spec *Spec) apiFlags() vitess.Flags {
-	return vitess.Flags{
+	flags := vitess.Flags{
 		"addr":         fmt.Sprintf(":%d", planetscalev2.DefaultAPIPort),
 		"http-origin":  "*",
 		"tracer":       "opentracing-jaeger",
@@ -302,6 +306,10 @@ func (spec *Spec) apiFlags() vitess.Flags {
 		"logtostderr":     true,
 		"alsologtostderr": true,
 	}
+	// vtadmin calls both vtctld and vtgate, which require a client
+	// certificate when internal TLS is enabled.
+	flags = flags.Merge(internaltls.VtctldClientFlags(spec.InternalTLS))
+	return flags.Merge(internaltls.VtgateClientFlags(spec.InternalTLS))
 }
 
 // updateRbac updates the rbac flags and creates the mount for rbac configuration if specified
@@ -28,7 +28,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/internaltls"
 	"planetscale.dev/vitess-operator/pkg/operator/k8s"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
 	"planetscale.dev/vitess-operator/pkg/operator/names"
 	"planetscale.dev/vitess-operator/pkg/operator/update"
 	"planetscale.dev/vitess-operator/pkg/operator/vitess"
@@ -72,6 +74,7 @@ type Spec struct {
 	Tolerations       []corev1.Toleration
 	BackupLocation    *planetscalev2.VitessBackupLocation
 	BackupEngine      planetscalev2.VitessBackupEngine
+	InternalTLS       *planetscalev2.VitessInternalTLSSpec
 }
 
 // NewDeployment creates a new Deployment object for vtctld.
@@ -149,6 +152,10 @@ func UpdateDeployment(obj *appsv1.Deployment, spec *Spec, mysqldImage string) {
 		volumeMounts = append(volumeMounts, vitessbackup.StorageVolumeMounts(spec.BackupLocation)...)
 		env = append(env, vitessbackup.StorageEnvVars(spec.BackupLocation)...)
 	}
+	volumes = append(volumes, internaltls.Volumes(spec.InternalTLS)...)
+	volumeMounts = append(volumeMounts, internaltls.VolumeMounts(spec.InternalTLS)...)
+	volumes = append(volumes, lockserver.ClientTLSVolumes(spec.GlobalLockserver)...)
+	volumeMounts = append(volumeMounts, lockserver.ClientTLSVolumeMounts(spec.GlobalLockserver)...)
 	update.Volumes(&obj.Spec.Template.Spec.Volumes, volumes)
 
 	securityContext := &corev1.SecurityContext{}
@@ -245,6 +252,10 @@ func (spec *Spec) flags() vitess.Flags {
 
 		"logtostderr": true,
 	}
+	flags = flags.Merge(internaltls.ServerFlags(spec.InternalTLS))
+	flags = flags.Merge(internaltls.TabletClientFlags(spec.InternalTLS))
+	flags = flags.Merge(internaltls.TabletManagerClientFlags(spec.InternalTLS))
+	flags = flags.Merge(lockserver.ClientTLSFlags(spec.GlobalLockserver))
 	if spec.BackupLocation == nil {
 		return flags
 	}
@@ -32,6 +32,11 @@ func ServiceName(clusterName string) string {
 	return names.JoinWithConstraints(names.ServiceConstraints, clusterName, planetscalev2.VtctldComponentName)
 }
 
+// PDBName returns the name of the vtctld PodDisruptionBudget for a cluster.
+func PDBName(clusterName string) string {
+	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, planetscalev2.VtctldComponentName)
+}
+
 // NewService creates a new Service object for vtctld.
 func NewService(key client.ObjectKey, labels map[string]string) *corev1.Service {
 	// Fill in the immutable parts.
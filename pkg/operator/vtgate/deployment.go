@@ -28,7 +28,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/internaltls"
 	"planetscale.dev/vitess-operator/pkg/operator/k8s"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
 	"planetscale.dev/vitess-operator/pkg/operator/names"
 	"planetscale.dev/vitess-operator/pkg/operator/secrets"
 	"planetscale.dev/vitess-operator/pkg/operator/update"
@@ -50,7 +52,12 @@ const (
 
 	grpcMaxMessageSize = 64 * 1024 * 1024
 
+	// defaultStaticAuthReloadInterval is used if the API-level default for the
+	// static auth reload interval hasn't been filled in.
+	defaultStaticAuthReloadInterval = "30s"
+
 	staticAuthDirName      = "vtgate-static-auth"
+	ldapAuthDirName        = "vtgate-ldap-auth"
 	tlsCertDirName         = "vtgate-tls-cert"
 	tlsKeyDirName          = "vtgate-tls-key"
 	tlsClientCACertDirName = "vtgate-tls-ca-cert"
@@ -61,6 +68,16 @@ func DeploymentName(clusterName, cellName string) string {
 	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, cellName, planetscalev2.VtgateComponentName)
 }
 
+// PDBName returns the name of the PodDisruptionBudget for vtgates in a given cell.
+func PDBName(clusterName, cellName string) string {
+	return DeploymentName(clusterName, cellName)
+}
+
+// PoolDeploymentName returns the name of the vtgate Deployment for a named pool in a given cell.
+func PoolDeploymentName(clusterName, cellName, poolName string) string {
+	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, cellName, planetscalev2.VtgateComponentName, poolName)
+}
+
 // Spec specifies all the internal parameters needed to deploy vtgate,
 // as opposed to the API type planetscalev2.VitessCellGatewaySpec, which is the public API.
 type Spec struct {
@@ -85,6 +102,7 @@ type Spec struct {
 	Lifecycle                     corev1.Lifecycle
 	TerminationGracePeriodSeconds *int64
 	Strategy                      appsv1.DeploymentStrategy
+	Drain                         *planetscalev2.VitessGatewayDrainSpec
 }
 
 // NewDeployment creates a new Deployment object for vtgate.
@@ -125,6 +143,9 @@ func UpdateDeployment(obj *appsv1.Deployment, spec *Spec, mysqldImage string) {
 	obj.Spec.Replicas = ptr.To(spec.Replicas)
 	obj.Spec.RevisionHistoryLimit = ptr.To(int32(0))
 	obj.Spec.Strategy = spec.Strategy
+	if spec.Drain != nil && spec.Strategy == (appsv1.DeploymentStrategy{}) {
+		obj.Spec.Strategy = drainStrategy()
+	}
 
 	// Reset the list of volumes in the template so we remove old ones.
 	obj.Spec.Template.Spec.Volumes = nil
@@ -138,6 +159,8 @@ func UpdateDeployment(obj *appsv1.Deployment, spec *Spec, mysqldImage string) {
 
 	if spec.TerminationGracePeriodSeconds != nil {
 		obj.Spec.Template.Spec.TerminationGracePeriodSeconds = spec.TerminationGracePeriodSeconds
+	} else if spec.Drain != nil {
+		obj.Spec.Template.Spec.TerminationGracePeriodSeconds = ptr.To(drainGracePeriodSeconds(spec.Drain))
 	}
 
 	if spec.Affinity != nil {
@@ -222,6 +245,12 @@ func UpdateDeployment(obj *appsv1.Deployment, spec *Spec, mysqldImage string) {
 	// Make a copy of Resources since it contains pointers.
 	update.ResourceRequirements(&vtgateContainer.Resources, &spec.Resources)
 
+	// Set the container lifecycle configuration if provided. Otherwise, skip
+	// to avoid restarting existing pods due to an empty 'lifecycle' field.
+	if spec.Lifecycle != (corev1.Lifecycle{}) {
+		vtgateContainer.Lifecycle = &spec.Lifecycle
+	}
+
 	// Get all the flags that don't need any logic.
 	flags := spec.baseFlags()
 
@@ -229,6 +258,9 @@ func UpdateDeployment(obj *appsv1.Deployment, spec *Spec, mysqldImage string) {
 	mysql.UpdateMySQLServerVersion(flags, mysqldImage)
 	updateAuth(spec, flags, vtgateContainer, &obj.Spec.Template.Spec)
 	updateTransport(spec, flags, vtgateContainer, &obj.Spec.Template.Spec)
+	updateInternalTLS(spec, flags, vtgateContainer, &obj.Spec.Template.Spec)
+	updateTopoTLS(spec, flags, vtgateContainer, &obj.Spec.Template.Spec)
+	updateDrain(spec, flags, vtgateContainer, &obj.Spec.Template.Spec)
 	update.Volumes(&obj.Spec.Template.Spec.Volumes, spec.ExtraVolumes)
 
 	// Apply user-provided overrides last so they take precedence.
@@ -241,12 +273,6 @@ func UpdateDeployment(obj *appsv1.Deployment, spec *Spec, mysqldImage string) {
 	// Write out the final flags list.
 	vtgateContainer.Args = flags.FormatArgs()
 
-	// Set the container lifecycle configuration if provided. Otherwise, skip
-	// to avoid restarting existing pods due to an empty 'lifecycle' field.
-	if spec.Lifecycle != (corev1.Lifecycle{}) {
-		vtgateContainer.Lifecycle = &spec.Lifecycle
-	}
-
 	update.PodTemplateContainers(&obj.Spec.Template.Spec.InitContainers, spec.InitContainers)
 	update.PodTemplateContainers(&obj.Spec.Template.Spec.Containers, spec.SidecarContainers)
 
@@ -295,9 +321,17 @@ func updateAuth(spec *Spec, flags vitess.Flags, container *corev1.Container, pod
 		staticAuthFile := secrets.Mount(spec.Authentication.Static.Secret, staticAuthDirName)
 
 		// Get usernames and passwords from a static file, mounted from a Secret.
+		// The file is reloaded periodically, so we don't need to restart vtgate
+		// when credentials are rotated. Note that this relies on the Secret
+		// volume being mounted as a directory (not with subPath), so the
+		// kubelet can update the file in place.
+		reloadInterval := defaultStaticAuthReloadInterval
+		if spec.Authentication.Static.ReloadInterval != nil {
+			reloadInterval = spec.Authentication.Static.ReloadInterval.Duration.String()
+		}
 		flags["mysql_auth_server_impl"] = "static"
 		flags["mysql_auth_server_static_file"] = staticAuthFile.FilePath()
-		flags["mysql_auth_static_reload_interval"] = "30s"
+		flags["mysql_auth_static_reload_interval"] = reloadInterval
 
 		// Add the volume to the Pod, if needed.
 		update.Volumes(&podSpec.Volumes, staticAuthFile.PodVolumes())
@@ -305,6 +339,45 @@ func updateAuth(spec *Spec, flags vitess.Flags, container *corev1.Container, pod
 		// Mount the volume in the Container.
 		container.VolumeMounts = append(container.VolumeMounts, staticAuthFile.ContainerVolumeMount())
 	}
+
+	if spec.Authentication.LDAP != nil && spec.Authentication.LDAP.ConfigSecret != nil {
+		ldapConfigFile := secrets.Mount(spec.Authentication.LDAP.ConfigSecret, ldapAuthDirName)
+
+		// Authenticate users against an LDAP server, configured by a file
+		// mounted from a Secret.
+		flags["mysql_auth_server_impl"] = "ldap"
+		flags["mysql_ldap_auth_config_file"] = ldapConfigFile.FilePath()
+		if spec.Authentication.LDAP.Method != "" {
+			flags["mysql_ldap_auth_method"] = spec.Authentication.LDAP.Method
+		}
+
+		update.Volumes(&podSpec.Volumes, ldapConfigFile.PodVolumes())
+		container.VolumeMounts = append(container.VolumeMounts, ldapConfigFile.ContainerVolumeMount())
+	}
+
+	if spec.Authentication.ClientCert != nil && !ClientCertAuthMissingCA(spec) {
+		// Use the CN of the verified client certificate as the username.
+		// The client CA is configured along with the rest of TLS in updateTransport.
+		flags["mysql_auth_server_impl"] = "clientcert"
+		if spec.Authentication.ClientCert.Method != "" {
+			flags["mysql_clientcert_auth_method"] = spec.Authentication.ClientCert.Method
+		}
+	}
+}
+
+// ClientCertAuthMissingCA returns whether the spec asks for client certificate
+// authentication without a CA to verify client certificates with. Such a spec
+// must not be rolled out, since vtgate would have no way to check who a client
+// is.
+func ClientCertAuthMissingCA(spec *Spec) bool {
+	if spec.Authentication == nil || spec.Authentication.ClientCert == nil {
+		return false
+	}
+	tls := spec.SecureTransport
+	if tls == nil {
+		return true
+	}
+	return tls.TLS == nil || tls.TLS.CertSecret == nil || tls.TLS.KeySecret == nil || tls.TLS.ClientCACertSecret == nil
 }
 
 func updateTransport(spec *Spec, flags vitess.Flags, container *corev1.Container, podSpec *corev1.PodSpec) {
@@ -350,3 +423,28 @@ func updateTransport(spec *Spec, flags vitess.Flags, container *corev1.Container
 		}
 	}
 }
+
+// updateInternalTLS configures TLS for gRPC to and from vtgate.
+// This overrides the grpc_* flags set by updateTransport, since the gRPC
+// server must use the same CA as the rest of the cluster.
+func updateInternalTLS(spec *Spec, flags vitess.Flags, container *corev1.Container, podSpec *corev1.PodSpec) {
+	tls := spec.Cell.InternalTLS
+	if !internaltls.Enabled(tls) {
+		return
+	}
+
+	flags.Merge(internaltls.ServerFlags(tls))
+	flags.Merge(internaltls.TabletClientFlags(tls))
+
+	update.Volumes(&podSpec.Volumes, internaltls.Volumes(tls))
+	container.VolumeMounts = append(container.VolumeMounts, internaltls.VolumeMounts(tls)...)
+}
+
+// updateTopoTLS configures TLS for connections to the lockserver.
+func updateTopoTLS(spec *Spec, flags vitess.Flags, container *corev1.Container, podSpec *corev1.PodSpec) {
+	params := spec.Cell.GlobalLockserver
+	flags.Merge(lockserver.ClientTLSFlags(&params))
+
+	update.Volumes(&podSpec.Volumes, lockserver.ClientTLSVolumes(&params))
+	container.VolumeMounts = append(container.VolumeMounts, lockserver.ClientTLSVolumeMounts(&params)...)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"slices"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+func vtgateArgs(spec *Spec) []string {
+	obj := NewDeployment(client.ObjectKey{Namespace: "ns", Name: "vtgate"}, spec, "")
+	return obj.Spec.Template.Spec.Containers[0].Args
+}
+
+func TestLDAPAuthFlags(t *testing.T) {
+	spec := &Spec{
+		Cell: &planetscalev2.VitessCellSpec{},
+		Authentication: &planetscalev2.VitessGatewayAuthentication{
+			LDAP: &planetscalev2.VitessGatewayLDAPAuthentication{
+				ConfigSecret: &planetscalev2.SecretSource{Name: "ldap", Key: "config.json"},
+				Method:       "dialog",
+			},
+		},
+	}
+	args := vtgateArgs(spec)
+	for _, want := range []string{
+		"--mysql_auth_server_impl=ldap",
+		"--mysql_ldap_auth_config_file=/vt/secrets/vtgate-ldap-auth/config.json",
+		"--mysql_ldap_auth_method=dialog",
+	} {
+		if !slices.Contains(args, want) {
+			t.Errorf("Args = %v; want %v", args, want)
+		}
+	}
+}
+
+func TestClientCertAuthFlags(t *testing.T) {
+	spec := &Spec{
+		Cell: &planetscalev2.VitessCellSpec{},
+		Authentication: &planetscalev2.VitessGatewayAuthentication{
+			ClientCert: &planetscalev2.VitessGatewayClientCertAuthentication{
+				Method: "dialog",
+			},
+		},
+		SecureTransport: &planetscalev2.VitessGatewaySecureTransport{
+			TLS: &planetscalev2.VitessGatewayTLSSecureTransport{
+				CertSecret:         &planetscalev2.SecretSource{Name: "tls", Key: "tls.crt"},
+				KeySecret:          &planetscalev2.SecretSource{Name: "tls", Key: "tls.key"},
+				ClientCACertSecret: &planetscalev2.SecretSource{Name: "client-ca", Key: "ca.crt"},
+			},
+		},
+	}
+	if ClientCertAuthMissingCA(spec) {
+		t.Errorf("ClientCertAuthMissingCA() = true; want false")
+	}
+	args := vtgateArgs(spec)
+	for _, want := range []string{
+		"--mysql_auth_server_impl=clientcert",
+		"--mysql_clientcert_auth_method=dialog",
+		"--mysql_server_ssl_ca=/vt/secrets/vtgate-tls-ca-cert/ca.crt",
+	} {
+		if !slices.Contains(args, want) {
+			t.Errorf("Args = %v; want %v", args, want)
+		}
+	}
+
+	// Without a CA to verify clients with, vtgate must not be switched to
+	// client certificate authentication.
+	spec.SecureTransport.TLS.ClientCACertSecret = nil
+	if !ClientCertAuthMissingCA(spec) {
+		t.Errorf("ClientCertAuthMissingCA() = false; want true")
+	}
+	for _, arg := range vtgateArgs(spec) {
+		if arg == "--mysql_auth_server_impl=clientcert" || arg == "--mysql_clientcert_auth_method=dialog" {
+			t.Errorf("Args contain %v without a client CA", arg)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+	"planetscale.dev/vitess-operator/pkg/operator/vitess"
+)
+
+const (
+	// drainShutdownMargin is how much longer than the drain itself we give
+	// vtgate before the kubelet kills it, so it can finish shutting down
+	// after the last client disconnects.
+	drainShutdownMargin = 10 * time.Second
+
+	// drainVolumeName is the name of the volume that holds the drain marker.
+	drainVolumeName = "vtgate-drain"
+	// drainDirPath is where the drain volume is mounted.
+	drainDirPath = "/vt/drain"
+	// drainMarkerPath is created by the preStop hook to fail readiness.
+	drainMarkerPath = drainDirPath + "/draining"
+	// drainReadinessPeriodSeconds is how often readiness is probed while
+	// draining is enabled, so the marker is noticed within the preStop delay.
+	drainReadinessPeriodSeconds = 2
+)
+
+// drainReadinessScript fails once the preStop hook has created the drain
+// marker. Otherwise, it passes if vtgate's health endpoint returns 200, just
+// like the HTTP readiness probe it replaces.
+var drainReadinessScript = fmt.Sprintf(`[[ ! -e %s ]] || exit 1
+exec 3<>/dev/tcp/127.0.0.1/%d || exit 1
+printf 'GET /debug/health HTTP/1.0\r\n\r\n' >&3
+read -r _ status _ <&3
+[[ "$status" == 200 ]]`, drainMarkerPath, planetscalev2.DefaultWebPort)
+
+// updateDrain makes vtgate drain client connections when it's told to shut
+// down. Before that, a preStop hook fails vtgate's readiness and gives load
+// balancers time to stop sending new connections.
+func updateDrain(spec *Spec, flags vitess.Flags, container *corev1.Container, podSpec *corev1.PodSpec) {
+	if spec.Drain == nil {
+		return
+	}
+
+	// On SIGTERM, stop accepting MySQL connections and wait for the
+	// connected clients to go away, up to the timeout.
+	flags["mysql_server_drain_onterm"] = true
+	flags["onterm_timeout"] = spec.Drain.Timeout.Duration.String()
+
+	// Respect a preStop hook the user set up themselves.
+	if spec.Lifecycle.PreStop != nil {
+		return
+	}
+	lifecycle := spec.Lifecycle.DeepCopy()
+	lifecycle.PreStop = &corev1.LifecycleHandler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"bash", "-c", fmt.Sprintf("touch %s && sleep %d", drainMarkerPath, int64(spec.Drain.PreStopDelay.Duration/time.Second))},
+		},
+	}
+	container.Lifecycle = lifecycle
+
+	// The kubelet keeps probing readiness while the Pod shuts down, so the
+	// marker takes vtgate out of anything that routes by readiness.
+	container.ReadinessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"bash", "-c", drainReadinessScript},
+			},
+		},
+		PeriodSeconds: drainReadinessPeriodSeconds,
+	}
+	update.Volumes(&podSpec.Volumes, []corev1.Volume{
+		{
+			Name: drainVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	})
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      drainVolumeName,
+		MountPath: drainDirPath,
+	})
+}
+
+// drainGracePeriodSeconds returns a termination grace period that's long
+// enough for the preStop delay, the drain, and the final shutdown.
+func drainGracePeriodSeconds(drain *planetscalev2.VitessGatewayDrainSpec) int64 {
+	total := drain.PreStopDelay.Duration + drain.Timeout.Duration + drainShutdownMargin
+	return int64(total.Round(time.Second) / time.Second)
+}
+
+// drainStrategy returns a rolling update strategy that brings up new vtgates
+// before old ones start to drain, so draining never reduces capacity.
+func drainStrategy() appsv1.DeploymentStrategy {
+	maxUnavailable := intstr.FromInt32(0)
+	maxSurge := intstr.FromString("25%")
+	return appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxUnavailable: &maxUnavailable,
+			MaxSurge:       &maxSurge,
+		},
+	}
+}
+
+// DrainingReplicas returns how many Pods of a vtgate Deployment are shutting
+// down, which includes those that are still draining client connections.
+func DrainingReplicas(obj *appsv1.Deployment) int32 {
+	if obj.Status.TerminatingReplicas == nil {
+		return 0
+	}
+	return *obj.Status.TerminatingReplicas
+}
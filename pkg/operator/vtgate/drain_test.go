@@ -0,0 +1,92 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+func TestDrain(t *testing.T) {
+	spec := &Spec{
+		Cell:           &planetscalev2.VitessCellSpec{},
+		Authentication: &planetscalev2.VitessGatewayAuthentication{},
+		Drain: &planetscalev2.VitessGatewayDrainSpec{
+			PreStopDelay: &metav1.Duration{Duration: 5 * time.Second},
+			Timeout:      &metav1.Duration{Duration: time.Minute},
+		},
+	}
+	obj := NewDeployment(client.ObjectKey{Namespace: "ns", Name: "vtgate"}, spec, "")
+	podSpec := &obj.Spec.Template.Spec
+
+	if got := *podSpec.TerminationGracePeriodSeconds; got != 75 {
+		t.Errorf("TerminationGracePeriodSeconds = %v; want 75", got)
+	}
+	if got := obj.Spec.Strategy.RollingUpdate; got == nil || got.MaxUnavailable.IntValue() != 0 {
+		t.Errorf("RollingUpdate = %v; want maxUnavailable 0", got)
+	}
+	container := podSpec.Containers[0]
+	if got := container.Lifecycle; got == nil || got.PreStop == nil || got.PreStop.Exec == nil ||
+		!strings.Contains(strings.Join(got.PreStop.Exec.Command, " "), "touch "+drainMarkerPath+" && sleep 5") {
+		t.Errorf("Lifecycle = %v; want a preStop hook that marks vtgate as draining and sleeps 5s", got)
+	}
+	// Readiness fails once the preStop hook has marked vtgate as draining.
+	if got := container.ReadinessProbe; got == nil || got.Exec == nil || !strings.Contains(strings.Join(got.Exec.Command, " "), drainMarkerPath) {
+		t.Errorf("ReadinessProbe = %v; want a probe that checks the drain marker", got)
+	}
+	mounted := false
+	for _, mount := range container.VolumeMounts {
+		if mount.Name == drainVolumeName && mount.MountPath == drainDirPath {
+			mounted = true
+		}
+	}
+	if !mounted {
+		t.Errorf("VolumeMounts = %v; want the drain volume at %v", container.VolumeMounts, drainDirPath)
+	}
+	args := strings.Join(container.Args, " ")
+	for _, want := range []string{"--mysql_server_drain_onterm=true", "--onterm_timeout=1m0s"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("Args = %v; want %v", container.Args, want)
+		}
+	}
+
+	// A user-supplied preStop hook and grace period win.
+	spec.Lifecycle = corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"/drain.sh"}}},
+	}
+	spec.TerminationGracePeriodSeconds = ptr.To(int64(600))
+	UpdateDeployment(obj, spec, "")
+	podSpec = &obj.Spec.Template.Spec
+	if got := podSpec.Containers[0].Lifecycle.PreStop; got.Exec == nil || got.Exec.Command[0] != "/drain.sh" {
+		t.Errorf("PreStop = %v; want the user's exec hook", got)
+	}
+	// Nothing would create the drain marker, so keep the usual readiness probe.
+	if got := podSpec.Containers[0].ReadinessProbe; got.HTTPGet == nil {
+		t.Errorf("ReadinessProbe = %v; want the HTTP health check", got)
+	}
+	if got := *podSpec.TerminationGracePeriodSeconds; got != 600 {
+		t.Errorf("TerminationGracePeriodSeconds = %v; want 600", got)
+	}
+}
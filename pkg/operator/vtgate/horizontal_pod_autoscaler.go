@@ -18,10 +18,12 @@ package vtgate
 
 import (
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"planetscale.dev/vitess-operator/pkg/operator/update"
+	"planetscale.dev/vitess-operator/pkg/operator/vtgatemetrics"
 )
 
 // HpaSpec specifies all the internal parameters needed to create a HorizontalPodAutoscaler
@@ -32,6 +34,16 @@ type HpaSpec struct {
 	MaxReplicas int32
 	Behavior    *autoscalingv2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
 	Metrics     []autoscalingv2.MetricSpec                     `json:"metrics,omitempty"`
+
+	// TargetQPSPerPod and TargetConnectionsPerPod, if set, add external
+	// metrics served by the operator, scoped to Pods matching PodLabels.
+	TargetQPSPerPod         *int32
+	TargetConnectionsPerPod *int32
+	PodLabels               map[string]string
+
+	// ScaleTargetRef is the object to scale. If nil, the VitessCell with the
+	// same name as the HorizontalPodAutoscaler is scaled.
+	ScaleTargetRef *autoscalingv2.CrossVersionObjectReference
 }
 
 // NewHorizontalPodAutoscaler creates a new HorizontalPodAutoscaler object for vtgate.
@@ -50,6 +62,9 @@ func NewHorizontalPodAutoscaler(key client.ObjectKey, spec *HpaSpec) *autoscalin
 			},
 		},
 	}
+	if spec.ScaleTargetRef != nil {
+		obj.Spec.ScaleTargetRef = *spec.ScaleTargetRef
+	}
 	// Set everything else.
 	UpdateHorizontalPodAutoscaler(obj, spec)
 	return obj
@@ -63,6 +78,42 @@ func UpdateHorizontalPodAutoscaler(obj *autoscalingv2.HorizontalPodAutoscaler, s
 	// Set the specs for the HorizontalPodAutoscaler object.
 	obj.Spec.MinReplicas = spec.MinReplicas
 	obj.Spec.MaxReplicas = spec.MaxReplicas
-	obj.Spec.Metrics = spec.Metrics
+	obj.Spec.Metrics = hpaMetrics(spec)
 	obj.Spec.Behavior = spec.Behavior
 }
+
+// hpaMetrics returns the user-provided metrics followed by any Vitess-native
+// metrics requested in the spec.
+func hpaMetrics(spec *HpaSpec) []autoscalingv2.MetricSpec {
+	if spec.TargetQPSPerPod == nil && spec.TargetConnectionsPerPod == nil {
+		return spec.Metrics
+	}
+
+	metrics := make([]autoscalingv2.MetricSpec, 0, len(spec.Metrics)+2)
+	metrics = append(metrics, spec.Metrics...)
+	if spec.TargetQPSPerPod != nil {
+		metrics = append(metrics, externalAverageMetric(vtgatemetrics.QueriesPerSecondMetric, spec.PodLabels, *spec.TargetQPSPerPod))
+	}
+	if spec.TargetConnectionsPerPod != nil {
+		metrics = append(metrics, externalAverageMetric(vtgatemetrics.ConnectionsMetric, spec.PodLabels, *spec.TargetConnectionsPerPod))
+	}
+	return metrics
+}
+
+// externalAverageMetric returns an External metric whose total across all
+// matching Pods is divided by the number of replicas and compared to target.
+func externalAverageMetric(name string, podLabels map[string]string, target int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ExternalMetricSourceType,
+		External: &autoscalingv2.ExternalMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{
+				Name:     name,
+				Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			},
+			Target: autoscalingv2.MetricTarget{
+				Type:         autoscalingv2.AverageValueMetricType,
+				AverageValue: resource.NewQuantity(int64(target), resource.DecimalSI),
+			},
+		},
+	}
+}
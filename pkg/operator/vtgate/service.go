@@ -17,6 +17,8 @@ limitations under the License.
 package vtgate
 
 import (
+	"maps"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -27,16 +29,54 @@ import (
 	"planetscale.dev/vitess-operator/pkg/operator/update"
 )
 
+const (
+	// externalComponentName is used in the names of objects that expose
+	// vtgate outside the Kubernetes cluster. It can't collide with a pool
+	// name, since names.Join hashes each part separately.
+	externalComponentName = planetscalev2.VtgateComponentName + "-external"
+
+	// zoneComponentName is used in the names of per-zone vtgate Services.
+	zoneComponentName = planetscalev2.VtgateComponentName + "-zone"
+
+	// topologyModeAnnotation enables topology-aware hints on a Service.
+	topologyModeAnnotation = "service.kubernetes.io/topology-mode"
+
+	// externalDNSHostnameAnnotation is the annotation that external-dns
+	// reads to create DNS records for a Service.
+	externalDNSHostnameAnnotation = "external-dns.alpha.kubernetes.io/hostname"
+)
+
 // ServiceName returns the name of the vtgate Service for a cell.
 func ServiceName(clusterName, cellName string) string {
 	return names.JoinWithConstraints(names.ServiceConstraints, clusterName, cellName, planetscalev2.VtgateComponentName)
 }
 
+// PoolServiceName returns the name of the vtgate Service for a named pool in a cell.
+func PoolServiceName(clusterName, cellName, poolName string) string {
+	return names.JoinWithConstraints(names.ServiceConstraints, clusterName, cellName, planetscalev2.VtgateComponentName, poolName)
+}
+
 // ClusterServiceName returns the name of the vtgate Service for a cluster.
 func ClusterServiceName(clusterName string) string {
 	return names.JoinWithConstraints(names.ServiceConstraints, clusterName, planetscalev2.VtgateComponentName)
 }
 
+// ExternalServiceName returns the name of the external vtgate Service for a cell.
+func ExternalServiceName(clusterName, cellName string) string {
+	return names.JoinWithConstraints(names.ServiceConstraints, clusterName, cellName, externalComponentName)
+}
+
+// ClusterExternalServiceName returns the name of the external vtgate Service
+// that spans all cells in a cluster.
+func ClusterExternalServiceName(clusterName string) string {
+	return names.JoinWithConstraints(names.ServiceConstraints, clusterName, externalComponentName)
+}
+
+// ZoneServiceName returns the name of the headless vtgate Service for a zone.
+func ZoneServiceName(clusterName, zone string) string {
+	return names.JoinWithConstraints(names.ServiceConstraints, clusterName, zone, zoneComponentName)
+}
+
 // NewService creates a new Service object for vtgate.
 func NewService(key client.ObjectKey, labels map[string]string) *corev1.Service {
 	// Fill in the immutable parts.
@@ -57,11 +97,16 @@ func UpdateService(obj *corev1.Service, labels map[string]string) {
 
 	obj.Spec.Selector = labels
 
+	obj.Spec.Ports = servicePorts()
+}
+
+// servicePorts returns the ports of the internal vtgate Services.
+func servicePorts() []corev1.ServicePort {
 	// Using named TargetPorts instead of hard-coded port numbers means that
 	// each Pod can decide what port numbers to use.
 	// The Pod just needs to assign the proper name to those ports so we
 	// can find them.
-	obj.Spec.Ports = []corev1.ServicePort{
+	return []corev1.ServicePort{
 		{
 			Name:       planetscalev2.DefaultWebPortName,
 			Protocol:   corev1.ProtocolTCP,
@@ -82,3 +127,137 @@ func UpdateService(obj *corev1.Service, labels map[string]string) {
 		},
 	}
 }
+
+// NewExternalService creates a new externally reachable Service for vtgate.
+func NewExternalService(key client.ObjectKey, labels map[string]string, spec *planetscalev2.VitessGatewayExternalService) *corev1.Service {
+	obj := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+		},
+	}
+	UpdateExternalService(obj, labels, spec)
+	return obj
+}
+
+// UpdateExternalService updates the mutable parts of an external vtgate Service.
+func UpdateExternalService(obj *corev1.Service, labels map[string]string, spec *planetscalev2.VitessGatewayExternalService) {
+	update.Labels(&obj.Labels, labels)
+
+	annotations := maps.Clone(spec.Annotations)
+	if spec.Hostname != "" {
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[externalDNSHostnameAnnotation] = spec.Hostname
+	}
+	update.Annotations(&obj.Annotations, annotations)
+
+	obj.Spec.Selector = labels
+	obj.Spec.Type = spec.Type
+	obj.Spec.ExternalTrafficPolicy = spec.ExternalTrafficPolicy
+	if spec.Type == corev1.ServiceTypeLoadBalancer {
+		obj.Spec.LoadBalancerSourceRanges = spec.LoadBalancerSourceRanges
+	} else {
+		obj.Spec.LoadBalancerSourceRanges = nil
+	}
+
+	// Only expose the client-facing ports. Keep any node ports that were
+	// already allocated, so we don't fight with the API server over them.
+	nodePorts := make(map[string]int32, len(obj.Spec.Ports))
+	for _, port := range obj.Spec.Ports {
+		nodePorts[port.Name] = port.NodePort
+	}
+	obj.Spec.Ports = []corev1.ServicePort{
+		{
+			Name:       planetscalev2.DefaultGrpcPortName,
+			Protocol:   corev1.ProtocolTCP,
+			Port:       planetscalev2.DefaultGrpcPort,
+			TargetPort: intstr.FromString(planetscalev2.DefaultGrpcPortName),
+			NodePort:   nodePorts[planetscalev2.DefaultGrpcPortName],
+		},
+		{
+			Name:       planetscalev2.DefaultMysqlPortName,
+			Protocol:   corev1.ProtocolTCP,
+			Port:       planetscalev2.DefaultMysqlPort,
+			TargetPort: intstr.FromString(planetscalev2.DefaultMysqlPortName),
+			NodePort:   nodePorts[planetscalev2.DefaultMysqlPortName],
+		},
+	}
+}
+
+// UpdateExternalStatus fills in how clients can reach vtgate through an
+// external Service.
+func UpdateExternalStatus(status *planetscalev2.VitessGatewayExternalStatus, svc *corev1.Service, spec *planetscalev2.VitessGatewayExternalService) {
+	status.ServiceName = svc.Name
+	status.Hostname = spec.Hostname
+	status.Addresses = nil
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.Hostname != "" {
+			status.Addresses = append(status.Addresses, ingress.Hostname)
+		}
+		if ingress.IP != "" {
+			status.Addresses = append(status.Addresses, ingress.IP)
+		}
+	}
+	for _, port := range svc.Spec.Ports {
+		if port.Name != planetscalev2.DefaultMysqlPortName {
+			continue
+		}
+		status.MysqlPort = port.Port
+		if svc.Spec.Type == corev1.ServiceTypeNodePort {
+			status.MysqlPort = port.NodePort
+		}
+	}
+}
+
+// UpdateServiceTopology applies zone-aware routing settings to the
+// cluster-wide vtgate Service.
+func UpdateServiceTopology(obj *corev1.Service, spec *planetscalev2.VitessGatewayTopologySpec) {
+	if spec == nil {
+		spec = &planetscalev2.VitessGatewayTopologySpec{}
+	}
+
+	obj.Spec.TrafficDistribution = spec.TrafficDistribution
+
+	// The API server fills in the default, so we do too.
+	internalTrafficPolicy := corev1.ServiceInternalTrafficPolicyCluster
+	if spec.InternalTrafficPolicy != nil {
+		internalTrafficPolicy = *spec.InternalTrafficPolicy
+	}
+	obj.Spec.InternalTrafficPolicy = &internalTrafficPolicy
+
+	if spec.TopologyAwareHints {
+		update.Annotations(&obj.Annotations, map[string]string{topologyModeAnnotation: "Auto"})
+	} else {
+		delete(obj.Annotations, topologyModeAnnotation)
+	}
+}
+
+// ZoneServicesEnabled returns whether per-zone vtgate Services are enabled.
+func ZoneServicesEnabled(spec *planetscalev2.VitessGatewayTopologySpec) bool {
+	return spec != nil && spec.ZoneServices
+}
+
+// NewZoneService creates a new headless Service for the vtgates in a zone.
+func NewZoneService(key client.ObjectKey, labels, selector map[string]string) *corev1.Service {
+	obj := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+		},
+	}
+	UpdateZoneService(obj, labels, selector)
+	return obj
+}
+
+// UpdateZoneService updates the mutable parts of a per-zone vtgate Service.
+func UpdateZoneService(obj *corev1.Service, labels, selector map[string]string) {
+	update.Labels(&obj.Labels, labels)
+
+	obj.Spec.Selector = selector
+	obj.Spec.Ports = servicePorts()
+}
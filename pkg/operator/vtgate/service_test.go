@@ -0,0 +1,102 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+func TestUpdateExternalServiceKeepsNodePorts(t *testing.T) {
+	spec := &planetscalev2.VitessGatewayExternalService{
+		Type:                     corev1.ServiceTypeNodePort,
+		ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyLocal,
+		LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+		Hostname:                 "db.example.com",
+	}
+	svc := NewExternalService(client.ObjectKey{Namespace: "ns", Name: "vtgate"}, nil, spec)
+	if got := svc.Annotations[externalDNSHostnameAnnotation]; got != spec.Hostname {
+		t.Errorf("hostname annotation = %q; want %q", got, spec.Hostname)
+	}
+	if svc.Spec.LoadBalancerSourceRanges != nil {
+		t.Errorf("LoadBalancerSourceRanges = %v; want none for NodePort", svc.Spec.LoadBalancerSourceRanges)
+	}
+
+	// Simulate the API server allocating node ports.
+	for i := range svc.Spec.Ports {
+		svc.Spec.Ports[i].NodePort = 30000 + int32(i)
+	}
+	want := svc.DeepCopy()
+	UpdateExternalService(svc, nil, spec)
+	for i := range svc.Spec.Ports {
+		if got, want := svc.Spec.Ports[i].NodePort, want.Spec.Ports[i].NodePort; got != want {
+			t.Errorf("Ports[%d].NodePort = %v; want %v", i, got, want)
+		}
+	}
+
+	status := &planetscalev2.VitessGatewayExternalStatus{}
+	UpdateExternalStatus(status, svc, spec)
+	if status.MysqlPort != 30001 {
+		t.Errorf("MysqlPort = %v; want the MySQL node port 30001", status.MysqlPort)
+	}
+
+	// Switching to a load balancer reports its address and the Service port.
+	spec.Type = corev1.ServiceTypeLoadBalancer
+	UpdateExternalService(svc, nil, spec)
+	svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}}
+	UpdateExternalStatus(status, svc, spec)
+	if status.MysqlPort != planetscalev2.DefaultMysqlPort {
+		t.Errorf("MysqlPort = %v; want %v", status.MysqlPort, planetscalev2.DefaultMysqlPort)
+	}
+	if len(status.Addresses) != 1 || status.Addresses[0] != "203.0.113.10" {
+		t.Errorf("Addresses = %v; want [203.0.113.10]", status.Addresses)
+	}
+	if len(svc.Spec.LoadBalancerSourceRanges) != 1 {
+		t.Errorf("LoadBalancerSourceRanges = %v; want %v", svc.Spec.LoadBalancerSourceRanges, spec.LoadBalancerSourceRanges)
+	}
+}
+
+func TestUpdateServiceTopology(t *testing.T) {
+	svc := NewService(client.ObjectKey{Namespace: "ns", Name: "vtgate"}, nil)
+	preferSameZone := corev1.ServiceTrafficDistributionPreferSameZone
+	UpdateServiceTopology(svc, &planetscalev2.VitessGatewayTopologySpec{
+		TrafficDistribution: &preferSameZone,
+		TopologyAwareHints:  true,
+	})
+	if got := svc.Spec.TrafficDistribution; got == nil || *got != preferSameZone {
+		t.Errorf("TrafficDistribution = %v; want %v", got, preferSameZone)
+	}
+	if got := svc.Annotations[topologyModeAnnotation]; got != "Auto" {
+		t.Errorf("topology mode annotation = %q; want Auto", got)
+	}
+
+	// Turning it off again removes what we set.
+	UpdateServiceTopology(svc, nil)
+	if got := svc.Spec.TrafficDistribution; got != nil {
+		t.Errorf("TrafficDistribution = %v; want none", *got)
+	}
+	if _, ok := svc.Annotations[topologyModeAnnotation]; ok {
+		t.Errorf("topology mode annotation still set after disabling hints")
+	}
+	if got := svc.Spec.InternalTrafficPolicy; got == nil || *got != corev1.ServiceInternalTrafficPolicyCluster {
+		t.Errorf("InternalTrafficPolicy = %v; want Cluster", got)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/update"
+)
+
+// TCPRouteGVK is the Gateway API TCPRoute kind. We use it through the
+// unstructured client so the operator doesn't depend on the Gateway API CRDs
+// being installed unless a TCPRoute is requested.
+var TCPRouteGVK = schema.GroupVersionKind{
+	Group:   "gateway.networking.k8s.io",
+	Version: "v1alpha2",
+	Kind:    "TCPRoute",
+}
+
+// TCPRouteInstalled returns whether the TCPRoute CRD is installed.
+func TCPRouteInstalled(mapper meta.RESTMapper) (bool, error) {
+	_, err := mapper.RESTMapping(TCPRouteGVK.GroupKind(), TCPRouteGVK.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// NewTCPRouteKind returns an empty TCPRoute to use as a prototype.
+func NewTCPRouteKind() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(TCPRouteGVK)
+	return obj
+}
+
+// NewTCPRoute creates a TCPRoute that sends traffic from the given Gateway
+// listeners to the MySQL port of the given vtgate Service.
+func NewTCPRoute(key client.ObjectKey, labels map[string]string, spec *planetscalev2.VitessGatewayTCPRoute, serviceName string) *unstructured.Unstructured {
+	obj := NewTCPRouteKind()
+	obj.SetNamespace(key.Namespace)
+	obj.SetName(key.Name)
+	UpdateTCPRoute(obj, labels, spec, serviceName)
+	return obj
+}
+
+// UpdateTCPRoute updates the mutable parts of a TCPRoute.
+func UpdateTCPRoute(obj *unstructured.Unstructured, labels map[string]string, spec *planetscalev2.VitessGatewayTCPRoute, serviceName string) {
+	objLabels := obj.GetLabels()
+	update.Labels(&objLabels, labels)
+	obj.SetLabels(objLabels)
+
+	// Fill in the fields that the API server would otherwise default, so
+	// we don't see a difference on every reconcile.
+	parentRefs := make([]any, 0, len(spec.ParentRefs))
+	for _, ref := range spec.ParentRefs {
+		parentRef := map[string]any{
+			"group": TCPRouteGVK.Group,
+			"kind":  "Gateway",
+			"name":  ref.Name,
+		}
+		if ref.Namespace != "" {
+			parentRef["namespace"] = ref.Namespace
+		}
+		if ref.SectionName != "" {
+			parentRef["sectionName"] = ref.SectionName
+		}
+		parentRefs = append(parentRefs, parentRef)
+	}
+	obj.Object["spec"] = map[string]any{
+		"parentRefs": parentRefs,
+		"rules": []any{
+			map[string]any{
+				"backendRefs": []any{
+					map[string]any{
+						"group":  "",
+						"kind":   "Service",
+						"name":   serviceName,
+						"port":   int64(planetscalev2.DefaultMysqlPort),
+						"weight": int64(1),
+					},
+				},
+			},
+		},
+	}
+}
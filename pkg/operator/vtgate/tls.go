@@ -0,0 +1,46 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/certs"
+	"planetscale.dev/vitess-operator/pkg/operator/names"
+)
+
+// CASecretName returns the name of the Secret holding the CA that signs
+// operator-managed vtgate certificates for a cluster.
+func CASecretName(clusterName string) string {
+	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, planetscalev2.VtgateComponentName, "ca")
+}
+
+// TLSSecretName returns the name of the Secret holding the operator-managed
+// vtgate serving certificate for a cell.
+func TLSSecretName(clusterName, cellName string) string {
+	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, cellName, planetscalev2.VtgateComponentName, "tls")
+}
+
+// TLSDNSNames returns the DNS names that an operator-managed vtgate serving
+// certificate for a cell must cover.
+func TLSDNSNames(namespace, clusterName string, cell *planetscalev2.VitessCellSpec, extraDNSNames []string) []string {
+	dnsNames := certs.ServiceDNSNames(ServiceName(clusterName, cell.Name), namespace)
+	dnsNames = append(dnsNames, certs.ServiceDNSNames(ClusterServiceName(clusterName), namespace)...)
+	for i := range cell.Gateway.Pools {
+		dnsNames = append(dnsNames, certs.ServiceDNSNames(PoolServiceName(clusterName, cell.Name, cell.Gateway.Pools[i].Name), namespace)...)
+	}
+	return append(dnsNames, extraDNSNames...)
+}
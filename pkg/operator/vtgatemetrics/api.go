@@ -0,0 +1,165 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgatemetrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// APIGroup is the API group of the Kubernetes external metrics API.
+	APIGroup = "external.metrics.k8s.io"
+	// APIVersion is the version of the external metrics API that we serve.
+	APIVersion = "v1beta1"
+	// APIPath is the URL path under which the external metrics API is served.
+	APIPath = "/apis/" + APIGroup + "/" + APIVersion
+)
+
+// externalMetricValueList mirrors ExternalMetricValueList from
+// k8s.io/metrics/pkg/apis/external_metrics/v1beta1.
+type externalMetricValueList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []externalMetricValue `json:"items"`
+}
+
+// externalMetricValue mirrors ExternalMetricValue from
+// k8s.io/metrics/pkg/apis/external_metrics/v1beta1.
+type externalMetricValue struct {
+	MetricName    string            `json:"metricName"`
+	MetricLabels  map[string]string `json:"metricLabels"`
+	Timestamp     metav1.Time       `json:"timestamp"`
+	WindowSeconds *int64            `json:"window,omitempty"`
+	Value         resource.Quantity `json:"value"`
+}
+
+// summer is the part of Collector that the API handler needs.
+type summer interface {
+	Sum(namespace, metric string, selector labels.Selector) (float64, bool)
+}
+
+// Handler serves the external metrics API.
+type Handler struct {
+	metrics summer
+}
+
+// NewHandler returns a Handler that serves metrics from the given Collector.
+func NewHandler(c *Collector) *Handler {
+	return &Handler{metrics: c}
+}
+
+// ServeHTTP implements http.Handler.
+//
+// It serves API discovery at APIPath, and metric values at
+// APIPath/namespaces/<namespace>/<metric>?labelSelector=<selector>.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, http.StatusMethodNotAllowed, metav1.StatusReasonMethodNotAllowed, "only GET is supported")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, APIPath), "/")
+	if path == "" {
+		writeJSON(w, http.StatusOK, discovery())
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) != 3 || parts[0] != "namespaces" {
+		writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound, fmt.Sprintf("unknown path %q", r.URL.Path))
+		return
+	}
+	namespace, metric := parts[1], parts[2]
+
+	selector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+	if err != nil {
+		writeStatus(w, http.StatusBadRequest, metav1.StatusReasonBadRequest, fmt.Sprintf("invalid labelSelector: %v", err))
+		return
+	}
+
+	value, ok := h.metrics.Sum(namespace, metric, selector)
+	if !ok {
+		writeStatus(w, http.StatusNotFound, metav1.StatusReasonNotFound, fmt.Sprintf("unknown metric %q", metric))
+		return
+	}
+
+	window := int64(scrapeInterval / time.Second)
+	writeJSON(w, http.StatusOK, &externalMetricValueList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: APIGroup + "/" + APIVersion,
+			Kind:       "ExternalMetricValueList",
+		},
+		Items: []externalMetricValue{
+			{
+				MetricName:    metric,
+				MetricLabels:  map[string]string{},
+				Timestamp:     metav1.Now(),
+				WindowSeconds: &window,
+				Value:         *resource.NewMilliQuantity(int64(value*1000), resource.DecimalSI),
+			},
+		},
+	})
+}
+
+// discovery returns the resource list for the external metrics API group.
+func discovery() *metav1.APIResourceList {
+	list := &metav1.APIResourceList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "APIResourceList",
+		},
+		GroupVersion: APIGroup + "/" + APIVersion,
+	}
+	for _, metric := range []string{QueriesPerSecondMetric, ConnectionsMetric} {
+		list.APIResources = append(list.APIResources, metav1.APIResource{
+			Name:       metric,
+			Namespaced: true,
+			Kind:       "ExternalMetricValueList",
+			Verbs:      metav1.Verbs{"get"},
+		})
+	}
+	return list
+}
+
+func writeStatus(w http.ResponseWriter, code int, reason metav1.StatusReason, message string) {
+	writeJSON(w, code, &metav1.Status{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Status",
+		},
+		Status:  metav1.StatusFailure,
+		Code:    int32(code),
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, obj any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		log.Error(err, "failed to write external metrics API response")
+	}
+}
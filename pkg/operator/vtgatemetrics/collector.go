@@ -0,0 +1,224 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgatemetrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+)
+
+var log = logf.Log.WithName("vtgatemetrics")
+
+// sample is the most recent observation of a single vtgate Pod.
+type sample struct {
+	labels map[string]string
+	time   time.Time
+
+	// queries is the cumulative number of queries processed.
+	queries float64
+	// qps is the rate of queries computed from the previous sample.
+	// It's only meaningful if hasQPS is true.
+	qps    float64
+	hasQPS bool
+
+	connections float64
+}
+
+// Collector periodically scrapes vtgate Pods and remembers the latest values.
+type Collector struct {
+	client     client.Client
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	samples map[types.NamespacedName]*sample
+}
+
+// NewCollector returns a Collector that finds vtgate Pods with the given client.
+func NewCollector(c client.Client) *Collector {
+	return &Collector{
+		client:     c,
+		httpClient: &http.Client{Timeout: scrapeTimeout},
+		samples:    map[types.NamespacedName]*sample{},
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+// Every operator replica that serves the API needs its own data.
+func (c *Collector) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable. It scrapes until the context is canceled.
+func (c *Collector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(scrapeInterval)
+	defer ticker.Stop()
+
+	for {
+		c.scrapeAll(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// scrapeAll scrapes every running vtgate Pod and forgets Pods that are gone.
+func (c *Collector) scrapeAll(ctx context.Context) {
+	// Scrape the main vtgates of each cell as well as those in named pools.
+	isVtgate, err := labels.NewRequirement(planetscalev2.ComponentLabel, selection.In, []string{
+		planetscalev2.VtgateComponentName,
+		planetscalev2.VtgatePoolComponentName,
+	})
+	if err != nil {
+		log.Error(err, "failed to build vtgate Pod selector")
+		return
+	}
+	podList := &corev1.PodList{}
+	if err := c.client.List(ctx, podList, client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*isVtgate)}); err != nil {
+		log.Error(err, "failed to list vtgate Pods")
+		return
+	}
+
+	seen := make(map[types.NamespacedName]bool, len(podList.Items))
+	wg := sync.WaitGroup{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+		seen[key] = true
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			vars, err := c.scrape(ctx, pod.Status.PodIP)
+			if err != nil {
+				log.V(1).Info("failed to scrape vtgate", "pod", key.String(), "err", err.Error())
+				return
+			}
+			c.record(key, pod.Labels, vars, time.Now())
+		}()
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.samples {
+		if !seen[key] {
+			delete(c.samples, key)
+		}
+	}
+}
+
+// debugVars holds the values we read from vtgate's /debug/vars.
+type debugVars struct {
+	// QueriesProcessed is a counter of queries, broken down by plan type.
+	QueriesProcessed map[string]float64 `json:"QueriesProcessed"`
+	// MysqlServerConnCount is a gauge of open MySQL protocol connections.
+	MysqlServerConnCount float64 `json:"MysqlServerConnCount"`
+}
+
+// scrape fetches /debug/vars from the vtgate at the given Pod IP.
+func (c *Collector) scrape(ctx context.Context, podIP string) (*debugVars, error) {
+	url := fmt.Sprintf("http://%s/debug/vars", net.JoinHostPort(podIP, strconv.Itoa(planetscalev2.DefaultWebPort)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status from %v: %v", url, resp.Status)
+	}
+	vars := &debugVars{}
+	if err := json.NewDecoder(resp.Body).Decode(vars); err != nil {
+		return nil, fmt.Errorf("can't decode %v: %v", url, err)
+	}
+	return vars, nil
+}
+
+// record stores a new observation for a Pod, computing the query rate from
+// the previous observation if there is one.
+func (c *Collector) record(key types.NamespacedName, podLabels map[string]string, vars *debugVars, now time.Time) {
+	s := &sample{
+		labels:      podLabels,
+		time:        now,
+		connections: vars.MysqlServerConnCount,
+	}
+	for _, count := range vars.QueriesProcessed {
+		s.queries += count
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// A counter that went backwards means vtgate restarted, so we can't
+	// compute a rate until the next sample.
+	if prev := c.samples[key]; prev != nil && s.queries >= prev.queries {
+		if elapsed := now.Sub(prev.time).Seconds(); elapsed > 0 {
+			s.qps = (s.queries - prev.queries) / elapsed
+			s.hasQPS = true
+		}
+	}
+	c.samples[key] = s
+}
+
+// Sum returns the total value of the given metric across all vtgate Pods in
+// the namespace that match the selector. It returns false if the metric name
+// is unknown.
+func (c *Collector) Sum(namespace, metric string, selector labels.Selector) (float64, bool) {
+	if metric != QueriesPerSecondMetric && metric != ConnectionsMetric {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var total float64
+	for key, s := range c.samples {
+		if key.Namespace != namespace || !selector.Matches(labels.Set(s.labels)) {
+			continue
+		}
+		if metric == ConnectionsMetric {
+			total += s.connections
+		} else if s.hasQPS {
+			total += s.qps
+		}
+	}
+	return total, true
+}
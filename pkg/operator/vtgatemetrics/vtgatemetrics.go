@@ -0,0 +1,67 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package vtgatemetrics serves Vitess-native vtgate metrics, such as queries per
+second and open MySQL connections, to HorizontalPodAutoscalers through the
+Kubernetes external metrics API (external.metrics.k8s.io).
+
+A Collector periodically scrapes the /debug/vars page of every vtgate Pod and
+keeps the latest per-Pod values in memory. The external metrics API handler
+answers HPA queries by summing those values across the vtgate Pods that match
+the metric's label selector. HPAs should use an AverageValue target, which the
+HPA controller divides by the current number of replicas.
+
+The handler is served on the operator's webhook server, so it shares that
+server's serving certificate. An APIService must be registered to route the
+external.metrics.k8s.io group to the operator (see deploy/external_metrics.yaml).
+*/
+package vtgatemetrics
+
+import (
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// QueriesPerSecondMetric is the external metric name for the rate of
+	// queries processed by vtgate.
+	QueriesPerSecondMetric = "vtgate_queries_per_second"
+	// ConnectionsMetric is the external metric name for the number of open
+	// MySQL protocol client connections to vtgate.
+	ConnectionsMetric = "vtgate_mysql_connections"
+
+	// scrapeInterval is how often each vtgate Pod is scraped. This matches the
+	// default HPA sync period.
+	scrapeInterval = 15 * time.Second
+	// scrapeTimeout is how long to wait for a single vtgate to respond.
+	scrapeTimeout = 5 * time.Second
+)
+
+// Add starts a Collector in the Manager and serves the external metrics API
+// from its data on the Manager's webhook server.
+func Add(mgr manager.Manager) error {
+	c := NewCollector(mgr.GetClient())
+	if err := mgr.Add(c); err != nil {
+		return err
+	}
+	h := NewHandler(c)
+	server := mgr.GetWebhookServer()
+	server.Register(APIPath, h)
+	server.Register(APIPath+"/", h)
+	return nil
+}
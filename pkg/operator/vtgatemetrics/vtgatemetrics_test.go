@@ -0,0 +1,122 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgatemetrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCollectorSum(t *testing.T) {
+	c := NewCollector(nil)
+	start := time.Now()
+	cell1 := map[string]string{"cell": "cell1"}
+	cell2 := map[string]string{"cell": "cell2"}
+	pod1 := types.NamespacedName{Namespace: "ns", Name: "vtgate-1"}
+	pod2 := types.NamespacedName{Namespace: "ns", Name: "vtgate-2"}
+	pod3 := types.NamespacedName{Namespace: "ns", Name: "vtgate-3"}
+	other := types.NamespacedName{Namespace: "other", Name: "vtgate-1"}
+
+	c.record(pod1, cell1, &debugVars{QueriesProcessed: map[string]float64{"Select": 100}, MysqlServerConnCount: 4}, start)
+	c.record(pod2, cell1, &debugVars{QueriesProcessed: map[string]float64{"Select": 500}, MysqlServerConnCount: 6}, start)
+	c.record(pod3, cell2, &debugVars{QueriesProcessed: map[string]float64{"Select": 0}, MysqlServerConnCount: 10}, start)
+	c.record(other, cell1, &debugVars{MysqlServerConnCount: 100}, start)
+
+	// No rates are known until the second sample.
+	if got, _ := c.Sum("ns", QueriesPerSecondMetric, labels.SelectorFromSet(cell1)); got != 0 {
+		t.Errorf("Sum(qps) after first sample = %v, want 0", got)
+	}
+
+	later := start.Add(10 * time.Second)
+	c.record(pod1, cell1, &debugVars{QueriesProcessed: map[string]float64{"Select": 150, "Insert": 50}, MysqlServerConnCount: 5}, later)
+	// This counter went backwards, as if vtgate restarted.
+	c.record(pod2, cell1, &debugVars{QueriesProcessed: map[string]float64{"Select": 10}, MysqlServerConnCount: 1}, later)
+	c.record(pod3, cell2, &debugVars{QueriesProcessed: map[string]float64{"Select": 1000}, MysqlServerConnCount: 10}, later)
+
+	tests := []struct {
+		metric   string
+		selector labels.Selector
+		want     float64
+	}{
+		{metric: QueriesPerSecondMetric, selector: labels.SelectorFromSet(cell1), want: 10},
+		{metric: QueriesPerSecondMetric, selector: labels.SelectorFromSet(cell2), want: 100},
+		{metric: QueriesPerSecondMetric, selector: labels.Everything(), want: 110},
+		{metric: ConnectionsMetric, selector: labels.SelectorFromSet(cell1), want: 6},
+		{metric: ConnectionsMetric, selector: labels.Everything(), want: 16},
+	}
+	for _, test := range tests {
+		got, ok := c.Sum("ns", test.metric, test.selector)
+		if !ok {
+			t.Fatalf("Sum(%v, %v) returned unknown metric", test.metric, test.selector)
+		}
+		if got != test.want {
+			t.Errorf("Sum(%v, %v) = %v, want %v", test.metric, test.selector, got, test.want)
+		}
+	}
+
+	if _, ok := c.Sum("ns", "bogus", labels.Everything()); ok {
+		t.Errorf("Sum(bogus) returned ok for unknown metric")
+	}
+}
+
+type fakeSummer map[string]float64
+
+func (f fakeSummer) Sum(namespace, metric string, selector labels.Selector) (float64, bool) {
+	value, ok := f[metric]
+	return value, ok
+}
+
+func TestHandler(t *testing.T) {
+	h := &Handler{metrics: fakeSummer{QueriesPerSecondMetric: 12.5}}
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantKind string
+	}{
+		{path: APIPath, wantCode: http.StatusOK, wantKind: "APIResourceList"},
+		{path: APIPath + "/namespaces/ns/" + QueriesPerSecondMetric + "?labelSelector=a%3Db", wantCode: http.StatusOK, wantKind: "ExternalMetricValueList"},
+		{path: APIPath + "/namespaces/ns/bogus", wantCode: http.StatusNotFound, wantKind: "Status"},
+		{path: APIPath + "/namespaces/ns/" + QueriesPerSecondMetric + "?labelSelector=%3D%3D", wantCode: http.StatusBadRequest, wantKind: "Status"},
+		{path: APIPath + "/foo", wantCode: http.StatusNotFound, wantKind: "Status"},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if w.Code != test.wantCode {
+			t.Errorf("GET %v: code = %v, want %v", test.path, w.Code, test.wantCode)
+		}
+		var got externalMetricValueList
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("GET %v: can't decode response: %v", test.path, err)
+		}
+		if got.Kind != test.wantKind {
+			t.Errorf("GET %v: kind = %q, want %q", test.path, got.Kind, test.wantKind)
+		}
+		if got.Kind == "ExternalMetricValueList" {
+			if len(got.Items) != 1 || got.Items[0].Value.MilliValue() != 12500 {
+				t.Errorf("GET %v: items = %+v, want a single value of 12.5", test.path, got.Items)
+			}
+		}
+	}
+}
@@ -27,7 +27,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/internaltls"
 	"planetscale.dev/vitess-operator/pkg/operator/k8s"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
 	"planetscale.dev/vitess-operator/pkg/operator/names"
 	"planetscale.dev/vitess-operator/pkg/operator/update"
 	"planetscale.dev/vitess-operator/pkg/operator/vitess"
@@ -48,6 +50,11 @@ func DeploymentName(clusterName, keyspace string, shardKeyRange planetscalev2.Vi
 	return deploymentName(clusterName, keyspace, shardKeyRange.SafeName(), cellName)
 }
 
+// PDBName returns the name of the PodDisruptionBudget for the VTOrcs of a given shard.
+func PDBName(clusterName, keyspace string, shardKeyRange planetscalev2.VitessKeyRange) string {
+	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, keyspace, shardKeyRange.SafeName(), planetscalev2.VtorcComponentName)
+}
+
 // Spec specifies all the internal parameters needed to deploy VTOrc,
 // as opposed to the API type planetscalev2.VitessDashboardSpec, which is the public API.
 type Spec struct {
@@ -71,6 +78,7 @@ type Spec struct {
 	Annotations       map[string]string
 	ExtraLabels       map[string]string
 	Tolerations       []corev1.Toleration
+	InternalTLS       *planetscalev2.VitessInternalTLSSpec
 }
 
 // NewDeployment creates a new Deployment object for vtorc.
@@ -137,6 +145,11 @@ func UpdateDeployment(obj *appsv1.Deployment, spec *Spec) {
 	obj.Spec.Template.Spec.ServiceAccountName = planetscalev2.DefaultVitessServiceAccount
 	obj.Spec.Template.Spec.Tolerations = spec.Tolerations
 	update.Volumes(&obj.Spec.Template.Spec.Volumes, spec.ExtraVolumes)
+	update.Volumes(&obj.Spec.Template.Spec.Volumes, internaltls.Volumes(spec.InternalTLS))
+	volumeMounts := spec.ExtraVolumeMounts
+	volumeMounts = append(volumeMounts, internaltls.VolumeMounts(spec.InternalTLS)...)
+	update.Volumes(&obj.Spec.Template.Spec.Volumes, lockserver.ClientTLSVolumes(&spec.GlobalLockserver))
+	volumeMounts = append(volumeMounts, lockserver.ClientTLSVolumeMounts(&spec.GlobalLockserver)...)
 
 	securityContext := &corev1.SecurityContext{}
 	if planetscalev2.DefaultVitessRunAsUser >= 0 {
@@ -176,7 +189,7 @@ func UpdateDeployment(obj *appsv1.Deployment, spec *Spec) {
 			InitialDelaySeconds: 300,
 			FailureThreshold:    30,
 		},
-		VolumeMounts: spec.ExtraVolumeMounts,
+		VolumeMounts: volumeMounts,
 		Env:          spec.ExtraEnv,
 	}
 	update.ResourceRequirements(&vtorcContainer.Resources, &spec.Resources)
@@ -210,7 +223,7 @@ func UpdateDeployment(obj *appsv1.Deployment, spec *Spec) {
 }
 
 func (spec *Spec) flags() vitess.Flags {
-	return vitess.Flags{
+	flags := vitess.Flags{
 		"topo_implementation":        spec.GlobalLockserver.Implementation,
 		"topo_global_server_address": spec.GlobalLockserver.Address,
 		"topo_global_root":           spec.GlobalLockserver.RootPath,
@@ -221,4 +234,7 @@ func (spec *Spec) flags() vitess.Flags {
 
 		"logtostderr": true,
 	}
+	flags = flags.Merge(lockserver.ClientTLSFlags(&spec.GlobalLockserver))
+	// VTOrc doesn't serve gRPC, but it calls the tablet manager service.
+	return flags.Merge(internaltls.TabletManagerClientFlags(spec.InternalTLS))
 }
@@ -74,6 +74,9 @@ const (
 
 	pvcVolumeName = "persistent-volume-claim"
 
+	volumeSnapshotAPIGroup = "snapshot.storage.k8s.io"
+	volumeSnapshotKind     = "VolumeSnapshot"
+
 	defaultMySQL56Charset = "utf8"
 	defaultMySQL80Charset = "utf8mb4"
 
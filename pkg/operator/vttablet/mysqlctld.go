@@ -51,6 +51,38 @@ done
 `
 )
 
+// dataVolumeSnapshotInitScript adopts a tablet dir that was copied from
+// another tablet's data volume snapshot. The paths and file names that
+// include the source tablet's UID are renamed to match this tablet, and the
+// state that must not be shared between tablets (server UUID, relay logs) is
+// removed. Anything else in my.cnf is regenerated by mysqlctld on startup.
+//
+// The script does nothing if this tablet's dir already exists, or if the
+// volume doesn't contain exactly one other tablet dir.
+func dataVolumeSnapshotInitScript(uid string) string {
+	return `set -ex
+shopt -s nullglob
+cd ` + vtDataRootPath + `
+target=vt_` + uid + `
+if [[ -d "${target}" ]]; then
+  exit 0
+fi
+sources=(vt_*)
+if [[ ${#sources[@]} -ne 1 ]]; then
+  exit 0
+fi
+source="${sources[0]}"
+source_uid="${source#vt_}"
+mv "${source}" "${target}"
+cd "${target}"
+rm -f data/auto.cnf mysql.pid mysql.sock mysql.sock.lock relay-logs/*
+for binlog in bin-logs/vt-${source_uid}-bin.*; do
+  mv "${binlog}" "bin-logs/vt-` + uid + `-bin.${binlog##*.}"
+done
+sed -i -e "s,vt_${source_uid},${target},g" -e "s,vt-${source_uid}-,vt-` + uid + `-,g" bin-logs/*.index my.cnf
+`
+}
+
 func init() {
 	// Copy Vitess files needed by mysqlctld into the mysqld container,
 	// which might be using a stock MySQL image.
@@ -97,6 +129,33 @@ func init() {
 			},
 		}
 
+		// If the data volume may have been provisioned from a snapshot of
+		// another tablet, adopt the copied tablet dir before anything else
+		// looks at it.
+		if spec.DataVolumePVCSpec != nil && spec.DataVolumeFromSnapshot {
+			initContainers = append(initContainers, corev1.Container{
+				Name:            "init-snapshot-data",
+				SecurityContext: securityContext,
+				Image:           spec.Images.Vttablet,
+				ImagePullPolicy: spec.ImagePullPolicies.Vttablet,
+				VolumeMounts: []corev1.VolumeMount{
+					{
+						Name:      pvcVolumeName,
+						MountPath: vtDataRootPath,
+						SubPath:   "vtdataroot",
+					},
+				},
+				Command: []string{"bash", "-c"},
+				Args:    []string{dataVolumeSnapshotInitScript(UIDString(spec.Alias.Uid))},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    *resource.NewMilliQuantity(planetscalev2.DefaultInitCPURequestMillis, resource.DecimalSI),
+						corev1.ResourceMemory: *resource.NewQuantity(planetscalev2.DefaultInitMemoryRequestBytes, resource.BinarySI),
+					},
+				},
+			})
+		}
+
 		// If we're using a PVC, add an init container to migrate the mysql UNIX
 		// socket location before vttablet and mysqlctld start up. This is
 		// needed to safely update tablet Pods with persistent volumes that were
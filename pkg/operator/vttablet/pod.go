@@ -42,6 +42,11 @@ func PodName(clusterName string, tabletAlias topodatapb.TabletAlias) string {
 	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, planetscalev2.VttabletComponentName, topoproto.TabletAliasString(&tabletAlias))
 }
 
+// PDBName returns the name of the PodDisruptionBudget for the tablets of a shard.
+func PDBName(clusterName, keyspaceName string, keyRange planetscalev2.VitessKeyRange) string {
+	return names.JoinWithConstraints(names.DefaultConstraints, clusterName, keyspaceName, keyRange.SafeName(), planetscalev2.VttabletComponentName)
+}
+
 // NewPod creates a new vttablet Pod from a Spec.
 func NewPod(key client.ObjectKey, spec *Spec) *corev1.Pod {
 	obj := &corev1.Pod{
@@ -19,6 +19,7 @@ package vttablet
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"planetscale.dev/vitess-operator/pkg/operator/update"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -30,14 +31,37 @@ func NewPVC(key client.ObjectKey, spec *Spec) *corev1.PersistentVolumeClaim {
 	update.Labels(&labels, spec.Labels)
 	update.Labels(&labels, spec.ExtraLabels)
 
-	return &corev1.PersistentVolumeClaim{
+	pvc := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: key.Namespace,
 			Name:      key.Name,
 			Labels:    labels,
 		},
-		Spec: *spec.DataVolumePVCSpec,
+		Spec: *spec.DataVolumePVCSpec.DeepCopy(),
 	}
+
+	// Pre-populate the volume from a snapshot of another tablet's data volume.
+	// The init-snapshot-data container adopts the copied tablet dir on startup.
+	if spec.DataVolumeSnapshotName != "" {
+		pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
+			APIGroup: ptr.To(volumeSnapshotAPIGroup),
+			Kind:     volumeSnapshotKind,
+			Name:     spec.DataVolumeSnapshotName,
+		}
+
+		// The volume can't be smaller than the snapshot, which may have been
+		// taken from a volume that was grown past the pool's requested size.
+		if spec.DataVolumeSnapshotSize != nil {
+			requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			if spec.DataVolumeSnapshotSize.Cmp(requested) > 0 {
+				if pvc.Spec.Resources.Requests == nil {
+					pvc.Spec.Resources.Requests = corev1.ResourceList{}
+				}
+				pvc.Spec.Resources.Requests[corev1.ResourceStorage] = spec.DataVolumeSnapshotSize.DeepCopy()
+			}
+		}
+	}
+	return pvc
 }
 
 // UpdatePVCInPlace updates an existing vttablet PVC in-place.
@@ -0,0 +1,64 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttablet
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestNewPVCSnapshotRestoreSize(t *testing.T) {
+	table := []struct {
+		name         string
+		snapshotSize *resource.Quantity
+		want         string
+	}{
+		{name: "unknown restore size", snapshotSize: nil, want: "10Gi"},
+		{name: "smaller snapshot", snapshotSize: ptr.To(resource.MustParse("5Gi")), want: "10Gi"},
+		{name: "larger snapshot", snapshotSize: ptr.To(resource.MustParse("15Gi")), want: "15Gi"},
+	}
+
+	for _, test := range table {
+		t.Run(test.name, func(t *testing.T) {
+			pvcSpec := &corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				},
+			}
+			spec := &Spec{
+				DataVolumePVCSpec:      pvcSpec,
+				DataVolumeSnapshotName: "snapshot",
+				DataVolumeSnapshotSize: test.snapshotSize,
+			}
+
+			pvc := NewPVC(client.ObjectKey{Namespace: "ns", Name: "tablet"}, spec)
+
+			got := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			if got.String() != test.want {
+				t.Errorf("storage request = %v, want %v", got.String(), test.want)
+			}
+			// The tablet pool's template is shared, so it must not change.
+			if template := pvcSpec.Resources.Requests[corev1.ResourceStorage]; template.String() != "10Gi" {
+				t.Errorf("template storage request = %v, want 10Gi", template.String())
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
 )
@@ -46,6 +47,9 @@ type Spec struct {
 	ExternalDatastore         *planetscalev2.ExternalDatastore
 	DataVolumePVCSpec         *corev1.PersistentVolumeClaimSpec
 	DataVolumePVCName         string
+	DataVolumeFromSnapshot    bool
+	DataVolumeSnapshotName    string
+	DataVolumeSnapshotSize    *resource.Quantity
 	GlobalLockserver          planetscalev2.VitessLockserverParams
 	DatabaseInitScriptSecret  planetscalev2.SecretSource
 	Annotations               map[string]string
@@ -60,6 +64,7 @@ type Spec struct {
 	SidecarContainers         []corev1.Container
 	Tolerations               []corev1.Toleration
 	TopologySpreadConstraints []corev1.TopologySpreadConstraint
+	InternalTLS               *planetscalev2.VitessInternalTLSSpec
 }
 
 // localDatabaseName returns the MySQL database name for a tablet Spec in the case of locally managed MySQL.
@@ -0,0 +1,48 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vttablet
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"planetscale.dev/vitess-operator/pkg/operator/lazy"
+	"planetscale.dev/vitess-operator/pkg/operator/lockserver"
+	"planetscale.dev/vitess-operator/pkg/operator/vitess"
+)
+
+func init() {
+	tabletVolumes.Add(func(s lazy.Spec) []corev1.Volume {
+		spec := s.(*Spec)
+		return lockserver.ClientTLSVolumes(&spec.GlobalLockserver)
+	})
+	vttabletVolumeMounts.Add(func(s lazy.Spec) []corev1.VolumeMount {
+		spec := s.(*Spec)
+		return lockserver.ClientTLSVolumeMounts(&spec.GlobalLockserver)
+	})
+
+	vttabletFlags.Add(func(s lazy.Spec) vitess.Flags {
+		spec := s.(*Spec)
+		return lockserver.ClientTLSFlags(&spec.GlobalLockserver)
+	})
+
+	// vtbackup Pods get the same volumes as vttablet, but need their own
+	// flags to connect to topology.
+	vtbackupFlags.Add(func(s lazy.Spec) vitess.Flags {
+		backupSpec := s.(*BackupSpec)
+		return lockserver.ClientTLSFlags(&backupSpec.TabletSpec.GlobalLockserver)
+	})
+}
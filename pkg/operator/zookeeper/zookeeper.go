@@ -0,0 +1,152 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zookeeper
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/serverset"
+)
+
+const (
+	// LockserverLabel is the label that should be added to Pods to identify
+	// which lockserver ensemble they belong to.
+	LockserverLabel = "zookeeper.planetscale.com/lockserver"
+	// IndexLabel is the label used to identify the index of a server.
+	IndexLabel = "zookeeper.planetscale.com/index"
+
+	// ClientPortName is the name of the ZooKeeper client port.
+	ClientPortName = "client"
+	// ClientPortNumber is the port number for ZooKeeper clients.
+	ClientPortNumber = 2181
+
+	// PeerPortName is the name of the port that followers use to connect to
+	// the leader.
+	PeerPortName = "peer"
+	// PeerPortNumber is the port number that followers use to connect to the
+	// leader.
+	PeerPortNumber = 2888
+
+	// ElectionPortName is the name of the leader election port.
+	ElectionPortName = "election"
+	// ElectionPortNumber is the port number for leader election.
+	ElectionPortNumber = 3888
+
+	dataVolumeMountPath = "/data"
+)
+
+// Backend runs a ZooKeeper ensemble as a server set.
+var Backend = &serverset.Backend{
+	ComponentName:   planetscalev2.ZkComponentName,
+	LockserverLabel: LockserverLabel,
+	IndexLabel:      IndexLabel,
+	PDBNamePrefix:   "zk-lockserver-",
+
+	ContainerName: "zookeeper",
+	ClientPort:    serverset.Port{Name: ClientPortName, Number: ClientPortNumber},
+	PeerPorts: []serverset.Port{
+		{Name: PeerPortName, Number: PeerPortNumber},
+		{Name: ElectionPortName, Number: ElectionPortNumber},
+	},
+	DataVolumeMountPath: dataVolumeMountPath,
+	// This only succeeds once the server has joined a quorum.
+	ReadinessCommand: []string{"zkServer.sh", "status"},
+
+	Env: Env,
+}
+
+// Env returns the environment variables of a ZooKeeper server. The official
+// image's entrypoint generates zoo.cfg from them.
+func Env(spec *serverset.Spec) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name:  "ZOO_MY_ID",
+			Value: strconv.Itoa(spec.Index),
+		},
+		{
+			Name:  "ZOO_SERVERS",
+			Value: strings.Join(Servers(spec), " "),
+		},
+		{
+			Name:  "ZOO_STANDALONE_ENABLED",
+			Value: strconv.FormatBool(spec.Replicas == 1),
+		},
+		{
+			Name:  "ZOO_DATA_DIR",
+			Value: dataVolumeMountPath,
+		},
+		{
+			Name:  "ZOO_DATA_LOG_DIR",
+			Value: dataVolumeMountPath + "/datalog",
+		},
+		{
+			// zkServer.sh status, which we use for readiness, needs "srvr".
+			Name:  "ZOO_4LW_COMMANDS_WHITELIST",
+			Value: "srvr,ruok,mntr",
+		},
+		{
+			Name:  "ZOO_CFG_EXTRA",
+			Value: ExtraConfigString(spec),
+		},
+	}
+}
+
+// Servers returns the server list, in the format expected by the ZOO_SERVERS
+// environment variable of the official image.
+func Servers(spec *serverset.Spec) []string {
+	subdomain := serverset.PeerServiceName(spec.LockserverName)
+	servers := make([]string, 0, spec.Replicas)
+	for i := 1; i <= spec.Replicas; i++ {
+		servers = append(servers, fmt.Sprintf("server.%d=%s.%s:%d:%d;%d", i, serverset.PodName(spec.LockserverName, i), subdomain, PeerPortNumber, ElectionPortNumber, ClientPortNumber))
+	}
+	return servers
+}
+
+// ExtraConfigString returns the extra zoo.cfg settings, in the format
+// expected by the ZOO_CFG_EXTRA environment variable of the official image.
+// The extra settings of the spec are extra zoo.cfg settings.
+func ExtraConfigString(spec *serverset.Spec) string {
+	config := map[string]string{
+		// Listen on all interfaces rather than only on the address that our
+		// own DNS name resolves to, which might not exist yet at startup.
+		"quorumListenOnAllIPs": "true",
+	}
+	// Apply user-supplied settings last so they take precedence.
+	for key, value := range spec.Extra {
+		config[key] = value
+	}
+
+	// Sort settings so the ordering is deterministic,
+	// which is important when diffing object specs.
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	settings := make([]string, 0, len(keys))
+	for _, key := range keys {
+		settings = append(settings, fmt.Sprintf("%s=%s", key, config[key]))
+	}
+	return strings.Join(settings, " ")
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2026 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zookeeper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"planetscale.dev/vitess-operator/pkg/operator/serverset"
+)
+
+func TestEnv(t *testing.T) {
+	spec := &serverset.Spec{
+		LockserverName: "example-zk",
+		Index:          1,
+		Replicas:       2,
+		Extra:          map[string]string{"tickTime": "3000", "quorumListenOnAllIPs": "false"},
+	}
+
+	env := map[string]string{}
+	for _, envVar := range Env(spec) {
+		env[envVar.Name] = envVar.Value
+	}
+	assert.Equal(t, "1", env["ZOO_MY_ID"])
+	assert.Equal(t, "false", env["ZOO_STANDALONE_ENABLED"])
+	assert.Equal(t, "server.1=example-zk-1.example-zk-peer:2888:3888;2181 server.2=example-zk-2.example-zk-peer:2888:3888;2181", env["ZOO_SERVERS"])
+	// User settings override ours, in a stable order.
+	assert.Equal(t, "quorumListenOnAllIPs=false tickTime=3000", env["ZOO_CFG_EXTRA"])
+}
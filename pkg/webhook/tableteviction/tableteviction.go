@@ -0,0 +1,167 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package tableteviction implements an admission webhook that translates Pod
+evictions of vttablet Pods into drain requests.
+
+Agents like `kubectl drain` and the cluster autoscaler don't know about the
+annotation protocol defined in the "drain" package. They just call the eviction
+API and retry for as long as it returns 429 (Too Many Requests), which is what
+the API server returns when a PodDisruptionBudget blocks an eviction.
+
+This webhook takes advantage of that retry loop. The first eviction request for
+a tablet Pod that supports drains starts a drain on the Pod and is refused with
+429. Subsequent requests are refused the same way until the VitessShard
+controller marks the drain as finished (e.g. after a planned reparent away from
+the Pod), at which point the eviction is allowed through.
+*/
+package tableteviction
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/drain"
+)
+
+const (
+	webhookName = "tableteviction-webhook"
+
+	// Path is the URL path on the operator's webhook server at which the
+	// eviction webhook is served.
+	Path = "/validate-tablet-eviction"
+
+	// drainMessage is the value of the drain "started" annotation that we set
+	// on Pods in response to an eviction.
+	drainMessage = "eviction requested"
+)
+
+var log = logf.Log.WithName(webhookName)
+
+// Add registers the tablet eviction webhook on the Manager's webhook server.
+func Add(mgr manager.Manager) error {
+	h := &handler{
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(webhookName),
+	}
+	mgr.GetWebhookServer().Register(Path, &webhook.Admission{Handler: h})
+	return nil
+}
+
+// handler is an admission.Handler for the pods/eviction subresource.
+type handler struct {
+	client   client.Client
+	recorder record.EventRecorder
+}
+
+// Handle implements admission.Handler.
+func (h *handler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Resource.Resource != "pods" || req.SubResource != "eviction" {
+		return admission.Allowed("not a pod eviction")
+	}
+
+	key := types.NamespacedName{Namespace: req.Namespace, Name: req.Name}
+	pod := &corev1.Pod{}
+	if err := h.client.Get(ctx, key, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Let the eviction API report that the Pod doesn't exist.
+			return admission.Allowed("pod not found")
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if allowed, reason := evictionAllowed(pod); allowed {
+		return admission.Allowed(reason)
+	}
+
+	if !drain.Started(pod) {
+		// Don't start a drain for a dry-run eviction, but still report that
+		// the eviction would have been refused.
+		if req.DryRun == nil || !*req.DryRun {
+			if err := h.startDrain(ctx, key); err != nil {
+				return admission.Errored(http.StatusInternalServerError, err)
+			}
+			log.Info("Started drain in response to eviction", "pod", key.String())
+			h.recorder.Eventf(pod, corev1.EventTypeNormal, "EvictionDrainStarted", "started drain in response to eviction request")
+		}
+	}
+
+	return tooManyRequests(fmt.Sprintf("vttablet Pod %v is being drained; eviction will be allowed once the drain is finished", key.String()))
+}
+
+// startDrain adds the drain "started" annotation to the Pod, retrying on
+// write conflicts with other updates to the Pod.
+func (h *handler) startDrain(ctx context.Context, key types.NamespacedName) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		pod := &corev1.Pod{}
+		if err := h.client.Get(ctx, key, pod); err != nil {
+			return err
+		}
+		if drain.Started(pod) {
+			return nil
+		}
+		drain.Start(pod, drainMessage)
+		return h.client.Update(ctx, pod)
+	})
+}
+
+// evictionAllowed returns whether an eviction of the given Pod can proceed
+// immediately, along with a human-readable reason if so.
+func evictionAllowed(pod *corev1.Pod) (bool, string) {
+	if pod.Labels[planetscalev2.ComponentLabel] != planetscalev2.VttabletComponentName {
+		return true, "not a vttablet pod"
+	}
+	if !drain.Supported(pod) {
+		return true, "pod does not support drains"
+	}
+	if pod.DeletionTimestamp != nil {
+		return true, "pod is already being deleted"
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		// A tablet that isn't running can't be serving, so there's nothing
+		// to drain. Blocking here could also stall indefinitely if the Pod
+		// can never start on its current Node.
+		return true, "pod is not running"
+	}
+	if drain.Finished(pod) {
+		return true, "drain finished"
+	}
+	return false, ""
+}
+
+// tooManyRequests returns a denial that the eviction API passes back to the
+// caller as a 429, which eviction clients treat as retryable.
+func tooManyRequests(message string) admission.Response {
+	resp := admission.Denied(message)
+	resp.Result.Code = http.StatusTooManyRequests
+	resp.Result.Reason = metav1.StatusReasonTooManyRequests
+	return resp
+}
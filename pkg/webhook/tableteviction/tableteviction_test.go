@@ -0,0 +1,157 @@
+/*
+Copyright 2019 PlanetScale Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tableteviction
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	planetscalev2 "planetscale.dev/vitess-operator/pkg/apis/planetscale/v2"
+	"planetscale.dev/vitess-operator/pkg/operator/drain"
+)
+
+func tabletPod(annotations map[string]string) *corev1.Pod {
+	ann := map[string]string{
+		drain.SupportedAnnotation: "",
+	}
+	for k, v := range annotations {
+		ann[k] = v
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "tablet",
+			Labels:      map[string]string{planetscalev2.ComponentLabel: planetscalev2.VttabletComponentName},
+			Annotations: ann,
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func evictionRequest(dryRun bool) admission.Request {
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Namespace:   "ns",
+		Name:        "tablet",
+		Resource:    metav1.GroupVersionResource{Version: "v1", Resource: "pods"},
+		SubResource: "eviction",
+		Operation:   admissionv1.Create,
+		DryRun:      ptr.To(dryRun),
+	}}
+}
+
+func TestHandle(t *testing.T) {
+	tests := map[string]struct {
+		pod          *corev1.Pod
+		dryRun       bool
+		wantAllowed  bool
+		wantStarted  bool
+		wantNotFound bool
+	}{
+		"starts drain and refuses eviction": {
+			pod:         tabletPod(nil),
+			wantAllowed: false,
+			wantStarted: true,
+		},
+		"dry run does not start drain": {
+			pod:         tabletPod(nil),
+			dryRun:      true,
+			wantAllowed: false,
+			wantStarted: false,
+		},
+		"refuses eviction while drain is in progress": {
+			pod:         tabletPod(map[string]string{drain.StartedAnnotation: "", drain.AcknowledgedAnnotation: ""}),
+			wantAllowed: false,
+			wantStarted: true,
+		},
+		"allows eviction once drain is finished": {
+			pod:         tabletPod(map[string]string{drain.StartedAnnotation: "", drain.FinishedAnnotation: ""}),
+			wantAllowed: true,
+			wantStarted: true,
+		},
+		"allows eviction of pods without drain support": {
+			pod: func() *corev1.Pod {
+				pod := tabletPod(nil)
+				delete(pod.Annotations, drain.SupportedAnnotation)
+				return pod
+			}(),
+			wantAllowed: true,
+		},
+		"allows eviction of non-tablet pods": {
+			pod: func() *corev1.Pod {
+				pod := tabletPod(nil)
+				pod.Labels[planetscalev2.ComponentLabel] = planetscalev2.VtgateComponentName
+				return pod
+			}(),
+			wantAllowed: true,
+		},
+		"allows eviction of pods that aren't running": {
+			pod: func() *corev1.Pod {
+				pod := tabletPod(nil)
+				pod.Status.Phase = corev1.PodPending
+				return pod
+			}(),
+			wantAllowed: true,
+		},
+		"allows eviction of missing pods": {
+			wantAllowed:  true,
+			wantNotFound: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			builder := fake.NewClientBuilder()
+			if test.pod != nil {
+				builder = builder.WithObjects(test.pod)
+			}
+			h := &handler{
+				client:   builder.Build(),
+				recorder: record.NewFakeRecorder(10),
+			}
+
+			resp := h.Handle(ctx, evictionRequest(test.dryRun))
+			if resp.Allowed != test.wantAllowed {
+				t.Fatalf("Allowed = %v, want %v (result: %v)", resp.Allowed, test.wantAllowed, resp.Result)
+			}
+			if !resp.Allowed && resp.Result.Code != http.StatusTooManyRequests {
+				t.Errorf("Result.Code = %v, want %v", resp.Result.Code, http.StatusTooManyRequests)
+			}
+			if test.wantNotFound {
+				return
+			}
+
+			pod := &corev1.Pod{}
+			if err := h.client.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "tablet"}, pod); err != nil {
+				t.Fatalf("Get() error: %v", err)
+			}
+			if got := drain.Started(pod); got != test.wantStarted {
+				t.Errorf("drain.Started() = %v, want %v", got, test.wantStarted)
+			}
+		})
+	}
+}